@@ -1,7 +1,6 @@
 package op_batcher
 
 import (
-	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"errors"
@@ -11,6 +10,7 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -22,14 +22,17 @@ import (
 	oprpc "github.com/ethereum-optimism/optimism/op-service/rpc"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/ethereum-optimism/optimism/op-batcher/channelmgr"
+	"github.com/ethereum-optimism/optimism/op-batcher/metrics"
+	"github.com/ethereum-optimism/optimism/op-batcher/noncemgr"
 	"github.com/ethereum-optimism/optimism/op-batcher/sequencer"
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
-	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
 	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -58,7 +61,10 @@ func Main(version string) func(cliCtx *cli.Context) error {
 		l := oplog.NewLogger(cfg.LogConfig)
 		l.Info("Initializing Batch Submitter")
 
-		batchSubmitter, err := NewBatchSubmitter(cfg, l)
+		registry := opmetrics.NewRegistry()
+		m := metrics.NewMetrics(registry)
+
+		batchSubmitter, err := NewBatchSubmitter(cfg, l, m)
 		if err != nil {
 			l.Error("Unable to create Batch Submitter", "error", err)
 			return err
@@ -85,7 +91,6 @@ func Main(version string) func(cliCtx *cli.Context) error {
 			}()
 		}
 
-		registry := opmetrics.NewRegistry()
 		metricsCfg := cfg.MetricsConfig
 		if metricsCfg.Enabled {
 			l.Info("starting metrics server", "addr", metricsCfg.ListenAddr, "port", metricsCfg.ListenPort)
@@ -129,58 +134,29 @@ type BatchSubmitter struct {
 	wg    sync.WaitGroup
 	done  chan struct{}
 	log   log.Logger
+	m     metrics.Metricer
+	clock clock.Clock
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	lastSubmittedBlock eth.BlockID
 
-	ch *derive.ChannelOut
+	state      *channelmgr.ChannelManager
+	inclusions *channelmgr.InclusionTracker
+	nonces     *noncemgr.NonceManager
 }
 
 // NewBatchSubmitter initializes the BatchSubmitter, gathering any resources
 // that will be needed during operation.
-func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
+func NewBatchSubmitter(cfg Config, l log.Logger, m metrics.Metricer) (*BatchSubmitter, error) {
 	ctx := context.Background()
 
-	var err error
-	var sequencerPrivKey *ecdsa.PrivateKey
-	var addr common.Address
-
-	if cfg.PrivateKey != "" && cfg.Mnemonic != "" {
-		return nil, errors.New("cannot specify both a private key and a mnemonic")
-	}
-
-	if cfg.PrivateKey == "" {
-		// Parse wallet private key that will be used to submit L2 txs to the batch
-		// inbox address.
-		wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
-		if err != nil {
-			return nil, err
-		}
-
-		acc := accounts.Account{
-			URL: accounts.URL{
-				Path: cfg.SequencerHDPath,
-			},
-		}
-		addr, err = wallet.Address(acc)
-		if err != nil {
-			return nil, err
-		}
-
-		sequencerPrivKey, err = wallet.PrivateKey(acc)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		sequencerPrivKey, err = crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
-		if err != nil {
-			return nil, err
-		}
-
-		addr = crypto.PubkeyToAddress(sequencerPrivKey.PublicKey)
+	txSigner, err := newSigner(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
+	addr := txSigner.Address()
 
 	batchInboxAddress, err := parseAddress(cfg.SequencerBatchInboxAddress)
 	if err != nil {
@@ -223,6 +199,7 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 		ReceiptQueryInterval:      time.Second,
 		NumConfirmations:          cfg.NumConfirmations,
 		SafeAbortNonceTooLowCount: cfg.SafeAbortNonceTooLowCount,
+		Metrics:                   m,
 	}
 
 	batcherCfg := sequencer.Config{
@@ -236,7 +213,7 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 		BatchInboxAddress: batchInboxAddress,
 		ChannelTimeout:    cfg.ChannelTimeout,
 		ChainID:           chainID,
-		PrivKey:           sequencerPrivKey,
+		Signer:            txSigner,
 		PollInterval:      cfg.PollInterval,
 	}
 
@@ -247,6 +224,16 @@ func NewBatchSubmitter(cfg Config, l log.Logger) (*BatchSubmitter, error) {
 		txMgr: txmgr.NewSimpleTxManager("batcher", txManagerConfig, l1Client),
 		done:  make(chan struct{}),
 		log:   l,
+		m:     m,
+		clock: clock.SystemClock,
+		state: channelmgr.NewChannelManager(l, channelmgr.ChannelConfig{
+			MaxL1OriginAge:  cfg.MaxL1OriginAge,
+			TargetFrameSize: cfg.TargetFrameSize,
+			TargetNumFrames: cfg.TargetNumFrames,
+		}),
+		inclusions: channelmgr.NewInclusionTracker(l),
+
+		nonces: noncemgr.NewNonceManager(l, l1Client, addr),
 		// TODO: this context only exists because the even loop doesn't reach done
 		// if the tx manager is blocking forever due to e.g. insufficient balance.
 		ctx:    ctx,
@@ -264,17 +251,20 @@ func (l *BatchSubmitter) Stop() {
 	l.cancel()
 	close(l.done)
 	l.wg.Wait()
+	if err := l.cfg.Signer.Close(); err != nil {
+		l.log.Error("failed to close signer", "err", err)
+	}
 }
 
 func (l *BatchSubmitter) loop() {
 	defer l.wg.Done()
 
-	ticker := time.NewTicker(l.cfg.PollInterval)
+	ticker := l.clock.NewTicker(l.cfg.PollInterval)
 	defer ticker.Stop()
 mainLoop:
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.Ch():
 			// Do the simplest thing of one channel per range of blocks since the iteration of this loop.
 			// The channel is closed at the end of this loop (to avoid lifecycle management of the channel).
 			ctx, cancel := context.WithTimeout(l.ctx, time.Second*10)
@@ -288,7 +278,25 @@ mainLoop:
 				l.log.Info("Rollup node has no L1 head info yet")
 				continue
 			}
+			if err := l.recoverNonces(); err != nil {
+				l.log.Warn("failed to recover from nonce gap", "err", err)
+				continue
+			}
 			l.log.Info("Got new L2 sync status", "safe_head", syncStatus.SafeL2, "unsafe_head", syncStatus.UnsafeL2, "last_submitted", l.lastSubmittedBlock, "l1_head", syncStatus.HeadL1)
+			// Submit the blocks of channels again if an L1 reorg removed their batch txs,
+			// as the txs may not be included again in time for the channels to be derived from.
+			l.inclusions.Prune(syncStatus.SafeL2.ID())
+			ctx, cancel = context.WithTimeout(l.ctx, time.Second*10)
+			parent, reorged, err := l.inclusions.CheckReorg(ctx, l.cfg.L1Client)
+			cancel()
+			if err != nil {
+				l.log.Warn("failed to check L1 inclusion of batch txs", "err", err)
+				continue
+			} else if reorged {
+				l.log.Warn("L1 reorg removed a batch tx, submitting the channel again", "parent", parent, "last_submitted", l.lastSubmittedBlock)
+				l.m.RecordChannelReorged()
+				l.lastSubmittedBlock = parent
+			}
 			if syncStatus.SafeL2.Number >= syncStatus.UnsafeL2.Number {
 				l.log.Trace("No unsubmitted blocks from sequencer")
 				continue
@@ -303,13 +311,7 @@ mainLoop:
 				l.log.Warn("last submitted block lagged behind L2 safe head: batch submission will continue from the safe head now", "last", l.lastSubmittedBlock, "safe", syncStatus.SafeL2)
 				l.lastSubmittedBlock = syncStatus.SafeL2.ID()
 			}
-			if ch, err := derive.NewChannelOut(syncStatus.HeadL1.Time); err != nil {
-				l.log.Error("Error creating channel", "err", err)
-				continue
-			} else {
-				l.ch = ch
-			}
-			prevID := l.lastSubmittedBlock
+			l.state.Reset(l.lastSubmittedBlock)
 			for i := l.lastSubmittedBlock.Number + 1; i <= syncStatus.UnsafeL2.Number; i++ {
 				ctx, cancel := context.WithTimeout(l.ctx, time.Second*10)
 				block, err := l.cfg.L2Client.BlockByNumber(ctx, new(big.Int).SetUint64(i))
@@ -318,40 +320,38 @@ mainLoop:
 					l.log.Error("issue fetching L2 block", "err", err)
 					continue mainLoop
 				}
-				if block.ParentHash() != prevID.Hash {
-					l.log.Error("detected a reorg in L2 chain vs previous submitted information, resetting to safe head now", "safe_head", syncStatus.SafeL2)
+				if err := l.state.AddL2Block(block, syncStatus.HeadL1); errors.Is(err, channelmgr.ErrReorg) {
+					l.log.Error("detected a reorg in L2 chain vs previous submitted information, resetting to safe head now", "safe_head", syncStatus.SafeL2, "err", err)
 					l.lastSubmittedBlock = syncStatus.SafeL2.ID()
 					continue mainLoop
-				}
-				if err := l.ch.AddBlock(block); err != nil {
-					l.log.Error("issue adding L2 Block to the channel", "err", err, "channel_id", l.ch.ID())
+				} else if errors.Is(err, channelmgr.ErrL1OriginTooOld) {
+					// The sequencing window of this block would likely expire before the batch lands on L1.
+					// Submit the blocks before it, and leave it to the sequencer to reorg the unsafe chain.
+					l.log.Warn("not batching unsafe L2 block with too old L1 origin", "block", i, "l1_head", syncStatus.HeadL1, "err", err)
+					l.m.RecordL1OriginTooOld()
+					break
+				} else if err != nil {
+					l.log.Error("issue adding L2 block", "err", err)
 					continue mainLoop
 				}
-				prevID = eth.BlockID{Hash: block.Hash(), Number: block.NumberU64()}
-				l.log.Info("added L2 block to channel", "block", prevID, "channel_id", l.ch.ID(), "tx_count", len(block.Transactions()), "time", block.Time())
 			}
-			if err := l.ch.Close(); err != nil {
-				l.log.Error("issue getting adding L2 Block", "err", err)
+			if l.state.PendingBlocks() == 0 {
 				continue
 			}
 			// Hand role do-while loop to fully pull all frames out of the channel
 			for {
 				// Collect the output frame
-				data := new(bytes.Buffer)
-				data.WriteByte(derive.DerivationVersion0)
-				done := false
-				// subtract one, to account for the version byte
-				if err := l.ch.OutputFrame(data, l.cfg.MaxL1TxSize-1); err == io.EOF {
-					done = true
+				data, err := l.state.TxData(syncStatus.HeadL1, l.cfg.MaxL1TxSize)
+				if err == io.EOF {
+					break // local do-while loop
 				} else if err != nil {
 					l.log.Error("error outputting frame", "err", err)
 					continue mainLoop
 				}
 
-				// Query for the submitter's current nonce.
-				walletAddr := crypto.PubkeyToAddress(l.cfg.PrivKey.PublicKey)
+				// Assign the nonce of the tx
 				ctx, cancel = context.WithTimeout(l.ctx, time.Second*10)
-				nonce, err := l.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+				nonce, err := l.nonces.Next(ctx)
 				cancel()
 				if err != nil {
 					l.log.Error("unable to get current nonce", "err", err)
@@ -360,12 +360,14 @@ mainLoop:
 
 				// Create the transaction
 				ctx, cancel = context.WithTimeout(l.ctx, time.Second*10)
-				tx, err := l.CraftTx(ctx, data.Bytes(), nonce)
+				tx, err := l.CraftTx(ctx, data, nonce)
 				cancel()
 				if err != nil {
 					l.log.Error("unable to craft tx", "err", err)
+					l.nonces.Reset()
 					continue mainLoop
 				}
+				l.nonces.Sent(tx)
 
 				// Construct the a closure that will update the txn with the current gas prices.
 				updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
@@ -382,16 +384,17 @@ mainLoop:
 				cancel()
 				if err != nil {
 					l.log.Warn("unable to publish tx", "err", err)
+					// the tx may still be included, or leave a nonce gap, to be recovered from on the next iteration
 					continue mainLoop
 				}
 
 				// The transaction was successfully submitted.
-				l.log.Info("tx successfully published", "tx_hash", receipt.TxHash, "channel_id", l.ch.ID())
-
-				// If `ch.OutputFrame` returned io.EOF we don't need to submit any more frames for this channel.
-				if done {
-					break // local do-while loop
-				}
+				cost := channelmgr.EstimateDataCost(data)
+				l.m.RecordBatchTxData(cost)
+				l.log.Info("tx successfully published", "tx_hash", receipt.TxHash, "channel_id", l.state.ChannelID(),
+					"data_size", cost.Size(), "intrinsic_gas", cost.IntrinsicGas)
+				l.inclusions.Included(l.state.ChannelID(), l.state.ChannelParent(), l.state.ChannelTip(),
+					eth.BlockID{Hash: receipt.BlockHash, Number: receipt.BlockNumber.Uint64()})
 			}
 			// TODO: if we exit to the mainLoop early on an error,
 			// it would be nice if we can determine which blocks are still readable from the partially submitted data.
@@ -399,7 +402,7 @@ mainLoop:
 			// and then take the block hash (if we remember which blocks we put in the channel)
 			//
 			// Now we just continue batch submission from the end of the channel.
-			l.lastSubmittedBlock = prevID
+			l.lastSubmittedBlock = l.state.ChannelTip()
 
 		case <-l.done:
 			return
@@ -407,6 +410,67 @@ mainLoop:
 	}
 }
 
+// recoverNonces fills the nonce gaps of the batcher account, if any, before new batch txs are submitted:
+// sent txs that are no longer included or in the tx pool are resubmitted, and unknown nonces are cancelled.
+// Txs that are stuck in the tx pool are resubmitted with bumped fees, to replace them.
+// The nonce manager is reset if the recovery fails, to continue from the nonces of the L1 chain.
+func (l *BatchSubmitter) recoverNonces() error {
+	ctx, cancel := context.WithTimeout(l.ctx, time.Second*10)
+	r, err := l.nonces.Check(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	txs := append([]*types.Transaction{}, r.Resubmit...)
+	for _, nonce := range r.Cancel {
+		ctx, cancel := context.WithTimeout(l.ctx, time.Second*10)
+		tx, err := l.CraftCancelTx(ctx, nonce)
+		cancel()
+		if err != nil {
+			l.nonces.Reset()
+			return fmt.Errorf("failed to craft cancel tx of nonce %d: %w", nonce, err)
+		}
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+	for _, tx := range txs {
+		tx := tx
+		l.log.Info("resubmitting tx to fill nonce gap", "nonce", tx.Nonce(), "to", tx.To())
+		updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+			if r.Stuck {
+				return l.BumpGasPrice(ctx, tx)
+			}
+			return l.UpdateGasPrice(ctx, tx)
+		}
+		ctx, cancel := context.WithTimeout(l.ctx, time.Second*time.Duration(l.cfg.ChannelTimeout))
+		_, err := l.txMgr.Send(ctx, updateGasPrice, l.cfg.L1Client.SendTransaction)
+		cancel()
+		if err != nil {
+			l.nonces.Reset()
+			return fmt.Errorf("failed to resubmit tx of nonce %d: %w", tx.Nonce(), err)
+		}
+		l.nonces.Sent(tx)
+	}
+	return nil
+}
+
+// CraftCancelTx creates a signed tx that fills the nonce without any effect, see noncemgr.CancelTx.
+//
+// NOTE: This method SHOULD NOT publish the resulting transaction.
+func (l *BatchSubmitter) CraftCancelTx(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	gasTipCap, err := l.cfg.L1Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := l.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := txmgr.CalcGasFeeCap(head.BaseFee, gasTipCap)
+	tx := noncemgr.CancelTx(l.cfg.ChainID, l.cfg.Signer.Address(), nonce, gasTipCap, gasFeeCap)
+	return l.cfg.Signer.SignTx(ctx, l.cfg.ChainID, tx)
+}
+
 // NOTE: This method SHOULD NOT publish the resulting transaction.
 func (l *BatchSubmitter) CraftTx(ctx context.Context, data []byte, nonce uint64) (*types.Transaction, error) {
 	gasTipCap, err := l.cfg.L1Client.SuggestGasTipCap(ctx)
@@ -429,15 +493,11 @@ func (l *BatchSubmitter) CraftTx(ctx context.Context, data []byte, nonce uint64)
 		GasFeeCap: gasFeeCap,
 		Data:      data,
 	}
-	l.log.Debug("creating tx", "to", rawTx.To, "from", crypto.PubkeyToAddress(l.cfg.PrivKey.PublicKey))
+	l.log.Debug("creating tx", "to", rawTx.To, "from", l.cfg.Signer.Address())
 
-	gas, err := core.IntrinsicGas(rawTx.Data, nil, false, true, true)
-	if err != nil {
-		return nil, err
-	}
-	rawTx.Gas = gas
+	rawTx.Gas = channelmgr.EstimateDataCost(rawTx.Data).IntrinsicGas
 
-	return types.SignNewTx(l.cfg.PrivKey, types.LatestSignerForChainID(l.cfg.ChainID), rawTx)
+	return l.cfg.Signer.SignTx(ctx, l.cfg.ChainID, types.NewTx(rawTx))
 }
 
 // UpdateGasPrice signs an otherwise identical txn to the one provided but with
@@ -467,7 +527,43 @@ func (l *BatchSubmitter) UpdateGasPrice(ctx context.Context, tx *types.Transacti
 		Data:      tx.Data(),
 	}
 
-	return types.SignNewTx(l.cfg.PrivKey, types.LatestSignerForChainID(l.cfg.ChainID), rawTx)
+	return l.cfg.Signer.SignTx(ctx, l.cfg.ChainID, types.NewTx(rawTx))
+}
+
+// BumpGasPrice is like UpdateGasPrice, but the gas prices are at least as high as needed to replace the
+// provided tx in the tx pool: the tx pool only accepts replacements that increase the fees by 10%.
+//
+// NOTE: This method SHOULD NOT publish the resulting transaction.
+func (l *BatchSubmitter) BumpGasPrice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	updated, err := l.UpdateGasPrice(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap := bumpFee(tx.GasTipCap(), updated.GasTipCap())
+	gasFeeCap := bumpFee(tx.GasFeeCap(), updated.GasFeeCap())
+	if gasFeeCap.Cmp(gasTipCap) < 0 {
+		gasFeeCap = gasTipCap
+	}
+	rawTx := &types.DynamicFeeTx{
+		ChainID:   l.cfg.ChainID,
+		Nonce:     tx.Nonce(),
+		To:        tx.To(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       tx.Gas(),
+		Data:      tx.Data(),
+	}
+	return l.cfg.Signer.SignTx(ctx, l.cfg.ChainID, types.NewTx(rawTx))
+}
+
+// bumpFee returns the suggested fee, or the old fee increased by more than 10% if that is higher.
+func bumpFee(old *big.Int, suggested *big.Int) *big.Int {
+	bumped := new(big.Int).Add(old, new(big.Int).Div(old, big.NewInt(10)))
+	bumped.Add(bumped, big.NewInt(1))
+	if suggested.Cmp(bumped) > 0 {
+		return suggested
+	}
+	return bumped
 }
 
 // SendTransaction injects a signed transaction into the pending pool for
@@ -476,6 +572,52 @@ func (l *BatchSubmitter) SendTransaction(ctx context.Context, tx *types.Transact
 	return l.cfg.L1Client.SendTransaction(ctx, tx)
 }
 
+// newSigner creates the signer of the batch transactions: a remote signer if a signer endpoint is configured,
+// or else a local signer of the configured private key or mnemonic.
+func newSigner(ctx context.Context, cfg Config) (signer.Signer, error) {
+	if cfg.SignerEndpoint != "" {
+		if cfg.PrivateKey != "" || cfg.Mnemonic != "" {
+			return nil, errors.New("cannot specify a private key or a mnemonic with a remote signer")
+		}
+		ctxt, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+		defer cancel()
+		return signer.DialRemoteSigner(ctxt, cfg.SignerEndpoint)
+	}
+
+	var err error
+	var sequencerPrivKey *ecdsa.PrivateKey
+
+	if cfg.PrivateKey != "" && cfg.Mnemonic != "" {
+		return nil, errors.New("cannot specify both a private key and a mnemonic")
+	}
+
+	if cfg.PrivateKey == "" {
+		// Parse wallet private key that will be used to submit L2 txs to the batch
+		// inbox address.
+		wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
+		if err != nil {
+			return nil, err
+		}
+
+		acc := accounts.Account{
+			URL: accounts.URL{
+				Path: cfg.SequencerHDPath,
+			},
+		}
+		sequencerPrivKey, err = wallet.PrivateKey(acc)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sequencerPrivKey, err = crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return signer.NewLocalSigner(sequencerPrivKey), nil
+}
+
 // dialEthClientWithTimeout attempts to dial the L1 provider using the provided
 // URL. If the dial doesn't complete within defaultDialTimeout seconds, this
 // method will return an error.
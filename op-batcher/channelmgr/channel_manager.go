@@ -0,0 +1,242 @@
+package channelmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// ErrReorg is returned by AddL2Block if the block does not extend the previously added block.
+	ErrReorg = errors.New("block does not extend existing chain")
+	// ErrL1OriginTooOld is returned by AddL2Block if the L1 origin of the block is too old relative to the L1 head.
+	ErrL1OriginTooOld = errors.New("block L1 origin is too old")
+)
+
+// ChannelConfig configures the channels built by the ChannelManager.
+type ChannelConfig struct {
+	// MaxL1OriginAge is the maximum number of L1 blocks between the L1 origin of an added block and the L1 head.
+	// Unlimited if 0.
+	MaxL1OriginAge uint64
+	// TargetFrameSize is the tx data size to output frames with, including the version byte.
+	// The max size passed to TxData is used if 0, or if it is smaller.
+	TargetFrameSize uint64
+	// TargetNumFrames is the number of frames to fill a channel with: once the compressed blocks
+	// in a channel fill this many frames of the frame size, the channel is closed,
+	// and the remaining blocks are added to the next channel. The channel takes all buffered blocks if 0.
+	TargetNumFrames uint64
+}
+
+// ChannelManager buffers L2 blocks, and packs them into channels of frames, ready to be submitted to L1.
+//
+// The manager does not fetch blocks or submit transactions itself:
+// the op-batcher service and the batcher of the action tests both drive it,
+// to share the channel construction logic.
+type ChannelManager struct {
+	log log.Logger
+	cfg ChannelConfig
+
+	// Blocks that have not been added to a channel yet, in order.
+	blocks []*types.Block
+	// ID of the last block that was added to the manager, or the block it was reset to.
+	// Zeroed if any next block is accepted.
+	tip eth.BlockID
+
+	// The current channel that frames are being output from. Nil if there is no open channel.
+	ch *derive.ChannelOut
+	// A previous channel that is done, kept to reuse its buffers for the next channel. Nil if there is none.
+	spare *derive.ChannelOut
+	// The block that the first block of the current channel builds on.
+	chParent eth.BlockID
+	// The last block that was added to the current channel.
+	chTip eth.BlockID
+	// True when the current channel has no more frames to output.
+	chDone bool
+}
+
+func NewChannelManager(log log.Logger, cfg ChannelConfig) *ChannelManager {
+	return &ChannelManager{log: log, cfg: cfg}
+}
+
+// Reset drops all buffered blocks and the current channel, and continues from the given tip,
+// e.g. the safe head after a L2 reorg. The next added block must build on the tip, unless the tip is zeroed.
+func (s *ChannelManager) Reset(tip eth.BlockID) {
+	s.log.Trace("resetting channel manager state", "tip", tip)
+	s.blocks = s.blocks[:0]
+	s.tip = tip
+	if s.ch != nil {
+		s.spare = s.ch
+	}
+	s.ch = nil
+	s.chParent = eth.BlockID{}
+	s.chTip = eth.BlockID{}
+	s.chDone = false
+}
+
+// AddL2Block buffers the block, to be added to the next channel.
+// It returns ErrReorg if the block does not build on the previously added block,
+// in which case the caller should Reset the manager and restart from a known good point.
+//
+// It returns ErrL1OriginTooOld if the L1 origin of the block is too old relative to the given L1 head:
+// the sequencing window of the block would likely expire before the batch is included on L1,
+// and the block would be dropped by the verifiers anyway. The block is not buffered.
+func (s *ChannelManager) AddL2Block(block *types.Block, l1Head eth.L1BlockRef) error {
+	if s.tip != (eth.BlockID{}) && s.tip.Hash != block.ParentHash() {
+		return fmt.Errorf("%w: block %s has parent %s, expected %s", ErrReorg, block.Hash(), block.ParentHash(), s.tip)
+	}
+	if s.cfg.MaxL1OriginAge > 0 {
+		origin, err := l1Origin(block)
+		if err != nil {
+			return err
+		}
+		if l1Head.Number > origin.Number && l1Head.Number-origin.Number > s.cfg.MaxL1OriginAge {
+			return fmt.Errorf("%w: block %s has L1 origin %d, L1 head is %d, max age %d",
+				ErrL1OriginTooOld, block.Hash(), origin.Number, l1Head.Number, s.cfg.MaxL1OriginAge)
+		}
+	}
+	s.blocks = append(s.blocks, block)
+	s.tip = eth.BlockID{Hash: block.Hash(), Number: block.NumberU64()}
+	return nil
+}
+
+// l1Origin decodes the L1 origin of the block from its L1 info deposit, the first transaction of the block.
+func l1Origin(block *types.Block) (derive.L1BlockInfo, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 || txs[0].Type() != types.DepositTxType {
+		return derive.L1BlockInfo{}, fmt.Errorf("block %s does not start with a L1 info deposit", block.Hash())
+	}
+	info, err := derive.L1InfoDepositTxData(txs[0].Data())
+	if err != nil {
+		return derive.L1BlockInfo{}, fmt.Errorf("failed to decode L1 info deposit of block %s: %w", block.Hash(), err)
+	}
+	return info, nil
+}
+
+// PendingBlocks returns the number of buffered blocks that are not yet in a channel.
+func (s *ChannelManager) PendingBlocks() int {
+	return len(s.blocks)
+}
+
+// HasOpenChannel returns true if there is a channel with frames left to output.
+func (s *ChannelManager) HasOpenChannel() bool {
+	return s.ch != nil && !s.chDone
+}
+
+// ChannelID returns the ID of the current channel, or a zeroed ID if there is no channel.
+func (s *ChannelManager) ChannelID() derive.ChannelID {
+	if s.ch == nil {
+		return derive.ChannelID{}
+	}
+	return s.ch.ID()
+}
+
+// ChannelParent returns the block that the first block of the current channel builds on, if any.
+func (s *ChannelManager) ChannelParent() eth.BlockID {
+	return s.chParent
+}
+
+// ChannelTip returns the last block that was added to the current channel, if any.
+func (s *ChannelManager) ChannelTip() eth.BlockID {
+	return s.chTip
+}
+
+// txDataOverhead is the size of the tx data of a frame besides the frame data: the version byte,
+// and the frame overhead plus the extra byte that ChannelOut.OutputFrame reserves.
+const txDataOverhead = 1 + derive.FrameV0OverHeadSize + 1
+
+// TxData returns the next frame, prefixed with the derivation version byte, to submit to L1 as tx data.
+// The returned data does not exceed maxSize bytes.
+//
+// If there is no open channel, the buffered blocks are added to a new channel, up to the target number of frames,
+// and the channel is closed immediately, with the channel time set to the L1 head time.
+// It returns io.EOF if there is no data to submit.
+func (s *ChannelManager) TxData(l1Head eth.L1BlockRef, maxSize uint64) ([]byte, error) {
+	if s.cfg.TargetFrameSize > 0 && s.cfg.TargetFrameSize < maxSize {
+		maxSize = s.cfg.TargetFrameSize
+	}
+	if maxSize <= txDataOverhead {
+		return nil, fmt.Errorf("max tx data size %d is too small to fit a frame", maxSize)
+	}
+	if !s.HasOpenChannel() {
+		if len(s.blocks) == 0 {
+			return nil, io.EOF
+		}
+		if err := s.openChannel(l1Head, maxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	data := new(bytes.Buffer)
+	data.WriteByte(derive.DerivationVersion0)
+	// subtract one, to account for the version byte
+	if err := s.ch.OutputFrame(data, maxSize-1); err == io.EOF {
+		s.chDone = true
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to output frame of channel %s: %w", s.ch.ID(), err)
+	}
+	return data.Bytes(), nil
+}
+
+// newChannel resets the previous channel for reuse if it is done, or creates a new channel otherwise.
+func (s *ChannelManager) newChannel(channelTime uint64) (*derive.ChannelOut, error) {
+	ch := s.spare
+	if s.ch != nil && s.chDone {
+		ch = s.ch
+	}
+	s.spare = nil
+	if ch == nil {
+		return derive.NewChannelOut(channelTime)
+	}
+	if err := ch.Reset(channelTime); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// openChannel adds the buffered blocks to a new channel, until the channel fills the target number of frames
+// of the given tx data size, and closes the channel.
+func (s *ChannelManager) openChannel(l1Head eth.L1BlockRef, frameSize uint64) error {
+	ch, err := s.newChannel(l1Head.Time)
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+	// The compression stage is flushed after every block to measure the compressed size of the channel,
+	// at a small cost in compression ratio. The last block may make the channel exceed the target.
+	targetSize := (frameSize - txDataOverhead) * s.cfg.TargetNumFrames
+	var tip eth.BlockID
+	added := 0
+	for _, block := range s.blocks {
+		if err := ch.AddBlock(block); err != nil {
+			return fmt.Errorf("failed to add L2 block %s to channel %s: %w", block.Hash(), ch.ID(), err)
+		}
+		added += 1
+		tip = eth.BlockID{Hash: block.Hash(), Number: block.NumberU64()}
+		s.log.Info("added L2 block to channel", "block", tip, "channel_id", ch.ID(), "tx_count", len(block.Transactions()), "time", block.Time())
+		if targetSize == 0 {
+			continue
+		}
+		if err := ch.Flush(); err != nil {
+			return fmt.Errorf("failed to flush channel %s: %w", ch.ID(), err)
+		}
+		if uint64(ch.ReadyBytes()) >= targetSize {
+			s.log.Info("channel reached target size", "channel_id", ch.ID(), "target_frames", s.cfg.TargetNumFrames, "frame_size", frameSize)
+			break
+		}
+	}
+	if err := ch.Close(); err != nil {
+		return fmt.Errorf("failed to close channel %s: %w", ch.ID(), err)
+	}
+	first := s.blocks[0]
+	s.blocks = append(s.blocks[:0], s.blocks[added:]...)
+	s.ch = ch
+	s.chParent = eth.BlockID{Hash: first.ParentHash(), Number: first.NumberU64() - 1}
+	s.chTip = tip
+	s.chDone = false
+	return nil
+}
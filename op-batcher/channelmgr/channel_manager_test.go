@@ -0,0 +1,193 @@
+package channelmgr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func newBlock(parent common.Hash, num uint64) *types.Block {
+	return newBlockWithOrigin(parent, num, rand.New(rand.NewSource(int64(num))).Uint64())
+}
+
+func newBlockWithOrigin(parent common.Hash, num uint64, origin uint64) *types.Block {
+	rng := rand.New(rand.NewSource(int64(num)))
+	info := testutils.RandomBlockInfo(rng)
+	info.InfoNum = origin
+	l1InfoTx, err := derive.L1InfoDeposit(0, info)
+	if err != nil {
+		panic(err)
+	}
+	header := &types.Header{
+		ParentHash: parent,
+		Number:     new(big.Int).SetUint64(num),
+		Time:       1000 + num*2,
+	}
+	return types.NewBlock(header, []*types.Transaction{types.NewTx(l1InfoTx)}, nil, nil, trie.NewStackTrie(nil))
+}
+
+func TestChannelManagerTxData(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{})
+	l1Head := eth.L1BlockRef{Number: 10, Time: 1000}
+
+	_, err := m.TxData(l1Head, 1000)
+	require.ErrorIs(t, err, io.EOF, "no data without blocks")
+
+	a := newBlock(common.Hash{0xaa}, 1)
+	b := newBlock(a.Hash(), 2)
+	require.NoError(t, m.AddL2Block(a, eth.L1BlockRef{}))
+	require.NoError(t, m.AddL2Block(b, eth.L1BlockRef{}))
+	require.Equal(t, 2, m.PendingBlocks())
+
+	var frames [][]byte
+	for {
+		data, err := m.TxData(l1Head, 100)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(data), 100)
+		require.Equal(t, byte(derive.DerivationVersion0), data[0])
+		frames = append(frames, data)
+	}
+	require.NotEmpty(t, frames)
+	require.Equal(t, 0, m.PendingBlocks())
+	require.False(t, m.HasOpenChannel())
+	require.Equal(t, eth.BlockID{Hash: b.Hash(), Number: 2}, m.ChannelTip())
+}
+
+func TestChannelManagerReorg(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{})
+	safe := eth.BlockID{Hash: common.Hash{0xaa}, Number: 0}
+	m.Reset(safe)
+
+	require.ErrorIs(t, m.AddL2Block(newBlock(common.Hash{0xbb}, 1), eth.L1BlockRef{}), ErrReorg, "must build on tip")
+
+	a := newBlock(safe.Hash, 1)
+	require.NoError(t, m.AddL2Block(a, eth.L1BlockRef{}))
+	require.ErrorIs(t, m.AddL2Block(newBlock(common.Hash{0xcc}, 2), eth.L1BlockRef{}), ErrReorg)
+	require.Equal(t, 1, m.PendingBlocks(), "reorged block is not buffered")
+
+	m.Reset(safe)
+	require.Equal(t, 0, m.PendingBlocks())
+	require.False(t, m.HasOpenChannel())
+	require.NoError(t, m.AddL2Block(a, eth.L1BlockRef{}))
+}
+
+func TestChannelManagerMaxSize(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{})
+	require.NoError(t, m.AddL2Block(newBlock(common.Hash{}, 1), eth.L1BlockRef{}))
+	_, err := m.TxData(eth.L1BlockRef{}, 1)
+	require.Error(t, err)
+}
+
+func TestChannelManagerL1OriginAge(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{MaxL1OriginAge: 5})
+	l1Head := eth.L1BlockRef{Number: 20}
+
+	a := newBlockWithOrigin(common.Hash{0xaa}, 1, 15)
+	require.NoError(t, m.AddL2Block(a, l1Head), "origin within max age")
+	b := newBlockWithOrigin(a.Hash(), 2, 14)
+	require.ErrorIs(t, m.AddL2Block(b, l1Head), ErrL1OriginTooOld)
+	require.Equal(t, 1, m.PendingBlocks(), "too old block is not buffered")
+
+	require.NoError(t, m.AddL2Block(b, eth.L1BlockRef{Number: 19}), "accepted once within max age of the L1 head")
+	require.Equal(t, 2, m.PendingBlocks())
+
+	require.Error(t, m.AddL2Block(types.NewBlockWithHeader(&types.Header{ParentHash: b.Hash(), Number: big.NewInt(3)}), l1Head),
+		"block without L1 info deposit")
+}
+
+func TestChannelManagerTargetFrames(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{TargetFrameSize: 120, TargetNumFrames: 6})
+	rng := rand.New(rand.NewSource(1234))
+	parent := common.Hash{0xaa}
+	var blocks []*types.Block
+	for i := uint64(1); i <= 6; i++ {
+		// random tx data, so the blocks do not compress
+		data := make([]byte, 150)
+		rng.Read(data)
+		tx := types.NewTransaction(i, common.Address{0x1}, big.NewInt(0), 100_000, big.NewInt(1), data)
+		b := newBlock(parent, i)
+		b = types.NewBlock(b.Header(), append(b.Transactions(), tx), nil, nil, trie.NewStackTrie(nil))
+		require.NoError(t, m.AddL2Block(b, eth.L1BlockRef{}))
+		blocks = append(blocks, b)
+		parent = b.Hash()
+	}
+
+	channels := make(map[derive.ChannelID]int)
+	var tips []eth.BlockID
+	for {
+		data, err := m.TxData(eth.L1BlockRef{Time: 1000}, 1000)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(data), 120, "frames are cut at the target frame size")
+		if _, ok := channels[m.ChannelID()]; !ok {
+			tips = append(tips, m.ChannelTip())
+		}
+		channels[m.ChannelID()] += 1
+	}
+	require.Len(t, channels, 3, "a channel is closed once it fills the target frames")
+	for i, tip := range tips {
+		b := blocks[2*i+1]
+		require.Equal(t, eth.BlockID{Hash: b.Hash(), Number: b.NumberU64()}, tip, "each channel holds two blocks")
+	}
+	require.Equal(t, 0, m.PendingBlocks())
+
+	_, err := m.TxData(eth.L1BlockRef{Time: 1000}, txDataOverhead)
+	require.ErrorContains(t, err, "too small to fit a frame")
+}
+
+func TestChannelManagerReuseChannel(t *testing.T) {
+	m := NewChannelManager(testlog.Logger(t, log.LvlError), ChannelConfig{})
+	l1Head := eth.L1BlockRef{Number: 10, Time: 1000}
+	parent := common.Hash{0xaa}
+	var ids []derive.ChannelID
+	for i := uint64(1); i <= 3; i++ {
+		block := newBlock(parent, i)
+		parent = block.Hash()
+		require.NoError(t, m.AddL2Block(block, eth.L1BlockRef{}))
+		var ch *derive.Channel
+		for {
+			data, err := m.TxData(l1Head, 1000)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			frames, err := derive.ParseFrames(data)
+			require.NoError(t, err)
+			for _, f := range frames {
+				if ch == nil {
+					ch = derive.NewChannel(f.ID)
+				}
+				require.Equal(t, m.ChannelID(), f.ID)
+				require.NoError(t, ch.AddFrame(f, eth.L1BlockRef{}))
+			}
+		}
+		require.True(t, ch.IsReady(), "channel %d is complete", i)
+		next, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{})
+		require.NoError(t, err)
+		batch, err := next()
+		require.NoError(t, err)
+		require.Equal(t, block.ParentHash(), batch.Batch.ParentHash, "reused channel only contains the new block")
+		_, err = next()
+		require.ErrorIs(t, err, io.EOF)
+		ids = append(ids, m.ChannelID())
+	}
+	require.NotEqual(t, ids[0], ids[1])
+	require.NotEqual(t, ids[1], ids[2])
+}
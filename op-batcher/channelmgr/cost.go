@@ -0,0 +1,51 @@
+package channelmgr
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DataCost is the estimated L1 cost of submitting data as calldata of a batch transaction.
+type DataCost struct {
+	// ZeroBytes and NonZeroBytes count the bytes of the data, which are priced differently as calldata.
+	ZeroBytes    uint64
+	NonZeroBytes uint64
+	// IntrinsicGas is the intrinsic gas of a transaction with the data:
+	// the base transaction gas, plus the calldata gas.
+	IntrinsicGas uint64
+}
+
+// EstimateDataCost estimates the L1 cost of submitting the data as calldata of a transaction
+// that is not a contract creation, priced as of the Istanbul fork (EIP-2028).
+func EstimateDataCost(data []byte) DataCost {
+	var c DataCost
+	for _, b := range data {
+		if b == 0 {
+			c.ZeroBytes++
+		} else {
+			c.NonZeroBytes++
+		}
+	}
+	c.IntrinsicGas = params.TxGas + c.ZeroBytes*params.TxDataZeroGas + c.NonZeroBytes*params.TxDataNonZeroGasEIP2028
+	return c
+}
+
+// Size returns the number of bytes of the data.
+func (c DataCost) Size() uint64 {
+	return c.ZeroBytes + c.NonZeroBytes
+}
+
+// Add returns the combined cost of the data of two transactions.
+func (c DataCost) Add(other DataCost) DataCost {
+	return DataCost{
+		ZeroBytes:    c.ZeroBytes + other.ZeroBytes,
+		NonZeroBytes: c.NonZeroBytes + other.NonZeroBytes,
+		IntrinsicGas: c.IntrinsicGas + other.IntrinsicGas,
+	}
+}
+
+// Fee returns the fee of the intrinsic gas at the given gas price, in wei.
+func (c DataCost) Fee(gasPrice *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.IntrinsicGas), gasPrice)
+}
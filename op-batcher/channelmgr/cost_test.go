@@ -0,0 +1,30 @@
+package channelmgr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateDataCost(t *testing.T) {
+	for _, data := range [][]byte{nil, {0}, {1}, {0, 1, 0, 0, 2, 3}, make([]byte, 1000)} {
+		c := EstimateDataCost(data)
+		require.Equal(t, uint64(len(data)), c.Size())
+		gas, err := core.IntrinsicGas(data, nil, false, true, true)
+		require.NoError(t, err)
+		require.Equal(t, gas, c.IntrinsicGas, "estimate must match the intrinsic gas of the L1 tx")
+	}
+
+	c := EstimateDataCost([]byte{0, 1, 0, 0, 2, 3})
+	require.Equal(t, uint64(3), c.ZeroBytes)
+	require.Equal(t, uint64(3), c.NonZeroBytes)
+	require.Equal(t, uint64(21000+3*4+3*16), c.IntrinsicGas)
+	require.Equal(t, big.NewInt(int64(c.IntrinsicGas)*7), c.Fee(big.NewInt(7)))
+
+	sum := c.Add(EstimateDataCost([]byte{0}))
+	require.Equal(t, uint64(4), sum.ZeroBytes)
+	require.Equal(t, uint64(3), sum.NonZeroBytes)
+	require.Equal(t, c.IntrinsicGas+21000+4, sum.IntrinsicGas)
+}
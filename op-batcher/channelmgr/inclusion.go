@@ -0,0 +1,96 @@
+package channelmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// HeaderSource reads the canonical L1 headers.
+// It is implemented by the go-ethereum ethclient.
+type HeaderSource interface {
+	// HeaderByNumber returns the canonical header at the given number, or ethereum.NotFound if there is none.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// submittedChannel is a channel of which batch txs were included on L1.
+type submittedChannel struct {
+	id derive.ChannelID
+	// L2 block the first block of the channel builds on
+	parent eth.BlockID
+	// last L2 block of the channel
+	tip eth.BlockID
+	// L1 blocks that included the batch txs of the channel
+	inclusions []eth.BlockID
+}
+
+// InclusionTracker tracks the L1 blocks that included the batch txs of submitted channels,
+// until the L2 blocks of the channels are safe, to detect when an L1 reorg removes a batch tx:
+// the tx may be included again, but possibly too late for the channel to be derived from,
+// so the L2 blocks of the channel have to be submitted again.
+//
+// Like the ChannelManager, the tracker does not read L1 or submit transactions itself.
+type InclusionTracker struct {
+	log log.Logger
+	// channels in submission order
+	channels []*submittedChannel
+}
+
+func NewInclusionTracker(log log.Logger) *InclusionTracker {
+	return &InclusionTracker{log: log}
+}
+
+// Included records the inclusion of a batch tx of the channel with the given L2 block range in an L1 block.
+func (t *InclusionTracker) Included(id derive.ChannelID, parent eth.BlockID, tip eth.BlockID, l1Block eth.BlockID) {
+	for _, ch := range t.channels {
+		if ch.id == id {
+			ch.inclusions = append(ch.inclusions, l1Block)
+			return
+		}
+	}
+	t.channels = append(t.channels, &submittedChannel{id: id, parent: parent, tip: tip, inclusions: []eth.BlockID{l1Block}})
+}
+
+// Tracked returns the number of tracked channels.
+func (t *InclusionTracker) Tracked() int {
+	return len(t.channels)
+}
+
+// Prune stops tracking the channels of which all L2 blocks are safe.
+func (t *InclusionTracker) Prune(safeHead eth.BlockID) {
+	i := 0
+	for ; i < len(t.channels) && t.channels[i].tip.Number <= safeHead.Number; i++ {
+	}
+	t.channels = append(t.channels[:0], t.channels[i:]...)
+}
+
+// CheckReorg checks the inclusions of the tracked channels against the canonical L1 chain.
+// If a batch tx was reorged out, the channel and all later channels are dropped,
+// and the L2 block the reorged channel builds on is returned, to continue batch submission from.
+func (t *InclusionTracker) CheckReorg(ctx context.Context, l1 HeaderSource) (eth.BlockID, bool, error) {
+	for i, ch := range t.channels {
+		for _, incl := range ch.inclusions {
+			header, err := l1.HeaderByNumber(ctx, new(big.Int).SetUint64(incl.Number))
+			if errors.Is(err, ethereum.NotFound) {
+				header = nil
+			} else if err != nil {
+				return eth.BlockID{}, false, fmt.Errorf("failed to get L1 header %d: %w", incl.Number, err)
+			}
+			if header != nil && header.Hash() == incl.Hash {
+				continue
+			}
+			t.log.Warn("batch tx was reorged out of L1", "channel_id", ch.id, "l1_block", incl, "parent", ch.parent, "tip", ch.tip)
+			t.channels = t.channels[:i]
+			return ch.parent, true, nil
+		}
+	}
+	return eth.BlockID{}, false, nil
+}
@@ -0,0 +1,64 @@
+package channelmgr
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// testL1Chain is a canonical L1 chain of headers by number.
+type testL1Chain map[uint64]*types.Header
+
+func (c testL1Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := c[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func (c testL1Chain) add(num uint64, extra byte) eth.BlockID {
+	header := &types.Header{Number: new(big.Int).SetUint64(num), Extra: []byte{extra}}
+	c[num] = header
+	return eth.BlockID{Hash: header.Hash(), Number: num}
+}
+
+func TestInclusionTracker(t *testing.T) {
+	l1 := make(testL1Chain)
+	tracker := NewInclusionTracker(testlog.Logger(t, log.LvlError))
+	l2 := func(num uint64) eth.BlockID { return eth.BlockID{Hash: common.Hash{byte(num)}, Number: num} }
+	chA, chB, chC := derive.ChannelID{Data: [32]byte{0xa}}, derive.ChannelID{Data: [32]byte{0xb}}, derive.ChannelID{Data: [32]byte{0xc}}
+
+	// channel A spans two L1 blocks, B and C are included in one
+	tracker.Included(chA, l2(0), l2(5), l1.add(100, 0))
+	tracker.Included(chA, l2(0), l2(5), l1.add(101, 0))
+	tracker.Included(chB, l2(5), l2(9), l1.add(102, 0))
+	tracker.Included(chC, l2(9), l2(12), l1.add(103, 0))
+	require.Equal(t, 3, tracker.Tracked())
+
+	_, reorged, err := tracker.CheckReorg(context.Background(), l1)
+	require.NoError(t, err)
+	require.False(t, reorged, "all inclusions are canonical")
+
+	tracker.Prune(l2(5))
+	require.Equal(t, 2, tracker.Tracked(), "channel A is safe")
+
+	// L1 reorgs out the block with channel B, and the chain is now shorter than before
+	l1.add(102, 1)
+	delete(l1, 103)
+	parent, reorged, err := tracker.CheckReorg(context.Background(), l1)
+	require.NoError(t, err)
+	require.True(t, reorged)
+	require.Equal(t, l2(5), parent, "continue submitting from the parent of channel B")
+	require.Equal(t, 0, tracker.Tracked(), "channel B and the later channel C are dropped")
+}
@@ -66,10 +66,26 @@ type Config struct {
 	// PrivateKey is the private key used to submit sequencer transactions.
 	PrivateKey string
 
+	// SignerEndpoint is the RPC endpoint of a remote signer service to sign
+	// the batch transactions with. Must not be used with PrivateKey or Mnemonic.
+	SignerEndpoint string
+
 	// SequencerBatchInboxAddress is the address in which to send batch
 	// transactions.
 	SequencerBatchInboxAddress string
 
+	// MaxL1OriginAge is the maximum number of L1 blocks the L1 origin of an unsafe L2 block
+	// may be behind the L1 head for the block to be batched. Unlimited if 0.
+	MaxL1OriginAge uint64
+
+	// TargetFrameSize is the target size of the frames, i.e. the batch tx data. MaxL1TxSize is used if 0.
+	TargetFrameSize uint64
+
+	// TargetNumFrames is the target number of frames per channel:
+	// a channel is closed once its compressed data fills this many frames.
+	// A channel takes all pending blocks if 0.
+	TargetNumFrames uint64
+
 	RPCConfig oprpc.CLIConfig
 
 	/* Optional Params */
@@ -114,7 +130,11 @@ func NewConfig(ctx *cli.Context) Config {
 		Mnemonic:                   ctx.GlobalString(flags.MnemonicFlag.Name),
 		SequencerHDPath:            ctx.GlobalString(flags.SequencerHDPathFlag.Name),
 		PrivateKey:                 ctx.GlobalString(flags.PrivateKeyFlag.Name),
+		SignerEndpoint:             ctx.GlobalString(flags.SignerEndpointFlag.Name),
 		SequencerBatchInboxAddress: ctx.GlobalString(flags.SequencerBatchInboxAddressFlag.Name),
+		MaxL1OriginAge:             ctx.GlobalUint64(flags.MaxL1OriginAgeFlag.Name),
+		TargetFrameSize:            ctx.GlobalUint64(flags.TargetFrameSizeFlag.Name),
+		TargetNumFrames:            ctx.GlobalUint64(flags.TargetNumFramesFlag.Name),
 		RPCConfig:                  oprpc.ReadCLIConfig(ctx),
 		LogConfig:                  oplog.ReadCLIConfig(ctx),
 		MetricsConfig:              opmetrics.ReadCLIConfig(ctx),
@@ -96,6 +96,27 @@ var (
 		Usage:  "The private key to use with the l2output wallet. Must not be used with mnemonic.",
 		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "PRIVATE_KEY"),
 	}
+	SignerEndpointFlag = cli.StringFlag{
+		Name:   "signer-endpoint",
+		Usage:  "RPC endpoint of a remote signer service to sign batch transactions with. Must not be used with private-key or mnemonic.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "SIGNER_ENDPOINT"),
+	}
+	MaxL1OriginAgeFlag = cli.Uint64Flag{
+		Name: "max-l1-origin-age",
+		Usage: "Maximum number of L1 blocks the L1 origin of an unsafe L2 block may be behind the L1 head for the block to be batched. " +
+			"Should be less than the sequencing window size of the rollup, so batches are included before the window expires. Unlimited if 0.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "MAX_L1_ORIGIN_AGE"),
+	}
+	TargetFrameSizeFlag = cli.Uint64Flag{
+		Name:   "target-frame-size",
+		Usage:  "Target size of the frames, i.e. the batch tx data, in bytes. Capped at max-l1-tx-size, which is used if 0.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "TARGET_FRAME_SIZE"),
+	}
+	TargetNumFramesFlag = cli.Uint64Flag{
+		Name:   "target-num-frames",
+		Usage:  "Target number of frames per channel. A channel is closed once its compressed data fills this many frames. A channel takes all pending blocks if 0.",
+		EnvVar: opservice.PrefixEnvVar(envVarPrefix, "TARGET_NUM_FRAMES"),
+	}
 	SequencerBatchInboxAddressFlag = cli.StringFlag{
 		Name:     "sequencer-batch-inbox-address",
 		Usage:    "L1 Address to receive batch transactions",
@@ -122,6 +143,10 @@ var optionalFlags = []cli.Flag{
 	MnemonicFlag,
 	SequencerHDPathFlag,
 	PrivateKeyFlag,
+	SignerEndpointFlag,
+	MaxL1OriginAgeFlag,
+	TargetFrameSizeFlag,
+	TargetNumFramesFlag,
 }
 
 func init() {
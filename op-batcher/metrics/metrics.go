@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ethereum-optimism/optimism/op-batcher/channelmgr"
+	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
+)
+
+const Namespace = "op_batcher"
+
+// Metricer records the metrics of the batch submitter.
+type Metricer interface {
+	txmgr.Metricer
+
+	// RecordBatchTxData records the estimated L1 data cost of a submitted batch transaction.
+	RecordBatchTxData(cost channelmgr.DataCost)
+	// RecordL1OriginTooOld records an unsafe L2 block that is not batched, since its L1 origin is too old.
+	RecordL1OriginTooOld()
+	// RecordChannelReorged records a submitted channel that is submitted again, since an L1 reorg removed a batch tx of it.
+	RecordChannelReorged()
+}
+
+type Metrics struct {
+	*txmgr.Metrics
+
+	BatchTxs             prometheus.Counter
+	BatchTxDataBytes     *prometheus.CounterVec
+	BatchTxIntrinsicGas  prometheus.Counter
+	BatchTxGasPerTx      prometheus.Histogram
+	LastBatchTxDataBytes prometheus.Gauge
+	L1OriginTooOld       prometheus.Counter
+	ChannelsReorged      prometheus.Counter
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+// NewMetrics registers the batch submitter metrics in the given registry.
+func NewMetrics(registry *prometheus.Registry) *Metrics {
+	return &Metrics{
+		Metrics: txmgr.NewMetrics(Namespace, registry),
+
+		BatchTxs: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "batch_txs_total",
+			Help:      "Count of submitted batch transactions",
+		}),
+		BatchTxDataBytes: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "batch_tx_data_bytes_total",
+			Help:      "Count of calldata bytes of submitted batch transactions, by zero and non-zero bytes",
+		}, []string{
+			"kind",
+		}),
+		BatchTxIntrinsicGas: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "batch_tx_intrinsic_gas_total",
+			Help:      "Estimated intrinsic L1 gas of submitted batch transactions",
+		}),
+		BatchTxGasPerTx: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "batch_tx_intrinsic_gas",
+			Help:      "Histogram of the estimated intrinsic L1 gas per batch transaction",
+			Buckets:   prometheus.ExponentialBuckets(21_000, 2, 10),
+		}),
+		LastBatchTxDataBytes: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "last_batch_tx_data_bytes",
+			Help:      "Calldata size of the last submitted batch transaction",
+		}),
+		L1OriginTooOld: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "l1_origin_too_old_total",
+			Help:      "Count of times an unsafe L2 block was not batched, since its L1 origin was too old relative to the L1 head",
+		}),
+		ChannelsReorged: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "channels_reorged_total",
+			Help:      "Count of submitted channels that were submitted again, since an L1 reorg removed one of their batch transactions",
+		}),
+	}
+}
+
+func (m *Metrics) RecordBatchTxData(cost channelmgr.DataCost) {
+	m.BatchTxs.Inc()
+	m.BatchTxDataBytes.WithLabelValues("zero").Add(float64(cost.ZeroBytes))
+	m.BatchTxDataBytes.WithLabelValues("non_zero").Add(float64(cost.NonZeroBytes))
+	m.BatchTxIntrinsicGas.Add(float64(cost.IntrinsicGas))
+	m.BatchTxGasPerTx.Observe(float64(cost.IntrinsicGas))
+	m.LastBatchTxDataBytes.Set(float64(cost.Size()))
+}
+
+func (m *Metrics) RecordL1OriginTooOld() {
+	m.L1OriginTooOld.Inc()
+}
+
+func (m *Metrics) RecordChannelReorged() {
+	m.ChannelsReorged.Inc()
+}
+
+type noopMetrics struct {
+	txmgr.Metricer
+}
+
+// NoopMetrics discards all metrics.
+var NoopMetrics Metricer = noopMetrics{txmgr.NoopMetrics}
+
+func (noopMetrics) RecordBatchTxData(channelmgr.DataCost) {}
+func (noopMetrics) RecordL1OriginTooOld()                 {}
+func (noopMetrics) RecordChannelReorged()                 {}
@@ -0,0 +1,200 @@
+package noncemgr
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// NonceSource reads the nonces of an account on L1.
+// It is implemented by the go-ethereum ethclient.
+type NonceSource interface {
+	// NonceAt returns the nonce of the account at the given block, or the latest block if nil:
+	// the number of included transactions.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	// PendingNonceAt returns the nonce of the account including the transactions in the tx pool.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// IncludedHistory is the number of nonces below the latest nonce of which the included transactions are kept,
+// to resubmit them if an L1 reorg removes them from the chain.
+const IncludedHistory = 64
+
+// StuckChecks is the number of consecutive checks after which transactions in the tx pool are considered stuck,
+// if the latest nonce did not advance in the meantime, e.g. because their fees are too low to be included.
+const StuckChecks = 10
+
+// Recovery lists what has to be (re)submitted to fill the nonce gaps of the account.
+type Recovery struct {
+	// Resubmit are the sent transactions that are neither included, nor in the tx pool anymore,
+	// e.g. after being dropped from the tx pool, or after an L1 reorg removed them from the chain.
+	Resubmit []*types.Transaction
+	// Cancel are the nonces of the gaps that have no known transaction, to fill with a CancelTx.
+	Cancel []uint64
+	// Stuck is true if the transactions to resubmit or cancel are stuck in the tx pool,
+	// and have to be replaced with higher fees.
+	Stuck bool
+}
+
+// Empty returns true if there is nothing to recover.
+func (r *Recovery) Empty() bool {
+	return len(r.Resubmit) == 0 && len(r.Cancel) == 0
+}
+
+// NonceManager assigns the nonces of the transactions of an account, without waiting for
+// the previous transaction to be included, and tracks the sent transactions until they are included.
+//
+// The manager does not send transactions itself: Check returns what has to be resubmitted when
+// the local state is ahead of the L1 chain, e.g. after a tx was dropped or an L1 reorg rolled the nonce back.
+// It is safe for concurrent use.
+type NonceManager struct {
+	log  log.Logger
+	src  NonceSource
+	addr common.Address
+
+	mu sync.Mutex
+	// next nonce to assign, only valid if synced
+	next   uint64
+	synced bool
+	// sent transactions by nonce, that may not be included yet
+	sent map[uint64]*types.Transaction
+	// latest nonce of the last check, and the number of consecutive checks it did not advance with pending txs
+	lastLatest uint64
+	stuckFor   int
+}
+
+func NewNonceManager(log log.Logger, src NonceSource, addr common.Address) *NonceManager {
+	return &NonceManager{
+		log:  log,
+		src:  src,
+		addr: addr,
+		sent: make(map[uint64]*types.Transaction),
+	}
+}
+
+// Next assigns the nonce of a new transaction.
+// The first nonce, or the first after a Reset, is the pending nonce of the account on L1.
+func (m *NonceManager) Next(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.synced {
+		pending, err := m.src.PendingNonceAt(ctx, m.addr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		m.next = pending
+		m.synced = true
+	}
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// Sent tracks the sent transaction, until it is included.
+func (m *NonceManager) Sent(tx *types.Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[tx.Nonce()] = tx
+}
+
+// Tracked returns the number of sent transactions that are tracked, see IncludedHistory.
+func (m *NonceManager) Tracked() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+// Reset drops the sent transactions, and syncs the next nonce with the L1 chain again.
+func (m *NonceManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced = false
+	m.sent = make(map[uint64]*types.Transaction)
+}
+
+// Check compares the local nonce state with the latest and pending nonces of the account on L1.
+// Sent transactions more than IncludedHistory nonces below the latest nonce are no longer tracked.
+// The nonces between the pending nonce and the next nonce are gaps: their transactions are not in the tx pool,
+// and have to be resubmitted, or cancelled if the transaction is unknown.
+//
+// If the latest nonce is ahead of the next nonce, the account was used by another sender,
+// and the next nonce continues from the latest nonce.
+//
+// If there are pending transactions, but the latest nonce did not advance for StuckChecks checks,
+// the transactions from the latest nonce onwards are stuck in the tx pool, and have to be replaced.
+func (m *NonceManager) Check(ctx context.Context) (*Recovery, error) {
+	latest, err := m.src.NonceAt(ctx, m.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest nonce: %w", err)
+	}
+	pending, err := m.src.PendingNonceAt(ctx, m.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	if pending < latest {
+		pending = latest
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for nonce := range m.sent {
+		if nonce+IncludedHistory < latest {
+			delete(m.sent, nonce)
+		}
+	}
+	var r Recovery
+	if pending > latest && latest == m.lastLatest {
+		m.stuckFor++
+	} else {
+		m.stuckFor = 0
+	}
+	m.lastLatest = latest
+	if !m.synced {
+		return &r, nil
+	}
+	if latest > m.next {
+		m.log.Warn("account nonce is ahead of the local nonce, the account was used by another sender", "latest", latest, "next", m.next)
+		m.next = latest
+		return &r, nil
+	}
+	if m.stuckFor >= StuckChecks {
+		m.log.Warn("pending txs are stuck, replacing them", "latest", latest, "pending", pending, "checks", m.stuckFor)
+		m.stuckFor = 0
+		r.Stuck = true
+		// replace all txs from the latest nonce, as if they were not in the tx pool
+		pending = latest
+	}
+	for nonce := pending; nonce < m.next; nonce++ {
+		if tx, ok := m.sent[nonce]; ok {
+			r.Resubmit = append(r.Resubmit, tx)
+		} else {
+			r.Cancel = append(r.Cancel, nonce)
+		}
+	}
+	sort.Slice(r.Resubmit, func(i, j int) bool { return r.Resubmit[i].Nonce() < r.Resubmit[j].Nonce() })
+	if !r.Empty() {
+		m.log.Warn("detected nonce gap", "latest", latest, "pending", pending, "next", m.next,
+			"resubmit", len(r.Resubmit), "cancel", len(r.Cancel), "stuck", r.Stuck)
+	}
+	return &r, nil
+}
+
+// CancelTx creates a transaction that fills the nonce without any effect: a zero-value transfer to the sender itself.
+func CancelTx(chainID *big.Int, addr common.Address, nonce uint64, gasTipCap *big.Int, gasFeeCap *big.Int) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       params.TxGas,
+		To:        &addr,
+		Value:     new(big.Int),
+	})
+}
@@ -0,0 +1,157 @@
+package noncemgr
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNonces is a NonceSource of which the tests set the latest and pending nonce.
+type fakeNonces struct {
+	latest, pending uint64
+}
+
+func (f *fakeNonces) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return f.latest, nil
+}
+
+func (f *fakeNonces) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return f.pending, nil
+}
+
+func newManager(t *testing.T, src NonceSource) *NonceManager {
+	return NewNonceManager(testlog.Logger(t, log.LvlError), src, common.Address{0xaa})
+}
+
+// send assigns the next nonce to a new tx, and tracks it as sent.
+func send(t *testing.T, m *NonceManager) *types.Transaction {
+	nonce, err := m.Next(context.Background())
+	require.NoError(t, err)
+	tx := CancelTx(big.NewInt(900), common.Address{0xaa}, nonce, big.NewInt(1), big.NewInt(2))
+	m.Sent(tx)
+	return tx
+}
+
+func TestNonceManagerNext(t *testing.T) {
+	src := &fakeNonces{latest: 3, pending: 5}
+	m := newManager(t, src)
+	for i := uint64(5); i < 8; i++ {
+		require.Equal(t, i, send(t, m).Nonce(), "continues from the pending nonce without waiting for inclusion")
+	}
+
+	src.latest, src.pending = 8, 8
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.True(t, r.Empty())
+	require.Equal(t, uint64(8), send(t, m).Nonce())
+
+	m.Reset()
+	require.Equal(t, 0, m.Tracked())
+	src.pending = 20
+	require.Equal(t, uint64(20), send(t, m).Nonce(), "syncs with the pending nonce after a reset")
+}
+
+func TestNonceManagerDroppedTx(t *testing.T) {
+	src := &fakeNonces{}
+	m := newManager(t, src)
+	txs := []*types.Transaction{send(t, m), send(t, m), send(t, m)}
+
+	// the first tx is included, the last two were dropped from the tx pool
+	src.latest, src.pending = 1, 1
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, txs[1:], r.Resubmit)
+	require.Empty(t, r.Cancel)
+}
+
+func TestNonceManagerReorgRollback(t *testing.T) {
+	src := &fakeNonces{}
+	m := newManager(t, src)
+	txs := []*types.Transaction{send(t, m), send(t, m), send(t, m), send(t, m)}
+
+	src.latest, src.pending = 4, 4
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.True(t, r.Empty())
+
+	// an L1 reorg removes the last two txs from the chain, and the tx pool does not have them
+	src.latest, src.pending = 2, 2
+	r, err = m.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, txs[2:], r.Resubmit, "included txs are kept to resubmit after a reorg")
+	require.Empty(t, r.Cancel)
+	require.Equal(t, uint64(4), send(t, m).Nonce(), "the next nonce is not rolled back, the gap is resubmitted")
+}
+
+func TestNonceManagerUnknownGap(t *testing.T) {
+	src := &fakeNonces{}
+	m := newManager(t, src)
+	send(t, m)
+	send(t, m)
+	tx := send(t, m)
+
+	// txs included more than IncludedHistory nonces ago are forgotten
+	src.latest, src.pending = 2+IncludedHistory, 2+IncludedHistory
+	_, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, m.Tracked())
+
+	// a deep reorg leaves gaps of forgotten and unknown txs, which have to be cancelled
+	src.latest, src.pending = 1, 1
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []*types.Transaction{tx}, r.Resubmit)
+	require.Equal(t, uint64(1), r.Cancel[0])
+	require.Equal(t, uint64(3), r.Cancel[1])
+	require.Len(t, r.Cancel, IncludedHistory)
+}
+
+func TestNonceManagerOtherSender(t *testing.T) {
+	src := &fakeNonces{}
+	m := newManager(t, src)
+	send(t, m)
+
+	src.latest, src.pending = 10, 10
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.True(t, r.Empty())
+	require.Equal(t, uint64(10), send(t, m).Nonce(), "continues after the nonces used by the other sender")
+}
+
+func TestNonceManagerStuckTxs(t *testing.T) {
+	src := &fakeNonces{}
+	m := newManager(t, src)
+	txs := []*types.Transaction{send(t, m), send(t, m), send(t, m)}
+
+	// the first tx is included, the others stay in the tx pool without being included
+	src.latest, src.pending = 1, 3
+	for i := 0; i < StuckChecks; i++ {
+		r, err := m.Check(context.Background())
+		require.NoError(t, err)
+		require.True(t, r.Empty(), "txs in the tx pool are not stuck yet at check %d", i)
+	}
+	r, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.True(t, r.Stuck)
+	require.Equal(t, txs[1:], r.Resubmit, "stuck txs are replaced from the latest nonce")
+	require.Empty(t, r.Cancel)
+
+	// the stuck detection starts over after replacing the txs
+	r, err = m.Check(context.Background())
+	require.NoError(t, err)
+	require.True(t, r.Empty())
+
+	// txs are not stuck while the latest nonce advances
+	for i := 0; i < 2*StuckChecks; i++ {
+		src.latest, src.pending = uint64(1+i%2), 3
+		r, err := m.Check(context.Background())
+		require.NoError(t, err)
+		require.True(t, r.Empty())
+	}
+}
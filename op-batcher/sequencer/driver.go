@@ -1,10 +1,10 @@
 package sequencer
 
 import (
-	"crypto/ecdsa"
 	"math/big"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -39,8 +39,8 @@ type Config struct {
 	// Chain ID of the L1 chain to submit txs to.
 	ChainID *big.Int
 
-	// Private key to sign batch txs with
-	PrivKey *ecdsa.PrivateKey
+	// Signer to sign batch txs with
+	Signer signer.Signer
 
 	PollInterval time.Duration
 }
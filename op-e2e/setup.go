@@ -8,6 +8,7 @@ import (
 	"time"
 
 	bss "github.com/ethereum-optimism/optimism/op-batcher"
+	batchermetrics "github.com/ethereum-optimism/optimism/op-batcher/metrics"
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
@@ -15,6 +16,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	l2os "github.com/ethereum-optimism/optimism/op-proposer"
+	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -158,6 +160,9 @@ func (sys *System) Close() {
 }
 
 func (cfg SystemConfig) start() (*System, error) {
+	if err := cfg.RollupConfig.CheckL1BlockTime(cfg.L1BlockTime); err != nil {
+		return nil, fmt.Errorf("invalid rollup config timing: %w", err)
+	}
 	sys := &System{
 		cfg:         cfg,
 		nodes:       make(map[string]*node.Node),
@@ -561,7 +566,7 @@ func (cfg SystemConfig) start() (*System, error) {
 		},
 		Mnemonic:       sys.cfg.Mnemonic,
 		L2OutputHDPath: sys.cfg.L2OutputHDPath,
-	}, "", sys.cfg.Loggers["proposer"])
+	}, "", sys.cfg.Loggers["proposer"], txmgr.NoopMetrics)
 	if err != nil {
 		return nil, fmt.Errorf("unable to setup l2 output submitter: %w", err)
 	}
@@ -589,7 +594,7 @@ func (cfg SystemConfig) start() (*System, error) {
 		Mnemonic:                   sys.cfg.Mnemonic,
 		SequencerHDPath:            sys.cfg.BatchSubmitterHDPath,
 		SequencerBatchInboxAddress: sys.cfg.RollupConfig.BatchInboxAddress.String(),
-	}, sys.cfg.Loggers["batcher"])
+	}, sys.cfg.Loggers["batcher"], batchermetrics.NoopMetrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup batch submitter: %w", err)
 	}
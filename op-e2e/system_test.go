@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
@@ -349,6 +350,8 @@ func TestConfirmationDepth(t *testing.T) {
 
 	cfg := defaultSystemConfig(t)
 	cfg.RollupConfig.SeqWindowSize = 4
+	// the channel timeout must not exceed the sequencing window duration
+	cfg.RollupConfig.ChannelTimeout = cfg.RollupConfig.SeqWindowSize * cfg.L1BlockTime
 	cfg.RollupConfig.MaxSequencerDrift = 3 * cfg.L1BlockTime
 	seqConfDepth := uint64(2)
 	verConfDepth := uint64(5)
@@ -461,6 +464,83 @@ func TestMintOnRevertedDeposit(t *testing.T) {
 	require.Equal(t, startNonce+1, endNonce, "Nonce of deposit sender should increment on L2, even if the deposit fails")
 }
 
+// forwarderCode returns the creation code of a minimal L1 contract that forwards any call,
+// with calldata and value, to the target address, and reverts if the forwarded call fails.
+func forwarderCode(target common.Address) []byte {
+	runtime := append(append(
+		// copy calldata to memory, then CALL(gas, target, callvalue, 0, calldatasize, 0, 0)
+		common.FromHex("0x3660006000376000600036600034"+"73"), target[:]...),
+		// if the call failed, jump to the revert at the end
+		common.FromHex("0x5af115602a57005b60006000fd")...)
+	// copy the runtime code to memory and return it
+	initCode := []byte{0x60, byte(len(runtime)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(initCode, runtime...)
+}
+
+// TestDepositFromContract makes a deposit through an L1 contract,
+// and checks the L2 sender of the deposit is the aliased contract address.
+func TestDepositFromContract(t *testing.T) {
+	if !verboseGethNodes {
+		log.Root().SetHandler(log.DiscardHandler())
+	}
+	cfg := defaultSystemConfig(t)
+
+	sys, err := cfg.start()
+	require.Nil(t, err, "Error starting up system")
+	defer sys.Close()
+
+	l1Client := sys.Clients["l1"]
+	l2Verif := sys.Clients["verifier"]
+
+	l1Node := sys.nodes["l1"]
+	ks := l1Node.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	opts, err := bind.NewKeyStoreTransactorWithChainID(ks, ks.Accounts()[0], cfg.L1ChainID)
+	require.Nil(t, err)
+
+	// Deploy a contract that forwards calls to the deposit contract
+	forwarderAddr, tx, _, err := bind.DeployContract(opts, abi.ABI{}, forwarderCode(sys.DepositContractAddr), l1Client)
+	require.NoError(t, err)
+	_, err = waitForTransaction(tx.Hash(), l1Client, 3*time.Duration(cfg.L1BlockTime)*time.Second)
+	require.NoError(t, err, "Waiting for forwarder deployment on L1")
+
+	// Call the deposit function through the forwarder, so the deposit contract sees a contract caller
+	forwarder, err := bindings.NewOptimismPortal(forwarderAddr, l1Client)
+	require.NoError(t, err)
+	toAddr := common.Address{0xff, 0xff}
+	value := big.NewInt(1_000_000)
+	opts.Value = value
+	tx, err = forwarder.DepositTransaction(opts, toAddr, value, 1_000_000, false, nil)
+	require.NoError(t, err, "with deposit tx")
+
+	receipt, err := waitForTransaction(tx.Hash(), l1Client, 3*time.Duration(cfg.L1BlockTime)*time.Second)
+	require.NoError(t, err, "Waiting for deposit tx on L1")
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	reconstructedDep, err := derive.UnmarshalDepositLogEvent(receipt.Logs[0])
+	require.NoError(t, err, "Could not reconstruct L2 Deposit")
+	aliasedAddr := derive.ApplyL1ToL2Alias(forwarderAddr)
+	require.Equal(t, aliasedAddr, reconstructedDep.From, "deposit from a contract must be sent by the aliased address")
+	require.Equal(t, forwarderAddr, derive.UndoL1ToL2Alias(reconstructedDep.From))
+
+	tx = types.NewTx(reconstructedDep)
+	receipt, err = waitForTransaction(tx.Hash(), l2Verif, 3*time.Duration(cfg.L1BlockTime)*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	// The L2 transaction is sent by the aliased address, not the contract or the EOA that called it
+	l2Tx, _, err := l2Verif.TransactionByHash(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	sender, err := types.LatestSignerForChainID(cfg.L2ChainID).Sender(l2Tx)
+	require.NoError(t, err)
+	require.Equal(t, aliasedAddr, sender)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	toAddrBalance, err := l2Verif.BalanceAt(ctx, toAddr, nil)
+	cancel()
+	require.NoError(t, err)
+	require.Equal(t, value, toAddrBalance, "recipient receives the deposited value")
+}
+
 func TestMissingBatchE2E(t *testing.T) {
 	if !verboseGethNodes {
 		log.Root().SetHandler(log.DiscardHandler())
@@ -472,6 +552,8 @@ func TestMissingBatchE2E(t *testing.T) {
 	cfg := defaultSystemConfig(t)
 	// small sequence window size so the test does not take as long
 	cfg.RollupConfig.SeqWindowSize = 4
+	// the channel timeout must not exceed the sequencing window duration
+	cfg.RollupConfig.ChannelTimeout = cfg.RollupConfig.SeqWindowSize * cfg.L1BlockTime
 
 	// Specifically set batch submitter balance to stop batches from being included
 	cfg.Premine[bssHDPath] = 0
@@ -888,6 +970,10 @@ func TestWithdrawals(t *testing.T) {
 	header, err = l2Verif.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
 	require.Nil(t, err)
 
+	rollupRPCClient, err := rpc.DialContext(context.Background(), cfg.Nodes["verifier"].RPC.HttpEndpoint())
+	require.Nil(t, err)
+	rollupClient := rollupclient.NewRollupClient(rollupRPCClient)
+
 	rpc, err := rpc.Dial(sys.nodes["verifier"].WSEndpoint())
 	require.Nil(t, err)
 	l2client := withdrawals.NewClient(rpc)
@@ -896,6 +982,13 @@ func TestWithdrawals(t *testing.T) {
 	params, err := withdrawals.FinalizeWithdrawalParameters(context.Background(), l2client, tx.Hash(), header)
 	require.Nil(t, err)
 
+	// The output root proof must commit to the same output root as the rollup node computes for the block
+	ctx, cancel = context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	output, err := rollupClient.OutputAtBlock(ctx, params.BlockNumber)
+	require.Nil(t, err)
+	require.Equal(t, output[1], withdrawals.OutputRoot(params.OutputRootProof), "output root proof matches the output root of the rollup node")
+
 	portal, err := bindings.NewOptimismPortal(sys.DepositContractAddr, l1Client)
 	require.Nil(t, err)
 
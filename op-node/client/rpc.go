@@ -5,7 +5,6 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 type RPC interface {
@@ -37,13 +36,13 @@ func (ic *InstrumentedRPCClient) Close() {
 }
 
 func (ic *InstrumentedRPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
-	return instrument1(ic.m, method, func() error {
+	return instrument1(ctx, ic.m, method, func() error {
 		return ic.c.CallContext(ctx, result, method, args...)
 	})
 }
 
 func (ic *InstrumentedRPCClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
-	return instrumentBatch(ic.m, func() error {
+	return instrumentBatch(ctx, ic.m, func() error {
 		return ic.c.BatchCallContext(ctx, b)
 	}, b)
 }
@@ -57,17 +56,14 @@ func (ic *InstrumentedRPCClient) Client() Client {
 }
 
 // instrumentBatch handles metrics for batch calls. Request metrics are
-// increased for each batch element. Request durations are tracked for
-// the batch as a whole using a special <batch> method. Errors are tracked
-// for each individual batch response, unless the overall request fails in
-// which case the <batch> method is used.
-func instrumentBatch(m *metrics.Metrics, cb func() error, b []rpc.BatchElem) error {
-	m.RPCClientRequestsTotal.WithLabelValues(metrics.BatchMethod).Inc()
-	for _, elem := range b {
-		m.RPCClientRequestsTotal.WithLabelValues(elem.Method).Inc()
-	}
-	timer := prometheus.NewTimer(m.RPCClientRequestDurationSeconds.WithLabelValues(metrics.BatchMethod))
-	defer timer.ObserveDuration()
+// increased for each batch element, and the batch size is recorded.
+// Batches themselves are counted by the batch size metric only, to not double count requests.
+// Request durations are tracked for the batch as a whole using a special
+// <batch> method. Errors are tracked for each individual batch response,
+// unless the overall request fails in which case the <batch> method is used.
+func instrumentBatch(ctx context.Context, m *metrics.Metrics, cb func() error, b []rpc.BatchElem) error {
+	m.RecordRPCClientBatch(b)
+	defer m.RecordRPCClientBatchDuration(ctx)()
 
 	// Track response times for batch requests separately.
 	if err := cb(); err != nil {
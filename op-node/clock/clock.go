@@ -0,0 +1,71 @@
+// Package clock provides an injectable source of time and timers,
+// so time-dependent logic can run against real time in production,
+// and be controlled deterministically in tests.
+package clock
+
+import "time"
+
+// Clock is the source of the current time, timers and tickers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After waits for the duration to elapse, and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// AfterFunc waits for the duration to elapse, and then calls f.
+	// The returned Timer can be used to cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker returns a new Ticker, that sends the current time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a pending call of AfterFunc.
+type Timer interface {
+	// Stop prevents the call from firing.
+	// It returns true if the call stops the timer, false if the call already fired or the timer was stopped.
+	Stop() bool
+}
+
+// Ticker sends the time on its channel at intervals, like time.Ticker.
+type Ticker interface {
+	// Ch returns the channel on which the ticks are delivered.
+	Ch() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+	// Reset stops the ticker, and resets its period to d.
+	Reset(d time.Duration)
+}
+
+// SystemClock is the Clock of the real time of the system.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t *systemTicker) Ch() <-chan time.Time {
+	return t.C
+}
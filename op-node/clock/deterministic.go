@@ -0,0 +1,166 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeterministicClock is a Clock of which the time only changes when it is advanced.
+// Timers and tickers fire when the time is advanced past their deadline, in order of deadline.
+// It is safe for concurrent use.
+type DeterministicClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	pending []*pendingAction
+}
+
+type pendingAction struct {
+	deadline time.Time
+	fire     func(now time.Time)
+	// period of a ticker, 0 if a one-off action
+	period time.Duration
+	done   bool
+}
+
+// NewDeterministicClock creates a DeterministicClock, starting at the given time.
+func NewDeterministicClock(now time.Time) *DeterministicClock {
+	return &DeterministicClock{now: now}
+}
+
+func (c *DeterministicClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *DeterministicClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *DeterministicClock) schedule(a *pendingAction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, a)
+}
+
+func (c *DeterministicClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.schedule(&pendingAction{deadline: c.Now().Add(d), fire: func(now time.Time) { ch <- now }})
+	return ch
+}
+
+func (c *DeterministicClock) AfterFunc(d time.Duration, f func()) Timer {
+	a := &pendingAction{deadline: c.Now().Add(d), fire: func(time.Time) { f() }}
+	c.schedule(a)
+	return &deterministicTimer{c: c, a: a}
+}
+
+func (c *DeterministicClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	ch := make(chan time.Time, 1)
+	a := &pendingAction{
+		deadline: c.Now().Add(d),
+		period:   d,
+		fire: func(now time.Time) {
+			select {
+			case ch <- now:
+			default: // drop the tick if the previous tick was not read yet, like time.Ticker
+			}
+		},
+	}
+	c.schedule(a)
+	return &deterministicTicker{c: c, a: a, ch: ch}
+}
+
+// AdvanceTime moves the time forward by d, and fires the timers and tickers of which the deadline passed,
+// in order of deadline. The time is set to the deadline of every action while it fires.
+func (c *DeterministicClock) AdvanceTime(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		next := c.nextDue(target)
+		if next == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		c.now = next.deadline
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			next.done = true
+		}
+		now := c.now
+		c.mu.Unlock()
+		// fire without holding the lock, the action may use the clock
+		next.fire(now)
+	}
+}
+
+// nextDue returns the pending action with the earliest deadline at or before the target time,
+// and forgets the actions that are done. Returns nil if there is none.
+func (c *DeterministicClock) nextDue(target time.Time) *pendingAction {
+	active := c.pending[:0]
+	for _, a := range c.pending {
+		if !a.done {
+			active = append(active, a)
+		}
+	}
+	c.pending = active
+	sort.SliceStable(c.pending, func(i, j int) bool {
+		return c.pending[i].deadline.Before(c.pending[j].deadline)
+	})
+	if len(c.pending) == 0 || c.pending[0].deadline.After(target) {
+		return nil
+	}
+	return c.pending[0]
+}
+
+type deterministicTimer struct {
+	c *DeterministicClock
+	a *pendingAction
+}
+
+func (t *deterministicTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	stopped := !t.a.done
+	t.a.done = true
+	return stopped
+}
+
+type deterministicTicker struct {
+	c  *DeterministicClock
+	a  *pendingAction
+	ch chan time.Time
+}
+
+func (t *deterministicTicker) Ch() <-chan time.Time {
+	return t.ch
+}
+
+func (t *deterministicTicker) Stop() {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	t.a.done = true
+}
+
+func (t *deterministicTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	if t.a.done {
+		// reschedule, the stopped action may have been forgotten already
+		t.a = &pendingAction{fire: t.a.fire}
+		t.c.pending = append(t.c.pending, t.a)
+	}
+	t.a.period = d
+	t.a.deadline = t.c.now.Add(d)
+}
@@ -0,0 +1,92 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Unix(1000, 0)
+
+func TestDeterministicClockNow(t *testing.T) {
+	c := NewDeterministicClock(start)
+	require.Equal(t, start, c.Now())
+	c.AdvanceTime(time.Second)
+	require.Equal(t, start.Add(time.Second), c.Now())
+	require.Equal(t, 3*time.Second, c.Since(start.Add(-2*time.Second)))
+}
+
+func TestDeterministicClockAfter(t *testing.T) {
+	c := NewDeterministicClock(start)
+	ch := c.After(time.Second)
+	c.AdvanceTime(time.Second - 1)
+	require.Empty(t, ch)
+	c.AdvanceTime(1)
+	require.Equal(t, start.Add(time.Second), <-ch)
+	c.AdvanceTime(time.Hour)
+	require.Empty(t, ch, "fires only once")
+}
+
+func TestDeterministicClockAfterFunc(t *testing.T) {
+	c := NewDeterministicClock(start)
+	var order []int
+	var firedAt []time.Time
+	record := func(i int) func() {
+		return func() {
+			order = append(order, i)
+			firedAt = append(firedAt, c.Now())
+		}
+	}
+	c.AfterFunc(3*time.Second, record(3))
+	c.AfterFunc(time.Second, record(1))
+	stopped := c.AfterFunc(2*time.Second, record(2))
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop(), "already stopped")
+
+	c.AdvanceTime(10 * time.Second)
+	require.Equal(t, []int{1, 3}, order, "fired in order of deadline")
+	require.Equal(t, []time.Time{start.Add(time.Second), start.Add(3 * time.Second)}, firedAt, "the time is the deadline while firing")
+	require.Equal(t, start.Add(10*time.Second), c.Now())
+}
+
+func TestDeterministicClockAfterFuncScheduling(t *testing.T) {
+	c := NewDeterministicClock(start)
+	count := 0
+	var reschedule func()
+	reschedule = func() {
+		count++
+		c.AfterFunc(time.Second, reschedule)
+	}
+	c.AfterFunc(time.Second, reschedule)
+	c.AdvanceTime(5 * time.Second)
+	require.Equal(t, 5, count, "functions can schedule new timers while firing")
+}
+
+func TestDeterministicClockTicker(t *testing.T) {
+	c := NewDeterministicClock(start)
+	ticker := c.NewTicker(time.Second)
+	c.AdvanceTime(500 * time.Millisecond)
+	require.Empty(t, ticker.Ch())
+	c.AdvanceTime(500 * time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-ticker.Ch())
+
+	// ticks are dropped if not read, like with a time.Ticker
+	c.AdvanceTime(3 * time.Second)
+	require.Equal(t, start.Add(2*time.Second), <-ticker.Ch())
+	require.Empty(t, ticker.Ch())
+
+	ticker.Reset(5 * time.Second)
+	c.AdvanceTime(4 * time.Second)
+	require.Empty(t, ticker.Ch())
+	c.AdvanceTime(time.Second)
+	require.Equal(t, start.Add(9*time.Second), <-ticker.Ch())
+
+	ticker.Stop()
+	c.AdvanceTime(time.Hour)
+	require.Empty(t, ticker.Ch())
+
+	ticker.Reset(time.Second)
+	c.AdvanceTime(time.Second)
+	require.Len(t, ticker.Ch(), 1, "a stopped ticker can be reset")
+}
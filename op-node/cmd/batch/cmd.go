@@ -0,0 +1,90 @@
+package batch
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive/testvectors"
+)
+
+var Subcommands = cli.Commands{
+	decodeCommand,
+	verifyCommand,
+	{
+		Name:  "export-vectors",
+		Usage: "Generates batch submission test vectors from random L2 blocks, and writes them to a JSON file",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "outfile",
+				Usage: "Path to the test vectors output file",
+				Value: "batch-vectors.json",
+			},
+			cli.Int64Flag{
+				Name:  "seed",
+				Usage: "Seed of the random L2 blocks",
+				Value: 1234,
+			},
+			cli.IntFlag{
+				Name:  "count",
+				Usage: "Number of test vectors to generate",
+				Value: 10,
+			},
+			cli.IntFlag{
+				Name:  "max-blocks",
+				Usage: "Maximum number of L2 blocks per channel",
+				Value: 20,
+			},
+			cli.Uint64Flag{
+				Name:  "max-frame-size",
+				Usage: "Maximum size of the L1 transaction data of a frame, including the derivation version byte",
+				Value: 1000,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			rng := rand.New(rand.NewSource(ctx.Int64("seed")))
+			maxBlocks := ctx.Int("max-blocks")
+			if maxBlocks < 1 {
+				return fmt.Errorf("max-blocks must be at least 1, got %d", maxBlocks)
+			}
+			var vectors []*testvectors.Vector
+			for i := 0; i < ctx.Int("count"); i++ {
+				blocks, err := testvectors.RandomBlocks(rng, 1+rng.Intn(maxBlocks), 5)
+				if err != nil {
+					return err
+				}
+				v, err := testvectors.FromBlocks(fmt.Sprintf("vector_%d", i), blocks, blocks[0].Time(), ctx.Uint64("max-frame-size"))
+				if err != nil {
+					return err
+				}
+				vectors = append(vectors, v)
+			}
+			return testvectors.WriteFile(ctx.String("outfile"), vectors)
+		},
+	},
+	{
+		Name:  "check-vectors",
+		Usage: "Decodes the batch submission test vectors of a JSON file, and checks the decoded batches match",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:     "infile",
+				Usage:    "Path to the test vectors input file",
+				Required: true,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			vectors, err := testvectors.ReadFile(ctx.String("infile"))
+			if err != nil {
+				return err
+			}
+			for _, v := range vectors {
+				if err := v.Check(); err != nil {
+					return fmt.Errorf("test vector %q failed: %w", v.Name, err)
+				}
+			}
+			fmt.Printf("%d test vectors passed\n", len(vectors))
+			return nil
+		},
+	},
+}
@@ -0,0 +1,176 @@
+package batch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+var decodeCommand = cli.Command{
+	Name:  "decode",
+	Usage: "Decodes the frames, channels and batches of batch inbox transactions, and prints a summary",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "tx",
+			Usage: "Hash of an L1 transaction to fetch, or path to a file with hex encoded transaction data. May be repeated, in L1 inclusion order",
+		},
+		cli.StringFlag{
+			Name:  "l1",
+			Usage: "Address of the L1 User JSON-RPC endpoint, required when fetching transactions by hash",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		txs := ctx.StringSlice("tx")
+		if len(txs) == 0 {
+			return fmt.Errorf("at least one --tx is required")
+		}
+		var client *ethclient.Client
+		var txData [][]byte
+		for _, tx := range txs {
+			var data []byte
+			var err error
+			if isTxHash(tx) {
+				if client == nil {
+					if client, err = ethclient.Dial(ctx.String("l1")); err != nil {
+						return fmt.Errorf("failed to dial L1 RPC: %w", err)
+					}
+					defer client.Close()
+				}
+				data, err = fetchTxData(client, common.HexToHash(tx))
+			} else {
+				data, err = readTxData(tx)
+			}
+			if err != nil {
+				return err
+			}
+			txData = append(txData, data)
+		}
+		return Decode(os.Stdout, txData)
+	},
+}
+
+func isTxHash(s string) bool {
+	return len(s) == 2+2*common.HashLength && strings.HasPrefix(s, "0x")
+}
+
+func fetchTxData(client *ethclient.Client, hash common.Hash) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	tx, _, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tx %s: %w", hash, err)
+	}
+	return tx.Data(), nil
+}
+
+func readTxData(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tx data file: %w", err)
+	}
+	data, err := hexutil.Decode(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("tx data file %q is not hex encoded: %w", path, err)
+	}
+	return data, nil
+}
+
+// Decode parses the frames of the given batch inbox transaction data, and writes a summary of
+// the frames, and of the channels and batches of all complete channels, to w.
+func Decode(w io.Writer, txData [][]byte) error {
+	channels := make(map[derive.ChannelID]*derive.Channel)
+	var order []derive.ChannelID
+	for i, data := range txData {
+		frames, err := derive.ParseFrames(data)
+		if err != nil {
+			fmt.Fprintf(w, "tx %d: invalid frames: %v\n", i, err)
+			continue
+		}
+		for _, f := range frames {
+			fmt.Fprintf(w, "tx %d: frame %d of channel %s, %d bytes, last: %v\n", i, f.FrameNumber, f.ID, len(f.Data), f.IsLast)
+			ch, ok := channels[f.ID]
+			if !ok {
+				ch = derive.NewChannel(f.ID)
+				channels[f.ID] = ch
+				order = append(order, f.ID)
+			}
+			if err := ch.AddFrame(f, eth.L1BlockRef{}); err != nil {
+				fmt.Fprintf(w, "tx %d: failed to add frame %d to channel %s: %v\n", i, f.FrameNumber, f.ID, err)
+			}
+		}
+	}
+	for _, id := range order {
+		if err := decodeChannel(w, id, channels[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeChannel(w io.Writer, id derive.ChannelID, ch *derive.Channel) error {
+	if !ch.IsReady() {
+		fmt.Fprintf(w, "channel %s: incomplete\n", id)
+		return nil
+	}
+	compressed, err := io.ReadAll(ch.Reader())
+	if err != nil {
+		return fmt.Errorf("failed to read channel %s: %w", id, err)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		fmt.Fprintf(w, "channel %s: invalid compression: %v\n", id, err)
+		return nil
+	}
+	uncompressed, err := io.ReadAll(zr)
+	if err != nil {
+		fmt.Fprintf(w, "channel %s: invalid compression: %v\n", id, err)
+		return nil
+	}
+	ratio := float64(0)
+	if len(uncompressed) > 0 {
+		ratio = float64(len(compressed)) / float64(len(uncompressed))
+	}
+	fmt.Fprintf(w, "channel %s: %d bytes compressed, %d bytes uncompressed, compression ratio %.3f\n",
+		id, len(compressed), len(uncompressed), ratio)
+
+	next, err := derive.BatchReader(bytes.NewReader(compressed), eth.L1BlockRef{})
+	if err != nil {
+		return fmt.Errorf("failed to read batches of channel %s: %w", id, err)
+	}
+	var count int
+	var first, last *derive.BatchData
+	for {
+		b, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fmt.Fprintf(w, "channel %s: invalid batch %d: %v\n", id, count, err)
+			break
+		}
+		fmt.Fprintf(w, "  batch %d: timestamp %d, epoch %d (%s), parent %s, %d txs\n",
+			count, b.Batch.Timestamp, b.Batch.EpochNum, b.Batch.EpochHash, b.Batch.ParentHash, len(b.Batch.Transactions))
+		if first == nil {
+			first = b.Batch
+		}
+		last = b.Batch
+		count++
+	}
+	if first != nil {
+		fmt.Fprintf(w, "channel %s: %d batches, timestamps %d to %d, epochs %d to %d\n",
+			id, count, first.Timestamp, last.Timestamp, first.EpochNum, last.EpochNum)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package batch
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive/testvectors"
+)
+
+func TestDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	blocks, err := testvectors.RandomBlocks(rng, 3, 2)
+	require.NoError(t, err)
+	v, err := testvectors.FromBlocks("decode", blocks, 1000, 300)
+	require.NoError(t, err)
+	require.Greater(t, len(v.Frames), 1)
+
+	var txData [][]byte
+	for _, data := range v.Frames {
+		txData = append(txData, data)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Decode(&out, txData[:len(txData)-1]))
+	require.Contains(t, out.String(), "incomplete")
+
+	out.Reset()
+	require.NoError(t, Decode(&out, append(txData, []byte{0x01})))
+	require.Contains(t, out.String(), "invalid frames")
+	require.Contains(t, out.String(), "compression ratio")
+	require.Contains(t, out.String(), "3 batches")
+	require.Contains(t, out.String(), "  batch 2: timestamp")
+}
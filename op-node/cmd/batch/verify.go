@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive/inclusion"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+)
+
+var verifyCommand = cli.Command{
+	Name:  "verify",
+	Usage: "Verifies the batch submissions of a range of L1 blocks decode, and cover a contiguous range of L2 blocks",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     "l1",
+			Usage:    "Address of the L1 User JSON-RPC endpoint",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     "rollup-config",
+			Usage:    "Path to the rollup config JSON file",
+			Required: true,
+		},
+		cli.Uint64Flag{
+			Name:     "start",
+			Usage:    "Number of the first L1 block to verify",
+			Required: true,
+		},
+		cli.Uint64Flag{
+			Name:     "end",
+			Usage:    "Number of the last L1 block to verify",
+			Required: true,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		cfg, err := readRollupConfig(ctx.String("rollup-config"))
+		if err != nil {
+			return err
+		}
+		reqCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		rpcClient, err := rpc.DialContext(reqCtx, ctx.String("l1"))
+		if err != nil {
+			return fmt.Errorf("failed to dial L1 RPC: %w", err)
+		}
+		defer rpcClient.Close()
+		l1, err := sources.NewL1Client(rpcClient, log.Root(), nil, sources.L1ClientDefaultConfig(cfg, false))
+		if err != nil {
+			return fmt.Errorf("failed to create L1 client: %w", err)
+		}
+		report, err := inclusion.Verify(reqCtx, log.Root(), cfg, l1, ctx.Uint64("start"), ctx.Uint64("end"))
+		if err != nil {
+			return err
+		}
+		if err := inclusion.WriteReport(os.Stdout, report); err != nil {
+			return err
+		}
+		if !report.OK() {
+			return errors.New("batch submissions failed verification")
+		}
+		return nil
+	},
+}
+
+func readRollupConfig(path string) (*rollup.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollup config: %w", err)
+	}
+	defer file.Close()
+	var cfg rollup.Config
+	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode rollup config: %w", err)
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid rollup config: %w", err)
+	}
+	return &cfg, nil
+}
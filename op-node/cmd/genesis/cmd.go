@@ -2,10 +2,15 @@ package genesis
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"os"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
@@ -56,58 +61,84 @@ var Subcommands = cli.Commands{
 				return err
 			}
 
-			l1Genesis, err := genesis.BuildL1DeveloperGenesis(hh, config)
+			devnet, err := BuildDevnetGenesis(hh, config)
 			if err != nil {
 				return err
 			}
 
-			l1StartBlock := l1Genesis.ToBlock()
-			l2Addrs := &genesis.L2Addresses{
-				ProxyAdmin:                  predeploys.DevProxyAdminAddr,
-				L1StandardBridgeProxy:       predeploys.DevL1StandardBridgeAddr,
-				L1CrossDomainMessengerProxy: predeploys.DevL1CrossDomainMessengerAddr,
-			}
-			l2Genesis, err := genesis.BuildL2DeveloperGenesis(hh, config, l1StartBlock, l2Addrs)
-			if err != nil {
+			if err := writeGenesisFile(ctx.String("outfile.l1"), devnet.L1); err != nil {
 				return err
 			}
-
-			rollupConfig := &rollup.Config{
-				Genesis: rollup.Genesis{
-					L1: eth.BlockID{
-						Hash:   l1StartBlock.Hash(),
-						Number: 0,
-					},
-					L2: eth.BlockID{
-						Hash:   l2Genesis.ToBlock().Hash(),
-						Number: 0,
-					},
-					L2Time: uint64(config.L1GenesisBlockTimestamp),
-				},
-				BlockTime:              config.L2BlockTime,
-				MaxSequencerDrift:      config.MaxSequencerDrift,
-				SeqWindowSize:          config.SequencerWindowSize,
-				ChannelTimeout:         config.ChannelTimeout,
-				L1ChainID:              new(big.Int).SetUint64(config.L1ChainID),
-				L2ChainID:              new(big.Int).SetUint64(config.L2ChainID),
-				P2PSequencerAddress:    config.P2PSequencerAddress,
-				FeeRecipientAddress:    config.OptimismL2FeeRecipient,
-				BatchInboxAddress:      config.BatchInboxAddress,
-				BatchSenderAddress:     config.BatchSenderAddress,
-				DepositContractAddress: predeploys.DevOptimismPortalAddr,
-			}
-
-			if err := writeGenesisFile(ctx.String("outfile.l1"), l1Genesis); err != nil {
+			if err := writeGenesisFile(ctx.String("outfile.l2"), devnet.L2); err != nil {
 				return err
 			}
-			if err := writeGenesisFile(ctx.String("outfile.l2"), l2Genesis); err != nil {
-				return err
-			}
-			return writeGenesisFile(ctx.String("outfile.rollup"), rollupConfig)
+			return writeGenesisFile(ctx.String("outfile.rollup"), devnet.Rollup)
 		},
 	},
 }
 
+// DevnetGenesis is the genesis of a devnet, for both L1 and L2, and the rollup config of it.
+type DevnetGenesis struct {
+	L1     *core.Genesis
+	L2     *core.Genesis
+	Rollup *rollup.Config
+}
+
+// BuildDevnetGenesis builds the L1 genesis, with the L1 contracts deployed, the L2 genesis with the predeploys,
+// and the rollup config, from the deploy config.
+func BuildDevnetGenesis(hh *hardhat.Hardhat, config *genesis.DeployConfig) (*DevnetGenesis, error) {
+	l1Genesis, err := genesis.BuildL1DeveloperGenesis(hh, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build L1 genesis: %w", err)
+	}
+
+	l1StartBlock := l1Genesis.ToBlock()
+	l2Addrs := &genesis.L2Addresses{
+		ProxyAdmin:                  predeploys.DevProxyAdminAddr,
+		L1StandardBridgeProxy:       predeploys.DevL1StandardBridgeAddr,
+		L1CrossDomainMessengerProxy: predeploys.DevL1CrossDomainMessengerAddr,
+	}
+	l2Genesis, err := genesis.BuildL2DeveloperGenesis(hh, config, l1StartBlock, l2Addrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build L2 genesis: %w", err)
+	}
+
+	return &DevnetGenesis{
+		L1:     l1Genesis,
+		L2:     l2Genesis,
+		Rollup: RollupConfig(config, l1StartBlock, l2Genesis.ToBlock(), predeploys.DevOptimismPortalAddr),
+	}, nil
+}
+
+// RollupConfig creates the rollup config of the deploy config,
+// anchored at the given L1 start block and L2 genesis block.
+func RollupConfig(config *genesis.DeployConfig, l1StartBlock *types.Block, l2GenesisBlock *types.Block, depositContract common.Address) *rollup.Config {
+	return &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1: eth.BlockID{
+				Hash:   l1StartBlock.Hash(),
+				Number: l1StartBlock.NumberU64(),
+			},
+			L2: eth.BlockID{
+				Hash:   l2GenesisBlock.Hash(),
+				Number: l2GenesisBlock.NumberU64(),
+			},
+			L2Time: l2GenesisBlock.Time(),
+		},
+		BlockTime:              config.L2BlockTime,
+		MaxSequencerDrift:      config.MaxSequencerDrift,
+		SeqWindowSize:          config.SequencerWindowSize,
+		ChannelTimeout:         config.ChannelTimeout,
+		L1ChainID:              new(big.Int).SetUint64(config.L1ChainID),
+		L2ChainID:              new(big.Int).SetUint64(config.L2ChainID),
+		P2PSequencerAddress:    config.P2PSequencerAddress,
+		FeeRecipientAddress:    config.OptimismL2FeeRecipient,
+		BatchInboxAddress:      config.BatchInboxAddress,
+		BatchSenderAddress:     config.BatchSenderAddress,
+		DepositContractAddress: depositContract,
+	}
+}
+
 func writeGenesisFile(outfile string, input interface{}) error {
 	f, err := os.OpenFile(outfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
 	if err != nil {
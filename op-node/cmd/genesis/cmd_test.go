@@ -0,0 +1,31 @@
+package genesis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-chain-ops/genesis"
+)
+
+func TestRollupConfig(t *testing.T) {
+	config, err := genesis.NewDeployConfig("../../../op-chain-ops/genesis/testdata/test-deploy-config-full.json")
+	require.NoError(t, err)
+
+	l1Start := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Time: 1000})
+	l2Genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Time: 1000, ParentHash: common.Hash{0x42}})
+	depositContract := common.Address{0xde}
+
+	cfg := RollupConfig(config, l1Start, l2Genesis, depositContract)
+	require.Equal(t, l1Start.Hash(), cfg.Genesis.L1.Hash)
+	require.Equal(t, uint64(10), cfg.Genesis.L1.Number)
+	require.Equal(t, l2Genesis.Hash(), cfg.Genesis.L2.Hash)
+	require.Equal(t, uint64(1000), cfg.Genesis.L2Time)
+	require.Equal(t, depositContract, cfg.DepositContractAddress)
+	require.Equal(t, config.BatchInboxAddress, cfg.BatchInboxAddress)
+	require.Equal(t, config.L2BlockTime, cfg.BlockTime)
+	require.Equal(t, config.P2PSequencerAddress, cfg.P2PSequencerAddress)
+}
@@ -9,7 +9,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/cmd/batch"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/genesis"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/output"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/p2p"
 
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
@@ -71,6 +73,14 @@ func main() {
 			Name:        "genesis",
 			Subcommands: genesis.Subcommands,
 		},
+		{
+			Name:        "batch",
+			Subcommands: batch.Subcommands,
+		},
+		{
+			Name:        "output",
+			Subcommands: output.Subcommands,
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -94,6 +104,11 @@ func RollupNodeMain(ctx *cli.Context) error {
 		log.Error("Unable to create the rollup node config", "error", err)
 		return err
 	}
+	configHash, err := cfg.Rollup.Hash()
+	if err != nil {
+		log.Error("Unable to compute rollup config hash", "error", err)
+		return err
+	}
 	snapshotLog, err := opnode.NewSnapshotLogger(ctx)
 	if err != nil {
 		log.Error("Unable to create snapshot root logger", "error", err)
@@ -114,6 +129,7 @@ func RollupNodeMain(ctx *cli.Context) error {
 	defer n.Close()
 
 	m.RecordInfo(VersionWithMeta)
+	m.RecordConfigHash(configHash.String())
 	m.RecordUp()
 	log.Info("Rollup node started")
 
@@ -145,8 +161,19 @@ func RollupNodeMain(ctx *cli.Context) error {
 		syscall.SIGTERM,
 		syscall.SIGQUIT,
 	}...)
-	<-interruptChannel
-
-	return nil
+	// SIGHUP reloads the runtime config, instead of stopping the node
+	reloadChannel := make(chan os.Signal, 1)
+	signal.Notify(reloadChannel, syscall.SIGHUP)
+	for {
+		select {
+		case <-reloadChannel:
+			log.Info("Received SIGHUP, reloading runtime config")
+			reloadCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			_ = n.ReloadRuntimeConfig(reloadCtx) // the error is logged and recorded by the node
+			cancel()
+		case <-interruptChannel:
+			return nil
+		}
+	}
 
 }
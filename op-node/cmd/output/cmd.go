@@ -0,0 +1,78 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/op-node/node"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+)
+
+var Subcommands = cli.Commands{
+	{
+		Name:  "root",
+		Usage: "Computes the L2 output root of a block, and prints it as JSON along with the values it commits to",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:     "l2",
+				Usage:    "Address of the L2 execution engine JSON-RPC endpoint",
+				Required: true,
+			},
+			cli.StringFlag{
+				Name:  "block",
+				Usage: "Number of the L2 block, or a block tag such as \"latest\" or \"finalized\"",
+				Value: "latest",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			number, err := parseBlockNumber(ctx.String("block"))
+			if err != nil {
+				return err
+			}
+
+			reqCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			rpcClient, err := rpc.DialContext(reqCtx, ctx.String("l2"))
+			if err != nil {
+				return fmt.Errorf("failed to dial L2 RPC: %w", err)
+			}
+			defer rpcClient.Close()
+			l2Client, err := sources.NewEthClient(rpcClient, log.Root(), nil, &sources.EthClientConfig{
+				MaxRequestsPerBatch:   20,
+				MaxConcurrentRequests: 10,
+				MustBePostMerge:       true,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create L2 client: %w", err)
+			}
+
+			output, err := node.OutputRootAtBlock(reqCtx, l2Client, number)
+			if err != nil {
+				return fmt.Errorf("failed to compute output root: %w", err)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(output)
+		},
+	},
+}
+
+// parseBlockNumber parses a decimal or hex block number, or a block tag.
+func parseBlockNumber(s string) (rpc.BlockNumber, error) {
+	if n, err := strconv.ParseUint(s, 10, 63); err == nil {
+		return rpc.BlockNumber(n), nil
+	}
+	var number rpc.BlockNumber
+	if err := number.UnmarshalJSON([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid block %q: %w", s, err)
+	}
+	return number, nil
+}
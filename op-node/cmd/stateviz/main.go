@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"compress/gzip"
 	"embed"
 	"encoding/json"
@@ -13,12 +12,12 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -26,67 +25,16 @@ var (
 	snapshot   = flag.String("snapshot", "", "path to snapshot log")
 	listenAddr = flag.String("addr", "", "listen address of webserver")
 	refresh    = flag.Duration("refresh", 10*time.Second, "snapshot refresh rate")
+	report     = flag.String("report", "", "write a markdown report of the snapshot log to this path, instead of running the webserver")
 )
 
 var (
-	entries      map[string][]SnapshotState
+	entries      map[string][]driver.SnapshotState
 	entriesMutex sync.Mutex
 
 	assetFS fs.FS
 )
 
-type SnapshotState struct {
-	Timestamp       string         `json:"t"`
-	EngineAddr      string         `json:"engine_addr"`
-	Event           string         `json:"event"`           // event name
-	L1Head          eth.L1BlockRef `json:"l1Head"`          // what we see as head on L1
-	L1Current       eth.L1BlockRef `json:"l1Current"`       // l1 block that the derivation is currently using
-	L2Head          eth.L2BlockRef `json:"l2Head"`          // l2 block that was last optimistically accepted (unsafe head)
-	L2SafeHead      eth.L2BlockRef `json:"l2SafeHead"`      // l2 block that was last derived
-	L2FinalizedHead eth.BlockID    `json:"l2FinalizedHead"` // l2 block that is irreversible
-}
-
-func (e *SnapshotState) UnmarshalJSON(data []byte) error {
-	t := struct {
-		Timestamp       string          `json:"t"`
-		EngineAddr      string          `json:"engine_addr"`
-		Event           string          `json:"event"`
-		L1Head          json.RawMessage `json:"l1Head"`
-		L1Current       json.RawMessage `json:"l1Current"`
-		L2Head          json.RawMessage `json:"l2Head"`
-		L2SafeHead      json.RawMessage `json:"l2SafeHead"`
-		L2FinalizedHead json.RawMessage `json:"l2FinalizedHead"`
-	}{}
-	if err := json.Unmarshal(data, &t); err != nil {
-		return err
-	}
-	e.Timestamp = t.Timestamp
-	e.EngineAddr = t.EngineAddr
-	e.Event = t.Event
-
-	unquote := func(d json.RawMessage) []byte {
-		s, _ := strconv.Unquote(string(d))
-		return []byte(s)
-	}
-
-	if err := json.Unmarshal(unquote(t.L1Head), &e.L1Head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L1Current), &e.L1Current); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2Head), &e.L2Head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2SafeHead), &e.L2SafeHead); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(unquote(t.L2FinalizedHead), &e.L2FinalizedHead); err != nil {
-		return err
-	}
-	return nil
-}
-
 //go:embed assets
 var embeddedAssets embed.FS
 
@@ -101,6 +49,13 @@ func main() {
 		log.Crit("missing required -snapshot flag")
 	}
 
+	if *report != "" {
+		if err := writeReport(); err != nil {
+			log.Crit("Failed to write report", "message", err)
+		}
+		return
+	}
+
 	sub, err := fs.Sub(embeddedAssets, "assets")
 	if err != nil {
 		log.Crit("Failed to open asset directory", "message", err)
@@ -129,18 +84,13 @@ func loadSnapshot() error {
 	}
 	defer file.Close()
 
-	tempEntries := make(map[string][]SnapshotState)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var entry SnapshotState
-		if err := json.Unmarshal([]byte(scanner.Text()), &entry); err != nil {
-			return fmt.Errorf("%w: failed to decode snapshot log", err)
-		}
-
-		tempEntries[entry.EngineAddr] = append(tempEntries[entry.EngineAddr], entry)
+	snapshots, err := driver.ReadSnapshots(file)
+	if err != nil {
+		return err
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("%w: failed to scan snapshot file", err)
+	tempEntries := make(map[string][]driver.SnapshotState)
+	for _, entry := range snapshots {
+		tempEntries[entry.EngineAddr] = append(tempEntries[entry.EngineAddr], entry)
 	}
 
 	entriesMutex.Lock()
@@ -150,6 +100,33 @@ func loadSnapshot() error {
 	return nil
 }
 
+// writeReport writes a markdown report of the snapshots of every engine in the snapshot log.
+func writeReport() error {
+	if err := loadSnapshot(); err != nil {
+		return err
+	}
+	addrs := make([]string, 0, len(entries))
+	for addr := range entries {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	f, err := os.Create(*report)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+	for _, addr := range addrs {
+		if err := driver.WriteSnapshotReport(f, fmt.Sprintf("Engine %s", addr), entries[addr]); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, "\n"); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
 func runServer() {
 	l, err := net.Listen("tcp", *listenAddr)
 	if err != nil {
@@ -190,11 +167,11 @@ func makeGzipHandler(fn http.HandlerFunc) http.HandlerFunc {
 }
 
 func logsHandler(w http.ResponseWriter, r *http.Request) {
-	var output [][]SnapshotState
+	var output [][]driver.SnapshotState
 
 	entriesMutex.Lock()
 	// shallow copy so we can update the SnapshotState slice head
-	entriesCopy := make(map[string][]SnapshotState)
+	entriesCopy := make(map[string][]driver.SnapshotState)
 	for k, v := range entries {
 		entriesCopy[k] = v
 	}
@@ -204,7 +181,7 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 	// Each record/row contains SnapshotStates for each rollup driver
 	// Note that we assume each SnapshotState slice is sorted by the timestamp
 	for {
-		var min *SnapshotState
+		var min *driver.SnapshotState
 		var minKey string
 		for k, v := range entriesCopy {
 			if len(v) == 0 {
@@ -222,7 +199,7 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 
 		entriesCopy[minKey] = entriesCopy[minKey][1:]
 
-		rec := make([]SnapshotState, 0, len(entriesCopy))
+		rec := make([]driver.SnapshotState, 0, len(entriesCopy))
 		rec = append(rec, *min)
 		for k, v := range entriesCopy {
 			if k != minKey && len(v) != 0 {
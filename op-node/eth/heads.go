@@ -82,3 +82,52 @@ func PollBlockChanges(ctx context.Context, log log.Logger, src L1BlockRefsSource
 		}
 	})
 }
+
+// L1HeadSource is an L1 chain source that supports both subscribing to and polling for new heads.
+type L1HeadSource interface {
+	NewHeadSource
+	L1BlockRefsSource
+}
+
+type HeadSubscriptionMetrics interface {
+	RecordL1HeadSubscriptionDrop()
+}
+
+// WatchOrPollHeadChanges subscribes to the head changes of the L1HeadSource, and falls back to polling
+// the unsafe head, on provided interval and with request timeout, if subscribing fails or the subscription drops.
+// Whilst polling, subscribing is retried on every resubscribe interval.
+func WatchOrPollHeadChanges(ctx context.Context, log log.Logger, src L1HeadSource, fn HeadSignalFn, m HeadSubscriptionMetrics,
+	pollInterval time.Duration, timeout time.Duration, resubscribeInterval time.Duration) ethereum.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			sub, err := WatchHeadChanges(ctx, src, fn)
+			if err != nil {
+				log.Warn("failed to subscribe to L1 heads, polling instead", "err", err)
+			} else {
+				select {
+				case err := <-sub.Err():
+					m.RecordL1HeadSubscriptionDrop()
+					log.Warn("L1 heads subscription dropped, polling instead", "err", err)
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return ctx.Err()
+				case <-quit:
+					sub.Unsubscribe()
+					return nil
+				}
+			}
+
+			poll := PollBlockChanges(ctx, log, src, fn, Unsafe, pollInterval, timeout)
+			select {
+			case <-time.After(resubscribeInterval):
+				poll.Unsubscribe()
+			case <-ctx.Done():
+				poll.Unsubscribe()
+				return ctx.Err()
+			case <-quit:
+				poll.Unsubscribe()
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHeadSource fails to subscribe until subscriptions are enabled, and always serves polls.
+type fakeHeadSource struct {
+	mu        sync.Mutex
+	canSub    bool
+	subCalls  int
+	headsCh   chan<- *types.Header
+	subErr    chan error
+	pollCalls int
+}
+
+func (s *fakeHeadSource) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subCalls++
+	if !s.canSub {
+		return nil, errors.New("notifications not supported")
+	}
+	s.headsCh = ch
+	errCh := make(chan error, 1)
+	s.subErr = errCh
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case err := <-errCh:
+			return err
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
+
+func (s *fakeHeadSource) L1BlockRefByLabel(ctx context.Context, label BlockLabel) (L1BlockRef, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pollCalls++
+	return L1BlockRef{Number: 100}, nil
+}
+
+type dropMetrics struct {
+	mu    sync.Mutex
+	drops int
+}
+
+func (m *dropMetrics) RecordL1HeadSubscriptionDrop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drops++
+}
+
+func TestWatchOrPollHeadChanges(t *testing.T) {
+	src := &fakeHeadSource{}
+	m := &dropMetrics{}
+	heads := make(chan L1BlockRef, 100)
+	fn := func(ctx context.Context, sig L1BlockRef) {
+		select {
+		case heads <- sig:
+		default:
+		}
+	}
+	sub := WatchOrPollHeadChanges(context.Background(), log.New(), src, fn, m,
+		time.Millisecond*5, time.Second, time.Millisecond*50)
+	defer sub.Unsubscribe()
+
+	// the subscription fails, so the head is polled
+	require.Equal(t, uint64(100), (<-heads).Number)
+
+	// subscribing is retried, and the subscription replaces polling once it succeeds
+	src.mu.Lock()
+	src.canSub = true
+	src.mu.Unlock()
+	require.Eventually(t, func() bool {
+		src.mu.Lock()
+		defer src.mu.Unlock()
+		return src.headsCh != nil
+	}, time.Second, time.Millisecond)
+	src.mu.Lock()
+	headsCh, subErr, pollCalls := src.headsCh, src.subErr, src.pollCalls
+	src.mu.Unlock()
+	headsCh <- &types.Header{Number: big.NewInt(101)}
+	for head := range heads {
+		if head.Number == 101 {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond * 20)
+	src.mu.Lock()
+	require.Equal(t, pollCalls, src.pollCalls, "no polling whilst subscribed")
+	src.canSub = false
+	src.mu.Unlock()
+
+	// a dropped subscription falls back to polling
+	subErr <- errors.New("connection lost")
+	require.Eventually(t, func() bool {
+		src.mu.Lock()
+		defer src.mu.Unlock()
+		return src.pollCalls > pollCalls
+	}, time.Second, time.Millisecond)
+	m.mu.Lock()
+	require.Equal(t, 1, m.drops)
+	m.mu.Unlock()
+}
@@ -1,7 +1,12 @@
 package eth
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -94,3 +99,152 @@ func (id L2BlockRef) ParentID() BlockID {
 		Number: n,
 	}
 }
+
+// The block references implement encoding.TextMarshaler and encoding.TextUnmarshaler,
+// to be used in flags, config files and RPC params. They also implement json.Marshaler and json.Unmarshaler,
+// to keep encoding as a JSON object rather than as a JSON string of the text form.
+//
+// The text form of a BlockID is "hash:number", like String.
+// The text form of a L1BlockRef or L2BlockRef is its JSON object, to not lose any fields,
+// but the "hash:number" form can be parsed as well, leaving the other fields zeroed.
+
+// ParseBlockID parses a BlockID from its "hash:number" form, or from its JSON object form.
+func ParseBlockID(s string) (BlockID, error) {
+	var id BlockID
+	err := id.UnmarshalText([]byte(s))
+	return id, err
+}
+
+// ParseL1BlockRef parses a L1BlockRef from its JSON object form, or from the "hash:number" form.
+func ParseL1BlockRef(s string) (L1BlockRef, error) {
+	var ref L1BlockRef
+	err := ref.UnmarshalText([]byte(s))
+	return ref, err
+}
+
+// ParseL2BlockRef parses a L2BlockRef from its JSON object form, or from the "hash:number" form.
+func ParseL2BlockRef(s string) (L2BlockRef, error) {
+	var ref L2BlockRef
+	err := ref.UnmarshalText([]byte(s))
+	return ref, err
+}
+
+// parseHashNumber parses the "hash:number" form of a block reference.
+func parseHashNumber(text []byte) (BlockID, error) {
+	hashStr, numStr, ok := strings.Cut(strings.TrimSpace(string(text)), ":")
+	if !ok {
+		return BlockID{}, fmt.Errorf("block reference %q is not in the hash:number format", text)
+	}
+	var hash common.Hash
+	if err := hash.UnmarshalText([]byte(hashStr)); err != nil {
+		return BlockID{}, fmt.Errorf("invalid block hash %q: %w", hashStr, err)
+	}
+	num, err := strconv.ParseUint(numStr, 10, 64)
+	if err != nil {
+		return BlockID{}, fmt.Errorf("invalid block number %q: %w", numStr, err)
+	}
+	return BlockID{Hash: hash, Number: num}, nil
+}
+
+// isJSONObject returns true if the text is a JSON object, rather than the "hash:number" form.
+func isJSONObject(text []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(text), []byte("{"))
+}
+
+// unmarshalJSONText decodes a JSON object, or a JSON string of the text form, with the text unmarshaler.
+// A JSON null is ignored, like json.Unmarshal does.
+func unmarshalJSONText(input []byte, dest encoding.TextUnmarshaler) error {
+	input = bytes.TrimSpace(input)
+	if string(input) == "null" {
+		return nil
+	}
+	if !bytes.HasPrefix(input, []byte(`"`)) {
+		return dest.UnmarshalText(input)
+	}
+	var s string
+	if err := json.Unmarshal(input, &s); err != nil {
+		return err
+	}
+	return dest.UnmarshalText([]byte(s))
+}
+
+type blockIDJSON BlockID
+
+func (id BlockID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *BlockID) UnmarshalText(text []byte) error {
+	if isJSONObject(text) {
+		return json.Unmarshal(text, (*blockIDJSON)(id))
+	}
+	parsed, err := parseHashNumber(text)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+func (id BlockID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blockIDJSON(id))
+}
+
+// UnmarshalJSON decodes a BlockID from a JSON object, or from a JSON string of the "hash:number" form.
+func (id *BlockID) UnmarshalJSON(input []byte) error {
+	return unmarshalJSONText(input, id)
+}
+
+type l1BlockRefJSON L1BlockRef
+
+func (id L1BlockRef) MarshalText() ([]byte, error) {
+	return id.MarshalJSON()
+}
+
+func (id *L1BlockRef) UnmarshalText(text []byte) error {
+	if isJSONObject(text) {
+		return json.Unmarshal(text, (*l1BlockRefJSON)(id))
+	}
+	parsed, err := parseHashNumber(text)
+	if err != nil {
+		return err
+	}
+	*id = L1BlockRef{Hash: parsed.Hash, Number: parsed.Number}
+	return nil
+}
+
+func (id L1BlockRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l1BlockRefJSON(id))
+}
+
+// UnmarshalJSON decodes a L1BlockRef from a JSON object, or from a JSON string of the "hash:number" form.
+func (id *L1BlockRef) UnmarshalJSON(input []byte) error {
+	return unmarshalJSONText(input, id)
+}
+
+type l2BlockRefJSON L2BlockRef
+
+func (id L2BlockRef) MarshalText() ([]byte, error) {
+	return id.MarshalJSON()
+}
+
+func (id *L2BlockRef) UnmarshalText(text []byte) error {
+	if isJSONObject(text) {
+		return json.Unmarshal(text, (*l2BlockRefJSON)(id))
+	}
+	parsed, err := parseHashNumber(text)
+	if err != nil {
+		return err
+	}
+	*id = L2BlockRef{Hash: parsed.Hash, Number: parsed.Number}
+	return nil
+}
+
+func (id L2BlockRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l2BlockRefJSON(id))
+}
+
+// UnmarshalJSON decodes a L2BlockRef from a JSON object, or from a JSON string of the "hash:number" form.
+func (id *L2BlockRef) UnmarshalJSON(input []byte) error {
+	return unmarshalJSONText(input, id)
+}
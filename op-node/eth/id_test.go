@@ -0,0 +1,69 @@
+package eth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockIDText(t *testing.T) {
+	id := BlockID{Hash: common.Hash{0xaa}, Number: 123}
+	text, err := id.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, id.String(), string(text))
+
+	parsed, err := ParseBlockID(string(text))
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	parsed, err = ParseBlockID(`{"hash":"` + id.Hash.String() + `","number":123}`)
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	for _, invalid := range []string{"", "123", id.Hash.String(), "0xaa:123", id.Hash.String() + ":-1", id.Hash.String() + ":0x7b"} {
+		_, err := ParseBlockID(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestBlockRefJSON(t *testing.T) {
+	ref := L2BlockRef{
+		Hash:           common.Hash{0xaa},
+		Number:         123,
+		ParentHash:     common.Hash{0xbb},
+		Time:           1000,
+		L1Origin:       BlockID{Hash: common.Hash{0xcc}, Number: 10},
+		SequenceNumber: 2,
+	}
+	data, err := json.Marshal(ref)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"hash": "`+ref.Hash.String()+`",
+		"number": 123,
+		"parentHash": "`+ref.ParentHash.String()+`",
+		"timestamp": 1000,
+		"l1origin": {"hash": "`+ref.L1Origin.Hash.String()+`", "number": 10},
+		"sequenceNumber": 2
+	}`, string(data), "JSON encoding stays an object")
+
+	var decoded L2BlockRef
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, ref, decoded)
+
+	text, err := ref.MarshalText()
+	require.NoError(t, err)
+	parsed, err := ParseL2BlockRef(string(text))
+	require.NoError(t, err)
+	require.Equal(t, ref, parsed, "text form does not lose any fields")
+
+	// a JSON string of the hash:number form only sets the hash and number
+	var short L1BlockRef
+	require.NoError(t, json.Unmarshal([]byte(`"`+ref.ID().String()+`"`), &short))
+	require.Equal(t, L1BlockRef{Hash: ref.Hash, Number: ref.Number}, short)
+
+	var l1Ref L1BlockRef
+	require.NoError(t, json.Unmarshal([]byte("null"), &l1Ref))
+	require.Equal(t, L1BlockRef{}, l1Ref)
+}
@@ -170,6 +170,9 @@ func (payload *ExecutionPayload) UnmarshalSSZ(scope uint32, r io.Reader) error {
 	if offset != executionPayloadFixedPart {
 		panic("fixed part size is inconsistent")
 	}
+	if transactionsOffset < extraDataOffset {
+		return fmt.Errorf("transactions offset is smaller than extra-data offset: %d < %d", transactionsOffset, extraDataOffset)
+	}
 	if transactionsOffset > extraDataOffset+32 || transactionsOffset > scope {
 		return fmt.Errorf("extra-data is too large: %d", transactionsOffset-extraDataOffset)
 	}
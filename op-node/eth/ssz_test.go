@@ -3,14 +3,76 @@ package eth
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"os"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 )
 
+// sszVectors are the SSZ conformance vectors of ExecutionPayload in testdata/ssz_execution_payload.json.
+// The valid vectors pair the JSON (engine API) encoding of a payload with its SSZ encoding,
+// the invalid vectors are SSZ inputs that must be rejected.
+// Vectors of other clients can be added to the file to check the encoding against them.
+type sszVectors struct {
+	Valid []struct {
+		Name    string           `json:"name"`
+		Payload ExecutionPayload `json:"payload"`
+		SSZ     hexutil.Bytes    `json:"ssz"`
+	} `json:"valid"`
+	Invalid []struct {
+		Name string        `json:"name"`
+		SSZ  hexutil.Bytes `json:"ssz"`
+	} `json:"invalid"`
+}
+
+func loadSSZVectors(t testing.TB) *sszVectors {
+	data, err := os.ReadFile("testdata/ssz_execution_payload.json")
+	require.NoError(t, err)
+	var vectors sszVectors
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	return &vectors
+}
+
+func TestExecutionPayloadSSZVectors(t *testing.T) {
+	vectors := loadSSZVectors(t)
+	for _, v := range vectors.Valid {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			require.Equal(t, uint32(len(v.SSZ)), v.Payload.SizeSSZ())
+
+			var buf bytes.Buffer
+			n, err := v.Payload.MarshalSSZ(&buf)
+			require.NoError(t, err)
+			require.Equal(t, len(v.SSZ), n)
+			require.Equal(t, v.SSZ, hexutil.Bytes(buf.Bytes()), "encoding does not match")
+
+			var decoded ExecutionPayload
+			require.NoError(t, decoded.UnmarshalSSZ(uint32(len(v.SSZ)), bytes.NewReader(v.SSZ)))
+			require.Equal(t, v.Payload, decoded, "decoding does not match")
+		})
+	}
+	for _, v := range vectors.Invalid {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			var decoded ExecutionPayload
+			require.Error(t, decoded.UnmarshalSSZ(uint32(len(v.SSZ)), bytes.NewReader(v.SSZ)))
+		})
+	}
+}
+
 // FuzzExecutionPayloadUnmarshal checks that our SSZ decoding never panics
 func FuzzExecutionPayloadUnmarshal(f *testing.F) {
+	vectors := loadSSZVectors(f)
+	for _, v := range vectors.Valid {
+		f.Add([]byte(v.SSZ))
+	}
+	for _, v := range vectors.Invalid {
+		f.Add([]byte(v.SSZ))
+	}
 	f.Fuzz(func(t *testing.T, data []byte) {
 		var payload ExecutionPayload
 		err := payload.UnmarshalSSZ(uint32(len(data)), bytes.NewReader(data))
@@ -2,8 +2,10 @@ package flags
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/rollup/chains"
 	"github.com/urfave/cli"
 )
 
@@ -30,7 +32,7 @@ var (
 	}
 	RollupConfig = cli.StringFlag{
 		Name:   "rollup.config",
-		Usage:  "Rollup chain parameters",
+		Usage:  "Rollup chain parameters. Required if no --network is specified.",
 		EnvVar: prefixEnvVar("ROLLUP_CONFIG"),
 	}
 	RPCListenAddr = cli.StringFlag{
@@ -48,8 +50,50 @@ var (
 		Usage:  "Enable the admin API (experimental)",
 		EnvVar: prefixEnvVar("RPC_ENABLE_ADMIN"),
 	}
+	RPCCORSOrigins = cli.StringFlag{
+		Name:   "rpc.cors-origins",
+		Usage:  "Comma-separated list of origins that browsers may make cross-origin RPC requests from, * for any origin. Cross-origin requests are not allowed if empty",
+		Value:  "*",
+		EnvVar: prefixEnvVar("RPC_CORS_ORIGINS"),
+	}
+	RPCEnableWS = cli.BoolFlag{
+		Name:   "rpc.enable-ws",
+		Usage:  "Serve the optimism namespace, including the syncStatusUpdates subscription, over WebSocket connections on the RPC port. WebSocket connections are refused if any RPC access control is configured",
+		EnvVar: prefixEnvVar("RPC_ENABLE_WS"),
+	}
+	RPCAllowedMethods = cli.StringFlag{
+		Name:   "rpc.allowed-methods",
+		Usage:  "Comma-separated list of RPC methods that may be called, as namespace_method, or namespace_* for a whole namespace. All methods are allowed if empty",
+		EnvVar: prefixEnvVar("RPC_ALLOWED_METHODS"),
+	}
+	RPCAdminToken = cli.StringFlag{
+		Name:   "rpc.admin-token",
+		Usage:  "Bearer token that admin RPC requests must be authenticated with. Authentication is disabled if empty",
+		EnvVar: prefixEnvVar("RPC_ADMIN_TOKEN"),
+	}
+	RPCRateLimit = cli.Float64Flag{
+		Name:   "rpc.rate-limit",
+		Usage:  "Maximum number of RPC requests per second per client IP. Unlimited if 0",
+		EnvVar: prefixEnvVar("RPC_RATE_LIMIT"),
+	}
+	RPCRateBurst = cli.IntFlag{
+		Name:   "rpc.rate-burst",
+		Usage:  "Number of RPC requests a client IP may burst above rpc.rate-limit",
+		Value:  10,
+		EnvVar: prefixEnvVar("RPC_RATE_BURST"),
+	}
+	RPCMaxRequestSize = cli.Int64Flag{
+		Name:   "rpc.max-request-size",
+		Usage:  "Maximum size of an RPC request body in bytes. Unlimited if 0",
+		EnvVar: prefixEnvVar("RPC_MAX_REQUEST_SIZE"),
+	}
 
 	/* Optional Flags */
+	Network = cli.StringFlag{
+		Name:   "network",
+		Usage:  fmt.Sprintf("Predefined network selection, used instead of --rollup.config. Available networks: %s", strings.Join(chains.NetworkNames(), ", ")),
+		EnvVar: prefixEnvVar("NETWORK"),
+	}
 	L1TrustRPC = cli.BoolFlag{
 		Name:   "l1.trustrpc",
 		Usage:  "Trust the L1 RPC, sync faster at risk of malicious/buggy RPC providing bad or inconsistent L1 data",
@@ -63,6 +107,53 @@ var (
 		Value:       "",
 		Destination: new(string),
 	}
+	L2PrefetchPayloads = cli.Uint64Flag{
+		Name:   "l2.prefetch-payloads",
+		Usage:  "Number of L2 payloads to prefetch ahead of the payloads consumed by sequential sync, to speed up catching up with the unsafe chain. Disabled if 0.",
+		EnvVar: prefixEnvVar("L2_PREFETCH_PAYLOADS"),
+		Value:  0,
+	}
+	L2BackfillRPC = cli.StringFlag{
+		Name:   "l2.backfill-rpc",
+		Usage:  "L2 RPC of another node to fetch missing unsafe L2 payloads from, instead of the L2 execution engine. Requires verifier.unsafe-backfill-depth.",
+		EnvVar: prefixEnvVar("L2_BACKFILL_RPC"),
+	}
+	L2EngineForkchoiceTimeout = cli.DurationFlag{
+		Name:   "l2.forkchoice-timeout",
+		Usage:  "Timeout of a single engine_forkchoiceUpdated call to the L2 engine.",
+		EnvVar: prefixEnvVar("L2_ENGINE_FORKCHOICE_TIMEOUT"),
+		Value:  time.Second * 5,
+	}
+	L2EngineForkchoiceRetries = cli.IntFlag{
+		Name:   "l2.forkchoice-retries",
+		Usage:  "Number of times a engine_forkchoiceUpdated call to the L2 engine is retried after a timeout or RPC failure.",
+		EnvVar: prefixEnvVar("L2_ENGINE_FORKCHOICE_RETRIES"),
+		Value:  0,
+	}
+	L2EngineNewPayloadTimeout = cli.DurationFlag{
+		Name:   "l2.new-payload-timeout",
+		Usage:  "Timeout of a single engine_newPayload call to the L2 engine.",
+		EnvVar: prefixEnvVar("L2_ENGINE_NEW_PAYLOAD_TIMEOUT"),
+		Value:  time.Second * 5,
+	}
+	L2EngineNewPayloadRetries = cli.IntFlag{
+		Name:   "l2.new-payload-retries",
+		Usage:  "Number of times a engine_newPayload call to the L2 engine is retried after a timeout or RPC failure.",
+		EnvVar: prefixEnvVar("L2_ENGINE_NEW_PAYLOAD_RETRIES"),
+		Value:  0,
+	}
+	L2EngineGetPayloadTimeout = cli.DurationFlag{
+		Name:   "l2.get-payload-timeout",
+		Usage:  "Timeout of a single engine_getPayload call to the L2 engine.",
+		EnvVar: prefixEnvVar("L2_ENGINE_GET_PAYLOAD_TIMEOUT"),
+		Value:  time.Second * 5,
+	}
+	L2EngineGetPayloadRetries = cli.IntFlag{
+		Name:   "l2.get-payload-retries",
+		Usage:  "Number of times a engine_getPayload call to the L2 engine is retried after a timeout or RPC failure.",
+		EnvVar: prefixEnvVar("L2_ENGINE_GET_PAYLOAD_RETRIES"),
+		Value:  0,
+	}
 	VerifierL1Confs = cli.Uint64Flag{
 		Name:     "verifier.l1-confs",
 		Usage:    "Number of L1 blocks to keep distance from the L1 head before deriving L2 data from. Reorgs are supported, but may be slow to perform.",
@@ -70,6 +161,46 @@ var (
 		Required: false,
 		Value:    0,
 	}
+	VerifierUnsafePayloadsSpillDir = cli.StringFlag{
+		Name:   "verifier.unsafe-payloads-spill-dir",
+		Usage:  "Directory to temporarily store buffered unsafe L2 payloads in when they exceed the memory limit. Payloads are dropped instead if not set.",
+		EnvVar: prefixEnvVar("VERIFIER_UNSAFE_PAYLOADS_SPILL_DIR"),
+	}
+	VerifierUnsafePayloadsSpillSize = cli.Uint64Flag{
+		Name:   "verifier.unsafe-payloads-spill-size",
+		Usage:  "Maximum total size in bytes of the unsafe L2 payloads stored in the spill directory.",
+		EnvVar: prefixEnvVar("VERIFIER_UNSAFE_PAYLOADS_SPILL_SIZE"),
+		Value:  4 * 1024 * 1024 * 1024,
+	}
+	VerifierUnsafeBackfillDepth = cli.Uint64Flag{
+		Name:   "verifier.unsafe-backfill-depth",
+		Usage:  "Maximum number of missing unsafe L2 payloads to fetch by hash when the parent of a received unsafe payload is unknown. Disabled if 0.",
+		EnvVar: prefixEnvVar("VERIFIER_UNSAFE_BACKFILL_DEPTH"),
+		Value:  0,
+	}
+	VerifierUnsafePayloadBatchSize = cli.Uint64Flag{
+		Name:   "verifier.unsafe-payload-batch-size",
+		Usage:  "Maximum number of consecutive queued unsafe L2 payloads to insert into the engine per forkchoice update, when catching up. Does not apply to blocks derived from L1. Disabled if 0 or 1.",
+		EnvVar: prefixEnvVar("VERIFIER_UNSAFE_PAYLOAD_BATCH_SIZE"),
+		Value:  0,
+	}
+	VerifierAnchorL2Hash = cli.StringFlag{
+		Name:   "verifier.anchor-l2-hash",
+		Usage:  "Hash of a trusted L2 block to sync from instead of the L2 genesis, e.g. the head of a restored L2 snapshot. Requires verifier.anchor-l1-origin-hash.",
+		EnvVar: prefixEnvVar("VERIFIER_ANCHOR_L2_HASH"),
+	}
+	VerifierAnchorL1OriginHash = cli.StringFlag{
+		Name:   "verifier.anchor-l1-origin-hash",
+		Usage:  "Hash of the L1 origin of the trusted L2 anchor block. Requires verifier.anchor-l2-hash.",
+		EnvVar: prefixEnvVar("VERIFIER_ANCHOR_L1_ORIGIN_HASH"),
+	}
+	VerifierStepBudget = cli.DurationFlag{
+		Name:     "verifier.step-budget",
+		Usage:    "Maximum time to spend on consecutive derivation steps before processing other events, such as new L1 heads and gossip. A single step at a time if 0.",
+		EnvVar:   prefixEnvVar("VERIFIER_STEP_BUDGET"),
+		Required: false,
+		Value:    0,
+	}
 	SequencerEnabledFlag = cli.BoolFlag{
 		Name:   "sequencer.enabled",
 		Usage:  "Enable sequencing of new L2 blocks. A separate batch submitter has to be deployed to publish the data for verifiers.",
@@ -77,11 +208,32 @@ var (
 	}
 	SequencerL1Confs = cli.Uint64Flag{
 		Name:     "sequencer.l1-confs",
-		Usage:    "Number of L1 blocks to keep distance from the L1 head as a sequencer for picking an L1 origin.",
+		Usage:    "Number of L1 blocks to keep distance from the L1 head as a sequencer for picking an L1 origin: the minimum age of a new L1 origin, in L1 blocks. Can be changed at runtime with the runtime config (sequencer_conf_depth).",
 		EnvVar:   prefixEnvVar("SEQUENCER_L1_CONFS"),
 		Required: false,
 		Value:    4,
 	}
+	SequencerMaxSafeLagFlag = cli.Uint64Flag{
+		Name:     "sequencer.max-safe-lag",
+		Usage:    "Maximum number of L2 blocks the unsafe head may be ahead of the safe head, before the sequencer pauses until the safe head catches up. Disabled if 0.",
+		EnvVar:   prefixEnvVar("SEQUENCER_MAX_SAFE_LAG"),
+		Required: false,
+		Value:    0,
+	}
+	SequencerBuildDeadlineFlag = cli.DurationFlag{
+		Name:     "sequencer.build-deadline",
+		Usage:    "Maximum time the engine may take to build a block as sequencer. Building is aborted and retried if exceeded. Disabled if 0.",
+		EnvVar:   prefixEnvVar("SEQUENCER_BUILD_DEADLINE"),
+		Required: false,
+		Value:    0,
+	}
+	L1HeadPollIntervalFlag = cli.DurationFlag{
+		Name:     "l1.head-poll-interval",
+		Usage:    "Poll interval for retrieving the L1 head when the L1 RPC fails to keep a new-heads subscription open. Disabled if 0 or negative.",
+		EnvVar:   prefixEnvVar("L1_HEAD_POLL_INTERVAL"),
+		Required: false,
+		Value:    time.Second * 4,
+	}
 	L1EpochPollIntervalFlag = cli.DurationFlag{
 		Name:     "l1.epoch-poll-interval",
 		Usage:    "Poll interval for retrieving new L1 epoch updates such as safe and finalized block changes. Disabled if 0 or negative.",
@@ -89,6 +241,11 @@ var (
 		Required: false,
 		Value:    time.Second * 12 * 32,
 	}
+	L1DBPath = cli.StringFlag{
+		Name:   "l1.db-path",
+		Usage:  "Path of a database to persist the fetched L1 headers, transactions and receipts in, so they are not fetched again after a restart. Blocks older than the finalized L1 block by more than a sequencing window are pruned. Disabled if empty.",
+		EnvVar: prefixEnvVar("L1_DB_PATH"),
+	}
 	LogLevelFlag = cli.StringFlag{
 		Name:   "log.level",
 		Usage:  "The lowest log level that will be output",
@@ -101,6 +258,11 @@ var (
 		Value:  "text",
 		EnvVar: prefixEnvVar("LOG_FORMAT"),
 	}
+	LogSubsystemLevelsFlag = cli.StringFlag{
+		Name:   "log.subsystem-levels",
+		Usage:  "Comma-separated log levels of subsystems, overriding the log level, e.g. 'derivation=debug,p2p=warn'. Subsystems: driver, derivation, engine, l1-source, p2p",
+		EnvVar: prefixEnvVar("LOG_SUBSYSTEM_LEVELS"),
+	}
 	LogColorFlag = cli.BoolFlag{
 		Name:   "log.color",
 		Usage:  "Color the log output",
@@ -123,6 +285,16 @@ var (
 		Value:  7300,
 		EnvVar: prefixEnvVar("METRICS_PORT"),
 	}
+	MetricsAuthTokenFlag = cli.StringFlag{
+		Name:   "metrics.auth-token",
+		Usage:  "Bearer token that metrics requests must be authenticated with. Authentication is disabled if empty",
+		EnvVar: prefixEnvVar("METRICS_AUTH_TOKEN"),
+	}
+	MetricsAccessLogFlag = cli.BoolFlag{
+		Name:   "metrics.access-log",
+		Usage:  "Log every request to the metrics server",
+		EnvVar: prefixEnvVar("METRICS_ACCESS_LOG"),
+	}
 	PprofEnabledFlag = cli.BoolFlag{
 		Name:   "pprof.enabled",
 		Usage:  "Enable the pprof server",
@@ -141,6 +313,29 @@ var (
 		EnvVar: prefixEnvVar("PPROF_PORT"),
 	}
 
+	HeartbeatEnabledFlag = cli.BoolFlag{
+		Name:   "heartbeat.enabled",
+		Usage:  "Enables or disables heartbeating. Heartbeats report anonymized node info (version, chain ID, head numbers, peer count)",
+		EnvVar: prefixEnvVar("HEARTBEAT_ENABLED"),
+	}
+	HeartbeatURLFlag = cli.StringFlag{
+		Name:   "heartbeat.url",
+		Usage:  "Sets the URL to heartbeat to",
+		EnvVar: prefixEnvVar("HEARTBEAT_URL"),
+	}
+	HeartbeatIntervalFlag = cli.DurationFlag{
+		Name:   "heartbeat.interval",
+		Usage:  "Time between heartbeats",
+		EnvVar: prefixEnvVar("HEARTBEAT_INTERVAL"),
+		Value:  time.Minute * 10,
+	}
+
+	RuntimeConfigFlag = cli.StringFlag{
+		Name:   "runtime-config",
+		Usage:  "Path to a JSON file with settings to reload at runtime, on SIGHUP or the admin_reloadRuntimeConfig RPC: log_level, sequencer_enabled, verifier_conf_depth, sequencer_conf_depth and sequencer_max_safe_lag.",
+		EnvVar: prefixEnvVar("RUNTIME_CONFIG"),
+	}
+
 	SnapshotLog = cli.StringFlag{
 		Name:   "snapshotlog.file",
 		Usage:  "Path to the snapshot log file",
@@ -157,22 +352,56 @@ var requiredFlags = []cli.Flag{
 }
 
 var optionalFlags = append([]cli.Flag{
+	Network,
 	L1TrustRPC,
 	L2EngineJWTSecret,
+	L2PrefetchPayloads,
+	L2BackfillRPC,
+	L2EngineForkchoiceTimeout,
+	L2EngineForkchoiceRetries,
+	L2EngineNewPayloadTimeout,
+	L2EngineNewPayloadRetries,
+	L2EngineGetPayloadTimeout,
+	L2EngineGetPayloadRetries,
 	VerifierL1Confs,
+	VerifierUnsafePayloadsSpillDir,
+	VerifierUnsafePayloadsSpillSize,
+	VerifierUnsafeBackfillDepth,
+	VerifierUnsafePayloadBatchSize,
+	VerifierAnchorL2Hash,
+	VerifierAnchorL1OriginHash,
+	VerifierStepBudget,
 	SequencerEnabledFlag,
 	SequencerL1Confs,
+	SequencerMaxSafeLagFlag,
+	SequencerBuildDeadlineFlag,
+	L1HeadPollIntervalFlag,
 	L1EpochPollIntervalFlag,
+	L1DBPath,
 	LogLevelFlag,
 	LogFormatFlag,
+	LogSubsystemLevelsFlag,
 	LogColorFlag,
 	RPCEnableAdmin,
+	RPCCORSOrigins,
+	RPCEnableWS,
+	RPCAllowedMethods,
+	RPCAdminToken,
+	RPCRateLimit,
+	RPCRateBurst,
+	RPCMaxRequestSize,
 	MetricsEnabledFlag,
 	MetricsAddrFlag,
 	MetricsPortFlag,
+	MetricsAuthTokenFlag,
+	MetricsAccessLogFlag,
 	PprofEnabledFlag,
 	PprofAddrFlag,
 	PprofPortFlag,
+	HeartbeatEnabledFlag,
+	HeartbeatURLFlag,
+	HeartbeatIntervalFlag,
+	RuntimeConfigFlag,
 	SnapshotLog,
 }, p2pFlags...)
 
@@ -189,8 +418,12 @@ func CheckRequired(ctx *cli.Context) error {
 		return fmt.Errorf("flag %s is required", L2EngineAddr.Name)
 	}
 	rollupConfig := ctx.GlobalString(RollupConfig.Name)
-	if rollupConfig == "" {
-		return fmt.Errorf("flag %s is required", RollupConfig.Name)
+	network := ctx.GlobalString(Network.Name)
+	if rollupConfig == "" && network == "" {
+		return fmt.Errorf("flag %s or %s is required", RollupConfig.Name, Network.Name)
+	}
+	if rollupConfig != "" && network != "" {
+		return fmt.Errorf("cannot use both %s and %s flags", RollupConfig.Name, Network.Name)
 	}
 	rpcListenAddr := ctx.GlobalString(RPCListenAddr.Name)
 	if rpcListenAddr == "" {
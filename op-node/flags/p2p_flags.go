@@ -94,7 +94,7 @@ var (
 	}
 	StaticPeers = cli.StringFlag{
 		Name:     "p2p.static",
-		Usage:    "Comma-separated multiaddr-format peer list. Static connections to make and maintain, these peers will be regarded as trusted.",
+		Usage:    "Comma-separated multiaddr-format or ENR-format peer list. Static connections to make and maintain, these peers will be regarded as trusted.",
 		Required: false,
 		Value:    "",
 		EnvVar:   p2pEnv("STATIC"),
@@ -136,6 +136,33 @@ var (
 		Value:    30 * time.Second,
 		EnvVar:   p2pEnv("PEERS_GRACE"),
 	}
+	BanThreshold = cli.Float64Flag{
+		Name:     "p2p.ban.threshold",
+		Usage:    "Application-level peer score at or below which a peer is temporarily banned, e.g. after gossiping invalid payloads, e.g. -100. Peers are not banned if 0.",
+		Required: false,
+		Value:    0,
+		EnvVar:   p2pEnv("BAN_THRESHOLD"),
+	}
+	BanDuration = cli.DurationFlag{
+		Name:     "p2p.ban.duration",
+		Usage:    "Duration of the temporary ban of a peer with a score at or below the ban threshold.",
+		Required: false,
+		Value:    time.Hour,
+		EnvVar:   p2pEnv("BAN_DURATION"),
+	}
+	ScoreDecayHalfLife = cli.DurationFlag{
+		Name:     "p2p.score.half-life",
+		Usage:    "Time for the penalties of a peer to decay halfway. Penalties do not decay if 0.",
+		Required: false,
+		Value:    10 * time.Minute,
+		EnvVar:   p2pEnv("SCORE_HALF_LIFE"),
+	}
+	SyncReqRespFlag = cli.BoolFlag{
+		Name:     "p2p.sync.req-resp",
+		Usage:    "Enables the req-resp sync protocol, to request missing unsafe payloads from peers and serve them to peers.",
+		Required: false,
+		EnvVar:   p2pEnv("SYNC_REQ_RESP"),
+	}
 	NAT = cli.BoolFlag{
 		Name:     "p2p.nat",
 		Usage:    "Enable NAT traversal with PMP/UPNP devices to learn external IP.",
@@ -200,6 +227,13 @@ var (
 		Value:     "",
 		EnvVar:    p2pEnv("SEQUENCER_KEY"),
 	}
+	SequencerP2PSignerEndpointFlag = cli.StringFlag{
+		Name:     "p2p.sequencer.signer-endpoint",
+		Usage:    "RPC endpoint of a remote signer service, to sign p2p application messages as sequencer with. Must not be used with p2p.sequencer.key.",
+		Required: false,
+		Value:    "",
+		EnvVar:   p2pEnv("SEQUENCER_SIGNER_ENDPOINT"),
+	}
 )
 
 // None of these flags are strictly required.
@@ -222,6 +256,10 @@ var p2pFlags = []cli.Flag{
 	PeersLo,
 	PeersHi,
 	PeersGrace,
+	BanThreshold,
+	BanDuration,
+	ScoreDecayHalfLife,
+	SyncReqRespFlag,
 	NAT,
 	UserAgent,
 	TimeoutNegotiation,
@@ -230,4 +268,5 @@ var p2pFlags = []cli.Flag{
 	PeerstorePath,
 	DiscoveryPath,
 	SequencerP2PKeyFlag,
+	SequencerP2PSignerEndpointFlag,
 }
@@ -0,0 +1,82 @@
+// Package heartbeat provides an opt-in reporter of anonymized node information,
+// to get an overview of the health of a network.
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Payload is the anonymized node information that is reported with every heartbeat.
+type Payload struct {
+	Version         string `json:"version"`
+	ChainID         uint64 `json:"chainID"`
+	UnsafeHeadBlock uint64 `json:"unsafeHeadBlock"`
+	SafeHeadBlock   uint64 `json:"safeHeadBlock"`
+	PeerCount       int    `json:"peerCount"`
+}
+
+// PayloadFn collects the latest node information to report.
+type PayloadFn func(ctx context.Context) (*Payload, error)
+
+type Metricer interface {
+	RecordHeartbeat(success bool)
+}
+
+// Beat sends a heartbeat with the payload to the given URL every interval, until the context is cancelled.
+// Failures are logged and metered, but do not stop the heartbeats.
+func Beat(ctx context.Context, log log.Logger, url string, interval time.Duration, payloadFn PayloadFn, m Metricer) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := send(ctx, client, url, payloadFn)
+			m.RecordHeartbeat(err == nil)
+			if err != nil {
+				log.Warn("failed to send heartbeat", "err", err)
+			} else {
+				log.Debug("sent heartbeat")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func send(ctx context.Context, client *http.Client, url string, payloadFn PayloadFn) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	payload, err := payloadFn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect heartbeat payload: %w", err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode heartbeat payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	defer res.Body.Close()
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat endpoint returned status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	results chan bool
+}
+
+func (m *testMetrics) RecordHeartbeat(success bool) {
+	m.results <- success
+}
+
+func TestBeat(t *testing.T) {
+	expected := Payload{Version: "v1.2.3", ChainID: 901, UnsafeHeadBlock: 20, SafeHeadBlock: 10, PeerCount: 5}
+	received := make(chan Payload, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		var p Payload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		received <- p
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &testMetrics{results: make(chan bool, 10)}
+	go Beat(ctx, testlog.Logger(t, log.LvlError), srv.URL, 10*time.Millisecond, func(ctx context.Context) (*Payload, error) {
+		return &expected, nil
+	}, m)
+
+	require.Equal(t, expected, <-received)
+	require.True(t, <-m.results)
+}
+
+func TestBeatFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &testMetrics{results: make(chan bool, 10)}
+	fail := true
+	go Beat(ctx, testlog.Logger(t, log.LvlCrit), srv.URL, 10*time.Millisecond, func(ctx context.Context) (*Payload, error) {
+		if fail {
+			fail = false
+			return nil, errors.New("no sync status")
+		}
+		return &Payload{}, nil
+	}, m)
+
+	require.False(t, <-m.results, "payload collection failure")
+	require.False(t, <-m.results, "endpoint failure")
+}
@@ -2,20 +2,24 @@ package metrics
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum/go-ethereum"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -32,19 +36,31 @@ const (
 )
 
 type Metrics struct {
-	Info *prometheus.GaugeVec
-	Up   prometheus.Gauge
+	Info       *prometheus.GaugeVec
+	ConfigInfo *prometheus.GaugeVec
+	Up         prometheus.Gauge
 
 	RPCServerRequestsTotal          *prometheus.CounterVec
 	RPCServerRequestDurationSeconds *prometheus.HistogramVec
+	RPCServerRejectedTotal          *prometheus.CounterVec
 	RPCClientRequestsTotal          *prometheus.CounterVec
 	RPCClientRequestDurationSeconds *prometheus.HistogramVec
 	RPCClientResponsesTotal         *prometheus.CounterVec
+	RPCClientBatchSize              prometheus.Histogram
 
 	L1SourceCache *CacheMetrics
 	L2SourceCache *CacheMetrics
 
-	DerivationIdle prometheus.Gauge
+	UnsafePayloadsCache *CacheMetrics
+
+	DerivationIdle       prometheus.Gauge
+	SequencerThrottled   prometheus.Gauge
+	SequencerL1OriginAge prometheus.Gauge
+
+	// timestamps of the current L1 origin of derivation, and of the latest batch processed by derivation,
+	// accessed atomically, to compute the age of the derived data at collection time
+	derivationOriginTime uint64
+	derivationBatchTime  uint64
 
 	PipelineResets   *EventMetrics
 	UnsafePayloads   *EventMetrics
@@ -52,8 +68,47 @@ type Metrics struct {
 	SequencingErrors *EventMetrics
 	PublishingErrors *EventMetrics
 
+	SequencerBuildDeadlineMissed *EventMetrics
+
+	DerivationStepDuration prometheus.Histogram
+	DerivationYields       *EventMetrics
+
 	UnsafePayloadsBufferLen     prometheus.Gauge
 	UnsafePayloadsBufferMemSize prometheus.Gauge
+	UnsafePayloadsRejected      *prometheus.CounterVec
+	UnsafeBackfillAttempts      *prometheus.CounterVec
+	UnsafeBackfillPayloads      prometheus.Counter
+	UnsafePayloadBatches        prometheus.Counter
+	UnsafePayloadBatchPayloads  prometheus.Counter
+
+	PeerPenalties *prometheus.CounterVec
+	BannedPeers   prometheus.Gauge
+	PeerDials     *prometheus.CounterVec
+
+	GossipPayloadWireSize *prometheus.HistogramVec
+	GossipPayloadSize     *prometheus.HistogramVec
+
+	ConsolidationMismatches *prometheus.CounterVec
+
+	ChannelBankSize      prometheus.Gauge
+	ChannelBankEvictions *EventMetrics
+
+	DroppedFrames   *prometheus.CounterVec
+	DroppedChannels *prometheus.CounterVec
+	DroppedBatches  *prometheus.CounterVec
+
+	DepositsPending         prometheus.Gauge
+	DepositInclusionLatency prometheus.Histogram
+
+	L1HeadSubscriptionDrops *EventMetrics
+
+	RuntimeConfigReloads        *EventMetrics
+	RuntimeConfigReloadFailures *EventMetrics
+
+	EngineTimeouts *prometheus.CounterVec
+	EngineRetries  *prometheus.CounterVec
+
+	EngineForkchoiceSkips prometheus.Counter
 
 	RefsNumber  *prometheus.GaugeVec
 	RefsTime    *prometheus.GaugeVec
@@ -64,23 +119,68 @@ type Metrics struct {
 	// and only count the first occurrence
 	LatencySeen map[string]common.Hash
 
+	clock clock.Clock
+
 	L1ReorgDepth prometheus.Histogram
 
 	TransactionsSequencedTotal prometheus.Counter
 
+	Heartbeats *prometheus.CounterVec
+
 	registry *prometheus.Registry
 }
 
+// DefaultRPCDurationBuckets are the buckets, in seconds, of the RPC request duration histograms.
+var DefaultRPCDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsConfig configures the naming and collectors of the metrics.
+// The zero value results in the default metrics of the op-node.
+type MetricsConfig struct {
+	// Namespace of the metrics, Namespace if empty.
+	Namespace string
+	// ProcName is appended to the namespace, "default" if empty.
+	ProcName string
+	// RPCDurationBuckets of the RPC server and client request durations, DefaultRPCDurationBuckets if empty.
+	RPCDurationBuckets []float64
+	// DisableGoCollector disables the Go runtime metrics.
+	DisableGoCollector bool
+	// DisableProcessCollector disables the process metrics.
+	DisableProcessCollector bool
+	// Clock to measure latencies and durations with, clock.SystemClock if nil.
+	Clock clock.Clock
+}
+
 func NewMetrics(procName string) *Metrics {
+	return NewMetricsWithConfig(MetricsConfig{ProcName: procName})
+}
+
+func NewMetricsWithConfig(cfg MetricsConfig) *Metrics {
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = Namespace
+	}
+	procName := cfg.ProcName
 	if procName == "" {
 		procName = "default"
 	}
-	ns := Namespace + "_" + procName
+	ns := namespace + "_" + procName
+	rpcDurationBuckets := cfg.RPCDurationBuckets
+	if len(rpcDurationBuckets) == 0 {
+		rpcDurationBuckets = DefaultRPCDurationBuckets
+	}
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.SystemClock
+	}
 
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-	registry.MustRegister(collectors.NewGoCollector())
-	return &Metrics{
+	if !cfg.DisableProcessCollector {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+	if !cfg.DisableGoCollector {
+		registry.MustRegister(collectors.NewGoCollector())
+	}
+	m := &Metrics{
 		Info: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: ns,
 			Name:      "info",
@@ -88,6 +188,13 @@ func NewMetrics(procName string) *Metrics {
 		}, []string{
 			"version",
 		}),
+		ConfigInfo: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "rollup_config_info",
+			Help:      "Pseudo-metric tracking the hash of the rollup config",
+		}, []string{
+			"config_hash",
+		}),
 		Up: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
 			Name:      "up",
@@ -106,11 +213,19 @@ func NewMetrics(procName string) *Metrics {
 			Namespace: ns,
 			Subsystem: RPCServerSubsystem,
 			Name:      "request_duration_seconds",
-			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			Buckets:   rpcDurationBuckets,
 			Help:      "Histogram of RPC server request durations",
 		}, []string{
 			"method",
 		}),
+		RPCServerRejectedTotal: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: RPCServerSubsystem,
+			Name:      "rejected_total",
+			Help:      "Total requests rejected by the RPC server access control, by reason",
+		}, []string{
+			"reason",
+		}),
 		RPCClientRequestsTotal: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
 			Namespace: ns,
 			Subsystem: RPCClientSubsystem,
@@ -123,7 +238,7 @@ func NewMetrics(procName string) *Metrics {
 			Namespace: ns,
 			Subsystem: RPCClientSubsystem,
 			Name:      "request_duration_seconds",
-			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			Buckets:   rpcDurationBuckets,
 			Help:      "Histogram of RPC client request durations",
 		}, []string{
 			"method",
@@ -137,15 +252,34 @@ func NewMetrics(procName string) *Metrics {
 			"method",
 			"error",
 		}),
+		RPCClientBatchSize: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: RPCClientSubsystem,
+			Name:      "batch_size",
+			Buckets:   []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+			Help:      "Histogram of the number of elements in RPC client batch requests",
+		}),
 
 		L1SourceCache: NewCacheMetrics(registry, ns, "l1_source_cache", "L1 Source cache"),
 		L2SourceCache: NewCacheMetrics(registry, ns, "l2_source_cache", "L2 Source cache"),
 
+		UnsafePayloadsCache: NewCacheMetrics(registry, ns, "unsafe_payloads_cache", "Unsafe payloads cache"),
+
 		DerivationIdle: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
 			Name:      "derivation_idle",
 			Help:      "1 if the derivation pipeline is idle",
 		}),
+		SequencerThrottled: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "sequencer_throttled",
+			Help:      "1 if the sequencer is paused because the safe head lags too far behind the unsafe head",
+		}),
+		SequencerL1OriginAge: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "sequencer_l1_origin_age",
+			Help:      "Number of L1 blocks on top of the L1 origin of the last block built by the sequencer, at the time of building",
+		}),
 
 		PipelineResets:   NewEventMetrics(registry, ns, "pipeline_resets", "derivation pipeline resets"),
 		UnsafePayloads:   NewEventMetrics(registry, ns, "unsafe_payloads", "unsafe payloads"),
@@ -153,6 +287,19 @@ func NewMetrics(procName string) *Metrics {
 		SequencingErrors: NewEventMetrics(registry, ns, "sequencing_errors", "sequencing errors"),
 		PublishingErrors: NewEventMetrics(registry, ns, "publishing_errors", "p2p publishing errors"),
 
+		SequencerBuildDeadlineMissed: NewEventMetrics(registry, ns, "sequencer_build_deadline_missed", "blocks that the sequencer did not build within the deadline"),
+
+		DerivationStepDuration: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "derivation_step_duration_seconds",
+			Help:      "Histogram of the duration of derivation pipeline steps, the count is the number of steps",
+			Buckets:   []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}),
+		DerivationYields: NewEventMetrics(registry, ns, "derivation_yields", "derivation step budgets used up, yielding the driver to other events"),
+
+		RuntimeConfigReloads:        NewEventMetrics(registry, ns, "runtime_config_reloads", "runtime config reload attempts"),
+		RuntimeConfigReloadFailures: NewEventMetrics(registry, ns, "runtime_config_reload_failures", "failed runtime config reloads"),
+
 		UnsafePayloadsBufferLen: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
 			Name:      "unsafe_payloads_buffer_len",
@@ -163,6 +310,144 @@ func NewMetrics(procName string) *Metrics {
 			Name:      "unsafe_payloads_buffer_mem_size",
 			Help:      "Total estimated memory size of buffered L2 unsafe payloads",
 		}),
+		UnsafePayloadsRejected: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "unsafe_payloads_rejected_total",
+			Help:      "Count of rejected L2 unsafe payloads, by rejection reason",
+		}, []string{
+			"reason",
+		}),
+		UnsafeBackfillAttempts: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "unsafe_backfill_attempts_total",
+			Help:      "Count of attempts to backfill missing L2 unsafe payloads, by result",
+		}, []string{
+			"result",
+		}),
+		UnsafeBackfillPayloads: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "unsafe_backfill_payloads_total",
+			Help:      "Count of backfilled L2 unsafe payloads",
+		}),
+		UnsafePayloadBatches: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "unsafe_payload_batches_total",
+			Help:      "Count of batches of L2 unsafe payloads inserted into the engine with a single forkchoice update",
+		}),
+		UnsafePayloadBatchPayloads: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "unsafe_payload_batch_payloads_total",
+			Help:      "Count of L2 unsafe payloads inserted into the engine as part of a batch",
+		}),
+
+		PeerPenalties: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "peer_penalties_total",
+			Help:      "Count of penalties of peers for misbehavior, by reason",
+		}, []string{
+			"reason",
+		}),
+		BannedPeers: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "banned_peers",
+			Help:      "Number of peers that are temporarily banned for misbehavior",
+		}),
+		PeerDials: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "peer_dials_total",
+			Help:      "Count of dials of peers, by source of the peer (static or discovery) and result (success or failure)",
+		}, []string{
+			"source",
+			"result",
+		}),
+
+		GossipPayloadWireSize: promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "gossip_payload_wire_size_bytes",
+			Help:      "Histogram of the snappy compressed size of gossiped payload messages, by direction",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 2, 11),
+		}, []string{
+			"direction",
+		}),
+		GossipPayloadSize: promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "gossip_payload_size_bytes",
+			Help:      "Histogram of the decompressed size of gossiped payload messages, by direction",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 2, 11),
+		}, []string{
+			"direction",
+		}),
+
+		ConsolidationMismatches: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "consolidation_mismatches_total",
+			Help:      "Count of mismatched fields of unsafe L2 blocks that did not match the attributes derived from L1, by field",
+		}, []string{
+			"field",
+		}),
+
+		EngineTimeouts: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "engine_timeouts_total",
+			Help:      "Count of engine API calls that timed out, by method",
+		}, []string{
+			"method",
+		}),
+		EngineRetries: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "engine_retries_total",
+			Help:      "Count of retried engine API calls, by method",
+		}, []string{
+			"method",
+		}),
+		EngineForkchoiceSkips: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "engine_forkchoice_skips_total",
+			Help:      "Count of redundant forkchoice updates that were not sent to the engine",
+		}),
+
+		ChannelBankSize: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "channel_bank_size",
+			Help:      "Total estimated memory size, in bytes, of the channels buffered in the channel bank",
+		}),
+		ChannelBankEvictions: NewEventMetrics(registry, ns, "channel_bank_evictions", "channels evicted from the full channel bank"),
+
+		DroppedFrames: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "derivation_dropped_frames_total",
+			Help:      "Count of frames, or batch tx data of frames, dropped by the channel bank, by reason",
+		}, []string{
+			"reason",
+		}),
+		DroppedChannels: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "derivation_dropped_channels_total",
+			Help:      "Count of incomplete channels dropped by the channel bank, by reason",
+		}, []string{
+			"reason",
+		}),
+		DroppedBatches: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "derivation_dropped_batches_total",
+			Help:      "Count of invalid batches dropped by the batch queue, by reason",
+		}, []string{
+			"reason",
+		}),
+
+		DepositsPending: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "deposits_pending",
+			Help:      "Number of L1 deposits derived into payload attributes, but not yet included in the L2 safe chain",
+		}),
+		DepositInclusionLatency: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "deposit_inclusion_latency_seconds",
+			Help:      "Histogram of the time between the L1 inclusion and the L2 inclusion of deposits, by block timestamps",
+			Buckets:   []float64{2, 4, 8, 12, 24, 48, 96, 192, 384, 768, 1536},
+		}),
+
+		L1HeadSubscriptionDrops: NewEventMetrics(registry, ns, "l1_head_subscription_drops", "dropped L1 head subscriptions"),
 
 		RefsNumber: promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: ns,
@@ -205,6 +490,8 @@ func NewMetrics(procName string) *Metrics {
 		}),
 		LatencySeen: make(map[string]common.Hash),
 
+		clock: clk,
+
 		L1ReorgDepth: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
 			Namespace: ns,
 			Name:      "l1_reorg_depth",
@@ -218,8 +505,40 @@ func NewMetrics(procName string) *Metrics {
 			Help:      "Count of total transactions sequenced",
 		}),
 
+		Heartbeats: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "heartbeat",
+			Name:      "sent_total",
+			Help:      "Count of heartbeats sent, by success",
+		}, []string{
+			"success",
+		}),
+
 		registry: registry,
 	}
+	promauto.With(registry).NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "derivation_origin_age_seconds",
+		Help:      "Wall-clock age of the current L1 origin of the derivation pipeline, in seconds",
+	}, func() float64 {
+		return m.age(atomic.LoadUint64(&m.derivationOriginTime))
+	})
+	promauto.With(registry).NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "derivation_batch_age_seconds",
+		Help:      "Wall-clock age of the L2 timestamp of the latest batch processed by the derivation pipeline, in seconds",
+	}, func() float64 {
+		return m.age(atomic.LoadUint64(&m.derivationBatchTime))
+	})
+	return m
+}
+
+// age returns the seconds elapsed since the given unix timestamp, or 0 if the timestamp is not set.
+func (m *Metrics) age(timestamp uint64) float64 {
+	if timestamp == 0 {
+		return 0
+	}
+	return float64(m.clock.Now().UnixNano())/1e9 - float64(timestamp)
 }
 
 // RecordInfo sets a pseudo-metric that contains versioning and
@@ -228,6 +547,12 @@ func (m *Metrics) RecordInfo(version string) {
 	m.Info.WithLabelValues(version).Set(1)
 }
 
+// RecordConfigHash sets a pseudo-metric that identifies the rollup config
+// by its hash, see rollup.ComputeConfigHash.
+func (m *Metrics) RecordConfigHash(configHash string) {
+	m.ConfigInfo.WithLabelValues(configHash).Set(1)
+}
+
 // RecordUp sets the up metric to 1.
 func (m *Metrics) RecordUp() {
 	prometheus.MustRegister()
@@ -237,23 +562,49 @@ func (m *Metrics) RecordUp() {
 // RecordRPCServerRequest is a helper method to record an incoming RPC
 // call to the opnode's RPC server. It bumps the requests metric,
 // and tracks how long it takes to serve a response.
-func (m *Metrics) RecordRPCServerRequest(method string) func() {
+// The duration is linked to the trace ID of the context, if any, as exemplar.
+func (m *Metrics) RecordRPCServerRequest(ctx context.Context, method string) func() {
 	m.RPCServerRequestsTotal.WithLabelValues(method).Inc()
-	timer := prometheus.NewTimer(m.RPCServerRequestDurationSeconds.WithLabelValues(method))
+	start := m.clock.Now()
 	return func() {
-		timer.ObserveDuration()
+		observeDuration(ctx, m.RPCServerRequestDurationSeconds.WithLabelValues(method), m.clock.Since(start))
 	}
 }
 
+// RecordRPCServerRejected records a request that was rejected by the access control of the RPC server.
+// The reason is one of a fixed set of values, the method is not recorded as it is chosen by the client.
+func (m *Metrics) RecordRPCServerRejected(reason string) {
+	m.RPCServerRejectedTotal.WithLabelValues(reason).Inc()
+}
+
 // RecordRPCClientRequest is a helper method to record an RPC client
 // request. It bumps the requests metric, tracks the response
 // duration, and records the response's error code.
-func (m *Metrics) RecordRPCClientRequest(method string) func(err error) {
+// The duration is linked to the trace ID of the context, if any, as exemplar.
+func (m *Metrics) RecordRPCClientRequest(ctx context.Context, method string) func(err error) {
 	m.RPCClientRequestsTotal.WithLabelValues(method).Inc()
-	timer := prometheus.NewTimer(m.RPCClientRequestDurationSeconds.WithLabelValues(method))
+	start := m.clock.Now()
 	return func(err error) {
 		m.RecordRPCClientResponse(method, err)
-		timer.ObserveDuration()
+		observeDuration(ctx, m.RPCClientRequestDurationSeconds.WithLabelValues(method), m.clock.Since(start))
+	}
+}
+
+// RecordRPCClientBatchDuration returns a function to record the duration of an RPC client batch request,
+// linked to the trace ID of the context, if any, as exemplar.
+func (m *Metrics) RecordRPCClientBatchDuration(ctx context.Context) func() {
+	start := m.clock.Now()
+	return func() {
+		observeDuration(ctx, m.RPCClientRequestDurationSeconds.WithLabelValues(BatchMethod), m.clock.Since(start))
+	}
+}
+
+// RecordRPCClientBatch records an RPC client batch request: the batch size,
+// and a request for each of the individual batch elements, like for requests that are not batched.
+func (m *Metrics) RecordRPCClientBatch(b []rpc.BatchElem) {
+	m.RPCClientBatchSize.Observe(float64(len(b)))
+	for _, elem := range b {
+		m.RPCClientRequestsTotal.WithLabelValues(elem.Method).Inc()
 	}
 }
 
@@ -289,6 +640,14 @@ func (m *Metrics) SetDerivationIdle(status bool) {
 	m.DerivationIdle.Set(val)
 }
 
+func (m *Metrics) SetSequencerThrottled(throttled bool) {
+	var val float64
+	if throttled {
+		val = 1
+	}
+	m.SequencerThrottled.Set(val)
+}
+
 func (m *Metrics) RecordPipelineReset() {
 	m.PipelineResets.RecordEvent()
 }
@@ -297,6 +656,34 @@ func (m *Metrics) RecordSequencingError() {
 	m.SequencingErrors.RecordEvent()
 }
 
+func (m *Metrics) RecordSequencerBuildDeadlineMissed() {
+	m.SequencerBuildDeadlineMissed.RecordEvent()
+}
+
+func (m *Metrics) RecordSequencerL1OriginAge(blocks uint64) {
+	m.SequencerL1OriginAge.Set(float64(blocks))
+}
+
+// RecordDerivationOrigin records the current L1 origin of the derivation pipeline,
+// to report its wall-clock age: the age grows while derivation is stalled, or behind on old L1 data.
+func (m *Metrics) RecordDerivationOrigin(origin eth.L1BlockRef) {
+	atomic.StoreUint64(&m.derivationOriginTime, origin.Time)
+}
+
+// RecordProcessedBatch records the L2 timestamp of the latest batch processed by the derivation pipeline,
+// to report its wall-clock age.
+func (m *Metrics) RecordProcessedBatch(l2Time uint64) {
+	atomic.StoreUint64(&m.derivationBatchTime, l2Time)
+}
+
+func (m *Metrics) RecordDerivationStep(d time.Duration) {
+	m.DerivationStepDuration.Observe(d.Seconds())
+}
+
+func (m *Metrics) RecordDerivationYield() {
+	m.DerivationYields.RecordEvent()
+}
+
 func (m *Metrics) RecordPublishingError() {
 	m.PublishingErrors.RecordEvent()
 }
@@ -317,7 +704,7 @@ func (m *Metrics) recordRef(layer string, name string, num uint64, timestamp uin
 		// only meter the latency when we first see this hash for the given label name
 		if m.LatencySeen[name] != h {
 			m.LatencySeen[name] = h
-			m.RefsLatency.WithLabelValues(layer, name).Set(float64(timestamp) - (float64(time.Now().UnixNano()) / 1e9))
+			m.RefsLatency.WithLabelValues(layer, name).Set(float64(timestamp) - (float64(m.clock.Now().UnixNano()) / 1e9))
 		}
 	}
 	// we map the first 8 bytes to a float64, so we can graph changes of the hash to find divergences visually.
@@ -341,6 +728,109 @@ func (m *Metrics) RecordUnsafePayloadsBuffer(length uint64, memSize uint64, next
 	m.UnsafePayloadsBufferMemSize.Set(float64(memSize))
 }
 
+// RecordUnsafePayloadRejected counts an unsafe payload that was rejected by gossip validation
+// or by the driver, with the reason for rejection, e.g. "bad_signature" or "parent_unknown".
+func (m *Metrics) RecordUnsafePayloadRejected(reason string) {
+	m.UnsafePayloadsRejected.WithLabelValues(reason).Inc()
+}
+
+// RecordUnsafeBackfill counts an attempt to backfill the unsafe payloads missing before the queued unsafe payloads,
+// with the result, e.g. "connected" or "too_deep", and the number of backfilled payloads.
+func (m *Metrics) RecordUnsafeBackfill(result string, payloads int) {
+	m.UnsafeBackfillAttempts.WithLabelValues(result).Inc()
+	m.UnsafeBackfillPayloads.Add(float64(payloads))
+}
+
+// RecordUnsafePayloadBatch counts a batch of unsafe payloads that was inserted with a single forkchoice update.
+func (m *Metrics) RecordUnsafePayloadBatch(payloads int) {
+	m.UnsafePayloadBatches.Inc()
+	m.UnsafePayloadBatchPayloads.Add(float64(payloads))
+}
+
+func (m *Metrics) RecordPeerPenalty(reason string) {
+	m.PeerPenalties.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) SetBannedPeers(count int) {
+	m.BannedPeers.Set(float64(count))
+}
+
+func (m *Metrics) RecordPeerDial(source string, success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	m.PeerDials.WithLabelValues(source, result).Inc()
+}
+
+func (m *Metrics) RecordGossipPayloadSize(direction string, wireSize int, size int) {
+	m.GossipPayloadWireSize.WithLabelValues(direction).Observe(float64(wireSize))
+	m.GossipPayloadSize.WithLabelValues(direction).Observe(float64(size))
+}
+
+// RecordConsolidationMismatch counts a field of an unsafe L2 block that did not match the attributes derived from L1,
+// e.g. "timestamp" or "transactions".
+func (m *Metrics) RecordConsolidationMismatch(field string) {
+	m.ConsolidationMismatches.WithLabelValues(field).Inc()
+}
+
+func (m *Metrics) RecordEngineTimeout(method string) {
+	m.EngineTimeouts.WithLabelValues(method).Inc()
+}
+
+func (m *Metrics) RecordEngineRetry(method string) {
+	m.EngineRetries.WithLabelValues(method).Inc()
+}
+
+func (m *Metrics) RecordEngineForkchoiceSkip() {
+	m.EngineForkchoiceSkips.Inc()
+}
+
+func (m *Metrics) RecordChannelBankSize(size uint64) {
+	m.ChannelBankSize.Set(float64(size))
+}
+
+func (m *Metrics) RecordChannelBankEviction() {
+	m.ChannelBankEvictions.RecordEvent()
+}
+
+// RecordDroppedFrame counts frame data dropped by the channel bank, e.g. with reason "bad_version" or "channel_timeout".
+func (m *Metrics) RecordDroppedFrame(reason string) {
+	m.DroppedFrames.WithLabelValues(reason).Inc()
+}
+
+// RecordDroppedChannel counts an incomplete channel dropped by the channel bank, with reason "timeout" or "overflow".
+func (m *Metrics) RecordDroppedChannel(reason string) {
+	m.DroppedChannels.WithLabelValues(reason).Inc()
+}
+
+// RecordDroppedBatch counts an invalid batch dropped by the batch queue, e.g. with reason "old_timestamp" or "parent_hash".
+func (m *Metrics) RecordDroppedBatch(reason string) {
+	m.DroppedBatches.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) RecordDepositsPending(count uint64) {
+	m.DepositsPending.Set(float64(count))
+}
+
+func (m *Metrics) RecordDepositsIncluded(count uint64, latency time.Duration) {
+	for i := uint64(0); i < count; i++ {
+		m.DepositInclusionLatency.Observe(latency.Seconds())
+	}
+}
+
+func (m *Metrics) RecordL1HeadSubscriptionDrop() {
+	m.L1HeadSubscriptionDrops.RecordEvent()
+}
+
+// RecordRuntimeConfigReload counts an attempt to reload the runtime config, and whether it failed.
+func (m *Metrics) RecordRuntimeConfigReload(success bool) {
+	m.RuntimeConfigReloads.RecordEvent()
+	if !success {
+		m.RuntimeConfigReloadFailures.RecordEvent()
+	}
+}
+
 func (m *Metrics) CountSequencedTxs(count int) {
 	m.TransactionsSequencedTotal.Add(float64(count))
 }
@@ -349,15 +839,41 @@ func (m *Metrics) RecordL1ReorgDepth(d uint64) {
 	m.L1ReorgDepth.Observe(float64(d))
 }
 
+// RecordHeartbeat counts a sent heartbeat, and whether it was successful.
+func (m *Metrics) RecordHeartbeat(success bool) {
+	m.Heartbeats.WithLabelValues(strconv.FormatBool(success)).Inc()
+}
+
+// ServerConfig configures the optional features of the metrics server.
+// The zero value serves metrics without authentication or access logging.
+type ServerConfig struct {
+	// AuthToken, if not empty, must be presented as bearer token in the Authorization header of every request.
+	AuthToken string
+	// AccessLog, if not nil, logs every request to the metrics server.
+	AccessLog log.Logger
+}
+
+// Handler returns the HTTP handler that serves the metrics, with the given server options applied.
+func (m *Metrics) Handler(cfg ServerConfig) http.Handler {
+	handler := promhttp.InstrumentMetricHandler(
+		m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+	if cfg.AuthToken != "" {
+		handler = bearerAuthHandler(cfg.AuthToken, handler)
+	}
+	if cfg.AccessLog != nil {
+		handler = accessLogHandler(cfg.AccessLog, handler)
+	}
+	return handler
+}
+
 // Serve starts the metrics server on the given hostname and port.
 // The server will be closed when the passed-in context is cancelled.
-func (m *Metrics) Serve(ctx context.Context, hostname string, port int) error {
+func (m *Metrics) Serve(ctx context.Context, hostname string, port int, cfg ServerConfig) error {
 	addr := net.JoinHostPort(hostname, strconv.Itoa(port))
 	server := &http.Server{
-		Addr: addr,
-		Handler: promhttp.InstrumentMetricHandler(
-			m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}),
-		),
+		Addr:    addr,
+		Handler: m.Handler(cfg),
 	}
 	go func() {
 		<-ctx.Done()
@@ -365,3 +881,36 @@ func (m *Metrics) Serve(ctx context.Context, hostname string, port int) error {
 	}()
 	return server.ListenAndServe()
 }
+
+func bearerAuthHandler(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the response status code for access logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func accessLogHandler(log log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Info("served metrics request", "remote", r.RemoteAddr, "method", r.Method, "path", r.URL.Path,
+			"status", rec.status, "duration", time.Since(start))
+	})
+}
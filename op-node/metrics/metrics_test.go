@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/clock"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func serveMetrics(t *testing.T, cfg ServerConfig, authorization string) *httptest.ResponseRecorder {
+	m := NewMetrics("")
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	m.Handler(cfg).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeNoAuth(t *testing.T) {
+	rec := serveMetrics(t, ServerConfig{}, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "op_node_default_up")
+}
+
+func TestServeAuth(t *testing.T) {
+	cfg := ServerConfig{AuthToken: "secret"}
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := serveMetrics(t, cfg, "")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		require.NotContains(t, rec.Body.String(), "op_node_default_up")
+	})
+	t.Run("wrong token", func(t *testing.T) {
+		rec := serveMetrics(t, cfg, "Bearer wrong")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+	t.Run("wrong scheme", func(t *testing.T) {
+		rec := serveMetrics(t, cfg, "Basic secret")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+	t.Run("valid token", func(t *testing.T) {
+		rec := serveMetrics(t, cfg, "Bearer secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "op_node_default_up")
+	})
+}
+
+func TestServeAccessLog(t *testing.T) {
+	cfg := ServerConfig{AuthToken: "secret", AccessLog: testlog.Logger(t, log.LvlInfo)}
+	require.Equal(t, http.StatusUnauthorized, serveMetrics(t, cfg, "").Code, "unauthorized requests are logged too")
+	require.Equal(t, http.StatusOK, serveMetrics(t, cfg, "Bearer secret").Code)
+}
+
+func TestRecordRPCClientBatch(t *testing.T) {
+	m := NewMetrics("")
+	m.RecordRPCClientBatch([]rpc.BatchElem{
+		{Method: "eth_getTransactionReceipt"},
+		{Method: "eth_getTransactionReceipt"},
+		{Method: "eth_getBlockByHash"},
+	})
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RPCClientRequestsTotal.WithLabelValues("eth_getTransactionReceipt")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RPCClientRequestsTotal.WithLabelValues("eth_getBlockByHash")))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.RPCClientRequestsTotal.WithLabelValues(BatchMethod)), "batches are not counted as requests")
+	require.Equal(t, 1, testutil.CollectAndCount(m.RPCClientBatchSize))
+}
+
+func TestNewMetricsWithConfig(t *testing.T) {
+	m := NewMetricsWithConfig(MetricsConfig{
+		Namespace:               "op_custom",
+		ProcName:                "replica",
+		RPCDurationBuckets:      []float64{0.5, 1},
+		DisableGoCollector:      true,
+		DisableProcessCollector: true,
+	})
+	m.RPCServerRequestDurationSeconds.WithLabelValues("eth_chainId").Observe(0.1)
+	mfs, err := m.registry.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, mf := range mfs {
+		names[mf.GetName()] = true
+		require.NotContains(t, mf.GetName(), "go_", "go collector is disabled")
+		require.NotContains(t, mf.GetName(), "process_", "process collector is disabled")
+		if mf.GetName() == "op_custom_replica_rpc_server_request_duration_seconds" {
+			require.Len(t, mf.GetMetric()[0].GetHistogram().GetBucket(), 2)
+		}
+	}
+	require.True(t, names["op_custom_replica_up"])
+	require.True(t, names["op_custom_replica_rpc_server_request_duration_seconds"])
+}
+
+func TestParseTraceParent(t *testing.T) {
+	id, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", id)
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceParent(v)
+		require.False(t, ok, "invalid traceparent %q", v)
+	}
+}
+
+func TestRPCDurationExemplars(t *testing.T) {
+	m := NewMetrics("")
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	m.RecordRPCServerRequest(WithTraceID(context.Background(), traceID), "optimism_syncStatus")()
+	m.RecordRPCClientRequest(context.Background(), "eth_chainId")(nil)
+
+	exemplars := func(name string) (out []string) {
+		mfs, err := m.registry.Gather()
+		require.NoError(t, err)
+		for _, mf := range mfs {
+			if mf.GetName() != name {
+				continue
+			}
+			for _, b := range mf.GetMetric()[0].GetHistogram().GetBucket() {
+				for _, l := range b.GetExemplar().GetLabel() {
+					if l.GetName() == TraceIDLabel {
+						out = append(out, l.GetValue())
+					}
+				}
+			}
+		}
+		return out
+	}
+	require.Equal(t, []string{traceID}, exemplars("op_node_default_rpc_server_request_duration_seconds"))
+	require.Empty(t, exemplars("op_node_default_rpc_client_request_duration_seconds"), "no trace, no exemplar")
+}
+
+func TestTraceHandler(t *testing.T) {
+	var got string
+	h := NewTraceHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = TraceIDFromContext(r.Context())
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got)
+}
+
+func TestDeterministicClock(t *testing.T) {
+	clk := clock.NewDeterministicClock(time.Unix(1000, 0))
+	m := NewMetricsWithConfig(MetricsConfig{Clock: clk})
+
+	// the latency of a ref is the time of the block relative to the time the ref is first seen
+	clk.AdvanceTime(3 * time.Second)
+	m.RecordL2Ref("l2_unsafe", eth.L2BlockRef{Hash: common.Hash{1}, Number: 10, Time: 1000})
+	require.Equal(t, -3.0, testutil.ToFloat64(m.RefsLatency.WithLabelValues("l2", "l2_unsafe")))
+	clk.AdvanceTime(time.Second)
+	m.RecordL2Ref("l2_unsafe", eth.L2BlockRef{Hash: common.Hash{1}, Number: 10, Time: 1000})
+	require.Equal(t, -3.0, testutil.ToFloat64(m.RefsLatency.WithLabelValues("l2", "l2_unsafe")), "only the first sighting")
+	m.RecordL2Ref("l2_unsafe", eth.L2BlockRef{Hash: common.Hash{2}, Number: 11, Time: 1006})
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RefsLatency.WithLabelValues("l2", "l2_unsafe")))
+
+	done := m.RecordRPCServerRequest(context.Background(), "optimism_syncStatus")
+	clk.AdvanceTime(1500 * time.Millisecond)
+	done()
+	mfs, err := m.registry.Gather()
+	require.NoError(t, err)
+	var sum float64
+	for _, mf := range mfs {
+		if mf.GetName() == "op_node_default_rpc_server_request_duration_seconds" {
+			sum = mf.GetMetric()[0].GetHistogram().GetSampleSum()
+		}
+	}
+	require.Equal(t, 1.5, sum)
+}
+
+func TestDerivationAge(t *testing.T) {
+	clk := clock.NewDeterministicClock(time.Unix(1000, 0))
+	m := NewMetricsWithConfig(MetricsConfig{Clock: clk})
+	gauge := func(name string) float64 {
+		mfs, err := m.registry.Gather()
+		require.NoError(t, err)
+		for _, mf := range mfs {
+			if mf.GetName() == name {
+				return mf.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatalf("metric %s not found", name)
+		return 0
+	}
+
+	require.Equal(t, 0.0, gauge("op_node_default_derivation_origin_age_seconds"), "no origin yet")
+	m.RecordDerivationOrigin(eth.L1BlockRef{Number: 10, Time: 900})
+	m.RecordProcessedBatch(950)
+	require.Equal(t, 100.0, gauge("op_node_default_derivation_origin_age_seconds"))
+	require.Equal(t, 50.0, gauge("op_node_default_derivation_batch_age_seconds"))
+
+	// without progress, the age keeps growing
+	clk.AdvanceTime(10 * time.Second)
+	require.Equal(t, 110.0, gauge("op_node_default_derivation_origin_age_seconds"))
+	require.Equal(t, 60.0, gauge("op_node_default_derivation_batch_age_seconds"))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceIDLabel is the exemplar label of the trace ID of an observed request.
+const TraceIDLabel = "trace_id"
+
+// TraceParentHeader is the W3C trace-context header that trace IDs are extracted from.
+const TraceParentHeader = "traceparent"
+
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of the context that carries the given trace ID.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID of the context, if any.
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDKey{}).(string)
+	return traceID, ok && traceID != ""
+}
+
+// ParseTraceParent returns the trace ID of a W3C traceparent header value,
+// formatted as "<version>-<trace-id>-<parent-id>-<flags>".
+func ParseTraceParent(v string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
+	}
+	if strings.Trim(parts[1], "0") == "" {
+		return "", false // the all-zero trace ID is invalid
+	}
+	return strings.ToLower(parts[1]), true
+}
+
+// NewTraceHandler wraps the handler to attach the trace ID of the traceparent header,
+// if present and valid, to the request context. The RPC server passes the request context
+// to the RPC methods, so the RPC request metrics can link their observations to the trace.
+func NewTraceHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if traceID, ok := ParseTraceParent(r.Header.Get(TraceParentHeader)); ok {
+			r = r.WithContext(WithTraceID(r.Context(), traceID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// observeDuration observes the duration, with the trace ID of the context as exemplar if there is one.
+func observeDuration(ctx context.Context, obs prometheus.Observer, duration time.Duration) {
+	d := duration.Seconds()
+	if traceID, ok := TraceIDFromContext(ctx); ok {
+		if eo, ok := obs.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(d, prometheus.Labels{TraceIDLabel: traceID})
+			return
+		}
+	}
+	obs.Observe(d)
+}
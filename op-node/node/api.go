@@ -3,6 +3,8 @@ package node
 import (
 	"context"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
@@ -12,48 +14,89 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/version"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
-type l2EthClient interface {
+// L2EthClient is the L2 execution engine RPC access required to compute output roots.
+type L2EthClient interface {
 	InfoByRpcNumber(ctx context.Context, num rpc.BlockNumber) (eth.BlockInfo, error)
 	// GetProof returns a proof of the account, it may return a nil result without error if the address was not found.
 	GetProof(ctx context.Context, address common.Address, blockTag string) (*eth.AccountResult, error)
 }
 
+// syncStatusPollInterval is the interval at which sync status subscriptions check the sync status for changes.
+const syncStatusPollInterval = time.Second
+
 type driverClient interface {
 	SyncStatus(ctx context.Context) (*driver.SyncStatus, error)
 	ResetDerivationPipeline(context.Context) error
+	SequencerThrottle(ctx context.Context) (*driver.SequencerThrottle, error)
+	SetSequencerMaxSafeLag(ctx context.Context, maxSafeLag uint64) error
+	SubscribeHeads(ch chan driver.HeadUpdate) (unsubscribe func())
+}
+
+type nodeAdmin interface {
+	ReloadRuntimeConfig(ctx context.Context) error
+	SetLogLevel(subsystem string, level string) error
 }
 
 type adminAPI struct {
-	dr driverClient
-	m  *metrics.Metrics
+	dr   driverClient
+	node nodeAdmin
+	m    *metrics.Metrics
 }
 
-func newAdminAPI(dr driverClient, m *metrics.Metrics) *adminAPI {
+func newAdminAPI(dr driverClient, node nodeAdmin, m *metrics.Metrics) *adminAPI {
 	return &adminAPI{
-		dr: dr,
-		m:  m,
+		dr:   dr,
+		node: node,
+		m:    m,
 	}
 }
 
 func (n *adminAPI) ResetDerivationPipeline(ctx context.Context) error {
-	recordDur := n.m.RecordRPCServerRequest("admin_resetDerivationPipeline")
+	recordDur := n.m.RecordRPCServerRequest(ctx, "admin_resetDerivationPipeline")
 	defer recordDur()
 	return n.dr.ResetDerivationPipeline(ctx)
 }
 
+func (n *adminAPI) SequencerThrottle(ctx context.Context) (*driver.SequencerThrottle, error) {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "admin_sequencerThrottle")
+	defer recordDur()
+	return n.dr.SequencerThrottle(ctx)
+}
+
+func (n *adminAPI) SetSequencerMaxSafeLag(ctx context.Context, maxSafeLag hexutil.Uint64) error {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "admin_setSequencerMaxSafeLag")
+	defer recordDur()
+	return n.dr.SetSequencerMaxSafeLag(ctx, uint64(maxSafeLag))
+}
+
+// ReloadRuntimeConfig reloads the runtime config file of the node, like a SIGHUP does.
+func (n *adminAPI) ReloadRuntimeConfig(ctx context.Context) error {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "admin_reloadRuntimeConfig")
+	defer recordDur()
+	return n.node.ReloadRuntimeConfig(ctx)
+}
+
+// SetLogLevel changes the log level of a subsystem of the node, or the default log level if the subsystem is empty.
+func (n *adminAPI) SetLogLevel(ctx context.Context, subsystem string, level string) error {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "admin_setLogLevel")
+	defer recordDur()
+	return n.node.SetLogLevel(subsystem, level)
+}
+
 type nodeAPI struct {
 	config *rollup.Config
-	client l2EthClient
+	client L2EthClient
 	dr     driverClient
 	log    log.Logger
 	m      *metrics.Metrics
 }
 
-func newNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger, m *metrics.Metrics) *nodeAPI {
+func newNodeAPI(config *rollup.Config, l2Client L2EthClient, dr driverClient, log log.Logger, m *metrics.Metrics) *nodeAPI {
 	return &nodeAPI{
 		config: config,
 		client: l2Client,
@@ -64,59 +107,140 @@ func newNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, lo
 }
 
 func (n *nodeAPI) OutputAtBlock(ctx context.Context, number rpc.BlockNumber) ([]eth.Bytes32, error) {
-	recordDur := n.m.RecordRPCServerRequest("optimism_outputAtBlock")
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_outputAtBlock")
 	defer recordDur()
 	// TODO: rpc.BlockNumber doesn't support the "safe" tag. Need a new type
 
-	head, err := n.client.InfoByRpcNumber(ctx, number)
+	output, err := OutputRootAtBlock(ctx, n.client, number)
 	if err != nil {
-		n.log.Error("failed to get block", "err", err)
+		n.log.Error("failed to compute output root", "blocknum", number, "err", err)
 		return nil, err
 	}
+	return []eth.Bytes32{output.Version, output.OutputRoot}, nil
+}
+
+// L2Output is the output root of an L2 block, along with the values that it commits to.
+type L2Output struct {
+	Version               eth.Bytes32 `json:"version"`
+	OutputRoot            eth.Bytes32 `json:"outputRoot"`
+	BlockHash             common.Hash `json:"blockHash"`
+	BlockNumber           uint64      `json:"blockNumber"`
+	StateRoot             common.Hash `json:"stateRoot"`
+	WithdrawalStorageRoot common.Hash `json:"withdrawalStorageRoot"`
+}
+
+// OutputRootAtBlock computes the output root of the given L2 block.
+// The storage proof of the withdrawals contract is verified against the state root of the block.
+func OutputRootAtBlock(ctx context.Context, client L2EthClient, number rpc.BlockNumber) (*L2Output, error) {
+	head, err := client.InfoByRpcNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
 	if head == nil {
 		return nil, ethereum.NotFound
 	}
 
-	proof, err := n.client.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, toBlockNumArg(number))
+	// get the proof at the block hash, the block of a label like "latest" may have changed since the block was retrieved
+	proof, err := client.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, head.Hash().String())
 	if err != nil {
-		n.log.Error("failed to get contract proof", "err", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to get contract proof: %w", err)
 	}
 	if proof == nil {
 		return nil, ethereum.NotFound
 	}
 	// make sure that the proof (including storage hash) that we retrieved is correct by verifying it against the state-root
 	if err := proof.Verify(head.Root()); err != nil {
-		n.log.Error("invalid withdrawal root detected in block", "stateRoot", head.Root(), "blocknum", number, "msg", err)
-		return nil, fmt.Errorf("invalid withdrawal root hash")
+		return nil, fmt.Errorf("invalid withdrawal root hash, state root %s: %w", head.Root(), err)
 	}
 
 	var l2OutputRootVersion eth.Bytes32 // it's zero for now
 	l2OutputRoot := rollup.ComputeL2OutputRoot(l2OutputRootVersion, head.Hash(), head.Root(), proof.StorageHash)
 
-	return []eth.Bytes32{l2OutputRootVersion, l2OutputRoot}, nil
+	return &L2Output{
+		Version:               l2OutputRootVersion,
+		OutputRoot:            l2OutputRoot,
+		BlockHash:             head.Hash(),
+		BlockNumber:           head.NumberU64(),
+		StateRoot:             head.Root(),
+		WithdrawalStorageRoot: proof.StorageHash,
+	}, nil
 }
 
 func (n *nodeAPI) SyncStatus(ctx context.Context) (*driver.SyncStatus, error) {
-	recordDur := n.m.RecordRPCServerRequest("optimism_syncStatus")
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_syncStatus")
 	defer recordDur()
 	return n.dr.SyncStatus(ctx)
 }
 
-func (n *nodeAPI) RollupConfig(_ context.Context) (*rollup.Config, error) {
-	recordDur := n.m.RecordRPCServerRequest("optimism_rollupConfig")
+// SyncStatusUpdates subscribes to changes of the sync status, with optimism_subscribe("syncStatusUpdates").
+// The current sync status is sent first. Subscriptions require a WebSocket connection.
+// The sync status is sent on every change of the L2 heads, and polled for changes of the L1 heads.
+func (n *nodeAPI) SyncStatusUpdates(ctx context.Context) (*rpc.Subscription, error) {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_syncStatusUpdates")
+	defer recordDur()
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	heads := make(chan driver.HeadUpdate, 1)
+	unsubscribe := n.dr.SubscribeHeads(heads)
+	go func() {
+		defer unsubscribe()
+		ticker := time.NewTicker(syncStatusPollInterval)
+		defer ticker.Stop()
+		var last *driver.SyncStatus
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			status, err := n.dr.SyncStatus(ctx)
+			cancel()
+			if err != nil {
+				n.log.Warn("failed to get sync status for subscription", "err", err)
+			} else if last == nil || *status != *last {
+				if err := notifier.Notify(sub.ID, status); err != nil {
+					n.log.Debug("failed to notify sync status subscription", "err", err)
+				}
+				last = status
+			}
+			select {
+			case <-heads:
+			case <-ticker.C:
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func (n *nodeAPI) RollupConfig(ctx context.Context) (*rollup.Config, error) {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_rollupConfig")
 	defer recordDur()
 	return n.config, nil
 }
 
-func (n *nodeAPI) Version(ctx context.Context) (string, error) {
-	recordDur := n.m.RecordRPCServerRequest("optimism_version")
+// RollupConfigHash returns the rollup config along with its hash.
+// The latest hash version is used if no version is specified.
+func (n *nodeAPI) RollupConfigHash(ctx context.Context, version *hexutil.Uint64) (*rollup.ConfigHashResult, error) {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_rollupConfigHash")
 	defer recordDur()
-	return version.Version + "-" + version.Meta, nil
+	v := rollup.ConfigHashVersion0
+	if version != nil {
+		if uint64(*version) > math.MaxUint8 {
+			return nil, fmt.Errorf("unsupported config hash version: %d", uint64(*version))
+		}
+		v = rollup.ConfigHashVersion(*version)
+	}
+	h, err := rollup.ComputeConfigHash(v, n.config)
+	if err != nil {
+		n.log.Warn("failed to compute rollup config hash", "version", v, "err", err)
+		return nil, err
+	}
+	return &rollup.ConfigHashResult{Version: v.Bytes32(), Hash: h, Config: n.config}, nil
 }
 
-func toBlockNumArg(number rpc.BlockNumber) string {
-	// never returns an error
-	out, _ := number.MarshalText()
-	return string(out)
+func (n *nodeAPI) Version(ctx context.Context) (string, error) {
+	recordDur := n.m.RecordRPCServerRequest(ctx, "optimism_version")
+	defer recordDur()
+	return version.Version + "-" + version.Meta, nil
 }
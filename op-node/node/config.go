@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 )
 
 type Config struct {
@@ -31,9 +32,32 @@ type Config struct {
 
 	Pprof PprofConfig
 
+	Heartbeat HeartbeatConfig
+
+	// Used to poll the L1 for new head blocks when subscribing to L1 heads fails
+	L1HeadPollInterval time.Duration
+
 	// Used to poll the L1 for new finalized or safe blocks
 	L1EpochPollInterval time.Duration
 
+	// L1DBPath is the path of the database to persist fetched L1 blocks in. Disabled if empty.
+	L1DBPath string
+
+	// L2PrefetchPayloads is the number of L2 payloads to prefetch during sequential sync, disabled if 0.
+	L2PrefetchPayloads uint64
+
+	// L2BackfillRPC is the L2 RPC of another node to fetch missing unsafe payloads from, instead of the L2 engine.
+	// Only used if backfilling is enabled with Driver.UnsafeBackfillDepth.
+	L2BackfillRPC string
+
+	// EngineCalls configures the timeouts and retries of the engine API calls.
+	// The defaults are used if left empty.
+	EngineCalls sources.EngineCallsConfig
+
+	// RuntimeConfigPath is the path of the runtime config JSON file, that is reloaded on SIGHUP
+	// or through the admin RPC. Reloading is disabled if empty.
+	RuntimeConfigPath string
+
 	// Optional
 	Tracer Tracer
 }
@@ -42,6 +66,14 @@ type RPCConfig struct {
 	ListenAddr  string
 	ListenPort  int
 	EnableAdmin bool
+	// CORSOrigins are the origins that browsers may make cross-origin requests from, "*" for any origin.
+	// Cross-origin requests are not allowed if empty.
+	CORSOrigins []string
+	// EnableWS serves the optimism namespace, including subscriptions, over WebSocket connections as well.
+	// WebSocket connections are refused if the access control is enabled.
+	EnableWS bool
+	// Access controls which requests the RPC server serves
+	Access RPCAccessConfig
 }
 
 func (cfg *RPCConfig) HttpEndpoint() string {
@@ -52,6 +84,10 @@ type MetricsConfig struct {
 	Enabled    bool
 	ListenAddr string
 	ListenPort int
+	// AuthToken is the optional bearer token that metrics requests must be authenticated with
+	AuthToken string
+	// AccessLog enables logging of every request to the metrics server
+	AccessLog bool
 }
 
 func (m MetricsConfig) Check() error {
@@ -66,6 +102,25 @@ func (m MetricsConfig) Check() error {
 	return nil
 }
 
+type HeartbeatConfig struct {
+	Enabled  bool
+	URL      string
+	Interval time.Duration
+}
+
+func (h HeartbeatConfig) Check() error {
+	if !h.Enabled {
+		return nil
+	}
+	if h.URL == "" {
+		return errors.New("heartbeat URL is required when heartbeats are enabled")
+	}
+	if h.Interval <= 0 {
+		return errors.New("heartbeat interval must be positive when heartbeats are enabled")
+	}
+	return nil
+}
+
 type PprofConfig struct {
 	Enabled    bool
 	ListenAddr string
@@ -84,12 +139,18 @@ func (cfg *Config) Check() error {
 	if err := cfg.Rollup.Check(); err != nil {
 		return fmt.Errorf("rollup config error: %w", err)
 	}
+	if err := cfg.EngineCalls.Check(); err != nil {
+		return fmt.Errorf("engine calls config error: %w", err)
+	}
 	if err := cfg.Metrics.Check(); err != nil {
 		return fmt.Errorf("metrics config error: %w", err)
 	}
 	if err := cfg.Pprof.Check(); err != nil {
 		return fmt.Errorf("pprof config error: %w", err)
 	}
+	if err := cfg.Heartbeat.Check(); err != nil {
+		return fmt.Errorf("heartbeat config error: %w", err)
+	}
 	if cfg.P2P != nil {
 		if err := cfg.P2P.Check(); err != nil {
 			return fmt.Errorf("p2p config error: %w", err)
@@ -1,18 +1,40 @@
 package node
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/log"
 	"golang.org/x/term"
 )
 
+// SubsystemKey is the log context key that tags the logger of a subsystem,
+// for LevelHandler to filter the records of the subsystem at the level of the subsystem.
+const SubsystemKey = "subsystem"
+
+// Subsystems of the node with an independently adjustable log level.
+const (
+	SubsystemDriver     = "driver"
+	SubsystemDerivation = "derivation"
+	SubsystemEngine     = "engine"
+	SubsystemL1Source   = "l1-source"
+	SubsystemP2P        = "p2p"
+)
+
+// Subsystems are all subsystems with an independently adjustable log level.
+var Subsystems = []string{SubsystemDriver, SubsystemDerivation, SubsystemEngine, SubsystemL1Source, SubsystemP2P}
+
 type LogConfig struct {
 	Level  string // Log level: trace, debug, info, warn, error, crit. Capitals are accepted too.
 	Color  bool   // Color the log output. Defaults to true if terminal is detected.
 	Format string // Format the log output. Supported formats: 'text', 'json'
+
+	// SubsystemLevels overrides the log level of subsystems, by subsystem name, see Subsystems.
+	SubsystemLevels map[string]string
 }
 
 func DefaultLogConfig() LogConfig {
@@ -36,20 +58,172 @@ func (cfg *LogConfig) Check() error {
 	if err != nil {
 		return fmt.Errorf("unrecognized log level: %w", err)
 	}
+	for subsystem, level := range cfg.SubsystemLevels {
+		if err := checkSubsystem(subsystem); err != nil {
+			return err
+		}
+		if _, err := log.LvlFromString(strings.ToLower(level)); err != nil {
+			return fmt.Errorf("unrecognized log level of subsystem %s: %w", subsystem, err)
+		}
+	}
 	return nil
 }
 
-// NewLogger creates a logger based on the supplied configuration
+func checkSubsystem(subsystem string) error {
+	for _, s := range Subsystems {
+		if s == subsystem {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log subsystem %q, expected one of %s", subsystem, strings.Join(Subsystems, ", "))
+}
+
+// ParseSubsystemLevels parses a comma-separated list of subsystem log levels, e.g. "derivation=debug,p2p=warn".
+func ParseSubsystemLevels(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		subsystem, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid subsystem log level %q, expected <subsystem>=<level>", entry)
+		}
+		out[strings.TrimSpace(subsystem)] = strings.TrimSpace(level)
+	}
+	return out, nil
+}
+
+// NewLogger creates a logger based on the supplied configuration.
+// The levels of the logger can be changed with SetLogLevel and SetSubsystemLogLevel.
 func (cfg *LogConfig) NewLogger() log.Logger {
 	handler := log.StreamHandler(os.Stdout, format(cfg.Format, cfg.Color))
 	handler = log.SyncHandler(handler)
-	log.LvlFilterHandler(level(cfg.Level), handler)
+	levelHandler := NewLevelHandler(level(cfg.Level), handler)
+	for subsystem, lvl := range cfg.SubsystemLevels {
+		levelHandler.SetSubsystemLevel(subsystem, level(lvl))
+	}
 	logger := log.New()
-	logger.SetHandler(handler)
+	logger.SetHandler(levelHandler)
 	return logger
 
 }
 
+// LevelHandler is a log handler that filters records by a level that can be changed at runtime.
+// Records of loggers tagged with a SubsystemKey are filtered by the level of the subsystem instead, if it has one.
+type LevelHandler struct {
+	lvl int32
+	// subsystem levels, a map[string]log.Lvl that is replaced rather than modified on change
+	subsystems atomic.Value
+	mu         sync.Mutex // serializes changes of the subsystem levels
+	h          log.Handler
+}
+
+func NewLevelHandler(lvl log.Lvl, h log.Handler) *LevelHandler {
+	out := &LevelHandler{lvl: int32(lvl), h: h}
+	out.subsystems.Store(map[string]log.Lvl{})
+	return out
+}
+
+func (h *LevelHandler) Log(r *log.Record) error {
+	lvl := h.Level()
+	if levels := h.subsystems.Load().(map[string]log.Lvl); len(levels) > 0 {
+		if subsystemLvl, ok := levels[recordSubsystem(r)]; ok {
+			lvl = subsystemLvl
+		}
+	}
+	if r.Lvl <= lvl {
+		return h.h.Log(r)
+	}
+	return nil
+}
+
+// recordSubsystem returns the last subsystem tag of the record context, the most specific one of nested loggers.
+func recordSubsystem(r *log.Record) string {
+	var out string
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if k, ok := r.Ctx[i].(string); ok && k == SubsystemKey {
+			out, _ = r.Ctx[i+1].(string)
+		}
+	}
+	return out
+}
+
+func (h *LevelHandler) Level() log.Lvl {
+	return log.Lvl(atomic.LoadInt32(&h.lvl))
+}
+
+func (h *LevelHandler) SetLevel(lvl log.Lvl) {
+	atomic.StoreInt32(&h.lvl, int32(lvl))
+}
+
+// SubsystemLevel returns the level of the subsystem, and false if the subsystem uses the default level.
+func (h *LevelHandler) SubsystemLevel(subsystem string) (log.Lvl, bool) {
+	lvl, ok := h.subsystems.Load().(map[string]log.Lvl)[subsystem]
+	return lvl, ok
+}
+
+// SetSubsystemLevel changes the level of the subsystem.
+func (h *LevelHandler) SetSubsystemLevel(subsystem string, lvl log.Lvl) {
+	h.updateSubsystems(func(levels map[string]log.Lvl) { levels[subsystem] = lvl })
+}
+
+// ResetSubsystemLevel changes the subsystem back to the default level.
+func (h *LevelHandler) ResetSubsystemLevel(subsystem string) {
+	h.updateSubsystems(func(levels map[string]log.Lvl) { delete(levels, subsystem) })
+}
+
+func (h *LevelHandler) updateSubsystems(fn func(levels map[string]log.Lvl)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	old := h.subsystems.Load().(map[string]log.Lvl)
+	levels := make(map[string]log.Lvl, len(old)+1)
+	for k, v := range old {
+		levels[k] = v
+	}
+	fn(levels)
+	h.subsystems.Store(levels)
+}
+
+// SetLogLevel changes the level of a logger created by LogConfig.NewLogger, or of any child logger of it.
+func SetLogLevel(logger log.Logger, lvl log.Lvl) error {
+	h, err := levelHandler(logger)
+	if err != nil {
+		return err
+	}
+	h.SetLevel(lvl)
+	return nil
+}
+
+// SetSubsystemLogLevel changes the level of a subsystem of a logger created by LogConfig.NewLogger.
+func SetSubsystemLogLevel(logger log.Logger, subsystem string, lvl log.Lvl) error {
+	if err := checkSubsystem(subsystem); err != nil {
+		return err
+	}
+	h, err := levelHandler(logger)
+	if err != nil {
+		return err
+	}
+	h.SetSubsystemLevel(subsystem, lvl)
+	return nil
+}
+
+func levelHandler(logger log.Logger) (*LevelHandler, error) {
+	h := logger.GetHandler()
+	for {
+		switch x := h.(type) {
+		case *LevelHandler:
+			return x, nil
+		case interface{ Get() log.Handler }:
+			// the handler of a child logger is the swappable handler of the parent logger
+			h = x.Get()
+		default:
+			return nil, errors.New("logger does not support changing the log level")
+		}
+	}
+}
+
 // format turns a string and color into a structured Format object
 func format(lf string, color bool) log.Format {
 	switch lf {
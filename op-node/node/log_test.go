@@ -0,0 +1,82 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+	msgs []string
+}
+
+func (h *recordingHandler) Log(r *log.Record) error {
+	h.msgs = append(h.msgs, r.Msg)
+	return nil
+}
+
+func TestSetLogLevel(t *testing.T) {
+	h := &recordingHandler{}
+	logger := log.New()
+	logger.SetHandler(NewLevelHandler(log.LvlInfo, h))
+	child := logger.New("module", "test")
+
+	child.Debug("hidden")
+	child.Info("shown")
+	require.Equal(t, []string{"shown"}, h.msgs)
+
+	require.NoError(t, SetLogLevel(child, log.LvlDebug))
+	logger.Debug("debug")
+	require.Equal(t, []string{"shown", "debug"}, h.msgs)
+
+	other := log.New()
+	other.SetHandler(log.DiscardHandler())
+	require.Error(t, SetLogLevel(other, log.LvlDebug))
+}
+
+func TestSubsystemLogLevel(t *testing.T) {
+	h := &recordingHandler{}
+	logger := log.New()
+	levels := NewLevelHandler(log.LvlInfo, h)
+	logger.SetHandler(levels)
+	driverLog := logger.New(SubsystemKey, SubsystemDriver)
+	derivationLog := driverLog.New(SubsystemKey, SubsystemDerivation)
+	p2pLog := logger.New(SubsystemKey, SubsystemP2P)
+
+	require.NoError(t, SetSubsystemLogLevel(logger, SubsystemDerivation, log.LvlDebug))
+	require.NoError(t, SetSubsystemLogLevel(p2pLog, SubsystemP2P, log.LvlWarn))
+	lvl, ok := levels.SubsystemLevel(SubsystemDerivation)
+	require.True(t, ok)
+	require.Equal(t, log.LvlDebug, lvl)
+
+	derivationLog.Debug("derivation debug")
+	driverLog.Debug("driver debug")
+	p2pLog.Info("p2p info")
+	p2pLog.Warn("p2p warn")
+	logger.Info("root info")
+	require.Equal(t, []string{"derivation debug", "p2p warn", "root info"}, h.msgs,
+		"the most specific subsystem of nested loggers applies")
+
+	levels.ResetSubsystemLevel(SubsystemP2P)
+	p2pLog.Info("p2p info again")
+	require.Equal(t, "p2p info again", h.msgs[len(h.msgs)-1])
+
+	require.ErrorContains(t, SetSubsystemLogLevel(logger, "batcher", log.LvlDebug), "unknown log subsystem")
+}
+
+func TestLogConfigSubsystemLevels(t *testing.T) {
+	levels, err := ParseSubsystemLevels("derivation=debug, p2p=WARN,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"derivation": "debug", "p2p": "WARN"}, levels)
+	_, err = ParseSubsystemLevels("derivation")
+	require.ErrorContains(t, err, "expected <subsystem>=<level>")
+
+	cfg := DefaultLogConfig()
+	cfg.SubsystemLevels = levels
+	require.NoError(t, cfg.Check())
+	cfg.SubsystemLevels = map[string]string{"derivation": "loud"}
+	require.ErrorContains(t, cfg.Check(), "log level of subsystem derivation")
+	cfg.SubsystemLevels = map[string]string{"batcher": "debug"}
+	require.ErrorContains(t, cfg.Check(), "unknown log subsystem")
+}
@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/libp2p/go-libp2p-core/peer"
@@ -10,15 +11,17 @@ import (
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/heartbeat"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type OpNode struct {
@@ -26,17 +29,21 @@ type OpNode struct {
 	appVersion string
 	metrics    *metrics.Metrics
 
-	l1HeadsSub     ethereum.Subscription // Subscription to get L1 heads (automatically re-subscribes on error)
+	l1HeadsSub     ethereum.Subscription // Subscription to get L1 heads (polls and re-subscribes on error)
 	l1SafeSub      ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 	l1FinalizedSub ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 
-	l1Source  *sources.L1Client     // L1 Client to fetch data from
-	l2Driver  *driver.Driver        // L2 Engine to Sync
-	l2Source  *sources.EngineClient // L2 Execution Engine RPC bindings
-	server    *rpcServer            // RPC server hosting the rollup-node API
-	p2pNode   *p2p.NodeP2P          // P2P node functionality
-	p2pSigner p2p.Signer            // p2p gogssip application messages will be signed with this signer
-	tracer    Tracer                // tracer to get events for testing/debugging
+	l1Source   *sources.L1Client     // L1 Client to fetch data from
+	l1DB       *sources.BlockDB      // Persisted L1 blocks, optional
+	l2Driver   *driver.Driver        // L2 Engine to Sync
+	l2Source   *sources.EngineClient // L2 Execution Engine RPC bindings
+	l2Backfill *sources.L2Client     // L2 RPC to backfill missing unsafe payloads from, optional
+	server     *rpcServer            // RPC server hosting the rollup-node API
+	p2pNode    *p2p.NodeP2P          // P2P node functionality
+	p2pSigner  p2p.Signer            // p2p gogssip application messages will be signed with this signer
+	tracer     Tracer                // tracer to get events for testing/debugging
+
+	runtimeConfigPath string // runtime config file to reload settings from, if any
 
 	// some resources cannot be stopped directly, like the p2p gossipsub router (not our design),
 	// and depend on this ctx to be closed.
@@ -56,6 +63,8 @@ func New(ctx context.Context, cfg *Config, log log.Logger, snapshotLog log.Logge
 		log:        log,
 		appVersion: appVersion,
 		metrics:    m,
+
+		runtimeConfigPath: cfg.RuntimeConfigPath,
 	}
 	// not a context leak, gossipsub is closed with a context.
 	n.resourcesCtx, n.resourcesClose = context.WithCancel(context.Background())
@@ -94,6 +103,9 @@ func (n *OpNode) init(ctx context.Context, cfg *Config, snapshotLog log.Logger)
 	if err := n.initMetricsServer(ctx, cfg); err != nil {
 		return err
 	}
+	if err := n.initHeartbeat(ctx, cfg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -107,57 +119,83 @@ func (n *OpNode) initTracer(ctx context.Context, cfg *Config) error {
 }
 
 func (n *OpNode) initL1(ctx context.Context, cfg *Config) error {
-	l1Node, trustRPC, err := cfg.L1.Setup(ctx, n.log)
+	l1Log := n.log.New(SubsystemKey, SubsystemL1Source)
+	l1Node, trustRPC, err := cfg.L1.Setup(ctx, l1Log)
 	if err != nil {
 		return fmt.Errorf("failed to get L1 RPC client: %w", err)
 	}
 
+	l1Config := sources.L1ClientDefaultConfig(&cfg.Rollup, trustRPC)
+	if cfg.L1DBPath != "" {
+		// keep a sequencing window of blocks below the finalized block, to derive from after a restart
+		n.l1DB, err = sources.OpenBlockDB(cfg.L1DBPath, cfg.Rollup.SeqWindowSize, l1Log)
+		if err != nil {
+			return err
+		}
+		l1Config.DB = n.l1DB
+	}
 	n.l1Source, err = sources.NewL1Client(
-		client.NewInstrumentedRPC(l1Node, n.metrics), n.log, n.metrics.L1SourceCache,
-		sources.L1ClientDefaultConfig(&cfg.Rollup, trustRPC))
+		client.NewInstrumentedRPC(l1Node, n.metrics), l1Log, n.metrics.L1SourceCache, l1Config)
 	if err != nil {
 		return fmt.Errorf("failed to create L1 source: %v", err)
 	}
 
-	// Keep subscribed to the L1 heads, which keeps the L1 maintainer pointing to the best headers to sync
-	n.l1HeadsSub = event.ResubscribeErr(time.Second*10, func(ctx context.Context, err error) (event.Subscription, error) {
-		if err != nil {
-			n.log.Warn("resubscribing after failed L1 subscription", "err", err)
-		}
-		return eth.WatchHeadChanges(n.resourcesCtx, n.l1Source, n.OnNewL1Head)
-	})
+	// Keep subscribed to the L1 heads, which keeps the L1 maintainer pointing to the best headers to sync.
+	// Poll for the L1 head instead whilst the L1 RPC fails to keep a subscription open.
+	n.l1HeadsSub = eth.WatchOrPollHeadChanges(n.resourcesCtx, l1Log, n.l1Source, n.OnNewL1Head, n.metrics,
+		cfg.L1HeadPollInterval, time.Second*10, time.Second*10)
 	go func() {
 		err, ok := <-n.l1HeadsSub.Err()
 		if !ok {
 			return
 		}
-		n.log.Error("l1 heads subscription error", "err", err)
+		l1Log.Error("l1 heads subscription error", "err", err)
 	}()
 
 	// Poll for the safe L1 block and finalized block,
 	// which only change once per epoch at most and may be delayed.
-	n.l1SafeSub = eth.PollBlockChanges(n.resourcesCtx, n.log, n.l1Source, n.OnNewL1Safe, eth.Safe,
+	n.l1SafeSub = eth.PollBlockChanges(n.resourcesCtx, l1Log, n.l1Source, n.OnNewL1Safe, eth.Safe,
 		cfg.L1EpochPollInterval, time.Second*10)
-	n.l1FinalizedSub = eth.PollBlockChanges(n.resourcesCtx, n.log, n.l1Source, n.OnNewL1Finalized, eth.Finalized,
+	n.l1FinalizedSub = eth.PollBlockChanges(n.resourcesCtx, l1Log, n.l1Source, n.OnNewL1Finalized, eth.Finalized,
 		cfg.L1EpochPollInterval, time.Second*10)
 	return nil
 }
 
 func (n *OpNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Logger) error {
-	rpcClient, err := cfg.L2.Setup(ctx, n.log)
+	engineLog := n.log.New(SubsystemKey, SubsystemEngine)
+	rpcClient, err := cfg.L2.Setup(ctx, engineLog)
 	if err != nil {
 		return fmt.Errorf("failed to setup L2 execution-engine RPC client: %w", err)
 	}
 
+	engineConfig := sources.EngineClientDefaultConfig(&cfg.Rollup)
+	engineConfig.PrefetchPayloads = cfg.L2PrefetchPayloads
+	if cfg.EngineCalls != (sources.EngineCallsConfig{}) {
+		engineConfig.EngineCallsConfig = cfg.EngineCalls
+	}
 	n.l2Source, err = sources.NewEngineClient(
-		client.NewInstrumentedRPC(rpcClient, n.metrics), n.log, n.metrics.L2SourceCache,
-		sources.EngineClientDefaultConfig(&cfg.Rollup),
+		client.NewInstrumentedRPC(rpcClient, n.metrics), engineLog, n.metrics.L2SourceCache, n.metrics,
+		engineConfig,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create Engine client: %w", err)
 	}
 
-	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, n, n.log, snapshotLog, n.metrics)
+	driverLog := n.log.New(SubsystemKey, SubsystemDriver)
+	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.l2Source, n.l1Source, n, n, driverLog, snapshotLog, n.metrics, n.metrics.UnsafePayloadsCache, clock.SystemClock)
+
+	if cfg.L2BackfillRPC != "" && cfg.Driver.UnsafeBackfillDepth > 0 {
+		backfillRPC, err := dialRPCClientWithBackoff(ctx, engineLog, cfg.L2BackfillRPC)
+		if err != nil {
+			return fmt.Errorf("failed to dial L2 backfill RPC: %w", err)
+		}
+		n.l2Backfill, err = sources.NewL2Client(client.NewInstrumentedRPC(backfillRPC, n.metrics), engineLog, n.metrics.L2SourceCache,
+			sources.L2ClientDefaultConfig(&cfg.Rollup, false))
+		if err != nil {
+			return fmt.Errorf("failed to create L2 backfill client: %w", err)
+		}
+		n.l2Driver.SetUnsafeBackfillSource(n.l2Backfill)
+	}
 
 	return nil
 }
@@ -172,7 +210,7 @@ func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 		n.server.EnableP2P(p2p.NewP2PAPIBackend(n.p2pNode, n.log, n.metrics))
 	}
 	if cfg.RPC.EnableAdmin {
-		n.server.EnableAdminAPI(newAdminAPI(n.l2Driver, n.metrics))
+		n.server.EnableAdminAPI(newAdminAPI(n.l2Driver, n, n.metrics))
 	}
 	n.log.Info("Starting JSON-RPC server")
 	if err := n.server.Start(); err != nil {
@@ -186,24 +224,60 @@ func (n *OpNode) initMetricsServer(ctx context.Context, cfg *Config) error {
 		n.log.Info("metrics disabled")
 		return nil
 	}
-	n.log.Info("starting metrics server", "addr", cfg.Metrics.ListenAddr, "port", cfg.Metrics.ListenPort)
+	n.log.Info("starting metrics server", "addr", cfg.Metrics.ListenAddr, "port", cfg.Metrics.ListenPort,
+		"auth", cfg.Metrics.AuthToken != "", "access_log", cfg.Metrics.AccessLog)
+	serverCfg := metrics.ServerConfig{AuthToken: cfg.Metrics.AuthToken}
+	if cfg.Metrics.AccessLog {
+		serverCfg.AccessLog = n.log.New("server", "metrics")
+	}
 	go func() {
-		if err := n.metrics.Serve(ctx, cfg.Metrics.ListenAddr, cfg.Metrics.ListenPort); err != nil {
+		if err := n.metrics.Serve(ctx, cfg.Metrics.ListenAddr, cfg.Metrics.ListenPort, serverCfg); err != nil {
 			log.Crit("error starting metrics server", "err", err)
 		}
 	}()
 	return nil
 }
 
+func (n *OpNode) initHeartbeat(ctx context.Context, cfg *Config) error {
+	if !cfg.Heartbeat.Enabled {
+		return nil
+	}
+	n.log.Info("starting heartbeat", "url", cfg.Heartbeat.URL, "interval", cfg.Heartbeat.Interval)
+	var chainID uint64
+	if cfg.Rollup.L2ChainID != nil {
+		chainID = cfg.Rollup.L2ChainID.Uint64()
+	}
+	payloadFn := func(ctx context.Context) (*heartbeat.Payload, error) {
+		status, err := n.l2Driver.SyncStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var peerCount int
+		if n.p2pNode != nil {
+			peerCount = len(n.p2pNode.Host().Network().Peers())
+		}
+		return &heartbeat.Payload{
+			Version:         n.appVersion,
+			ChainID:         chainID,
+			UnsafeHeadBlock: status.UnsafeL2.Number,
+			SafeHeadBlock:   status.SafeL2.Number,
+			PeerCount:       peerCount,
+		}, nil
+	}
+	go heartbeat.Beat(n.resourcesCtx, n.log.New("service", "heartbeat"), cfg.Heartbeat.URL, cfg.Heartbeat.Interval, payloadFn, n.metrics)
+	return nil
+}
+
 func (n *OpNode) initP2P(ctx context.Context, cfg *Config) error {
 	if cfg.P2P != nil {
-		p2pNode, err := p2p.NewNodeP2P(n.resourcesCtx, &cfg.Rollup, n.log, cfg.P2P, n)
+		p2pLog := n.log.New(SubsystemKey, SubsystemP2P)
+		p2pNode, err := p2p.NewNodeP2P(n.resourcesCtx, &cfg.Rollup, p2pLog, cfg.P2P, n, n.l2Source, n.metrics)
 		if err != nil {
 			return err
 		}
 		n.p2pNode = p2pNode
 		if n.p2pNode.Dv5Udp() != nil {
-			go n.p2pNode.DiscoveryProcess(n.resourcesCtx, n.log, &cfg.Rollup, cfg.P2P.TargetPeers())
+			go n.p2pNode.DiscoveryProcess(n.resourcesCtx, p2pLog, &cfg.Rollup, cfg.P2P.TargetPeers())
 		}
 	}
 	return nil
@@ -220,6 +294,24 @@ func (n *OpNode) initP2PSigner(ctx context.Context, cfg *Config) error {
 	return err
 }
 
+// SetLogLevel changes the log level of a subsystem, see Subsystems, or the default log level if the subsystem is empty.
+func (n *OpNode) SetLogLevel(subsystem string, level string) error {
+	lvl, err := log.LvlFromString(strings.ToLower(level))
+	if err != nil {
+		return fmt.Errorf("unrecognized log level: %w", err)
+	}
+	if subsystem == "" {
+		err = SetLogLevel(n.log, lvl)
+	} else {
+		err = SetSubsystemLogLevel(n.log, subsystem, lvl)
+	}
+	if err != nil {
+		return err
+	}
+	n.log.Info("Changed log level", "subsystem", subsystem, "level", lvl)
+	return nil
+}
+
 func (n *OpNode) Start(ctx context.Context) error {
 	n.log.Info("Starting execution engine driver")
 	// Request initial head update, default to genesis otherwise
@@ -279,6 +371,14 @@ func (n *OpNode) PublishL2Payload(ctx context.Context, payload *eth.ExecutionPay
 	return nil
 }
 
+// RequestL2Range requests the unsafe payloads between start and end from peers, if the req-resp sync of p2p is enabled.
+func (n *OpNode) RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error {
+	if n.p2pNode != nil {
+		return n.p2pNode.RequestL2Range(ctx, start, end)
+	}
+	return nil
+}
+
 func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
 	// ignore if it's from ourselves
 	if n.p2pNode != nil && from == n.p2pNode.Host().ID() {
@@ -299,6 +399,11 @@ func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *e
 	return nil
 }
 
+// DialRPCInProc connects a client to the RPC APIs of the started node in-process, without a socket.
+func (n *OpNode) DialRPCInProc() *rpc.Client {
+	return n.server.DialInProc()
+}
+
 func (n *OpNode) P2P() p2p.Node {
 	return n.p2pNode
 }
@@ -341,10 +446,18 @@ func (n *OpNode) Close() error {
 	if n.l2Source != nil {
 		n.l2Source.Close()
 	}
+	if n.l2Backfill != nil {
+		n.l2Backfill.Close()
+	}
 
 	// close L1 data source
 	if n.l1Source != nil {
 		n.l1Source.Close()
 	}
+	if n.l1DB != nil {
+		if err := n.l1DB.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close L1 block database: %w", err))
+		}
+	}
 	return result.ErrorOrNil()
 }
@@ -0,0 +1,209 @@
+package node
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+)
+
+// Reasons of rejected RPC requests, as recorded in the metrics.
+const (
+	rejectRateLimited  = "rate_limited"
+	rejectTooLarge     = "too_large"
+	rejectNotAllowed   = "not_allowed"
+	rejectUnauthorized = "unauthorized"
+	rejectInvalid      = "invalid_request"
+)
+
+// maxRateLimitedIPs bounds the number of tracked client IPs. Idle clients are dropped beyond this.
+const maxRateLimitedIPs = 10_000
+
+// RPCAccessConfig configures the access control of the RPC server. The zero value allows everything.
+type RPCAccessConfig struct {
+	// AllowedMethods lists the methods that may be called, as "namespace_method",
+	// or "namespace_*" to allow the whole namespace. All methods are allowed if empty.
+	AllowedMethods []string
+	// AdminToken, if not empty, must be presented as bearer token to call admin namespace methods.
+	AdminToken string
+	// RateLimit is the number of requests per second allowed per client IP. Unlimited if 0.
+	RateLimit float64
+	// RateBurst is the number of requests a client IP may burst above the rate limit.
+	RateBurst int
+	// MaxRequestSize is the maximum size of a request body in bytes. Unlimited if 0.
+	MaxRequestSize int64
+}
+
+func (c *RPCAccessConfig) enabled() bool {
+	return len(c.AllowedMethods) > 0 || c.AdminToken != "" || c.RateLimit > 0 || c.MaxRequestSize > 0
+}
+
+// methodAllowed checks the method against the allowlist.
+func (c *RPCAccessConfig) methodAllowed(method string) bool {
+	if len(c.AllowedMethods) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+		if ns := strings.TrimSuffix(allowed, "*"); ns != allowed && strings.HasPrefix(method, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRPCAccessHandler wraps the RPC handler with the access control of the config:
+// requests are rate limited per client IP first, then limited in size,
+// and then each method of the (batch) request is checked against the allowlist and admin token.
+// Requests of which the methods cannot be decoded are rejected, as they cannot be checked.
+func newRPCAccessHandler(cfg *RPCAccessConfig, m *metrics.Metrics, next http.Handler) http.Handler {
+	var limiter *ipRateLimiter
+	if cfg.RateLimit > 0 {
+		limiter = newIPRateLimiter(cfg.RateLimit, cfg.RateBurst)
+	}
+	adminAuth := []byte("Bearer " + cfg.AdminToken)
+	reject := func(w http.ResponseWriter, reason string, status int) {
+		m.RecordRPCServerRejected(reason)
+		http.Error(w, http.StatusText(status), status)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter != nil && !limiter.allow(remoteIP(r), time.Now()) {
+			reject(w, rejectRateLimited, http.StatusTooManyRequests)
+			return
+		}
+		body := r.Body
+		if cfg.MaxRequestSize > 0 {
+			body = io.NopCloser(io.LimitReader(r.Body, cfg.MaxRequestSize+1))
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if cfg.MaxRequestSize > 0 && int64(len(data)) > cfg.MaxRequestSize {
+			reject(w, rejectTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		methods, err := requestMethods(data)
+		if err != nil {
+			reject(w, rejectInvalid, http.StatusBadRequest)
+			return
+		}
+		for _, method := range methods {
+			if !cfg.methodAllowed(method) {
+				reject(w, rejectNotAllowed, http.StatusForbidden)
+				return
+			}
+			if cfg.AdminToken != "" && strings.HasPrefix(method, "admin_") &&
+				subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), adminAuth) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				reject(w, rejectUnauthorized, http.StatusUnauthorized)
+				return
+			}
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestMethods returns the methods of a JSON-RPC request or batch request.
+// The request is decoded like the RPC server does: only the first JSON value of the body is read.
+// An empty body has no methods, the RPC server does not call any method for it.
+func requestMethods(data []byte) ([]string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&raw); err != nil {
+		return nil, err
+	}
+	type request struct {
+		Method string `json:"method"`
+	}
+	var batch []request
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &batch); err != nil {
+			return nil, err
+		}
+	} else {
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		batch = append(batch, req)
+	}
+	methods := make([]string, 0, len(batch))
+	for _, req := range batch {
+		methods = append(methods, req.Method)
+	}
+	return methods, nil
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ipRateLimiter is a token bucket rate limiter per client IP.
+type ipRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+func newIPRateLimiter(rate float64, burst int) *ipRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ipRateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*tokenBucket)}
+}
+
+func (l *ipRateLimiter) allow(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[ip]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitedIPs {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[ip] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens -= 1
+	return true
+}
+
+// prune drops the buckets that have refilled completely, as those are equivalent to new buckets.
+func (l *ipRateLimiter) prune(now time.Time) {
+	for ip, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, ip)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package node
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+)
+
+func TestRPCAccessHandler(t *testing.T) {
+	m := metrics.NewMetrics("")
+	cfg := &RPCAccessConfig{
+		AllowedMethods: []string{"optimism_*", "admin_stopSequencer"},
+		AdminToken:     "secret",
+		MaxRequestSize: 200,
+	}
+	var served string
+	h := newRPCAccessHandler(cfg, m, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		served = string(data)
+	}))
+	serve := func(body string, authorization string) int {
+		served = ""
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	req := `{"jsonrpc":"2.0","id":1,"method":"optimism_syncStatus"}`
+	require.Equal(t, http.StatusOK, serve(req, ""))
+	require.Equal(t, req, served, "the body is passed on")
+	require.Equal(t, http.StatusOK, serve(``, ""), "empty requests do not call any method")
+
+	require.Equal(t, http.StatusBadRequest, serve(`not json`, ""), "undecodable requests are rejected")
+	require.Equal(t, http.StatusBadRequest, serve(`[{"method":"optimism_syncStatus"},1]`, ""), "undecodable batch requests are rejected")
+	require.Equal(t, http.StatusBadRequest, serve(`"admin_stopSequencer"`, ""), "requests that are not an object are rejected")
+
+	require.Equal(t, http.StatusForbidden, serve(`{"method":"p2p_peers"}`, ""))
+	require.Equal(t, http.StatusForbidden, serve(`[{"method":"optimism_syncStatus"},{"method":"p2p_peers"}]`, ""), "every method of a batch is checked")
+	require.Equal(t, http.StatusForbidden, serve(`{"method":"admin_resetDerivationPipeline"}`, "Bearer secret"))
+	require.Equal(t, http.StatusForbidden, serve(` {"METHOD":"p2p_peers"} {"method":"optimism_syncStatus"}`, ""), "the first request value is checked like the RPC server reads it")
+
+	require.Equal(t, http.StatusUnauthorized, serve(`{"method":"admin_stopSequencer"}`, ""))
+	require.Equal(t, http.StatusUnauthorized, serve(`{"method":"admin_stopSequencer"}`, "Bearer wrong"))
+	require.Equal(t, http.StatusOK, serve(`{"method":"admin_stopSequencer"}`, "Bearer secret"))
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, serve(`{"method":"optimism_syncStatus","params":["`+strings.Repeat("a", 200)+`"]}`, ""))
+
+	require.Equal(t, 4.0, testutil.ToFloat64(m.RPCServerRejectedTotal.WithLabelValues(rejectNotAllowed)))
+	require.Equal(t, 3.0, testutil.ToFloat64(m.RPCServerRejectedTotal.WithLabelValues(rejectInvalid)))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RPCServerRejectedTotal.WithLabelValues(rejectUnauthorized)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RPCServerRejectedTotal.WithLabelValues(rejectTooLarge)))
+}
+
+func TestIPRateLimiter(t *testing.T) {
+	l := newIPRateLimiter(2, 3)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 3; i++ {
+		require.True(t, l.allow("1.2.3.4", now), "burst %d", i)
+	}
+	require.False(t, l.allow("1.2.3.4", now))
+	require.True(t, l.allow("5.6.7.8", now), "limited per IP")
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, l.allow("1.2.3.4", now), "refilled one token")
+	require.False(t, l.allow("1.2.3.4", now))
+
+	now = now.Add(time.Hour)
+	l.prune(now)
+	require.Empty(t, l.buckets, "refilled buckets are pruned")
+}
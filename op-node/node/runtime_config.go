@@ -0,0 +1,73 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+// RuntimeConfig is the part of the node config that can be reloaded while the node is running,
+// from the JSON file at Config.RuntimeConfigPath. Only the settings present in the file are changed.
+type RuntimeConfig struct {
+	// LogLevel is the new log level: trace, debug, info, warn, error or crit.
+	LogLevel string `json:"log_level,omitempty"`
+
+	driver.RuntimeConfig
+}
+
+// LoadRuntimeConfig reads and checks the runtime config JSON file.
+func LoadRuntimeConfig(path string) (*RuntimeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runtime config: %w", err)
+	}
+	var cfg RuntimeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode runtime config: %w", err)
+	}
+	if cfg.LogLevel != "" {
+		if _, err := log.LvlFromString(strings.ToLower(cfg.LogLevel)); err != nil {
+			return nil, fmt.Errorf("invalid runtime config log level: %w", err)
+		}
+	}
+	return &cfg, nil
+}
+
+// ReloadRuntimeConfig reads the runtime config file, and applies it to the running node.
+// It is called when the node receives a SIGHUP, or through the admin RPC.
+func (n *OpNode) ReloadRuntimeConfig(ctx context.Context) error {
+	err := n.reloadRuntimeConfig(ctx)
+	n.metrics.RecordRuntimeConfigReload(err == nil)
+	if err != nil {
+		n.log.Error("Failed to reload runtime config", "path", n.runtimeConfigPath, "err", err)
+		return err
+	}
+	n.log.Info("Reloaded runtime config", "path", n.runtimeConfigPath)
+	return nil
+}
+
+func (n *OpNode) reloadRuntimeConfig(ctx context.Context) error {
+	if n.runtimeConfigPath == "" {
+		return fmt.Errorf("no runtime config file is configured")
+	}
+	cfg, err := LoadRuntimeConfig(n.runtimeConfigPath)
+	if err != nil {
+		return err
+	}
+	if cfg.SequencerEnabled != nil && *cfg.SequencerEnabled && n.p2pNode != nil && n.p2pSigner == nil {
+		return fmt.Errorf("cannot enable the sequencer, the node has no p2p signer to publish blocks with")
+	}
+	if cfg.LogLevel != "" {
+		lvl, _ := log.LvlFromString(strings.ToLower(cfg.LogLevel))
+		if err := SetLogLevel(n.log, lvl); err != nil {
+			return err
+		}
+	}
+	return n.l2Driver.UpdateRuntimeConfig(ctx, cfg.RuntimeConfig)
+}
@@ -0,0 +1,40 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRuntimeConfig(t *testing.T) {
+	write := func(t *testing.T, data string) string {
+		path := filepath.Join(t.TempDir(), "runtime.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		cfg, err := LoadRuntimeConfig(write(t, `{"log_level": "DEBUG", "sequencer_enabled": false, "verifier_conf_depth": 2}`))
+		require.NoError(t, err)
+		require.Equal(t, "DEBUG", cfg.LogLevel)
+		require.NotNil(t, cfg.SequencerEnabled)
+		require.False(t, *cfg.SequencerEnabled)
+		require.Equal(t, uint64(2), *cfg.VerifierConfDepth)
+		require.Nil(t, cfg.SequencerConfDepth, "settings missing from the file are not changed")
+		require.Nil(t, cfg.SequencerMaxSafeLag)
+	})
+	t.Run("invalid log level", func(t *testing.T) {
+		_, err := LoadRuntimeConfig(write(t, `{"log_level": "loud"}`))
+		require.ErrorContains(t, err, "log level")
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := LoadRuntimeConfig(write(t, `{"verifier_conf_depth": -1}`))
+		require.ErrorContains(t, err, "failed to decode")
+	})
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadRuntimeConfig(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 
@@ -23,18 +24,23 @@ import (
 // TODO(inphi): add metrics
 
 type rpcServer struct {
-	endpoint   string
-	apis       []rpc.API
-	httpServer *http.Server
-	appVersion string
-	listenAddr net.Addr
-	log        log.Logger
+	endpoint    string
+	apis        []rpc.API
+	httpServer  *http.Server
+	srv         *rpc.Server
+	wsSrv       *rpc.Server
+	corsOrigins []string
+	enableWS    bool
+	appVersion  string
+	listenAddr  net.Addr
+	access      RPCAccessConfig
+	log         log.Logger
+	metrics     *metrics.Metrics
 	sources.L2Client
 }
 
-func newRPCServer(ctx context.Context, rpcCfg *RPCConfig, rollupCfg *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger, appVersion string, m *metrics.Metrics) (*rpcServer, error) {
+func newRPCServer(ctx context.Context, rpcCfg *RPCConfig, rollupCfg *rollup.Config, l2Client L2EthClient, dr driverClient, log log.Logger, appVersion string, m *metrics.Metrics) (*rpcServer, error) {
 	api := newNodeAPI(rollupCfg, l2Client, dr, log.New("rpc", "node"), m)
-	// TODO: extend RPC config with options for WS, IPC and HTTP RPC connections
 	endpoint := net.JoinHostPort(rpcCfg.ListenAddr, strconv.Itoa(rpcCfg.ListenPort))
 	r := &rpcServer{
 		endpoint: endpoint,
@@ -44,8 +50,12 @@ func newRPCServer(ctx context.Context, rpcCfg *RPCConfig, rollupCfg *rollup.Conf
 			Public:        true,
 			Authenticated: false,
 		}},
-		appVersion: appVersion,
-		log:        log,
+		appVersion:  appVersion,
+		corsOrigins: rpcCfg.CORSOrigins,
+		enableWS:    rpcCfg.EnableWS,
+		access:      rpcCfg.Access,
+		log:         log,
+		metrics:     m,
 	}
 	return r, nil
 }
@@ -71,16 +81,44 @@ func (s *rpcServer) EnableP2P(backend *p2p.APIBackend) {
 }
 
 func (s *rpcServer) Start() error {
-	srv := rpc.NewServer()
-	if err := node.RegisterApis(s.apis, nil, srv); err != nil {
+	s.srv = rpc.NewServer()
+	if err := node.RegisterApis(s.apis, nil, s.srv); err != nil {
 		return err
 	}
 
-	// The CORS and VHosts arguments below must be set in order for
-	// other services to connect to the opnode. VHosts in particular
+	// The VHosts argument below must be set in order for
+	// other services to connect to the opnode. VHosts
 	// defaults to localhost, which will prevent containers from
 	// calling into the opnode without an "invalid host" error.
-	nodeHandler := node.NewHTTPHandlerStack(srv, []string{"*"}, []string{"*"}, nil)
+	nodeHandler := node.NewHTTPHandlerStack(s.srv, s.corsOrigins, []string{"*"}, nil)
+	if s.enableWS && s.access.enabled() {
+		// The messages of WebSocket connections bypass the access control of the HTTP requests,
+		// so WebSocket connections are refused if the access control is enabled.
+		s.log.Warn("Refusing RPC WebSocket connections, as the RPC access control is enabled")
+		nodeHandler = wsOrHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.metrics.RecordRPCServerRejected(rejectNotAllowed)
+			http.Error(w, "WebSocket connections are not allowed with RPC access control", http.StatusForbidden)
+		}), nodeHandler)
+	} else if s.enableWS {
+		// Only the optimism namespace is served over WebSocket, the access control
+		// of the admin methods is based on the individual HTTP requests.
+		s.wsSrv = rpc.NewServer()
+		var apis []rpc.API
+		for _, api := range s.apis {
+			if api.Namespace == "optimism" {
+				apis = append(apis, api)
+			}
+		}
+		if err := node.RegisterApis(apis, nil, s.wsSrv); err != nil {
+			return err
+		}
+		nodeHandler = wsOrHTTPHandler(node.NewWSHandlerStack(s.wsSrv.WebsocketHandler(s.corsOrigins), nil), nodeHandler)
+	}
+	// Attach the trace ID of requests, if any, so the RPC request metrics can link to the trace.
+	nodeHandler = metrics.NewTraceHandler(nodeHandler)
+	if s.access.enabled() {
+		nodeHandler = newRPCAccessHandler(&s.access, s.metrics, nodeHandler)
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", nodeHandler)
@@ -103,12 +141,36 @@ func (s *rpcServer) Start() error {
 
 func (r *rpcServer) Stop() {
 	_ = r.httpServer.Shutdown(context.Background())
+	// Shutdown does not close the hijacked WebSocket connections, the RPC servers do.
+	r.srv.Stop()
+	if r.wsSrv != nil {
+		r.wsSrv.Stop()
+	}
+}
+
+// DialInProc connects a client to the RPC APIs in-process, without a socket.
+// Requests are still encoded and decoded as JSON, but bypass the HTTP handlers,
+// including the access control. The server must be started first.
+func (r *rpcServer) DialInProc() *rpc.Client {
+	return rpc.DialInProc(r.srv)
 }
 
 func (r *rpcServer) Addr() net.Addr {
 	return r.listenAddr
 }
 
+// wsOrHTTPHandler serves WebSocket upgrade requests with ws, and all other requests with other.
+func wsOrHTTPHandler(ws http.Handler, other http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+			strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		other.ServeHTTP(w, r)
+	})
+}
+
 func healthzHandler(appVersion string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(appVersion))
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"math/rand"
+	"net/http"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
@@ -26,7 +29,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOutputAtBlock(t *testing.T) {
@@ -100,7 +105,7 @@ func TestOutputAtBlock(t *testing.T) {
 		InfoReceiptRoot: header.ReceiptHash,
 	}
 	l2Client.ExpectInfoByRpcNumber(rpc.LatestBlockNumber, info, nil)
-	l2Client.ExpectGetProof(predeploys.L2ToL1MessagePasserAddr, "latest", &result, nil)
+	l2Client.ExpectGetProof(predeploys.L2ToL1MessagePasserAddr, header.Hash().String(), &result, nil)
 
 	drClient := &mockDriverClient{}
 
@@ -119,31 +124,78 @@ func TestOutputAtBlock(t *testing.T) {
 	l2Client.Mock.AssertExpectations(t)
 }
 
-func TestVersion(t *testing.T) {
-	log := testlog.Logger(t, log.LvlError)
-	l2Client := &testutils.MockL2Client{}
-	drClient := &mockDriverClient{}
+// startTestRPCServer starts a RPC server backed by the given mocks, and dials it.
+// The server is stopped when the test completes.
+func startTestRPCServer(t *testing.T, log log.Logger, rollupCfg *rollup.Config, l2Client L2EthClient, drClient driverClient) *rpc.Client {
 	rpcCfg := &RPCConfig{
 		ListenAddr: "localhost",
 		ListenPort: 0,
 	}
-	rollupCfg := &rollup.Config{
-		// ignore other rollup config info in this test
-	}
 	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, drClient, log, "0.0", metrics.NewMetrics(""))
-	assert.NoError(t, err)
-	assert.NoError(t, server.Start())
-	defer server.Stop()
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
 
 	client, err := dialRPCClientWithBackoff(context.Background(), log, "http://"+server.Addr().String())
-	assert.NoError(t, err)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestVersion(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	l2Client := &testutils.MockL2Client{}
+	drClient := &mockDriverClient{}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	client := startTestRPCServer(t, log, rollupCfg, l2Client, drClient)
 
 	var out string
-	err = client.CallContext(context.Background(), &out, "optimism_version")
+	err := client.CallContext(context.Background(), &out, "optimism_version")
 	assert.NoError(t, err)
 	assert.Equal(t, version.Version+"-"+version.Meta, out)
 }
 
+func TestRollupConfigHash(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	l2Client := &testutils.MockL2Client{}
+	drClient := &mockDriverClient{}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	client := startTestRPCServer(t, log, rollupCfg, l2Client, drClient)
+
+	expected, err := rollupCfg.Hash()
+	require.NoError(t, err)
+
+	var out *rollup.ConfigHashResult
+	err = client.CallContext(context.Background(), &out, "optimism_rollupConfigHash")
+	require.NoError(t, err)
+	assert.Equal(t, rollup.ConfigHashVersion0.Bytes32(), out.Version)
+	assert.Equal(t, expected, out.Hash)
+	assert.Equal(t, rollupCfg, out.Config)
+
+	// the config returned over RPC must hash the same
+	h, err := out.Config.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, expected, h)
+
+	// explicitly requesting the supported version
+	out = nil
+	err = client.CallContext(context.Background(), &out, "optimism_rollupConfigHash", hexutil.Uint64(rollup.ConfigHashVersion0))
+	require.NoError(t, err)
+	assert.Equal(t, expected, out.Hash)
+
+	// unsupported versions are rejected
+	out = nil
+	err = client.CallContext(context.Background(), &out, "optimism_rollupConfigHash", hexutil.Uint64(1))
+	assert.ErrorContains(t, err, "unsupported config hash version")
+	err = client.CallContext(context.Background(), &out, "optimism_rollupConfigHash", hexutil.Uint64(256))
+	assert.ErrorContains(t, err, "unsupported config hash version")
+	assert.Nil(t, out)
+}
+
 func TestSyncStatus(t *testing.T) {
 	log := testlog.Logger(t, log.LvlError)
 	l2Client := &testutils.MockL2Client{}
@@ -155,30 +207,125 @@ func TestSyncStatus(t *testing.T) {
 		UnsafeL2:    testutils.RandomL2BlockRef(rng),
 		SafeL2:      testutils.RandomL2BlockRef(rng),
 		FinalizedL2: testutils.RandomL2BlockRef(rng),
+
+		QueuedUnsafePayloads: 3,
+		LowestQueuedUnsafeL2: testutils.RandomL2BlockRef(rng),
 	}
 	drClient.On("SyncStatus").Return(&status)
 
-	rpcCfg := &RPCConfig{
-		ListenAddr: "localhost",
-		ListenPort: 0,
-	}
 	rollupCfg := &rollup.Config{
 		// ignore other rollup config info in this test
 	}
-	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, drClient, log, "0.0", metrics.NewMetrics(""))
-	assert.NoError(t, err)
-	assert.NoError(t, server.Start())
-	defer server.Stop()
-
-	client, err := dialRPCClientWithBackoff(context.Background(), log, "http://"+server.Addr().String())
-	assert.NoError(t, err)
+	client := startTestRPCServer(t, log, rollupCfg, l2Client, drClient)
 
 	var out *driver.SyncStatus
-	err = client.CallContext(context.Background(), &out, "optimism_syncStatus")
+	err := client.CallContext(context.Background(), &out, "optimism_syncStatus")
 	assert.NoError(t, err)
 	assert.Equal(t, &status, out)
 }
 
+func TestSyncStatusUpdates(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	drClient := &mockDriverClient{}
+	rng := rand.New(rand.NewSource(1234))
+	status := driver.SyncStatus{
+		CurrentL1: testutils.RandomBlockRef(rng),
+		HeadL1:    testutils.RandomBlockRef(rng),
+		UnsafeL2:  testutils.RandomL2BlockRef(rng),
+	}
+	drClient.On("SyncStatus").Return(&status)
+
+	rpcCfg := &RPCConfig{
+		ListenAddr:  "localhost",
+		ListenPort:  0,
+		CORSOrigins: []string{"https://dashboard.example"},
+		EnableWS:    true,
+	}
+	m := metrics.NewMetrics("")
+	server, err := newRPCServer(context.Background(), rpcCfg, &rollup.Config{}, &testutils.MockL2Client{}, drClient, log, "0.0", m)
+	require.NoError(t, err)
+	server.EnableAdminAPI(newAdminAPI(drClient, nil, m))
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
+
+	client, err := dialRPCClientWithBackoff(context.Background(), log, "ws://"+server.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	statuses := make(chan *driver.SyncStatus, 1)
+	sub, err := client.Subscribe(context.Background(), "optimism", statuses, "syncStatusUpdates")
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	select {
+	case out := <-statuses:
+		require.Equal(t, &status, out, "the current sync status is sent first")
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no sync status received")
+	}
+
+	var out *driver.SyncStatus
+	require.NoError(t, client.CallContext(context.Background(), &out, "optimism_syncStatus"), "calls are served over WebSocket")
+	require.Error(t, client.CallContext(context.Background(), nil, "admin_resetDerivationPipeline"), "the admin namespace is not served over WebSocket")
+
+	// HTTP requests are still served, with CORS for the configured origins only
+	cors := func(origin string) string {
+		req, err := http.NewRequest(http.MethodOptions, "http://"+server.Addr().String(), nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.Header.Get("Access-Control-Allow-Origin")
+	}
+	require.Equal(t, "https://dashboard.example", cors("https://dashboard.example"))
+	require.Empty(t, cors("https://other.example"))
+}
+
+func TestWSRefusedWithAccessControl(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	drClient := &mockDriverClient{}
+	rpcCfg := &RPCConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+		EnableWS:   true,
+		Access:     RPCAccessConfig{AllowedMethods: []string{"optimism_syncStatus"}},
+	}
+	m := metrics.NewMetrics("")
+	server, err := newRPCServer(context.Background(), rpcCfg, &rollup.Config{}, &testutils.MockL2Client{}, drClient, log, "0.0", m)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
+
+	_, err = rpc.DialWebsocket(context.Background(), "ws://"+server.Addr().String(), "")
+	require.Error(t, err, "the messages of WebSocket connections would bypass the access control")
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RPCServerRejectedTotal.WithLabelValues(rejectNotAllowed)))
+}
+
+func TestDialInProc(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	drClient := &mockDriverClient{}
+	status := driver.SyncStatus{UnsafeL2: testutils.RandomL2BlockRef(rand.New(rand.NewSource(1234)))}
+	drClient.On("SyncStatus").Return(&status)
+
+	rpcCfg := &RPCConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+	}
+	server, err := newRPCServer(context.Background(), rpcCfg, &rollup.Config{}, &testutils.MockL2Client{}, drClient, log, "0.0", metrics.NewMetrics(""))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
+
+	client := server.DialInProc()
+	t.Cleanup(client.Close)
+	var out *driver.SyncStatus
+	require.NoError(t, client.CallContext(context.Background(), &out, "optimism_syncStatus"))
+	require.Equal(t, &status, out)
+}
+
 type mockDriverClient struct {
 	mock.Mock
 }
@@ -190,3 +337,17 @@ func (c *mockDriverClient) SyncStatus(ctx context.Context) (*driver.SyncStatus,
 func (c *mockDriverClient) ResetDerivationPipeline(ctx context.Context) error {
 	return c.Mock.MethodCalled("ResetDerivationPipeline").Get(0).(error)
 }
+
+func (c *mockDriverClient) SequencerThrottle(ctx context.Context) (*driver.SequencerThrottle, error) {
+	return c.Mock.MethodCalled("SequencerThrottle").Get(0).(*driver.SequencerThrottle), nil
+}
+
+func (c *mockDriverClient) SubscribeHeads(ch chan driver.HeadUpdate) (unsubscribe func()) {
+	return func() {}
+}
+
+func (c *mockDriverClient) SetSequencerMaxSafeLag(ctx context.Context, maxSafeLag uint64) error {
+	out := c.Mock.MethodCalled("SetSequencerMaxSafeLag", maxSafeLag)
+	err, _ := out.Get(0).(error)
+	return err
+}
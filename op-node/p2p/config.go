@@ -32,7 +32,6 @@ import (
 	cmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/libp2p/go-libp2p/p2p/security/noise"
 	tls "github.com/libp2p/go-libp2p/p2p/security/tls"
-	"github.com/multiformats/go-multiaddr"
 	"github.com/urfave/cli"
 )
 
@@ -41,6 +40,12 @@ type SetupP2P interface {
 	Check() error
 	// Host creates a libp2p host service. Returns nil, nil if p2p is disabled.
 	Host(log log.Logger) (host.Host, error)
+	// PeerScoring returns the configuration of the application-level peer scoring.
+	PeerScoring() PeerScoringConfig
+	// StaticPeerAddrs returns the addresses of the peers to maintain a connection to.
+	StaticPeerAddrs() []core.Multiaddr
+	// ReqRespSyncEnabled returns true if missing unsafe payloads are requested from, and served to, peers.
+	ReqRespSyncEnabled() bool
 	// Discovery creates a disc-v5 service. Returns nil, nil, nil if discovery is disabled.
 	Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort uint16) (*enode.LocalNode, *discover.UDPv5, error)
 	TargetPeers() uint
@@ -85,6 +90,11 @@ type Config struct {
 	TimeoutAccept      time.Duration
 	TimeoutDial        time.Duration
 
+	Scoring PeerScoringConfig
+
+	// EnableReqRespSync enables the req-resp sync protocol, to request missing unsafe payloads from peers
+	EnableReqRespSync bool
+
 	// Underlying store that hosts connection-gater and peerstore data.
 	Store ds.Batching
 
@@ -178,6 +188,18 @@ func (conf *Config) TargetPeers() uint {
 	return conf.PeersLo
 }
 
+func (conf *Config) PeerScoring() PeerScoringConfig {
+	return conf.Scoring
+}
+
+func (conf *Config) StaticPeerAddrs() []core.Multiaddr {
+	return conf.StaticPeers
+}
+
+func (conf *Config) ReqRespSyncEnabled() bool {
+	return conf.EnableReqRespSync
+}
+
 func (conf *Config) loadListenOpts(ctx *cli.Context) error {
 	listenIP := ctx.GlobalString(flags.ListenIP.Name)
 	if listenIP != "" { // optional
@@ -298,9 +320,9 @@ func (conf *Config) loadLibp2pOpts(ctx *cli.Context) error {
 		if addr == "" {
 			continue // skip empty multi addrs
 		}
-		a, err := multiaddr.NewMultiaddr(addr)
+		a, err := ParsePeerAddr(addr)
 		if err != nil {
-			return fmt.Errorf("failed to parse multi addr of static peer %d (out of %d): %q err: %v", i, len(addrs), addr, err)
+			return fmt.Errorf("failed to parse address of static peer %d (out of %d): %q err: %v", i, len(addrs), addr, err)
 		}
 		conf.StaticPeers = append(conf.StaticPeers, a)
 	}
@@ -356,6 +378,13 @@ func (conf *Config) loadLibp2pOpts(ctx *cli.Context) error {
 	conf.TimeoutAccept = ctx.GlobalDuration(flags.TimeoutAccept.Name)
 	conf.TimeoutDial = ctx.GlobalDuration(flags.TimeoutDial.Name)
 
+	conf.Scoring = PeerScoringConfig{
+		BanThreshold:  ctx.GlobalFloat64(flags.BanThreshold.Name),
+		BanDuration:   ctx.GlobalDuration(flags.BanDuration.Name),
+		DecayHalfLife: ctx.GlobalDuration(flags.ScoreDecayHalfLife.Name),
+	}
+	conf.EnableReqRespSync = ctx.GlobalBool(flags.SyncReqRespFlag.Name)
+
 	peerstorePath := ctx.GlobalString(flags.PeerstorePath.Name)
 	if peerstorePath == "" {
 		return errors.New("peerstore path must be specified, use 'memory' to explicitly not persist peer records")
@@ -448,5 +477,8 @@ func (conf *Config) Check() error {
 	if conf.ConnGater == nil {
 		return errors.New("need a connection gater")
 	}
+	if err := conf.Scoring.Check(); err != nil {
+		return fmt.Errorf("invalid peer scoring config: %w", err)
+	}
 	return nil
 }
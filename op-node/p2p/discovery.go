@@ -269,6 +269,7 @@ func (n *NodeP2P) DiscoveryProcess(ctx context.Context, log log.Logger, cfg *rol
 			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 			err := n.Host().Connect(ctx, peer.AddrInfo{ID: id, Addrs: addrs})
 			cancel()
+			n.metrics.RecordPeerDial(DialDiscovery, err == nil)
 			if err != nil {
 				log.Debug("failed connection attempt", "peer", id, "err", err)
 			}
@@ -327,7 +328,6 @@ func (n *NodeP2P) DiscoveryProcess(ctx context.Context, log log.Logger, cfg *rol
 		}
 	}()
 
-	pstore := n.Host().Peerstore()
 	for {
 		select {
 		case <-ctx.Done():
@@ -338,18 +338,10 @@ func (n *NodeP2P) DiscoveryProcess(ctx context.Context, log log.Logger, cfg *rol
 			if err := found.Load(&dat); err != nil { // we already filtered on chain ID and version
 				continue
 			}
-			info, pub, err := enrToAddrInfo(found)
+			info, err := n.addDiscoveredPeer(found, dat)
 			if err != nil {
 				continue
 			}
-			// We add the addresses to the peerstore, and update the address TTL.
-			//After that we stop using the address, assuming it may not be valid anymore (until we rediscover the node)
-			pstore.AddAddrs(info.ID, info.Addrs, discoveredAddrTTL)
-			_ = pstore.AddPubKey(info.ID, pub)
-			// Tag the peer, we'd rather have the connection manager prune away old peers,
-			// or peers on different chains, or anyone we have not seen via discovery.
-			// There is no tag score decay yet, so just set it to 42.
-			n.ConnectionManager().TagPeer(info.ID, fmt.Sprintf("optimism-%d-%d", dat.chainID, dat.version), 42)
 			log.Debug("discovered peer", "peer", info.ID, "nodeID", found.ID(), "addr", info.Addrs[0])
 		case <-connectTicker.C:
 			connected := n.Host().Network().Peers()
@@ -404,6 +396,54 @@ func (n *NodeP2P) DiscoveryProcess(ctx context.Context, log log.Logger, cfg *rol
 	}
 }
 
+// addDiscoveredPeer adds the addresses and pubkey of the node to the peerstore, for the discovery process to connect to.
+func (n *NodeP2P) addDiscoveredPeer(node *enode.Node, dat OptimismENRData) (*peer.AddrInfo, error) {
+	info, pub, err := enrToAddrInfo(node)
+	if err != nil {
+		return nil, err
+	}
+	pstore := n.Host().Peerstore()
+	// We add the addresses to the peerstore, and update the address TTL.
+	//After that we stop using the address, assuming it may not be valid anymore (until we rediscover the node)
+	pstore.AddAddrs(info.ID, info.Addrs, discoveredAddrTTL)
+	_ = pstore.AddPubKey(info.ID, pub)
+	// Tag the peer, we'd rather have the connection manager prune away old peers,
+	// or peers on different chains, or anyone we have not seen via discovery.
+	// There is no tag score decay yet, so just set it to 42.
+	if connMgr := n.ConnectionManager(); connMgr != nil {
+		connMgr.TagPeer(info.ID, fmt.Sprintf("optimism-%d-%d", dat.chainID, dat.version), 42)
+	}
+	return info, nil
+}
+
+// AddBootnode requests the latest record of the node, and adds the node like a discovered peer,
+// for the discovery process to connect to, and to learn about more peers from.
+// This introduces a node at runtime, e.g. to bootstrap a private network:
+// discv5 only starts its table from the bootnodes of the configuration.
+func (n *NodeP2P) AddBootnode(node *enode.Node) error {
+	if n.dv5Udp == nil {
+		return DisabledDiscovery
+	}
+	latest, err := n.dv5Udp.RequestENR(node)
+	if err != nil {
+		return fmt.Errorf("failed to request latest record of node %s: %w", node.ID(), err)
+	}
+	var dat, local OptimismENRData
+	if err := latest.Load(&dat); err != nil {
+		return fmt.Errorf("node record has no optimism info: %w", err)
+	}
+	if err := n.dv5Local.Node().Load(&local); err != nil {
+		return fmt.Errorf("local node record has no optimism info: %w", err)
+	}
+	if dat != local {
+		return fmt.Errorf("node is on chain %d version %d, expected chain %d version %d", dat.chainID, dat.version, local.chainID, local.version)
+	}
+	if _, err := n.addDiscoveredPeer(latest, dat); err != nil {
+		return fmt.Errorf("failed to add node %s: %w", node.ID(), err)
+	}
+	return nil
+}
+
 // shuffle the slice of peer IDs in-place with a RNG seeded by secure randomness.
 func shufflePeers(ids peer.IDSlice) error {
 	var x [8]byte // shuffling is not critical, just need to avoid basic predictability by outside peers
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -15,7 +16,6 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/golang/snappy"
 	"github.com/libp2p/go-libp2p-core/host"
@@ -43,8 +43,36 @@ var MessageDomainValidSnappy = [4]byte{1, 0, 0, 0}
 
 const MaxGossipSize = 1 << 20
 
+// Directions of gossiped payloads, used as metric labels.
+const (
+	GossipReceived  = "received"
+	GossipPublished = "published"
+)
+
+var errGossipTooLarge = errors.New("decompressed gossip message is too large")
+
+// decompressGossip decompresses the snappy compressed gossip message into dst.
+// The decompressed length is checked against maxSize before decompressing,
+// to not allocate for zip bombs: small messages that claim a large decompressed length.
+func decompressGossip(dst []byte, data []byte, maxSize int) ([]byte, error) {
+	dLen, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy compression length data: %w", err)
+	}
+	if dLen > maxSize {
+		return nil, fmt.Errorf("%w: decoded length %d > %d", errGossipTooLarge, dLen, maxSize)
+	}
+	out, err := snappy.Decode(dst, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy compression: %w", err)
+	}
+	return out, nil
+}
+
+// blocksTopicV1 returns the name of the blocks topic, versioned by the L2 chain ID and the P2P fork version,
+// so the gossip of different chains, and of different forks of a chain, is isolated.
 func blocksTopicV1(cfg *rollup.Config) string {
-	return fmt.Sprintf("/optimism/%s/0/blocks", cfg.L2ChainID.String())
+	return fmt.Sprintf("/optimism/%s/%d/blocks", cfg.L2ChainID.String(), cfg.P2PForkVersion)
 }
 
 // BuildSubscriptionFilter builds a simple subscription filter,
@@ -68,14 +96,12 @@ func BuildMsgIdFn(cfg *rollup.Config) pubsub.MsgIdFunction {
 		// If it's a valid compressed snappy data, then hash the uncompressed contents.
 		// The validator can throw away the message later when recognized as invalid,
 		// and the unique hash helps detect duplicates.
-		dLen, err := snappy.DecodedLen(pmsg.Data)
-		if err == nil && dLen <= MaxGossipSize {
-			res := msgBufPool.Get().(*[]byte)
-			defer msgBufPool.Put(res)
-			if data, err = snappy.Decode((*res)[:0], pmsg.Data); err == nil {
-				*res = data // if we ended up growing the slice capacity, fine, keep the larger one.
-				valid = true
-			}
+		res := msgBufPool.Get().(*[]byte)
+		defer msgBufPool.Put(res)
+		if out, err := decompressGossip((*res)[:0], pmsg.Data, MaxGossipSize); err == nil {
+			*res = out // if we ended up growing the slice capacity, fine, keep the larger one.
+			data = out
+			valid = true
 		}
 		if data == nil {
 			data = pmsg.Data
@@ -183,7 +209,15 @@ func (sb *seenBlocks) markSeen(h common.Hash) {
 	sb.blockHashes = append(sb.blockHashes, h)
 }
 
-func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx {
+// GossipMetricer tracks the results of gossip validation.
+type GossipMetricer interface {
+	RecordUnsafePayloadRejected(reason string)
+	// RecordGossipPayloadSize records the compressed size on the wire and the decompressed size
+	// of a received or published payload message, see GossipReceived and GossipPublished.
+	RecordGossipPayloadSize(direction string, wireSize int, size int)
+}
+
+func BuildBlocksValidator(log log.Logger, cfg *rollup.Config, m GossipMetricer) pubsub.ValidatorEx {
 
 	// Seen block hashes per block height
 	// uint64 -> *seenBlocks
@@ -192,26 +226,38 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		panic(fmt.Errorf("failed to set up block height LRU cache: %v", err))
 	}
 
+	blocksTopic := blocksTopicV1(cfg)
+
 	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
-		// [REJECT] if the compression is not valid
-		outLen, err := snappy.DecodedLen(message.Data)
-		if err != nil {
-			log.Warn("invalid snappy compression length data", "err", err, "peer", id)
-			return pubsub.ValidationReject
-		}
-		if outLen > maxGossipSize {
-			log.Warn("possible snappy zip bomb, decoded length is too large", "decoded_length", outLen, "peer", id)
+		// [REJECT] if the message is not on the blocks topic of the chain and fork
+		if topic := message.GetTopic(); topic != blocksTopic {
+			log.Warn("message is on the wrong topic", "topic", topic, "expected", blocksTopic, "peer", id)
+			m.RecordUnsafePayloadRejected("wrong_topic")
 			return pubsub.ValidationReject
 		}
 
+		// [REJECT] if the compression is not valid, or decompresses to more than the max gossip size
 		res := msgBufPool.Get().(*[]byte)
 		defer msgBufPool.Put(res)
-		data, err := snappy.Decode((*res)[:0], message.Data)
-		if err != nil {
+		data, err := decompressGossip((*res)[:0], message.Data, maxGossipSize)
+		if errors.Is(err, errGossipTooLarge) {
+			log.Warn("possible snappy zip bomb, decoded length is too large", "err", err, "peer", id)
+			m.RecordUnsafePayloadRejected("too_large")
+			return pubsub.ValidationReject
+		} else if err != nil {
 			log.Warn("invalid snappy compression", "err", err, "peer", id)
+			m.RecordUnsafePayloadRejected("invalid_compression")
 			return pubsub.ValidationReject
 		}
 		*res = data // if we ended up growing the slice capacity, fine, keep the larger one.
+		m.RecordGossipPayloadSize(GossipReceived, len(message.Data), len(data))
+
+		// [REJECT] if the message is too short to hold the signature
+		if len(data) < 65 {
+			log.Warn("message is too short", "size", len(data), "peer", id)
+			m.RecordUnsafePayloadRejected("too_short")
+			return pubsub.ValidationReject
+		}
 
 		// message starts with compact-encoding secp256k1 encoded signature
 		signatureBytes, payloadBytes := data[:65], data[65:]
@@ -220,6 +266,7 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		var payload eth.ExecutionPayload
 		if err := payload.UnmarshalSSZ(uint32(len(payloadBytes)), bytes.NewReader(payloadBytes)); err != nil {
 			log.Warn("invalid payload", "err", err, "peer", id)
+			m.RecordUnsafePayloadRejected("invalid_payload")
 			return pubsub.ValidationReject
 		}
 
@@ -229,18 +276,23 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		// [REJECT] if the `payload.timestamp` is older than 60 seconds in the past
 		if uint64(payload.Timestamp) < now-60 {
 			log.Warn("payload is too old", "timestamp", uint64(payload.Timestamp))
+			m.RecordUnsafePayloadRejected("too_old")
+			message.ValidatorData = unpenalizedRejection("too_old")
 			return pubsub.ValidationReject
 		}
 
 		// [REJECT] if the `payload.timestamp` is more than 5 seconds into the future
 		if uint64(payload.Timestamp) > now+5 {
 			log.Warn("payload is too new", "timestamp", uint64(payload.Timestamp))
+			m.RecordUnsafePayloadRejected("future_timestamp")
+			message.ValidatorData = unpenalizedRejection("future_timestamp")
 			return pubsub.ValidationReject
 		}
 
 		// [REJECT] if the `block_hash` in the `payload` is not valid
 		if actual, ok := payload.CheckBlockHash(); !ok {
 			log.Warn("payload has bad block hash", "bad_hash", payload.BlockHash.String(), "actual", actual.String())
+			m.RecordUnsafePayloadRejected("bad_block_hash")
 			return pubsub.ValidationReject
 		}
 
@@ -253,6 +305,8 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		if count, hasSeen := seen.(*seenBlocks).hasSeen(payload.BlockHash); count > 5 {
 			// [REJECT] if more than 5 blocks have been seen with the same block height
 			log.Warn("seen too many different blocks at same height", "height", payload.BlockNumber)
+			m.RecordUnsafePayloadRejected("too_many_blocks")
+			message.ValidatorData = unpenalizedRejection("too_many_blocks")
 			return pubsub.ValidationReject
 		} else if hasSeen {
 			// [IGNORE] if the block has already been seen
@@ -261,18 +315,13 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		}
 
 		// [REJECT] if the signature by the sequencer is not valid
-		signingHash := BlockSigningHash(cfg, payloadBytes)
-
-		pub, err := crypto.SigToPub(signingHash[:], signatureBytes)
-		if err != nil {
-			log.Warn("invalid block signature", "err", err, "peer", id)
-			return pubsub.ValidationReject
-		}
-		addr := crypto.PubkeyToAddress(*pub)
-
-		// TODO: in the future we can support multiple valid p2p addresses.
-		if addr != cfg.P2PSequencerAddress {
+		if err := VerifyBlockSignature(cfg, payloadBytes, signatureBytes); errors.Is(err, ErrUnexpectedBlockSigner) {
 			log.Warn("unexpected block author", "err", err, "peer", id)
+			m.RecordUnsafePayloadRejected("unexpected_signer")
+			return pubsub.ValidationReject
+		} else if err != nil {
+			log.Warn("invalid block signature", "err", err, "peer", id)
+			m.RecordUnsafePayloadRejected("bad_signature")
 			return pubsub.ValidationReject
 		}
 
@@ -304,6 +353,7 @@ type publisher struct {
 	log         log.Logger
 	cfg         *rollup.Config
 	blocksTopic *pubsub.Topic
+	metrics     GossipMetricer
 }
 
 var _ GossipOut = (*publisher)(nil)
@@ -335,6 +385,7 @@ func (p *publisher) PublishL2Payload(ctx context.Context, payload *eth.Execution
 	// compress the full message
 	// This also copies the data, freeing up the original buffer to go back into the pool
 	out := snappy.Encode(nil, data)
+	p.metrics.RecordGossipPayloadSize(GossipPublished, len(out), len(data))
 
 	return p.blocksTopic.Publish(ctx, out)
 }
@@ -343,8 +394,8 @@ func (p *publisher) Close() error {
 	return p.blocksTopic.Close()
 }
 
-func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn) (GossipOut, error) {
-	val := logValidationResult(self, "validated block", log, BuildBlocksValidator(log, cfg))
+func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, m GossipMetricer, scorer *PeerScorer, gossipIn GossipIn) (GossipOut, error) {
+	val := logValidationResult(self, "validated block", log, scoreValidationResult(self, scorer, BuildBlocksValidator(log, cfg, m)))
 	blocksTopicName := blocksTopicV1(cfg)
 	err := ps.RegisterTopicValidator(blocksTopicName,
 		val,
@@ -377,7 +428,7 @@ func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log
 	subscriber := MakeSubscriber(log, BlocksHandler(gossipIn.OnUnsafeL2Payload))
 	go subscriber(p2pCtx, subscription)
 
-	return &publisher{log: log, cfg: cfg, blocksTopic: blocksTopic}, nil
+	return &publisher{log: log, cfg: cfg, blocksTopic: blocksTopic, metrics: m}, nil
 }
 
 type TopicSubscriber func(ctx context.Context, sub *pubsub.Subscription)
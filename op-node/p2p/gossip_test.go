@@ -0,0 +1,196 @@
+package p2p
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestDecompressGossip(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		data := []byte("signature and ssz encoded payload")
+		out, err := decompressGossip(nil, snappy.Encode(nil, data), maxGossipSize)
+		require.NoError(t, err)
+		require.Equal(t, data, out)
+	})
+	t.Run("max size", func(t *testing.T) {
+		data := make([]byte, 1000)
+		_, err := decompressGossip(nil, snappy.Encode(nil, data), 1000)
+		require.NoError(t, err)
+		_, err = decompressGossip(nil, snappy.Encode(nil, data), 999)
+		require.ErrorIs(t, err, errGossipTooLarge)
+	})
+	t.Run("bomb", func(t *testing.T) {
+		// zeroes compress well: a small message decompresses to beyond the max gossip size
+		compressed := snappy.Encode(nil, make([]byte, 10*maxGossipSize))
+		require.Less(t, len(compressed), maxGossipSize)
+		_, err := decompressGossip(nil, compressed, maxGossipSize)
+		require.ErrorIs(t, err, errGossipTooLarge)
+	})
+	t.Run("claimed length", func(t *testing.T) {
+		// a few bytes that claim a decompressed length of 4 GB, without any data to back it
+		data := make([]byte, binary.MaxVarintLen64+3)
+		n := binary.PutUvarint(data, 1<<32-1)
+		_, err := decompressGossip(nil, data[:n+3], maxGossipSize)
+		require.ErrorIs(t, err, errGossipTooLarge)
+	})
+	t.Run("claimed length too short", func(t *testing.T) {
+		compressed := snappy.Encode(nil, []byte("more data than claimed"))
+		compressed[0] = 3 // the varint of the decompressed length
+		_, err := decompressGossip(nil, compressed, maxGossipSize)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errGossipTooLarge)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := decompressGossip(nil, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, maxGossipSize)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, errGossipTooLarge)
+	})
+}
+
+func TestBlocksValidatorRejectsMessage(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(100)}
+	validate := func(t *testing.T, data []byte) *testutils.RecordingMetrics {
+		m := &testutils.RecordingMetrics{}
+		v := BuildBlocksValidator(testlog.Logger(t, log.LvlError), cfg, m)
+		topic := blocksTopicV1(cfg)
+		msg := &pubsub.Message{Message: &pb.Message{Data: data, Topic: &topic}}
+		require.Equal(t, pubsub.ValidationReject, v(context.Background(), "", msg))
+		return m
+	}
+	requireRejected := func(t *testing.T, m *testutils.RecordingMetrics, reason string) {
+		records := m.Records("RecordUnsafePayloadRejected")
+		require.Len(t, records, 1)
+		require.Equal(t, reason, records[0].Name)
+	}
+
+	t.Run("wrong topic", func(t *testing.T) {
+		m := &testutils.RecordingMetrics{}
+		v := BuildBlocksValidator(testlog.Logger(t, log.LvlError), cfg, m)
+		topic := blocksTopicV1(&rollup.Config{L2ChainID: big.NewInt(100), P2PForkVersion: 1})
+		msg := &pubsub.Message{Message: &pb.Message{Data: snappy.Encode(nil, make([]byte, 65+10)), Topic: &topic}}
+		require.Equal(t, pubsub.ValidationReject, v(context.Background(), "", msg))
+		requireRejected(t, m, "wrong_topic")
+	})
+	t.Run("bomb", func(t *testing.T) {
+		m := validate(t, snappy.Encode(nil, make([]byte, maxGossipSize+1)))
+		requireRejected(t, m, "too_large")
+		m.RequireCount(t, "RecordGossipPayloadSize", 0)
+	})
+	t.Run("invalid compression", func(t *testing.T) {
+		m := validate(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+		requireRejected(t, m, "invalid_compression")
+	})
+	t.Run("too short", func(t *testing.T) {
+		data := snappy.Encode(nil, make([]byte, 64))
+		m := validate(t, data)
+		requireRejected(t, m, "too_short")
+		sizes := m.Records("RecordGossipPayloadSize")
+		require.Len(t, sizes, 1)
+		require.Equal(t, GossipReceived, sizes[0].Name)
+		require.Equal(t, [2]int{len(data), 64}, sizes[0].Value)
+	})
+	t.Run("invalid payload", func(t *testing.T) {
+		m := validate(t, snappy.Encode(nil, make([]byte, 65+10)))
+		requireRejected(t, m, "invalid_payload")
+	})
+}
+
+func TestBlocksTopic(t *testing.T) {
+	require.Equal(t, "/optimism/100/0/blocks", blocksTopicV1(&rollup.Config{L2ChainID: big.NewInt(100)}))
+	require.Equal(t, "/optimism/100/2/blocks", blocksTopicV1(&rollup.Config{L2ChainID: big.NewInt(100), P2PForkVersion: 2}))
+	require.Equal(t, "/optimism/901/0/blocks", blocksTopicV1(&rollup.Config{L2ChainID: big.NewInt(901)}))
+}
+
+type payloadsGossipIn chan *eth.ExecutionPayload
+
+func (g payloadsGossipIn) OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error {
+	g <- msg
+	return nil
+}
+
+// TestGossipIsolation checks that blocks are only gossiped between nodes of the same chain and fork.
+func TestGossipIsolation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(priv)
+	newCfg := func(chainID int64, forkVersion uint64) *rollup.Config {
+		return &rollup.Config{
+			L2ChainID:           big.NewInt(chainID),
+			P2PForkVersion:      forkVersion,
+			P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey),
+		}
+	}
+	cfgs := []*rollup.Config{
+		newCfg(100, 0), // publisher
+		newCfg(100, 0), // same chain and fork
+		newCfg(100, 1), // other fork
+		newCfg(200, 0), // other chain
+	}
+
+	mnet, err := mocknet.FullMeshConnected(len(cfgs))
+	require.NoError(t, err)
+	defer mnet.Close()
+	var outs []GossipOut
+	var ins []payloadsGossipIn
+	for i, h := range mnet.Hosts() {
+		logger := testlog.Logger(t, log.LvlError).New("node", i)
+		ps, err := NewGossipSub(ctx, h, cfgs[i])
+		require.NoError(t, err)
+		m := &testutils.RecordingMetrics{}
+		in := make(payloadsGossipIn, 10)
+		out, err := JoinGossip(ctx, h.ID(), ps, logger, cfgs[i], m, NewPeerScorer(logger, PeerScoringConfig{}, nil, nil, m), in)
+		require.NoError(t, err)
+		defer out.Close()
+		outs = append(outs, out)
+		ins = append(ins, in)
+	}
+
+	// wait for the publisher to see the peer of the same chain and fork on the topic
+	require.Eventually(t, func() bool {
+		return len(outs[0].BlocksTopicPeers()) == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, []peer.ID{mnet.Hosts()[1].ID()}, outs[0].BlocksTopicPeers())
+
+	payload := &eth.ExecutionPayload{
+		BlockNumber:  1,
+		GasLimit:     30_000_000,
+		Timestamp:    eth.Uint64Quantity(time.Now().Unix()),
+		Transactions: []eth.Data{},
+	}
+	payload.BlockHash, _ = payload.CheckBlockHash()
+
+	// the gossip mesh may take a heartbeat to form, retry publishing until the peer receives the payload
+	require.Eventually(t, func() bool {
+		require.NoError(t, outs[0].PublishL2Payload(ctx, payload, signer))
+		select {
+		case got := <-ins[1]:
+			require.Equal(t, payload.BlockHash, got.BlockHash)
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Empty(t, ins[2], "node of other fork must not receive the payload")
+	require.Empty(t, ins[3], "node of other chain must not receive the payload")
+}
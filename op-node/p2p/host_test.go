@@ -133,7 +133,7 @@ func TestP2PFull(t *testing.T) {
 	// TODO: maybe swap the order of sec/mux preferences, to test that negotiation works
 
 	logA := testlog.Logger(t, log.LvlError).New("host", "A")
-	nodeA, err := NewNodeP2P(context.Background(), &rollup.Config{}, logA, &confA, &mockGossipIn{})
+	nodeA, err := NewNodeP2P(context.Background(), &rollup.Config{}, logA, &confA, &mockGossipIn{}, nil, metrics.NewMetrics(""))
 	require.NoError(t, err)
 	defer nodeA.Close()
 
@@ -156,7 +156,7 @@ func TestP2PFull(t *testing.T) {
 
 	logB := testlog.Logger(t, log.LvlError).New("host", "B")
 
-	nodeB, err := NewNodeP2P(context.Background(), &rollup.Config{}, logB, &confB, &mockGossipIn{})
+	nodeB, err := NewNodeP2P(context.Background(), &rollup.Config{}, logB, &confB, &mockGossipIn{}, nil, metrics.NewMetrics(""))
 	require.NoError(t, err)
 	defer nodeB.Close()
 	hostB := nodeB.Host()
@@ -199,6 +199,21 @@ func TestP2PFull(t *testing.T) {
 	require.Equal(t, subnet, blockedSubnets[0])
 	require.NoError(t, p2pClientA.UnblockSubnet(ctx, subnet))
 
+	addrsB, err := peer.AddrInfoToP2pAddrs(&peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()})
+	require.NoError(t, err)
+	staticID, err := p2pClientA.AddStaticPeer(ctx, addrsB[0].String())
+	require.NoError(t, err)
+	require.Equal(t, hostB.ID(), staticID)
+	staticPeers, err := p2pClientA.ListStaticPeers(ctx)
+	require.NoError(t, err)
+	require.Len(t, staticPeers, 1)
+	require.Equal(t, hostB.ID(), staticPeers[0].PeerID)
+	require.NoError(t, p2pClientA.RemoveStaticPeer(ctx, hostB.ID()))
+	require.Error(t, p2pClientA.RemoveStaticPeer(ctx, hostB.ID()), "not a static peer anymore")
+
+	err = p2pClientA.AddBootnode(ctx, nodeA.Host().ID().String())
+	require.Error(t, err, "not a valid ENR")
+
 	// Ask host A for all peer information they have
 	peerDump, err := p2pClientA.Peers(ctx, false)
 	require.Nil(t, err)
@@ -290,7 +305,7 @@ func TestDiscovery(t *testing.T) {
 	resourcesCtx, resourcesCancel := context.WithCancel(context.Background())
 	defer resourcesCancel()
 
-	nodeA, err := NewNodeP2P(context.Background(), rollupCfg, logA, &confA, &mockGossipIn{})
+	nodeA, err := NewNodeP2P(context.Background(), rollupCfg, logA, &confA, &mockGossipIn{}, nil, metrics.NewMetrics(""))
 	require.NoError(t, err)
 	defer nodeA.Close()
 	hostA := nodeA.Host()
@@ -305,7 +320,7 @@ func TestDiscovery(t *testing.T) {
 	confB.DiscoveryDB = discDBC
 
 	// Start B
-	nodeB, err := NewNodeP2P(context.Background(), rollupCfg, logB, &confB, &mockGossipIn{})
+	nodeB, err := NewNodeP2P(context.Background(), rollupCfg, logB, &confB, &mockGossipIn{}, nil, metrics.NewMetrics(""))
 	require.NoError(t, err)
 	defer nodeB.Close()
 	hostB := nodeB.Host()
@@ -320,7 +335,7 @@ func TestDiscovery(t *testing.T) {
 		}})
 
 	// Start C
-	nodeC, err := NewNodeP2P(context.Background(), rollupCfg, logC, &confC, &mockGossipIn{})
+	nodeC, err := NewNodeP2P(context.Background(), rollupCfg, logC, &confC, &mockGossipIn{}, nil, metrics.NewMetrics(""))
 	require.NoError(t, err)
 	defer nodeC.Close()
 	hostC := nodeC.Host()
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	ma "github.com/multiformats/go-multiaddr"
 
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/discover"
@@ -15,6 +17,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/libp2p/go-libp2p-core/connmgr"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
 )
@@ -28,14 +31,25 @@ type NodeP2P struct {
 	dv5Udp   *discover.UDPv5  // p2p discovery service
 	gs       *pubsub.PubSub   // p2p gossip router
 	gsOut    GossipOut        // p2p gossip application interface for publishing
+	scorer   *PeerScorer      // application-level peer scores, to temporarily ban misbehaving peers with
+	syncCl   *SyncClient      // req-resp sync client, to request missing unsafe payloads with, nil if disabled
+	statics  *StaticPeers     // static peers, to maintain connections to
+	metrics  Metricer
 }
 
-func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn) (*NodeP2P, error) {
+// Metricer tracks the metrics of the p2p node.
+type Metricer interface {
+	GossipMetricer
+	PeerScoreMetricer
+	DialMetricer
+}
+
+func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn, l2Chain L2Chain, metrics Metricer) (*NodeP2P, error) {
 	if setup == nil {
 		return nil, errors.New("p2p node cannot be created without setup")
 	}
 	var n NodeP2P
-	if err := n.init(resourcesCtx, rollupCfg, log, setup, gossipIn); err != nil {
+	if err := n.init(resourcesCtx, rollupCfg, log, setup, gossipIn, l2Chain, metrics); err != nil {
 		closeErr := n.Close()
 		if closeErr != nil {
 			log.Error("failed to close p2p after starting with err", "closeErr", closeErr, "err", err)
@@ -48,8 +62,9 @@ func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.
 	return &n, nil
 }
 
-func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn) error {
+func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn, l2Chain L2Chain, metrics Metricer) error {
 	var err error
+	n.metrics = metrics
 	// nil if disabled.
 	n.host, err = setup.Host(log)
 	if err != nil {
@@ -69,17 +84,32 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 		n.host.Network().Notify(NewNetworkNotifier(log))
 		// unregister identify-push handler. Only identifying on dial is fine, and more robust against spam
 		n.host.RemoveStreamHandler(identify.IDDelta)
+		n.scorer = NewPeerScorer(log, setup.PeerScoring(), n.gater, n.host.Network().ClosePeer, metrics)
+		go n.scorer.Run(resourcesCtx, time.Minute)
+
+		n.statics = NewStaticPeers(log.New("p2p", "static"), n.host, n.connMgr, metrics)
+		for _, addr := range setup.StaticPeerAddrs() {
+			if _, err := n.statics.Add(addr); err != nil {
+				return fmt.Errorf("bad static peer %s: %w", addr, err)
+			}
+		}
+		go n.statics.Run(resourcesCtx, staticPeerDialInterval)
+
 		n.gs, err = NewGossipSub(resourcesCtx, n.host, rollupCfg)
 		if err != nil {
 			return fmt.Errorf("failed to start gossipsub router: %v", err)
 		}
 
-		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, gossipIn)
+		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, metrics, n.scorer, gossipIn)
 		if err != nil {
 			return fmt.Errorf("failed to join blocks gossip topic: %v", err)
 		}
 		log.Info("started p2p host", "addrs", n.host.Addrs(), "peerID", n.host.ID().Pretty())
 
+		if setup.ReqRespSyncEnabled() {
+			n.initReqRespSync(resourcesCtx, rollupCfg, log, gossipIn, l2Chain)
+		}
+
 		tcpPort, err := FindActiveTCPPort(n.host)
 		if err != nil {
 			log.Warn("failed to find what TCP port p2p is binded to", "err", err)
@@ -94,6 +124,37 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 	return nil
 }
 
+func (n *NodeP2P) initReqRespSync(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, gossipIn GossipIn, l2Chain L2Chain) {
+	protocolID := PayloadByNumberProtocolID(rollupCfg.L2ChainID)
+	srv := NewReqRespServer(l2Chain)
+	n.host.SetStreamHandler(protocolID, srv.MakeStreamHandler(resourcesCtx, log.New("serve", "payloads_by_number")))
+
+	newStream := func(ctx context.Context, id peer.ID) (SyncStream, error) {
+		return n.host.NewStream(ctx, id, protocolID)
+	}
+	// only request payloads from the connected peers that support the protocol
+	peers := func() []peer.ID {
+		var out []peer.ID
+		for _, id := range n.host.Network().Peers() {
+			if protocols, err := n.host.Peerstore().SupportsProtocols(id, string(protocolID)); err == nil && len(protocols) > 0 {
+				out = append(out, id)
+			}
+		}
+		return out
+	}
+	n.syncCl = NewSyncClient(log.New("sync", "payloads_by_number"), newStream, peers, gossipIn.OnUnsafeL2Payload, n.scorer)
+	n.syncCl.Start()
+}
+
+// RequestL2Range requests the unsafe payloads between start and end from peers, see SyncClient.RequestL2Range.
+// This is a no-op if the req-resp sync is disabled.
+func (n *NodeP2P) RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error {
+	if n.syncCl == nil {
+		return nil
+	}
+	return n.syncCl.RequestL2Range(ctx, start, end)
+}
+
 func (n *NodeP2P) Host() host.Host {
 	return n.host
 }
@@ -118,6 +179,14 @@ func (n *NodeP2P) ConnectionGater() ConnectionGater {
 	return n.gater
 }
 
+func (n *NodeP2P) PeerScorer() *PeerScorer {
+	return n.scorer
+}
+
+func (n *NodeP2P) StaticPeers() *StaticPeers {
+	return n.statics
+}
+
 func (n *NodeP2P) ConnectionManager() connmgr.ConnManager {
 	return n.connMgr
 }
@@ -127,6 +196,11 @@ func (n *NodeP2P) Close() error {
 	if n.dv5Udp != nil {
 		n.dv5Udp.Close()
 	}
+	if n.syncCl != nil {
+		if err := n.syncCl.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close sync client cleanly: %v", err))
+		}
+	}
 	if n.gsOut != nil {
 		if err := n.gsOut.Close(); err != nil {
 			result = multierror.Append(result, fmt.Errorf("failed to close gossip cleanly: %v", err))
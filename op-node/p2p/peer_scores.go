@@ -0,0 +1,229 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// PeerScoringConfig configures the application-level scoring of peers.
+type PeerScoringConfig struct {
+	// BanThreshold is the score at or below which a peer is temporarily banned.
+	// Peers are never banned if 0.
+	BanThreshold float64
+	// BanDuration is the time a peer stays banned.
+	BanDuration time.Duration
+	// DecayHalfLife is the time it takes for a score to decay halfway back to 0.
+	// Scores do not decay if 0.
+	DecayHalfLife time.Duration
+}
+
+func (c *PeerScoringConfig) Check() error {
+	if c.BanThreshold > 0 {
+		return fmt.Errorf("peer ban threshold must be negative, or 0 to disable bans: %f", c.BanThreshold)
+	}
+	if c.BanThreshold != 0 && c.BanDuration <= 0 {
+		return fmt.Errorf("peer ban duration must be positive: %s", c.BanDuration)
+	}
+	return nil
+}
+
+// Penalty is a change of the score of a peer, for misbehavior of the peer.
+type Penalty struct {
+	Reason string // used as metric label
+	Score  float64
+}
+
+var (
+	PenaltyInvalidPayload = Penalty{Reason: "invalid_payload", Score: -20}
+	PenaltySlowResponse   = Penalty{Reason: "slow_response", Score: -5}
+)
+
+// PeerScoreMetricer tracks the penalties and bans of peers.
+type PeerScoreMetricer interface {
+	RecordPeerPenalty(reason string)
+	SetBannedPeers(count int)
+}
+
+// PeerBlocker blocks and unblocks peers, e.g. the ConnectionGater.
+type PeerBlocker interface {
+	BlockPeer(p peer.ID) error
+	UnblockPeer(p peer.ID) error
+}
+
+type peerScore struct {
+	score   float64
+	updated time.Time
+}
+
+// PeerScorer tracks the application-level scores of peers, lowered by penalties for misbehavior,
+// and temporarily bans the peers of which the score drops to the ban threshold.
+// It is safe for concurrent use.
+type PeerScorer struct {
+	log     log.Logger
+	cfg     PeerScoringConfig
+	blocker PeerBlocker         // may be nil, peers are not banned then
+	close   func(peer.ID) error // closes the connections to a banned peer, may be nil
+	metrics PeerScoreMetricer
+	now     func() time.Time
+
+	mu     sync.Mutex
+	scores map[peer.ID]*peerScore
+	bans   map[peer.ID]time.Time // expiry of the temporary bans
+}
+
+func NewPeerScorer(log log.Logger, cfg PeerScoringConfig, blocker PeerBlocker, closePeer func(peer.ID) error, m PeerScoreMetricer) *PeerScorer {
+	return &PeerScorer{
+		log:     log,
+		cfg:     cfg,
+		blocker: blocker,
+		close:   closePeer,
+		metrics: m,
+		now:     time.Now,
+		scores:  make(map[peer.ID]*peerScore),
+		bans:    make(map[peer.ID]time.Time),
+	}
+}
+
+// decayed returns the score decayed up to now.
+func (s *PeerScorer) decayed(ps *peerScore, now time.Time) float64 {
+	if s.cfg.DecayHalfLife <= 0 {
+		return ps.score
+	}
+	halvings := float64(now.Sub(ps.updated)) / float64(s.cfg.DecayHalfLife)
+	return ps.score * math.Pow(0.5, halvings)
+}
+
+// Score returns the current score of the peer, 0 if the peer has no penalties.
+func (s *PeerScorer) Score(id peer.ID) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ps, ok := s.scores[id]; ok {
+		return s.decayed(ps, s.now())
+	}
+	return 0
+}
+
+// Penalize lowers the score of the peer, and bans the peer if the score drops to the ban threshold.
+func (s *PeerScorer) Penalize(id peer.ID, p Penalty) {
+	// the connections are closed without holding the lock, closing may call back into the scorer
+	if s.penalize(id, p) && s.close != nil {
+		if err := s.close(id); err != nil {
+			s.log.Warn("Failed to disconnect banned peer", "peer", id, "err", err)
+		}
+	}
+}
+
+// penalize lowers the score of the peer, and returns true if the peer is banned because of it.
+func (s *PeerScorer) penalize(id peer.ID, p Penalty) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.RecordPeerPenalty(p.Reason)
+	if _, banned := s.bans[id]; banned {
+		return false
+	}
+	now := s.now()
+	ps, ok := s.scores[id]
+	if !ok {
+		ps = &peerScore{}
+		s.scores[id] = ps
+	}
+	ps.score = s.decayed(ps, now) + p.Score
+	ps.updated = now
+	s.log.Debug("Penalized peer", "peer", id, "reason", p.Reason, "score", ps.score)
+	if s.cfg.BanThreshold == 0 || ps.score > s.cfg.BanThreshold || s.blocker == nil {
+		return false
+	}
+	if err := s.blocker.BlockPeer(id); err != nil {
+		s.log.Warn("Failed to ban peer", "peer", id, "err", err)
+		return false
+	}
+	delete(s.scores, id)
+	s.bans[id] = now.Add(s.cfg.BanDuration)
+	s.metrics.SetBannedPeers(len(s.bans))
+	s.log.Warn("Banned peer", "peer", id, "reason", p.Reason, "duration", s.cfg.BanDuration)
+	return true
+}
+
+// Bans returns the expiry of the temporary bans by peer.
+func (s *PeerScorer) Bans() map[peer.ID]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[peer.ID]time.Time, len(s.bans))
+	for id, expiry := range s.bans {
+		out[id] = expiry
+	}
+	return out
+}
+
+// ClearBan forgets the temporary ban of the peer, if any, without unblocking it.
+// This is used when a peer is blocked or unblocked manually, to not have the ban expiry change it back.
+func (s *PeerScorer) ClearBan(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.bans[id]; ok {
+		delete(s.bans, id)
+		s.metrics.SetBannedPeers(len(s.bans))
+	}
+}
+
+// UnbanExpired unblocks the peers of which the temporary ban expired.
+func (s *PeerScorer) UnbanExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, expiry := range s.bans {
+		if now.Before(expiry) {
+			continue
+		}
+		if err := s.blocker.UnblockPeer(id); err != nil {
+			s.log.Warn("Failed to unban peer", "peer", id, "err", err)
+			continue
+		}
+		delete(s.bans, id)
+		s.log.Info("Ban of peer expired", "peer", id)
+	}
+	s.metrics.SetBannedPeers(len(s.bans))
+}
+
+// Run unbans the peers of which the temporary ban expired, every interval, until the context is done.
+func (s *PeerScorer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.UnbanExpired()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// unpenalizedRejection is set as ValidatorData of a rejected gossip message, if the message was rejected
+// for a reason that honest peers run into as well, e.g. a timestamp check that depends on the local clock.
+// The peer is not penalized for such a message.
+type unpenalizedRejection string
+
+// scoreValidationResult penalizes the peers that gossip messages that are rejected by the validator.
+func scoreValidationResult(self peer.ID, scorer *PeerScorer, fn pubsub.ValidatorEx) pubsub.ValidatorEx {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		res := fn(ctx, id, message)
+		if res != pubsub.ValidationReject || id == self {
+			return res
+		}
+		if message != nil {
+			if _, ok := message.ValidatorData.(unpenalizedRejection); ok {
+				return res
+			}
+		}
+		scorer.Penalize(id, PenaltyInvalidPayload)
+		return res
+	}
+}
@@ -0,0 +1,167 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type mockPeerBlocker struct {
+	blocked map[peer.ID]bool
+}
+
+func (m *mockPeerBlocker) BlockPeer(p peer.ID) error {
+	m.blocked[p] = true
+	return nil
+}
+
+func (m *mockPeerBlocker) UnblockPeer(p peer.ID) error {
+	delete(m.blocked, p)
+	return nil
+}
+
+func newTestPeerScorer(t *testing.T, cfg PeerScoringConfig) (*PeerScorer, *mockPeerBlocker, *testutils.RecordingMetrics, *[]peer.ID, *time.Time) {
+	blocker := &mockPeerBlocker{blocked: make(map[peer.ID]bool)}
+	m := &testutils.RecordingMetrics{}
+	var closed []peer.ID
+	closePeer := func(id peer.ID) error {
+		closed = append(closed, id)
+		return nil
+	}
+	s := NewPeerScorer(testlog.Logger(t, log.LvlError), cfg, blocker, closePeer, m)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+	return s, blocker, m, &closed, &now
+}
+
+func TestPeerScorerBan(t *testing.T) {
+	s, blocker, m, closed, now := newTestPeerScorer(t, PeerScoringConfig{BanThreshold: -40, BanDuration: time.Hour})
+	a, b := peer.ID("a"), peer.ID("b")
+
+	s.Penalize(a, PenaltyInvalidPayload)
+	s.Penalize(b, PenaltySlowResponse)
+	require.Equal(t, -20.0, s.Score(a))
+	require.Equal(t, -5.0, s.Score(b))
+	require.Empty(t, blocker.blocked)
+
+	// reaching the threshold bans the peer, and disconnects it
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.True(t, blocker.blocked[a])
+	require.Equal(t, []peer.ID{a}, *closed)
+	require.Equal(t, map[peer.ID]time.Time{a: now.Add(time.Hour)}, s.Bans())
+	require.Equal(t, 0.0, s.Score(a), "score is reset by the ban")
+	m.RequireCount(t, "RecordPeerPenalty", 3)
+	require.Equal(t, 1, m.Records("SetBannedPeers")[0].Value)
+
+	// penalties of banned peers do not extend the ban
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.Equal(t, map[peer.ID]time.Time{a: now.Add(time.Hour)}, s.Bans())
+
+	// the ban expires
+	*now = now.Add(time.Hour - time.Second)
+	s.UnbanExpired()
+	require.True(t, blocker.blocked[a])
+	*now = now.Add(time.Second)
+	s.UnbanExpired()
+	require.False(t, blocker.blocked[a])
+	require.Empty(t, s.Bans())
+	records := m.Records("SetBannedPeers")
+	require.Equal(t, 0, records[len(records)-1].Value)
+}
+
+func TestPeerScorerDecay(t *testing.T) {
+	s, blocker, _, _, now := newTestPeerScorer(t, PeerScoringConfig{BanThreshold: -40, BanDuration: time.Hour, DecayHalfLife: time.Minute})
+	a := peer.ID("a")
+
+	s.Penalize(a, PenaltyInvalidPayload)
+	*now = now.Add(time.Minute)
+	require.InDelta(t, -10.0, s.Score(a), 1e-9)
+
+	// the decayed penalty does not add up to the threshold
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.InDelta(t, -30.0, s.Score(a), 1e-9)
+	require.Empty(t, blocker.blocked)
+
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.True(t, blocker.blocked[a])
+}
+
+func TestPeerScorerNoBans(t *testing.T) {
+	s, blocker, _, _, _ := newTestPeerScorer(t, PeerScoringConfig{})
+	a := peer.ID("a")
+	for i := 0; i < 100; i++ {
+		s.Penalize(a, PenaltyInvalidPayload)
+	}
+	require.Equal(t, -2000.0, s.Score(a))
+	require.Empty(t, blocker.blocked)
+}
+
+func TestPeerScorerClearBan(t *testing.T) {
+	s, blocker, _, _, now := newTestPeerScorer(t, PeerScoringConfig{BanThreshold: -20, BanDuration: time.Hour})
+	a := peer.ID("a")
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.True(t, blocker.blocked[a])
+
+	// a manual block does not expire
+	s.ClearBan(a)
+	*now = now.Add(2 * time.Hour)
+	s.UnbanExpired()
+	require.True(t, blocker.blocked[a])
+}
+
+func TestScoreValidationResult(t *testing.T) {
+	s, _, _, _, _ := newTestPeerScorer(t, PeerScoringConfig{})
+	self, a := peer.ID("self"), peer.ID("a")
+	result := pubsub.ValidationReject
+	val := scoreValidationResult(self, s, func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		return result
+	})
+
+	val(context.Background(), a, nil)
+	require.Equal(t, PenaltyInvalidPayload.Score, s.Score(a))
+	val(context.Background(), self, nil)
+	require.Equal(t, 0.0, s.Score(self), "own messages are not penalized")
+
+	result = pubsub.ValidationIgnore
+	val(context.Background(), a, nil)
+	result = pubsub.ValidationAccept
+	val(context.Background(), a, nil)
+	require.Equal(t, PenaltyInvalidPayload.Score, s.Score(a), "only rejected messages are penalized")
+
+	result = pubsub.ValidationReject
+	val = scoreValidationResult(self, s, func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		message.ValidatorData = unpenalizedRejection("too_old")
+		return result
+	})
+	val(context.Background(), a, &pubsub.Message{})
+	require.Equal(t, PenaltyInvalidPayload.Score, s.Score(a), "rejections that honest peers run into are not penalized")
+}
+
+func TestPeerScorerCloseWithoutLock(t *testing.T) {
+	s, _, _, _, _ := newTestPeerScorer(t, PeerScoringConfig{BanThreshold: -20, BanDuration: time.Hour})
+	a := peer.ID("a")
+	var score float64
+	s.close = func(id peer.ID) error {
+		// this deadlocks if the scorer is still locked
+		score = s.Score(id)
+		return nil
+	}
+	s.Penalize(a, PenaltyInvalidPayload)
+	require.Equal(t, 0.0, score)
+	require.Contains(t, s.Bans(), a)
+}
+
+func TestPeerScoringConfigCheck(t *testing.T) {
+	require.NoError(t, (&PeerScoringConfig{}).Check())
+	require.NoError(t, (&PeerScoringConfig{BanThreshold: -100, BanDuration: time.Hour}).Check())
+	require.Error(t, (&PeerScoringConfig{BanThreshold: 10, BanDuration: time.Hour}).Check())
+	require.Error(t, (&PeerScoringConfig{BanThreshold: -100}).Check())
+}
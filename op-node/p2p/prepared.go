@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/libp2p/go-libp2p-core/host"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 // Prepared provides a p2p host and discv5 service that is already set up.
@@ -20,6 +21,12 @@ type Prepared struct {
 	HostP2P   host.Host
 	LocalNode *enode.LocalNode
 	UDPv5     *discover.UDPv5
+
+	Scoring PeerScoringConfig
+
+	StaticPeers []ma.Multiaddr
+
+	EnableReqRespSync bool
 }
 
 var _ SetupP2P = (*Prepared)(nil)
@@ -38,6 +45,18 @@ func (p *Prepared) Check() error {
 	return nil
 }
 
+func (p *Prepared) PeerScoring() PeerScoringConfig {
+	return p.Scoring
+}
+
+func (p *Prepared) StaticPeerAddrs() []ma.Multiaddr {
+	return p.StaticPeers
+}
+
+func (p *Prepared) ReqRespSyncEnabled() bool {
+	return p.EnableReqRespSync
+}
+
 // Host creates a libp2p host service. Returns nil, nil if p2p is disabled.
 func (p *Prepared) Host(log log.Logger) (host.Host, error) {
 	return p.HostP2P, nil
@@ -19,7 +19,7 @@ type PeerInfo struct {
 	Addresses       []string `json:"addresses"` // multi-addresses. may be mix of LAN / docker / external IPs. All of them are communicated.
 	Protocols       []string `json:"protocols"` // negotiated protocols list
 	//GossipScore float64
-	//PeerScore float64
+	PeerScore     float64               `json:"peerScore"`     // application-level score, lowered by penalties for misbehavior
 	Connectedness network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
 	Direction     network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
 	Protected     bool                  `json:"protected"`     // Protected peers do not get
@@ -35,6 +35,8 @@ type PeerDump struct {
 	BannedPeers    []peer.ID            `json:"bannedPeers"`
 	BannedIPS      []net.IP             `json:"bannedIPS"`
 	BannedSubnets  []*net.IPNet         `json:"bannedSubnets"`
+	// BanExpiries is the expiry of the temporary bans of misbehaving peers, by peer ID
+	BanExpiries map[string]time.Time `json:"banExpiries"`
 }
 
 type API interface {
@@ -55,4 +57,8 @@ type API interface {
 	UnprotectPeer(ctx context.Context, p peer.ID) error
 	ConnectPeer(ctx context.Context, addr string) error
 	DisconnectPeer(ctx context.Context, id peer.ID) error
+	AddStaticPeer(ctx context.Context, addr string) (peer.ID, error)
+	RemoveStaticPeer(ctx context.Context, id peer.ID) error
+	ListStaticPeers(ctx context.Context) ([]StaticPeerInfo, error)
+	AddBootnode(ctx context.Context, enr string) error
 }
@@ -106,3 +106,23 @@ func (c *Client) ConnectPeer(ctx context.Context, addr string) error {
 func (c *Client) DisconnectPeer(ctx context.Context, id peer.ID) error {
 	return c.c.CallContext(ctx, nil, prefixRPC("disconnectPeer"), id)
 }
+
+func (c *Client) AddStaticPeer(ctx context.Context, addr string) (peer.ID, error) {
+	var out peer.ID
+	err := c.c.CallContext(ctx, &out, prefixRPC("addStaticPeer"), addr)
+	return out, err
+}
+
+func (c *Client) RemoveStaticPeer(ctx context.Context, id peer.ID) error {
+	return c.c.CallContext(ctx, nil, prefixRPC("removeStaticPeer"), id)
+}
+
+func (c *Client) ListStaticPeers(ctx context.Context) ([]StaticPeerInfo, error) {
+	var out []StaticPeerInfo
+	err := c.c.CallContext(ctx, &out, prefixRPC("listStaticPeers"))
+	return out, err
+}
+
+func (c *Client) AddBootnode(ctx context.Context, enr string) error {
+	return c.c.CallContext(ctx, nil, prefixRPC("addBootnode"), enr)
+}
@@ -32,6 +32,7 @@ var (
 	DisabledDiscovery   = errors.New("discovery disabled")
 	NoConnectionManager = errors.New("no connection manager")
 	NoConnectionGater   = errors.New("no connection gater")
+	NoStaticPeers       = errors.New("no static peers")
 )
 
 type Node interface {
@@ -49,6 +50,12 @@ type Node interface {
 	ConnectionGater() ConnectionGater
 	// ConnectionManager returns the connection manager, to protect peers with, may be nil
 	ConnectionManager() connmgr.ConnManager
+	// PeerScorer returns the application-level peer scores, may be nil
+	PeerScorer() *PeerScorer
+	// StaticPeers returns the static peers, to maintain connections to, may be nil
+	StaticPeers() *StaticPeers
+	// AddBootnode adds a node to discover peers from, returns DisabledDiscovery if discovery is disabled
+	AddBootnode(node *enode.Node) error
 }
 
 type APIBackend struct {
@@ -68,7 +75,7 @@ func NewP2PAPIBackend(node Node, log log.Logger, m *metrics.Metrics) *APIBackend
 }
 
 func (s *APIBackend) Self(ctx context.Context) (*PeerInfo, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_self")
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_self")
 	defer recordDur()
 	h := s.node.Host()
 	nw := h.Network()
@@ -153,7 +160,7 @@ func dumpPeer(id peer.ID, nw network.Network, pstore peerstore.Peerstore, connMg
 
 // Peers lists information of peers. Optionally filter to only retrieve connected peers.
 func (s *APIBackend) Peers(ctx context.Context, connected bool) (*PeerDump, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_peers")
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_peers")
 	defer recordDur()
 	h := s.node.Host()
 	nw := h.Network()
@@ -184,6 +191,15 @@ func (s *APIBackend) Peers(ctx context.Context, connected bool) (*PeerDump, erro
 			p.GossipBlocks = true
 		}
 	}
+	if scorer := s.node.PeerScorer(); scorer != nil {
+		for _, p := range dump.Peers {
+			p.PeerScore = scorer.Score(p.PeerID)
+		}
+		dump.BanExpiries = make(map[string]time.Time)
+		for id, expiry := range scorer.Bans() {
+			dump.BanExpiries[id.String()] = expiry
+		}
+	}
 	if gater := s.node.ConnectionGater(); gater != nil {
 		dump.BannedPeers = gater.ListBlockedPeers()
 		dump.BannedSubnets = gater.ListBlockedSubnets()
@@ -200,8 +216,8 @@ type PeerStats struct {
 	Known       uint `json:"known"`
 }
 
-func (s *APIBackend) PeerStats(_ context.Context) (*PeerStats, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_peerStats")
+func (s *APIBackend) PeerStats(ctx context.Context) (*PeerStats, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_peerStats")
 	defer recordDur()
 	h := s.node.Host()
 	nw := h.Network()
@@ -223,8 +239,8 @@ func (s *APIBackend) PeerStats(_ context.Context) (*PeerStats, error) {
 	return stats, nil
 }
 
-func (s *APIBackend) DiscoveryTable(_ context.Context) ([]*enode.Node, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_discoveryTable")
+func (s *APIBackend) DiscoveryTable(ctx context.Context) ([]*enode.Node, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_discoveryTable")
 	defer recordDur()
 	if dv5 := s.node.Dv5Udp(); dv5 != nil {
 		return dv5.AllNodes(), nil
@@ -233,28 +249,35 @@ func (s *APIBackend) DiscoveryTable(_ context.Context) ([]*enode.Node, error) {
 	}
 }
 
-func (s *APIBackend) BlockPeer(_ context.Context, p peer.ID) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_blockPeer")
+func (s *APIBackend) BlockPeer(ctx context.Context, p peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_blockPeer")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
 	} else {
+		// a manual block is permanent, and does not expire like an automatic ban
+		if scorer := s.node.PeerScorer(); scorer != nil {
+			scorer.ClearBan(p)
+		}
 		return gater.BlockPeer(p)
 	}
 }
 
-func (s *APIBackend) UnblockPeer(_ context.Context, p peer.ID) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_unblockPeer")
+func (s *APIBackend) UnblockPeer(ctx context.Context, p peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_unblockPeer")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
 	} else {
+		if scorer := s.node.PeerScorer(); scorer != nil {
+			scorer.ClearBan(p)
+		}
 		return gater.UnblockPeer(p)
 	}
 }
 
-func (s *APIBackend) ListBlockedPeers(_ context.Context) ([]peer.ID, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_listBlockedPeers")
+func (s *APIBackend) ListBlockedPeers(ctx context.Context) ([]peer.ID, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_listBlockedPeers")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return nil, NoConnectionGater
@@ -265,8 +288,8 @@ func (s *APIBackend) ListBlockedPeers(_ context.Context) ([]peer.ID, error) {
 
 // BlockAddr adds an IP address to the set of blocked addresses.
 // Note: active connections to the IP address are not automatically closed.
-func (s *APIBackend) BlockAddr(_ context.Context, ip net.IP) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_blockAddr")
+func (s *APIBackend) BlockAddr(ctx context.Context, ip net.IP) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_blockAddr")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
@@ -275,8 +298,8 @@ func (s *APIBackend) BlockAddr(_ context.Context, ip net.IP) error {
 	}
 }
 
-func (s *APIBackend) UnblockAddr(_ context.Context, ip net.IP) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_unblockAddr")
+func (s *APIBackend) UnblockAddr(ctx context.Context, ip net.IP) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_unblockAddr")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
@@ -285,8 +308,8 @@ func (s *APIBackend) UnblockAddr(_ context.Context, ip net.IP) error {
 	}
 }
 
-func (s *APIBackend) ListBlockedAddrs(_ context.Context) ([]net.IP, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_listBlockedAddrs")
+func (s *APIBackend) ListBlockedAddrs(ctx context.Context) ([]net.IP, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_listBlockedAddrs")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return nil, NoConnectionGater
@@ -297,8 +320,8 @@ func (s *APIBackend) ListBlockedAddrs(_ context.Context) ([]net.IP, error) {
 
 // BlockSubnet adds an IP subnet to the set of blocked addresses.
 // Note: active connections to the IP subnet are not automatically closed.
-func (s *APIBackend) BlockSubnet(_ context.Context, ipnet *net.IPNet) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_blockSubnet")
+func (s *APIBackend) BlockSubnet(ctx context.Context, ipnet *net.IPNet) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_blockSubnet")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
@@ -307,8 +330,8 @@ func (s *APIBackend) BlockSubnet(_ context.Context, ipnet *net.IPNet) error {
 	}
 }
 
-func (s *APIBackend) UnblockSubnet(_ context.Context, ipnet *net.IPNet) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_unblockSubnet")
+func (s *APIBackend) UnblockSubnet(ctx context.Context, ipnet *net.IPNet) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_unblockSubnet")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return NoConnectionGater
@@ -317,8 +340,8 @@ func (s *APIBackend) UnblockSubnet(_ context.Context, ipnet *net.IPNet) error {
 	}
 }
 
-func (s *APIBackend) ListBlockedSubnets(_ context.Context) ([]*net.IPNet, error) {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_listBlockedSubnets")
+func (s *APIBackend) ListBlockedSubnets(ctx context.Context) ([]*net.IPNet, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_listBlockedSubnets")
 	defer recordDur()
 	if gater := s.node.ConnectionGater(); gater == nil {
 		return nil, NoConnectionGater
@@ -327,8 +350,8 @@ func (s *APIBackend) ListBlockedSubnets(_ context.Context) ([]*net.IPNet, error)
 	}
 }
 
-func (s *APIBackend) ProtectPeer(_ context.Context, p peer.ID) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_protectPeer")
+func (s *APIBackend) ProtectPeer(ctx context.Context, p peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_protectPeer")
 	defer recordDur()
 	if manager := s.node.ConnectionManager(); manager == nil {
 		return NoConnectionManager
@@ -338,8 +361,8 @@ func (s *APIBackend) ProtectPeer(_ context.Context, p peer.ID) error {
 	}
 }
 
-func (s *APIBackend) UnprotectPeer(_ context.Context, p peer.ID) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_unprotectPeer")
+func (s *APIBackend) UnprotectPeer(ctx context.Context, p peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_unprotectPeer")
 	defer recordDur()
 	if manager := s.node.ConnectionManager(); manager == nil {
 		return NoConnectionManager
@@ -351,7 +374,7 @@ func (s *APIBackend) UnprotectPeer(_ context.Context, p peer.ID) error {
 
 // ConnectPeer connects to a given peer address, and wait for protocol negotiation & identification of the peer
 func (s *APIBackend) ConnectPeer(ctx context.Context, addr string) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_connectPeer")
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_connectPeer")
 	defer recordDur()
 	h := s.node.Host()
 	addrInfo, err := peer.AddrInfoFromString(addr)
@@ -364,8 +387,58 @@ func (s *APIBackend) ConnectPeer(ctx context.Context, addr string) error {
 	return h.Connect(ctx, *addrInfo)
 }
 
-func (s *APIBackend) DisconnectPeer(_ context.Context, id peer.ID) error {
-	recordDur := s.m.RecordRPCServerRequest("opp2p_disconnectPeer")
+func (s *APIBackend) DisconnectPeer(ctx context.Context, id peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_disconnectPeer")
 	defer recordDur()
 	return s.node.Host().Network().ClosePeer(id)
 }
+
+// AddStaticPeer adds a static peer, by multiaddr or ENR, to connect to and maintain a connection with.
+func (s *APIBackend) AddStaticPeer(ctx context.Context, addr string) (peer.ID, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_addStaticPeer")
+	defer recordDur()
+	statics := s.node.StaticPeers()
+	if statics == nil {
+		return "", NoStaticPeers
+	}
+	a, err := ParsePeerAddr(addr)
+	if err != nil {
+		return "", fmt.Errorf("bad peer address: %v", err)
+	}
+	return statics.Add(a)
+}
+
+// RemoveStaticPeer stops maintaining the connection with a static peer. The peer is not disconnected.
+func (s *APIBackend) RemoveStaticPeer(ctx context.Context, id peer.ID) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_removeStaticPeer")
+	defer recordDur()
+	statics := s.node.StaticPeers()
+	if statics == nil {
+		return NoStaticPeers
+	}
+	if !statics.Remove(id) {
+		return fmt.Errorf("not a static peer: %s", id)
+	}
+	return nil
+}
+
+func (s *APIBackend) ListStaticPeers(ctx context.Context) ([]StaticPeerInfo, error) {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_listStaticPeers")
+	defer recordDur()
+	statics := s.node.StaticPeers()
+	if statics == nil {
+		return nil, NoStaticPeers
+	}
+	return statics.List(), nil
+}
+
+// AddBootnode adds a node, by ENR, to discover peers from.
+func (s *APIBackend) AddBootnode(ctx context.Context, enr string) error {
+	recordDur := s.m.RecordRPCServerRequest(ctx, "opp2p_addBootnode")
+	defer recordDur()
+	node, err := enode.Parse(enode.ValidSchemes, enr)
+	if err != nil {
+		return fmt.Errorf("bad node record: %v", err)
+	}
+	return s.node.AddBootnode(node)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli"
@@ -23,21 +24,32 @@ type Signer interface {
 }
 
 func SigningHash(domain [32]byte, chainID *big.Int, payloadBytes []byte) common.Hash {
-	var msgInput [32 + 32 + 32]byte
-	// domain: first 32 bytes
-	copy(msgInput[:32], domain[:])
-	// chain_id: second 32 bytes
-	chainID.FillBytes(msgInput[32:64])
-	// payload_hash: third 32 bytes, hash of encoded payload
-	copy(msgInput[32:], crypto.Keccak256(payloadBytes))
-
-	return crypto.Keccak256Hash(msgInput[:])
+	return signer.PayloadSigningHash(domain, chainID, payloadBytes)
 }
 
 func BlockSigningHash(cfg *rollup.Config, payloadBytes []byte) common.Hash {
 	return SigningHash(SigningDomainBlocksV1, cfg.L2ChainID, payloadBytes)
 }
 
+var (
+	ErrInvalidBlockSignature = errors.New("invalid block signature")
+	ErrUnexpectedBlockSigner = errors.New("unexpected block signer")
+)
+
+// VerifyBlockSignature verifies that the SSZ encoded payload is signed by the P2P sequencer address of the rollup.
+func VerifyBlockSignature(cfg *rollup.Config, payloadBytes []byte, signature []byte) error {
+	signingHash := BlockSigningHash(cfg, payloadBytes)
+	pub, err := crypto.SigToPub(signingHash[:], signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBlockSignature, err)
+	}
+	// TODO: in the future we can support multiple valid p2p addresses.
+	if addr := crypto.PubkeyToAddress(*pub); addr != cfg.P2PSequencerAddress {
+		return fmt.Errorf("%w: signed by %s, expected %s", ErrUnexpectedBlockSigner, addr, cfg.P2PSequencerAddress)
+	}
+	return nil
+}
+
 // LocalSigner is suitable for testing
 type LocalSigner struct {
 	priv *ecdsa.PrivateKey
@@ -64,6 +76,24 @@ func (s *LocalSigner) Close() error {
 	return nil
 }
 
+// blockSigner signs p2p application messages with a sequencer key signer.
+type blockSigner struct {
+	s signer.Signer
+}
+
+// NewBlockSigner creates a Signer of p2p application messages, that signs with the given sequencer key signer.
+func NewBlockSigner(s signer.Signer) Signer {
+	return &blockSigner{s: s}
+}
+
+func (b *blockSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	return b.s.SignBlockPayload(ctx, domain, chainID, encodedMsg)
+}
+
+func (b *blockSigner) Close() error {
+	return b.s.Close()
+}
+
 type PreparedSigner struct {
 	Signer
 }
@@ -72,8 +102,18 @@ func (p *PreparedSigner) SetupSigner(ctx context.Context) (Signer, error) {
 	return p.Signer, nil
 }
 
-// TODO: implement remote signer setup (config to authenticated endpoint)
-// and remote signer itself (e.g. a open http client to make signing requests)
+// RemoteSignerSetup sets up a signer of a remote signer service, see signer.RemoteSigner.
+type RemoteSignerSetup struct {
+	Endpoint string
+}
+
+func (r *RemoteSignerSetup) SetupSigner(ctx context.Context) (Signer, error) {
+	s, err := signer.DialRemoteSigner(ctx, r.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlockSigner(s), nil
+}
 
 type SignerSetup interface {
 	SetupSigner(ctx context.Context) (Signer, error)
@@ -82,6 +122,10 @@ type SignerSetup interface {
 // LoadSignerSetup loads a configuration for a Signer to be set up later
 func LoadSignerSetup(ctx *cli.Context) (SignerSetup, error) {
 	keyFile := ctx.GlobalString(flags.SequencerP2PKeyFlag.Name)
+	endpoint := ctx.GlobalString(flags.SequencerP2PSignerEndpointFlag.Name)
+	if keyFile != "" && endpoint != "" {
+		return nil, errors.New("cannot use both a sequencer key file and a remote signer")
+	}
 	if keyFile != "" {
 		// Mnemonics are bad because they leak *all* keys when they leak.
 		// Unencrypted keys from file are bad because they are easy to leak (and we are not checking file permissions).
@@ -94,7 +138,9 @@ func LoadSignerSetup(ctx *cli.Context) (SignerSetup, error) {
 		return &PreparedSigner{Signer: NewLocalSigner(priv)}, nil
 	}
 
-	// TODO: create remote signer
+	if endpoint != "" {
+		return &RemoteSignerSetup{Endpoint: endpoint}, nil
+	}
 
 	return nil, nil
 }
@@ -0,0 +1,47 @@
+package p2p
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+func TestVerifyBlockSignature(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{
+		L2ChainID:           big.NewInt(100),
+		P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey),
+	}
+	payloadBytes := []byte("ssz encoded payload")
+	signer := NewLocalSigner(priv)
+	sig, err := signer.Sign(context.Background(), SigningDomainBlocksV1, cfg.L2ChainID, payloadBytes)
+	require.NoError(t, err)
+	require.NoError(t, VerifyBlockSignature(cfg, payloadBytes, sig[:]))
+
+	t.Run("other payload", func(t *testing.T) {
+		require.ErrorIs(t, VerifyBlockSignature(cfg, []byte("other payload"), sig[:]), ErrUnexpectedBlockSigner)
+	})
+	t.Run("rotated signer", func(t *testing.T) {
+		next, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		rotated := *cfg
+		rotated.P2PSequencerAddress = crypto.PubkeyToAddress(next.PublicKey)
+		require.ErrorIs(t, VerifyBlockSignature(&rotated, payloadBytes, sig[:]), ErrUnexpectedBlockSigner,
+			"payloads of the previous signer are rejected after the config update")
+		nextSig, err := NewLocalSigner(next).Sign(context.Background(), SigningDomainBlocksV1, cfg.L2ChainID, payloadBytes)
+		require.NoError(t, err)
+		require.NoError(t, VerifyBlockSignature(&rotated, payloadBytes, nextSig[:]))
+		require.ErrorIs(t, VerifyBlockSignature(cfg, payloadBytes, nextSig[:]), ErrUnexpectedBlockSigner,
+			"payloads of the next signer are rejected until the config update")
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		require.ErrorIs(t, VerifyBlockSignature(cfg, payloadBytes, make([]byte, 65)), ErrInvalidBlockSignature)
+		require.ErrorIs(t, VerifyBlockSignature(cfg, payloadBytes, sig[:64]), ErrInvalidBlockSignature)
+	})
+}
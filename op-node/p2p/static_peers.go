@@ -0,0 +1,239 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	staticPeerDialInterval = time.Second * 5
+	staticPeerDialTimeout  = time.Second * 30
+	staticPeerBackoffMin   = time.Second * 5
+	staticPeerBackoffMax   = time.Minute * 5
+	// static peer addresses do not expire, the peers are kept until removed
+	staticPeerAddrTTL = time.Hour * 24 * 365
+	// the connection manager tag to protect static peers with
+	staticPeerTag = "static"
+)
+
+// Sources of dialed peers, used as metric label
+const (
+	DialStatic    = "static"
+	DialDiscovery = "discovery"
+)
+
+// DialMetricer tracks the results of dialing peers.
+type DialMetricer interface {
+	RecordPeerDial(source string, success bool)
+}
+
+// ParsePeerAddr parses the address of a peer, either a multiaddr with a p2p component,
+// or a base64-format ENR (or enode URL) of a node that has a secp256k1 key and a TCP port.
+func ParsePeerAddr(addr string) (ma.Multiaddr, error) {
+	if strings.HasPrefix(addr, "enr:") || strings.HasPrefix(addr, "enode:") {
+		node, err := enode.Parse(enode.ValidSchemes, addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node record: %w", err)
+		}
+		info, _, err := enrToAddrInfo(node)
+		if err != nil {
+			return nil, fmt.Errorf("node record has no usable peer address: %w", err)
+		}
+		addrs, err := peer.AddrInfoToP2pAddrs(info)
+		if err != nil {
+			return nil, err
+		}
+		return addrs[0], nil
+	}
+	return ma.NewMultiaddr(addr)
+}
+
+// StaticPeerInfo is the state of a static peer, as exposed in the RPC API.
+type StaticPeerInfo struct {
+	PeerID    peer.ID   `json:"peerID"`
+	Addresses []string  `json:"addresses"`
+	Connected bool      `json:"connected"`
+	Failures  int       `json:"failures"` // consecutive failed dials
+	NextDial  time.Time `json:"nextDial"` // zero if connected
+}
+
+type staticPeer struct {
+	info     peer.AddrInfo
+	failures int
+	nextDial time.Time
+}
+
+// StaticPeers maintains the connections to the static peers, which are protected from pruning by the connection manager.
+// A static peer is redialed when disconnected, with an exponential backoff on failed dials.
+// It is safe for concurrent use.
+type StaticPeers struct {
+	log     log.Logger
+	h       host.Host
+	connMgr connmgr.ConnManager // may be nil, static peers are not protected then
+	metrics DialMetricer
+	now     func() time.Time
+
+	mu    sync.Mutex
+	peers map[peer.ID]*staticPeer
+}
+
+func NewStaticPeers(log log.Logger, h host.Host, connMgr connmgr.ConnManager, m DialMetricer) *StaticPeers {
+	return &StaticPeers{
+		log:     log,
+		h:       h,
+		connMgr: connMgr,
+		metrics: m,
+		now:     time.Now,
+		peers:   make(map[peer.ID]*staticPeer),
+	}
+}
+
+// Add adds a static peer, or updates the addresses of an existing static peer. It is dialed on the next dial round.
+func (s *StaticPeers) Add(addr ma.Multiaddr) (peer.ID, error) {
+	info, err := peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return "", fmt.Errorf("bad peer address: %w", err)
+	}
+	if info.ID == s.h.ID() {
+		return "", fmt.Errorf("cannot add self as static peer: %s", info.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.h.Peerstore().AddAddrs(info.ID, info.Addrs, staticPeerAddrTTL)
+	if s.connMgr != nil {
+		// We tag it with "static" so other protects/unprotects with different tags don't affect this protection.
+		s.connMgr.Protect(info.ID, staticPeerTag)
+	}
+	if p, ok := s.peers[info.ID]; ok {
+		p.info = *info
+		p.nextDial = time.Time{}
+	} else {
+		s.peers[info.ID] = &staticPeer{info: *info}
+	}
+	s.log.Info("Added static peer", "peer", info.ID, "addrs", info.Addrs)
+	return info.ID, nil
+}
+
+// Remove stops maintaining the connection to the static peer, and unprotects it.
+// The connection is not closed, the connection manager may prune it like any other connection.
+// Returns false if the peer is not a static peer.
+func (s *StaticPeers) Remove(id peer.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.peers[id]; !ok {
+		return false
+	}
+	delete(s.peers, id)
+	if s.connMgr != nil {
+		s.connMgr.Unprotect(id, staticPeerTag)
+	}
+	s.log.Info("Removed static peer", "peer", id)
+	return true
+}
+
+// List returns the state of the static peers.
+func (s *StaticPeers) List() []StaticPeerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]StaticPeerInfo, 0, len(s.peers))
+	for id, p := range s.peers {
+		info := StaticPeerInfo{
+			PeerID:    id,
+			Connected: s.h.Network().Connectedness(id) == network.Connected,
+			Failures:  p.failures,
+			NextDial:  p.nextDial,
+		}
+		for _, addr := range p.info.Addrs {
+			info.Addresses = append(info.Addresses, addr.String())
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// backoff returns the time to wait before dialing a peer again, after the given number of consecutive failed dials.
+func backoff(failures int) time.Duration {
+	d := staticPeerBackoffMin
+	for i := 1; i < failures && d < staticPeerBackoffMax; i++ {
+		d *= 2
+	}
+	if d > staticPeerBackoffMax {
+		d = staticPeerBackoffMax
+	}
+	return d
+}
+
+// DialDisconnected dials the static peers that are not connected, and of which the backoff has passed,
+// and waits for the dials to complete.
+func (s *StaticPeers) DialDisconnected(ctx context.Context) {
+	s.mu.Lock()
+	now := s.now()
+	var dials []peer.AddrInfo
+	for id, p := range s.peers {
+		if s.h.Network().Connectedness(id) == network.Connected {
+			p.failures = 0
+			p.nextDial = time.Time{}
+			continue
+		}
+		if now.Before(p.nextDial) {
+			continue
+		}
+		dials = append(dials, p.info)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, info := range dials {
+		wg.Add(1)
+		go func(info peer.AddrInfo) {
+			defer wg.Done()
+			s.log.Debug("Dialing static peer", "peer", info.ID, "addrs", info.Addrs)
+			ctx, cancel := context.WithTimeout(ctx, staticPeerDialTimeout)
+			err := s.h.Connect(ctx, info)
+			cancel()
+			s.metrics.RecordPeerDial(DialStatic, err == nil)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			p, ok := s.peers[info.ID]
+			if !ok { // removed while dialing
+				return
+			}
+			if err != nil {
+				p.failures += 1
+				p.nextDial = s.now().Add(backoff(p.failures))
+				s.log.Warn("Failed to dial static peer", "peer", info.ID, "addrs", info.Addrs, "failures", p.failures, "next_dial", p.nextDial, "err", err)
+				return
+			}
+			p.failures = 0
+			p.nextDial = time.Time{}
+			s.log.Info("Connected to static peer", "peer", info.ID)
+		}(info)
+	}
+	wg.Wait()
+}
+
+// Run dials the disconnected static peers immediately, and then every interval, until the context is done.
+func (s *StaticPeers) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.DialDisconnected(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type mockConnManager struct {
+	connmgr.NullConnMgr
+	protected map[peer.ID]string
+}
+
+func (m *mockConnManager) Protect(id peer.ID, tag string) {
+	m.protected[id] = tag
+}
+
+func (m *mockConnManager) Unprotect(id peer.ID, tag string) bool {
+	delete(m.protected, id)
+	return false
+}
+
+func TestStaticPeers(t *testing.T) {
+	mnet := mocknet.New()
+	defer mnet.Close()
+	hostA, err := mnet.GenPeer()
+	require.NoError(t, err)
+	hostB, err := mnet.GenPeer()
+	require.NoError(t, err)
+
+	connMgr := &mockConnManager{protected: make(map[peer.ID]string)}
+	m := &testutils.RecordingMetrics{}
+	s := NewStaticPeers(testlog.Logger(t, log.LvlError), hostA, connMgr, m)
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	addrs, err := peer.AddrInfoToP2pAddrs(&peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()})
+	require.NoError(t, err)
+	id, err := s.Add(addrs[0])
+	require.NoError(t, err)
+	require.Equal(t, hostB.ID(), id)
+	require.Equal(t, staticPeerTag, connMgr.protected[id])
+
+	selfAddrs, err := peer.AddrInfoToP2pAddrs(&peer.AddrInfo{ID: hostA.ID(), Addrs: hostA.Addrs()})
+	require.NoError(t, err)
+	_, err = s.Add(selfAddrs[0])
+	require.Error(t, err, "cannot add self")
+
+	// the hosts are not linked, so the dial fails
+	s.DialDisconnected(context.Background())
+	m.RequireCount(t, "RecordPeerDial", 1)
+	require.Equal(t, false, m.Records("RecordPeerDial")[0].Value)
+	list := s.List()
+	require.Len(t, list, 1)
+	require.False(t, list[0].Connected)
+	require.Equal(t, 1, list[0].Failures)
+	require.Equal(t, now.Add(staticPeerBackoffMin), list[0].NextDial)
+
+	// no dial until the backoff has passed
+	_, err = mnet.LinkPeers(hostA.ID(), hostB.ID())
+	require.NoError(t, err)
+	s.DialDisconnected(context.Background())
+	m.RequireCount(t, "RecordPeerDial", 1)
+
+	now = now.Add(staticPeerBackoffMin)
+	s.DialDisconnected(context.Background())
+	m.RequireCount(t, "RecordPeerDial", 2)
+	require.Equal(t, true, m.Records("RecordPeerDial")[1].Value)
+	require.Equal(t, network.Connected, hostA.Network().Connectedness(hostB.ID()))
+	list = s.List()
+	require.True(t, list[0].Connected)
+	require.Equal(t, 0, list[0].Failures)
+
+	// connected peers are not dialed again
+	s.DialDisconnected(context.Background())
+	m.RequireCount(t, "RecordPeerDial", 2)
+
+	require.True(t, s.Remove(id))
+	require.False(t, s.Remove(id))
+	require.Empty(t, s.List())
+	require.Empty(t, connMgr.protected)
+}
+
+func TestStaticPeerBackoff(t *testing.T) {
+	require.Equal(t, staticPeerBackoffMin, backoff(1))
+	require.Equal(t, 2*staticPeerBackoffMin, backoff(2))
+	require.Equal(t, 4*staticPeerBackoffMin, backoff(3))
+	require.Equal(t, staticPeerBackoffMax, backoff(10))
+	require.Equal(t, staticPeerBackoffMax, backoff(1000))
+}
+
+func TestParsePeerAddr(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	db, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer db.Close()
+	localNode := enode.NewLocalNode(db, priv)
+	localNode.SetStaticIP([]byte{10, 0, 0, 1})
+	localNode.Set(enr.TCP(9222))
+
+	expected, _, err := enrToAddrInfo(localNode.Node())
+	require.NoError(t, err)
+
+	addr, err := ParsePeerAddr(localNode.Node().String())
+	require.NoError(t, err)
+	info, err := peer.AddrInfoFromP2pAddr(addr)
+	require.NoError(t, err)
+	require.Equal(t, expected, info)
+	require.Equal(t, "/ip4/10.0.0.1/tcp/9222/p2p/"+expected.ID.String(), addr.String())
+
+	// multiaddrs are parsed as is
+	addr, err = ParsePeerAddr("/ip4/10.0.0.1/tcp/9222/p2p/" + expected.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, "/ip4/10.0.0.1/tcp/9222/p2p/"+expected.ID.String(), addr.String())
+
+	_, err = ParsePeerAddr("enr:invalid")
+	require.Error(t, err)
+	_, err = ParsePeerAddr("not a multiaddr")
+	require.Error(t, err)
+}
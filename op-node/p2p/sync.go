@@ -0,0 +1,335 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// The req-resp sync protocol lets a node request recent unsafe payloads by block number from its peers,
+// to fill the gaps in the payloads it received through gossip, rather than waiting to derive them from L1.
+//
+// A request is a range of block numbers: the start (inclusive) and the end (exclusive),
+// as 8-byte little-endian numbers, of at most MaxRangeRequest blocks.
+// The response is a chunk per payload, in ascending order: a 1-byte result code and,
+// if the result is a success, the 4-byte little-endian size and the SSZ encoding of the payload.
+// The response ends after the last payload of the range, or after the first result that is not a success.
+
+// MaxRangeRequest is the maximum number of payloads requested at once.
+const MaxRangeRequest = 64
+
+// Results of a response chunk.
+const (
+	ResultSuccess        byte = 0
+	ResultNotFound       byte = 1
+	ResultInvalidRequest byte = 2
+	ResultServerError    byte = 3
+)
+
+const (
+	// timeout to read the request, and to write the payloads of a request
+	serverReadRequestTimeout = time.Second * 10
+	serverWriteTimeout       = time.Second * 30
+	// timeout to open a stream, write the request, and read the full response
+	clientRequestTimeout = time.Second * 30
+)
+
+// PayloadByNumberProtocolID returns the protocol ID of the req-resp sync protocol of the L2 chain.
+func PayloadByNumberProtocolID(l2ChainID *big.Int) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/opstack/req/payloads_by_number/%d/0", l2ChainID))
+}
+
+// L2Chain provides the payloads to serve to peers.
+type L2Chain interface {
+	PayloadByNumber(ctx context.Context, number uint64) (*eth.ExecutionPayload, error)
+}
+
+// SyncStream is a stream of the req-resp sync protocol, e.g. a libp2p stream.
+type SyncStream interface {
+	io.ReadWriteCloser
+	SetDeadline(t time.Time) error
+}
+
+// ReqRespServer serves the payload range requests of peers from the L2 chain.
+type ReqRespServer struct {
+	l2 L2Chain
+}
+
+func NewReqRespServer(l2 L2Chain) *ReqRespServer {
+	return &ReqRespServer{l2: l2}
+}
+
+// HandleSyncRequest reads a request from the stream, and writes the response.
+func (srv *ReqRespServer) HandleSyncRequest(ctx context.Context, stream io.ReadWriter) error {
+	var req [16]byte
+	if _, err := io.ReadFull(stream, req[:]); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	start, end := binary.LittleEndian.Uint64(req[:8]), binary.LittleEndian.Uint64(req[8:])
+	if end <= start || end-start > MaxRangeRequest {
+		_, _ = stream.Write([]byte{ResultInvalidRequest})
+		return fmt.Errorf("invalid range request: %d to %d", start, end)
+	}
+	var buf bytes.Buffer
+	for n := start; n < end; n++ {
+		payload, err := srv.l2.PayloadByNumber(ctx, n)
+		if errors.Is(err, ethereum.NotFound) {
+			_, err := stream.Write([]byte{ResultNotFound})
+			return err
+		} else if err != nil {
+			_, _ = stream.Write([]byte{ResultServerError})
+			return fmt.Errorf("failed to retrieve payload %d: %w", n, err)
+		}
+		buf.Reset()
+		buf.WriteByte(ResultSuccess)
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], payload.SizeSSZ())
+		buf.Write(size[:])
+		if _, err := payload.MarshalSSZ(&buf); err != nil {
+			_, _ = stream.Write([]byte{ResultServerError})
+			return fmt.Errorf("failed to encode payload %d: %w", n, err)
+		}
+		if _, err := stream.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write payload %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+// MakeStreamHandler serves the requests of peers on the streams of the req-resp sync protocol.
+func (srv *ReqRespServer) MakeStreamHandler(resourcesCtx context.Context, log log.Logger) network.StreamHandler {
+	return func(stream network.Stream) {
+		defer stream.Close()
+		ctx, cancel := context.WithTimeout(resourcesCtx, serverReadRequestTimeout+serverWriteTimeout)
+		defer cancel()
+		_ = stream.SetDeadline(time.Now().Add(serverReadRequestTimeout + serverWriteTimeout))
+		if err := srv.HandleSyncRequest(ctx, stream); err != nil {
+			log.Debug("Failed to serve sync request", "peer", stream.Conn().RemotePeer(), "err", err)
+		}
+	}
+}
+
+// requestPayloads writes the range request to the stream, and reads the payloads of the response.
+// Less payloads than requested are returned if the peer does not have all of them.
+func requestPayloads(stream io.ReadWriter, start, end uint64) ([]*eth.ExecutionPayload, error) {
+	var req [16]byte
+	binary.LittleEndian.PutUint64(req[:8], start)
+	binary.LittleEndian.PutUint64(req[8:], end)
+	if _, err := stream.Write(req[:]); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+	var payloads []*eth.ExecutionPayload
+	for n := start; n < end; n++ {
+		var header [5]byte
+		if _, err := io.ReadFull(stream, header[:1]); err != nil {
+			return nil, fmt.Errorf("failed to read result of payload %d: %w", n, err)
+		}
+		switch header[0] {
+		case ResultSuccess:
+		case ResultNotFound:
+			return payloads, nil
+		default:
+			return nil, fmt.Errorf("peer failed to serve payload %d, result: %d", n, header[0])
+		}
+		if _, err := io.ReadFull(stream, header[1:]); err != nil {
+			return nil, fmt.Errorf("failed to read size of payload %d: %w", n, err)
+		}
+		size := binary.LittleEndian.Uint32(header[1:])
+		if size > MaxGossipSize {
+			return nil, fmt.Errorf("%w: payload %d is too large: %d", errInvalidPayloads, n, size)
+		}
+		var payload eth.ExecutionPayload
+		if err := payload.UnmarshalSSZ(size, stream); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("failed to read payload %d: %w", n, err)
+			}
+			return nil, fmt.Errorf("%w: failed to decode payload %d: %v", errInvalidPayloads, n, err)
+		}
+		payloads = append(payloads, &payload)
+	}
+	return payloads, nil
+}
+
+var errInvalidPayloads = errors.New("invalid payloads")
+
+// verifyPayloads checks that the payloads are the valid blocks from start up to the parent of end,
+// by checking the block hashes, and that every payload is the parent of the next payload,
+// and the last payload is the parent of end.
+func verifyPayloads(payloads []*eth.ExecutionPayload, start uint64, end eth.L2BlockRef) error {
+	if uint64(len(payloads)) != end.Number-start {
+		return fmt.Errorf("%w: expected %d payloads, got %d", errInvalidPayloads, end.Number-start, len(payloads))
+	}
+	for i, payload := range payloads {
+		if uint64(payload.BlockNumber) != start+uint64(i) {
+			return fmt.Errorf("%w: expected payload %d, got %d", errInvalidPayloads, start+uint64(i), uint64(payload.BlockNumber))
+		}
+		if actual, ok := payload.CheckBlockHash(); !ok {
+			return fmt.Errorf("%w: payload %s has bad block hash, actual hash: %s", errInvalidPayloads, payload.ID(), actual)
+		}
+		if i > 0 && payload.ParentHash != payloads[i-1].BlockHash {
+			return fmt.Errorf("%w: payload %s does not build on payload %s", errInvalidPayloads, payload.ID(), payloads[i-1].ID())
+		}
+	}
+	if last := payloads[len(payloads)-1]; last.BlockHash != end.ParentHash {
+		return fmt.Errorf("%w: payload %s is not the parent of %s", errInvalidPayloads, last.ID(), end)
+	}
+	return nil
+}
+
+type rangeRequest struct {
+	start, end eth.L2BlockRef
+}
+
+type newSyncStreamFn func(ctx context.Context, id peer.ID) (SyncStream, error)
+
+type receivePayloadFn func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error
+
+// SyncClient requests missing unsafe payloads from peers with the req-resp sync protocol, one range at a time.
+// The payloads are verified to build up to the first queued unsafe payload, which was received through gossip,
+// before they are passed on like unsafe payloads from gossip.
+type SyncClient struct {
+	log            log.Logger
+	newStream      newSyncStreamFn
+	peers          func() []peer.ID
+	receivePayload receivePayloadFn
+	scorer         *PeerScorer // may be nil, to not penalize peers
+
+	requests       chan rangeRequest
+	nextPeer       int
+	requestTimeout time.Duration
+
+	resourcesCtx   context.Context
+	resourcesClose context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+func NewSyncClient(log log.Logger, newStream newSyncStreamFn, peers func() []peer.ID, rcv receivePayloadFn, scorer *PeerScorer) *SyncClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SyncClient{
+		log:            log,
+		newStream:      newStream,
+		peers:          peers,
+		receivePayload: rcv,
+		scorer:         scorer,
+		requests:       make(chan rangeRequest, 1),
+		requestTimeout: clientRequestTimeout,
+		resourcesCtx:   ctx,
+		resourcesClose: cancel,
+	}
+}
+
+func (s *SyncClient) Start() {
+	s.wg.Add(1)
+	go s.mainLoop()
+}
+
+func (s *SyncClient) Close() error {
+	s.resourcesClose()
+	s.wg.Wait()
+	return nil
+}
+
+// RequestL2Range schedules a request of the payloads between start and end, both exclusive.
+// Only the last MaxRangeRequest payloads before end are requested if the range is larger.
+// The request is dropped if another request is still pending.
+func (s *SyncClient) RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error {
+	if end.Number <= start.Number+1 {
+		return nil
+	}
+	select {
+	case s.requests <- rangeRequest{start: start, end: end}:
+	default:
+		s.log.Debug("Dropping range request, another request is pending", "start", start, "end", end)
+	}
+	return nil
+}
+
+func (s *SyncClient) mainLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case req := <-s.requests:
+			s.doRequest(s.resourcesCtx, req)
+		case <-s.resourcesCtx.Done():
+			return
+		}
+	}
+}
+
+// doRequest requests the range from the peers, one peer at a time, until a peer serves the full range.
+func (s *SyncClient) doRequest(ctx context.Context, req rangeRequest) {
+	start := req.start.Number + 1
+	if req.end.Number-start > MaxRangeRequest {
+		start = req.end.Number - MaxRangeRequest
+	}
+	peers := s.peers()
+	for i := range peers {
+		id := peers[(s.nextPeer+i)%len(peers)]
+		payloads, err := s.requestFromPeer(ctx, id, start, req.end)
+		if err != nil {
+			s.log.Debug("Failed to sync payloads from peer", "peer", id, "start", start, "end", req.end, "err", err)
+			continue
+		}
+		s.nextPeer += i + 1 // spread the requests over the peers
+		s.log.Info("Synced payloads from peer", "peer", id, "start", start, "end", req.end, "count", len(payloads))
+		for _, payload := range payloads {
+			if err := s.receivePayload(ctx, id, payload); err != nil {
+				s.log.Warn("Failed to process synced payload", "payload", payload.ID(), "err", err)
+				return
+			}
+		}
+		return
+	}
+	s.log.Debug("No peer served the payloads", "start", start, "end", req.end, "peers", len(peers))
+}
+
+func (s *SyncClient) requestFromPeer(ctx context.Context, id peer.ID, start uint64, end eth.L2BlockRef) ([]*eth.ExecutionPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+	stream, err := s.newStream(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+	_ = stream.SetDeadline(time.Now().Add(s.requestTimeout))
+	payloads, err := requestPayloads(stream, start, end.Number)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			s.penalize(id, PenaltySlowResponse)
+		} else if errors.Is(err, errInvalidPayloads) {
+			s.penalize(id, PenaltyInvalidPayload)
+		}
+		return nil, err
+	}
+	if uint64(len(payloads)) < end.Number-start {
+		return nil, fmt.Errorf("peer does not have all payloads, got %d of %d", len(payloads), end.Number-start)
+	}
+	if err := verifyPayloads(payloads, start, end); err != nil {
+		s.penalize(id, PenaltyInvalidPayload)
+		return nil, err
+	}
+	return payloads, nil
+}
+
+func (s *SyncClient) penalize(id peer.ID, p Penalty) {
+	if s.scorer != nil {
+		s.scorer.Penalize(id, p)
+	}
+}
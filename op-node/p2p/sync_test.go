@@ -0,0 +1,185 @@
+package p2p
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type mockL2Chain map[uint64]*eth.ExecutionPayload
+
+func (m mockL2Chain) PayloadByNumber(ctx context.Context, number uint64) (*eth.ExecutionPayload, error) {
+	if p, ok := m[number]; ok {
+		return p, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// makeChain creates a chain of payloads with valid block hashes, from block 0 up to and including block n.
+func makeChain(rng *rand.Rand, n uint64) mockL2Chain {
+	chain := make(mockL2Chain)
+	var parent common.Hash
+	for i := uint64(0); i <= n; i++ {
+		payload := &eth.ExecutionPayload{
+			ParentHash:   parent,
+			FeeRecipient: testutils.RandomAddress(rng),
+			StateRoot:    eth.Bytes32(testutils.RandomHash(rng)),
+			BlockNumber:  eth.Uint64Quantity(i),
+			GasLimit:     30_000_000,
+			Timestamp:    eth.Uint64Quantity(1000 + i*2),
+			ExtraData:    eth.BytesMax32{},
+			Transactions: []eth.Data{testutils.RandomData(rng, 100)},
+		}
+		payload.BlockHash, _ = payload.CheckBlockHash()
+		chain[i] = payload
+		parent = payload.BlockHash
+	}
+	return chain
+}
+
+func refOf(payload *eth.ExecutionPayload) eth.L2BlockRef {
+	return eth.L2BlockRef{Hash: payload.BlockHash, Number: uint64(payload.BlockNumber), ParentHash: payload.ParentHash}
+}
+
+// servePipe returns the client end of an in-memory stream, of which the other end is served by the server.
+func servePipe(t *testing.T, srv *ReqRespServer) SyncStream {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		_ = srv.HandleSyncRequest(context.Background(), server)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestReqRespServer(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := makeChain(rng, 20)
+	srv := NewReqRespServer(chain)
+
+	t.Run("range", func(t *testing.T) {
+		payloads, err := requestPayloads(servePipe(t, srv), 5, 10)
+		require.NoError(t, err)
+		require.Len(t, payloads, 5)
+		for i, p := range payloads {
+			require.Equal(t, chain[5+uint64(i)], p)
+		}
+		require.NoError(t, verifyPayloads(payloads, 5, refOf(chain[10])))
+	})
+	t.Run("not found", func(t *testing.T) {
+		payloads, err := requestPayloads(servePipe(t, srv), 18, 25)
+		require.NoError(t, err)
+		require.Len(t, payloads, 3, "only the available payloads")
+	})
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := requestPayloads(servePipe(t, srv), 10, 10+MaxRangeRequest+1)
+		require.ErrorContains(t, err, "result: 2")
+	})
+}
+
+func TestVerifyPayloads(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := makeChain(rng, 10)
+	otherChain := makeChain(rng, 10)
+	payloads := func(start, end uint64) []*eth.ExecutionPayload {
+		var out []*eth.ExecutionPayload
+		for i := start; i < end; i++ {
+			out = append(out, chain[i])
+		}
+		return out
+	}
+	end := refOf(chain[8])
+
+	require.NoError(t, verifyPayloads(payloads(3, 8), 3, end))
+
+	require.ErrorIs(t, verifyPayloads(payloads(4, 8), 3, end), errInvalidPayloads, "missing payload")
+	require.ErrorIs(t, verifyPayloads(payloads(3, 7), 3, end), errInvalidPayloads, "missing parent of end")
+	require.ErrorIs(t, verifyPayloads(payloads(3, 8), 3, refOf(otherChain[8])), errInvalidPayloads, "other chain")
+
+	mixed := payloads(3, 8)
+	mixed[2] = otherChain[5]
+	require.ErrorIs(t, verifyPayloads(mixed, 3, end), errInvalidPayloads, "payload of other chain")
+
+	tampered := payloads(3, 8)
+	p := *tampered[4]
+	p.GasUsed = 123
+	tampered[4] = &p
+	require.ErrorIs(t, verifyPayloads(tampered, 3, end), errInvalidPayloads, "bad block hash")
+}
+
+func TestSyncClient(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := makeChain(rng, 100)
+	tampered := make(mockL2Chain)
+	for n, p := range chain {
+		tampered[n] = p
+	}
+	p := *chain[95]
+	p.GasUsed = 123
+	tampered[95] = &p
+
+	// a mock transport, that serves the requests of the client with the chain of the peer
+	slow, bad, partial, good := peer.ID("slow"), peer.ID("bad"), peer.ID("partial"), peer.ID("good")
+	servers := map[peer.ID]*ReqRespServer{
+		bad:     NewReqRespServer(tampered),
+		partial: NewReqRespServer(mockL2Chain{90: chain[90], 91: chain[91]}),
+		good:    NewReqRespServer(chain),
+	}
+	newStream := func(ctx context.Context, id peer.ID) (SyncStream, error) {
+		if id == slow {
+			client, _ := net.Pipe() // never responds
+			return client, nil
+		}
+		return servePipe(t, servers[id]), nil
+	}
+	peers := func() []peer.ID { return []peer.ID{slow, bad, partial, good} }
+
+	var mu sync.Mutex
+	var received []*eth.ExecutionPayload
+	var from []peer.ID
+	rcv := func(ctx context.Context, id peer.ID, payload *eth.ExecutionPayload) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, payload)
+		from = append(from, id)
+		return nil
+	}
+
+	m := &testutils.RecordingMetrics{}
+	logger := testlog.Logger(t, log.LvlError)
+	scorer := NewPeerScorer(logger, PeerScoringConfig{}, nil, nil, m)
+	cl := NewSyncClient(logger, newStream, peers, rcv, scorer)
+	cl.requestTimeout = 100 * time.Millisecond
+	cl.Start()
+	defer cl.Close()
+
+	// the range is capped to the last MaxRangeRequest payloads before the end
+	require.NoError(t, cl.RequestL2Range(context.Background(), refOf(chain[0]), refOf(chain[99])))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == MaxRangeRequest
+	}, 10*time.Second, 10*time.Millisecond)
+
+	for i, payload := range received {
+		require.Equal(t, chain[99-MaxRangeRequest+uint64(i)], payload)
+		require.Equal(t, good, from[i])
+	}
+	require.Equal(t, PenaltySlowResponse.Score, scorer.Score(slow))
+	require.Equal(t, PenaltyInvalidPayload.Score, scorer.Score(bad))
+	require.Equal(t, 0.0, scorer.Score(partial), "peers are not penalized for missing payloads")
+	require.Equal(t, 0.0, scorer.Score(good))
+}
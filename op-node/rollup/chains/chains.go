@@ -0,0 +1,54 @@
+package chains
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+//go:embed configs
+var configsFS embed.FS
+
+const configsDir = "configs"
+
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// NetworkNames lists the names of the networks with an embedded rollup config, sorted by name.
+func NetworkNames() []string {
+	entries, err := configsFS.ReadDir(configsDir)
+	if err != nil { // embedded, should never fail
+		panic(fmt.Errorf("failed to read embedded rollup configs: %w", err))
+	}
+	var out []string
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		out = append(out, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// GetRollupConfig loads the embedded rollup config of the given network.
+// A new copy of the config is returned on every call.
+func GetRollupConfig(name string) (*rollup.Config, error) {
+	if name == "" || strings.ContainsAny(name, "/\\.") {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownNetwork, name)
+	}
+	data, err := configsFS.ReadFile(path.Join(configsDir, name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q, available networks: %s", ErrUnknownNetwork, name, strings.Join(NetworkNames(), ", "))
+	}
+	var cfg rollup.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode rollup config of network %q: %w", name, err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,28 @@
+package chains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNetworks checks that all embedded rollup configs can be loaded and are valid.
+func TestNetworks(t *testing.T) {
+	names := NetworkNames()
+	require.Contains(t, names, "devnet")
+	for _, name := range names {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			cfg, err := GetRollupConfig(name)
+			require.NoError(t, err)
+			require.NoError(t, cfg.Check())
+		})
+	}
+}
+
+func TestUnknownNetwork(t *testing.T) {
+	for _, name := range []string{"", "does-not-exist", "../chains", "README"} {
+		_, err := GetRollupConfig(name)
+		require.ErrorIs(t, err, ErrUnknownNetwork, "network %q", name)
+	}
+}
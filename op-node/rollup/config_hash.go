@@ -0,0 +1,55 @@
+package rollup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ConfigHashVersion identifies the encoding that a config hash commits to.
+type ConfigHashVersion uint8
+
+// ConfigHashVersion0 commits to the JSON encoding of the Config.
+//
+// Any change to the JSON encoding of Config (added, removed or renamed fields or tags)
+// changes the resulting hash, and thus requires a new config hash version.
+const ConfigHashVersion0 ConfigHashVersion = 0
+
+// Bytes32 expands the version into the 32 byte prefix that is committed to in the hash.
+func (v ConfigHashVersion) Bytes32() (out eth.Bytes32) {
+	out[31] = byte(v)
+	return
+}
+
+// ConfigHashResult is the RPC response of a config hash request,
+// describing the hash along with the version and the config it commits to.
+type ConfigHashResult struct {
+	Version eth.Bytes32 `json:"version"`
+	Hash    eth.Bytes32 `json:"hash"`
+	Config  *Config     `json:"config,omitempty"`
+}
+
+// ComputeConfigHash computes a deterministic hash of the rollup config,
+// so different services can verify they run with an identical configuration.
+func ComputeConfigHash(version ConfigHashVersion, cfg *Config) (eth.Bytes32, error) {
+	if version != ConfigHashVersion0 {
+		return eth.Bytes32{}, fmt.Errorf("unsupported config hash version: %d", version)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return eth.Bytes32{}, fmt.Errorf("failed to encode rollup config: %w", err)
+	}
+	versionBytes := version.Bytes32()
+	var buf bytes.Buffer
+	buf.Write(versionBytes[:])
+	buf.Write(data)
+	return eth.Bytes32(crypto.Keccak256Hash(buf.Bytes())), nil
+}
+
+// Hash computes the latest version of the config hash, see ComputeConfigHash.
+func (cfg *Config) Hash() (eth.Bytes32, error) {
+	return ComputeConfigHash(ConfigHashVersion0, cfg)
+}
@@ -0,0 +1,31 @@
+package derive
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// aliasOffset is added to the address of L1 contracts that make a deposit,
+// to avoid collisions with L2 contracts at the same address. See AddressAliasHelper.sol.
+var aliasOffset = new(big.Int).SetBytes(common.FromHex("0x1111000000000000000000000000000000001111"))
+
+// addressModulo is 2**160, the modulo for address arithmetic.
+var addressModulo = new(big.Int).Lsh(big.NewInt(1), 160)
+
+// ApplyL1ToL2Alias returns the L2 sender address of a deposit made by the given L1 contract.
+// Deposits made by EOAs (msg.sender == tx.origin) are not aliased.
+func ApplyL1ToL2Alias(l1Addr common.Address) common.Address {
+	v := new(big.Int).SetBytes(l1Addr[:])
+	v.Add(v, aliasOffset)
+	v.Mod(v, addressModulo)
+	return common.BigToAddress(v)
+}
+
+// UndoL1ToL2Alias returns the L1 contract address of an aliased L2 deposit sender.
+func UndoL1ToL2Alias(l2Addr common.Address) common.Address {
+	v := new(big.Int).SetBytes(l2Addr[:])
+	v.Sub(v, aliasOffset)
+	v.Mod(v, addressModulo)
+	return common.BigToAddress(v)
+}
@@ -0,0 +1,37 @@
+package derive
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyL1ToL2Alias(t *testing.T) {
+	testCases := []struct {
+		name string
+		l1   common.Address
+		l2   common.Address
+	}{
+		{"zero", common.Address{}, common.HexToAddress("0x1111000000000000000000000000000000001111")},
+		{"regular", common.HexToAddress("0x0000000000000000000000000000000000001234"), common.HexToAddress("0x1111000000000000000000000000000000002345")},
+		{"overflow", common.HexToAddress("0xffffffffffffffffffffffffffffffffffffffff"), common.HexToAddress("0x1111000000000000000000000000000000001110")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.l2, ApplyL1ToL2Alias(tc.l1))
+			require.Equal(t, tc.l1, UndoL1ToL2Alias(tc.l2))
+		})
+	}
+}
+
+func TestAliasRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	for i := 0; i < 100; i++ {
+		addr := testutils.RandomAddress(rng)
+		require.Equal(t, addr, UndoL1ToL2Alias(ApplyL1ToL2Alias(addr)))
+		require.Equal(t, addr, ApplyL1ToL2Alias(UndoL1ToL2Alias(addr)))
+	}
+}
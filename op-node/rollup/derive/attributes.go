@@ -24,7 +24,12 @@ type L1ReceiptsFetcher interface {
 // The severity of the error is returned; a crit=false error means there was a temporary issue, like a failed RPC or time-out.
 // A crit=true error means the input arguments are inconsistent or invalid.
 func PreparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1ReceiptsFetcher, l2Parent eth.L2BlockRef, timestamp uint64, epoch eth.BlockID) (attrs *eth.PayloadAttributes, err error) {
-	var l1Info eth.BlockInfo
+	attrs, _, err = preparePayloadAttributes(ctx, cfg, dl, l2Parent, timestamp, epoch)
+	return attrs, err
+}
+
+// preparePayloadAttributes is PreparePayloadAttributes, but also returns the info of the L1 origin the attributes were prepared with.
+func preparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1ReceiptsFetcher, l2Parent eth.L2BlockRef, timestamp uint64, epoch eth.BlockID) (attrs *eth.PayloadAttributes, l1Info eth.BlockInfo, err error) {
 	var depositTxs []hexutil.Bytes
 	var seqNumber uint64
 
@@ -34,10 +39,10 @@ func PreparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1Rece
 	if l2Parent.L1Origin.Number != epoch.Number {
 		info, _, receiptsFetcher, err := dl.Fetch(ctx, epoch.Hash)
 		if err != nil {
-			return nil, NewTemporaryError(fmt.Errorf("failed to fetch L1 block info and receipts: %w", err))
+			return nil, nil, NewTemporaryError(fmt.Errorf("failed to fetch L1 block info and receipts: %w", err))
 		}
 		if l2Parent.L1Origin.Hash != info.ParentHash() {
-			return nil, NewResetError(
+			return nil, nil, NewResetError(
 				fmt.Errorf("cannot create new block with L1 origin %s (parent %s) on top of L1 origin %s",
 					epoch, info.ParentHash(), l2Parent.L1Origin))
 		}
@@ -45,28 +50,28 @@ func PreparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1Rece
 			if err := receiptsFetcher.Fetch(ctx); err == io.EOF {
 				break
 			} else if err != nil {
-				return nil, NewTemporaryError(fmt.Errorf("failed to fetch more receipts: %w", err))
+				return nil, nil, NewTemporaryError(fmt.Errorf("failed to fetch more receipts: %w", err))
 			}
 		}
 		receipts, err := receiptsFetcher.Result()
 		if err != nil {
-			return nil, NewResetError(fmt.Errorf("fetched bad receipt data: %w", err))
+			return nil, nil, NewResetError(fmt.Errorf("fetched bad receipt data: %w", err))
 		}
 		deposits, err := DeriveDeposits(receipts, cfg.DepositContractAddress)
 		if err != nil {
 			// deposits may never be ignored. Failing to process them is a critical error.
-			return nil, NewCriticalError(fmt.Errorf("failed to derive some deposits: %w", err))
+			return nil, nil, NewCriticalError(fmt.Errorf("failed to derive some deposits: %w", err))
 		}
 		l1Info = info
 		depositTxs = deposits
 		seqNumber = 0
 	} else {
 		if l2Parent.L1Origin.Hash != epoch.Hash {
-			return nil, NewResetError(fmt.Errorf("cannot create new block with L1 origin %s in conflict with L1 origin %s", epoch, l2Parent.L1Origin))
+			return nil, nil, NewResetError(fmt.Errorf("cannot create new block with L1 origin %s in conflict with L1 origin %s", epoch, l2Parent.L1Origin))
 		}
 		info, err := dl.InfoByHash(ctx, epoch.Hash)
 		if err != nil {
-			return nil, NewTemporaryError(fmt.Errorf("failed to fetch L1 block info: %w", err))
+			return nil, nil, NewTemporaryError(fmt.Errorf("failed to fetch L1 block info: %w", err))
 		}
 		l1Info = info
 		depositTxs = nil
@@ -75,7 +80,7 @@ func PreparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1Rece
 
 	l1InfoTx, err := L1InfoDepositBytes(seqNumber, l1Info)
 	if err != nil {
-		return nil, NewCriticalError(fmt.Errorf("failed to create l1InfoTx: %w", err))
+		return nil, nil, NewCriticalError(fmt.Errorf("failed to create l1InfoTx: %w", err))
 	}
 
 	txs := make([]hexutil.Bytes, 0, 1+len(depositTxs))
@@ -88,5 +93,5 @@ func PreparePayloadAttributes(ctx context.Context, cfg *rollup.Config, dl L1Rece
 		SuggestedFeeRecipient: cfg.FeeRecipientAddress,
 		Transactions:          txs,
 		NoTxPool:              true,
-	}, nil
+	}, l1Info, nil
 }
@@ -35,14 +35,26 @@ type AttributesQueue struct {
 	next     AttributesQueueOutput
 	progress Progress
 	batches  []*BatchData
+
+	metrics Metrics
+	// deposits of the attributes that were output, but not yet included in the L2 safe chain, in order
+	pendingDeposits []pendingDeposits
+}
+
+// pendingDeposits are the deposits of an L1 block, derived into the attributes of an L2 block.
+type pendingDeposits struct {
+	count  uint64
+	l1Time uint64
+	l2Time uint64
 }
 
-func NewAttributesQueue(log log.Logger, cfg *rollup.Config, l1Fetcher L1ReceiptsFetcher, next AttributesQueueOutput) *AttributesQueue {
+func NewAttributesQueue(log log.Logger, cfg *rollup.Config, l1Fetcher L1ReceiptsFetcher, next AttributesQueueOutput, metrics Metrics) *AttributesQueue {
 	return &AttributesQueue{
-		log:    log,
-		config: cfg,
-		dl:     l1Fetcher,
-		next:   next,
+		log:     log,
+		config:  cfg,
+		dl:      l1Fetcher,
+		next:    next,
+		metrics: metrics,
 	}
 }
 
@@ -59,6 +71,9 @@ func (aq *AttributesQueue) Step(ctx context.Context, outer Progress) error {
 	if changed, err := aq.progress.Update(outer); err != nil || changed {
 		return err
 	}
+	if len(aq.pendingDeposits) > 0 {
+		aq.includeDeposits(aq.next.SafeL2Head())
+	}
 	if len(aq.batches) == 0 {
 		return io.EOF
 	}
@@ -71,11 +86,17 @@ func (aq *AttributesQueue) Step(ctx context.Context, outer Progress) error {
 	}
 	fetchCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
-	attrs, err := PreparePayloadAttributes(fetchCtx, aq.config, aq.dl, safeL2Head, batch.Timestamp, batch.Epoch())
+	attrs, l1Info, err := preparePayloadAttributes(fetchCtx, aq.config, aq.dl, safeL2Head, batch.Timestamp, batch.Epoch())
 	if err != nil {
 		return err
 	}
 
+	// all transactions after the L1 info deposit are user deposits
+	if deposits := uint64(len(attrs.Transactions) - 1); deposits > 0 {
+		aq.pendingDeposits = append(aq.pendingDeposits, pendingDeposits{count: deposits, l1Time: l1Info.Time(), l2Time: batch.Timestamp})
+		aq.recordDepositsPending()
+	}
+
 	// we are verifying, not sequencing, we've got all transactions and do not pull from the tx-pool
 	// (that would make the block derivation non-deterministic)
 	attrs.NoTxPool = true
@@ -90,8 +111,33 @@ func (aq *AttributesQueue) Step(ctx context.Context, outer Progress) error {
 	return nil
 }
 
+// includeDeposits records the inclusion of the pending deposits of the blocks up to the safe head.
+func (aq *AttributesQueue) includeDeposits(safeL2Head eth.L2BlockRef) {
+	i := 0
+	for ; i < len(aq.pendingDeposits) && aq.pendingDeposits[i].l2Time <= safeL2Head.Time; i++ {
+		d := aq.pendingDeposits[i]
+		aq.metrics.RecordDepositsIncluded(d.count, time.Duration(d.l2Time-d.l1Time)*time.Second)
+	}
+	if i > 0 {
+		aq.pendingDeposits = aq.pendingDeposits[i:]
+		aq.recordDepositsPending()
+	}
+}
+
+func (aq *AttributesQueue) recordDepositsPending() {
+	var count uint64
+	for _, d := range aq.pendingDeposits {
+		count += d.count
+	}
+	aq.metrics.RecordDepositsPending(count)
+}
+
 func (aq *AttributesQueue) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error {
 	aq.batches = aq.batches[:0]
+	if len(aq.pendingDeposits) > 0 {
+		aq.pendingDeposits = aq.pendingDeposits[:0]
+		aq.recordDepositsPending()
+	}
 	aq.progress = aq.next.Progress()
 	return io.EOF
 }
@@ -6,13 +6,16 @@ import (
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,7 +70,7 @@ func TestAttributesQueue_Step(t *testing.T) {
 
 	out.ExpectSafeL2Head(safeHead)
 
-	batch := &BatchData{BatchV1{
+	batch := &BatchData{BatchV1: BatchV1{
 		ParentHash:   safeHead.Hash,
 		EpochNum:     rollup.Epoch(l1Info.InfoNum),
 		EpochHash:    l1Info.InfoHash,
@@ -86,7 +89,7 @@ func TestAttributesQueue_Step(t *testing.T) {
 	}
 	out.ExpectAddSafeAttributes(&attrs)
 
-	aq := NewAttributesQueue(testlog.Logger(t, log.LvlError), cfg, l1Fetcher, out)
+	aq := NewAttributesQueue(testlog.Logger(t, log.LvlError), cfg, l1Fetcher, out, &testutils.RecordingMetrics{})
 	require.NoError(t, RepeatResetStep(t, aq.ResetStep, l1Fetcher, 1))
 
 	aq.AddBatch(batch)
@@ -94,3 +97,61 @@ func TestAttributesQueue_Step(t *testing.T) {
 	require.NoError(t, aq.Step(context.Background(), out.progress), "adding batch to next stage, no EOF yet")
 	require.Equal(t, io.EOF, aq.Step(context.Background(), out.progress), "done with batches")
 }
+
+func TestAttributesQueueDepositMetrics(t *testing.T) {
+	cfg := &rollup.Config{
+		BlockTime:              2,
+		L1ChainID:              big.NewInt(101),
+		L2ChainID:              big.NewInt(102),
+		FeeRecipientAddress:    common.Address{0xaa},
+		DepositContractAddress: common.Address{0xbb},
+	}
+	rng := rand.New(rand.NewSource(1234))
+	safeHead := testutils.RandomL2BlockRef(rng)
+	l1Info := testutils.RandomBlockInfo(rng)
+	l1Info.InfoParentHash = safeHead.L1Origin.Hash
+	l1Info.InfoNum = safeHead.L1Origin.Number + 1
+	l1Info.InfoTime = safeHead.Time - 10
+
+	receipts, _ := makeReceipts(rng, l1Info.InfoHash, cfg.DepositContractAddress, []receiptData{
+		{goodReceipt: true, DepositLogs: []bool{true, true}},
+		{goodReceipt: false, DepositLogs: []bool{true}},
+	})
+	l1Fetcher := &testutils.MockL1Source{}
+	defer l1Fetcher.AssertExpectations(t)
+	l1Fetcher.ExpectFetch(l1Info.InfoHash, l1Info, make(types.Transactions, len(receipts)), receipts, nil)
+
+	out := &MockAttributesQueueOutput{}
+	out.progress = Progress{Origin: l1Info.BlockRef()}
+	defer out.AssertExpectations(t)
+	out.ExpectSafeL2Head(safeHead)
+	out.Mock.On("AddSafeAttributes", mock.Anything).Once().Return()
+
+	m := &testutils.RecordingMetrics{}
+	aq := NewAttributesQueue(testlog.Logger(t, log.LvlError), cfg, l1Fetcher, out, m)
+	require.NoError(t, RepeatResetStep(t, aq.ResetStep, l1Fetcher, 1))
+
+	aq.AddBatch(&BatchData{BatchV1: BatchV1{
+		ParentHash: safeHead.Hash,
+		EpochNum:   rollup.Epoch(l1Info.InfoNum),
+		EpochHash:  l1Info.InfoHash,
+		Timestamp:  safeHead.Time + cfg.BlockTime,
+	}})
+	require.NoError(t, aq.Step(context.Background(), out.progress))
+	require.Equal(t, uint64(2), m.Records("RecordDepositsPending")[0].Value, "deposits are pending until the block is safe")
+	m.RequireCount(t, "RecordDepositsIncluded", 0)
+
+	// the engine queue did not process the attributes yet
+	out.ExpectSafeL2Head(safeHead)
+	require.Equal(t, io.EOF, aq.Step(context.Background(), out.progress))
+	m.RequireCount(t, "RecordDepositsIncluded", 0)
+
+	newSafeHead := testutils.NextRandomL2Ref(rng, cfg.BlockTime, safeHead, l1Info.ID())
+	out.ExpectSafeL2Head(newSafeHead)
+	require.Equal(t, io.EOF, aq.Step(context.Background(), out.progress))
+	included := m.Records("RecordDepositsIncluded")
+	require.Len(t, included, 1)
+	require.Equal(t, 12*time.Second, included[0].Value, "latency from L1 to L2 block time")
+	pending := m.Records("RecordDepositsPending")
+	require.Equal(t, uint64(0), pending[len(pending)-1].Value)
+}
@@ -21,6 +21,11 @@ import (
 // BatchV1Type := 0
 // batchV1 := BatchV1Type ++ RLP([epoch, timestamp, transaction_list]
 //
+// SpanBatchType := 1
+// spanBatch := SpanBatchType ++ RLP([parent_hash, timestamp, [[epoch, transaction_list], ...]])
+//
+// Span batches are experimental, and only accepted if enabled in the rollup config, see rollup.Config.IsSpanBatch.
+//
 // An empty input is not a valid batch.
 //
 // Note: the type system is based on L1 typed transactions.
@@ -32,6 +37,7 @@ var encodeBufferPool = sync.Pool{
 
 const (
 	BatchV1Type = iota
+	SpanBatchType
 )
 
 type BatchV1 struct {
@@ -43,9 +49,62 @@ type BatchV1 struct {
 	Transactions []hexutil.Bytes
 }
 
+// SpanBatchV1 is a span of consecutive L2 blocks.
+// Only the parent hash of the first block is encoded, the next blocks build on the previous block of the span.
+// The timestamps of the next blocks follow the timestamp of the first block at the L2 block time.
+type SpanBatchV1 struct {
+	ParentHash common.Hash // parent L2 block hash of the first block
+	Timestamp  uint64      // timestamp of the first block
+	Blocks     []SpanBatchBlock
+}
+
+// SpanBatchBlock is a block of a span batch.
+type SpanBatchBlock struct {
+	EpochNum     rollup.Epoch // aka l1 num
+	EpochHash    common.Hash  // block hash
+	Transactions []hexutil.Bytes
+}
+
 type BatchData struct {
 	BatchV1
 	// batches may contain additional data with new upgrades
+
+	// Span is set instead of BatchV1 if the batch is a span batch,
+	// which is expanded into a batch per block by SpanBatches.
+	Span *SpanBatchV1
+
+	// span this batch was expanded from, if any, and the index of the batch in the span
+	fromSpan  *SpanBatchV1
+	spanIndex int
+}
+
+// SpanBatches expands the span batch into a batch per block, with the given L2 block time.
+// Except for the first batch, the parent hashes of the batches are unknown until the previous batch
+// of the span is applied, see BatchQueue.
+func (b *BatchData) SpanBatches(blockTime uint64) []*BatchData {
+	out := make([]*BatchData, 0, len(b.Span.Blocks))
+	for i, block := range b.Span.Blocks {
+		batch := &BatchData{
+			BatchV1: BatchV1{
+				EpochNum:     block.EpochNum,
+				EpochHash:    block.EpochHash,
+				Timestamp:    b.Span.Timestamp + uint64(i)*blockTime,
+				Transactions: block.Transactions,
+			},
+			fromSpan:  b.Span,
+			spanIndex: i,
+		}
+		if i == 0 {
+			batch.ParentHash = b.Span.ParentHash
+		}
+		out = append(out, batch)
+	}
+	return out
+}
+
+// continuesSpan returns true if the batch is the next block in the same span as prev.
+func (b *BatchData) continuesSpan(prev *BatchData) bool {
+	return b.fromSpan != nil && prev != nil && prev.fromSpan == b.fromSpan && prev.spanIndex+1 == b.spanIndex
 }
 
 func (b *BatchV1) Epoch() eth.BlockID {
@@ -71,6 +130,10 @@ func (b *BatchData) MarshalBinary() ([]byte, error) {
 }
 
 func (b *BatchData) encodeTyped(buf *bytes.Buffer) error {
+	if b.Span != nil {
+		buf.WriteByte(SpanBatchType)
+		return rlp.Encode(buf, b.Span)
+	}
 	buf.WriteByte(BatchV1Type)
 	return rlp.Encode(buf, &b.BatchV1)
 }
@@ -102,6 +165,16 @@ func (b *BatchData) decodeTyped(data []byte) error {
 	switch data[0] {
 	case BatchV1Type:
 		return rlp.DecodeBytes(data[1:], &b.BatchV1)
+	case SpanBatchType:
+		var span SpanBatchV1
+		if err := rlp.DecodeBytes(data[1:], &span); err != nil {
+			return err
+		}
+		if len(span.Blocks) == 0 {
+			return errors.New("empty span batch")
+		}
+		b.Span = &span
+		return nil
 	default:
 		return fmt.Errorf("unrecognized batch type: %d", data[0])
 	}
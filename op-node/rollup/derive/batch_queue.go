@@ -39,19 +39,24 @@ type BatchQueue struct {
 	config   *rollup.Config
 	next     BatchQueueOutput
 	progress Progress
+	metrics  Metrics
 
 	l1Blocks []eth.L1BlockRef
 
 	// batches in order of when we've first seen them, grouped by L2 timestamp
 	batches map[uint64][]*BatchWithL1InclusionBlock
+
+	// last derived batch, to link the batches of a span batch to their parent
+	lastBatch *BatchData
 }
 
 // NewBatchQueue creates a BatchQueue, which should be Reset(origin) before use.
-func NewBatchQueue(log log.Logger, cfg *rollup.Config, next BatchQueueOutput) *BatchQueue {
+func NewBatchQueue(log log.Logger, cfg *rollup.Config, next BatchQueueOutput, metrics Metrics) *BatchQueue {
 	return &BatchQueue{
-		log:    log,
-		config: cfg,
-		next:   next,
+		log:     log,
+		config:  cfg,
+		next:    next,
+		metrics: metrics,
 	}
 }
 
@@ -76,6 +81,7 @@ func (bq *BatchQueue) Step(ctx context.Context, outer Progress) error {
 	} else if err != nil {
 		return err
 	}
+	bq.lastBatch = batch
 	bq.next.AddBatch(batch)
 	return nil
 }
@@ -85,6 +91,7 @@ func (bq *BatchQueue) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error
 	// It is set in the engine queue (two stages away) such that the L2 Safe Head origin is the progress
 	bq.progress = bq.next.Progress()
 	bq.batches = make(map[uint64][]*BatchWithL1InclusionBlock)
+	bq.lastBatch = nil
 	// Include the new origin as an origin to build on
 	bq.l1Blocks = bq.l1Blocks[:0]
 	bq.l1Blocks = append(bq.l1Blocks, bq.progress.Origin)
@@ -102,13 +109,27 @@ func (bq *BatchQueue) AddBatch(batch *BatchData) {
 		L1InclusionBlock: bq.progress.Origin,
 		Batch:            batch,
 	}
-	validity := CheckBatch(bq.config, bq.log, bq.l1Blocks, bq.next.SafeL2Head(), &data)
+	bq.linkSpanBatch(batch, bq.next.SafeL2Head())
+	validity, reason := checkBatch(bq.config, bq.log, bq.l1Blocks, bq.next.SafeL2Head(), &data)
 	if validity == BatchDrop {
+		bq.metrics.RecordDroppedBatch(reason)
 		return // if we do drop the batch, CheckBatch will log the drop reason with WARN level.
 	}
 	bq.batches[batch.Timestamp] = append(bq.batches[batch.Timestamp], &data)
 }
 
+// linkSpanBatch sets the parent hash of a batch of a span batch, other than the first, to the safe head,
+// if the previous batch of the span is the last derived batch, and thus the safe head is the block of that batch.
+// Otherwise the parent hash remains unknown, and the batch is dropped if it is the next batch by timestamp.
+func (bq *BatchQueue) linkSpanBatch(batch *BatchData, l2SafeHead eth.L2BlockRef) {
+	if batch.fromSpan == nil || batch.spanIndex == 0 {
+		return
+	}
+	if batch.continuesSpan(bq.lastBatch) && bq.lastBatch.Timestamp == l2SafeHead.Time {
+		batch.ParentHash = l2SafeHead.Hash
+	}
+}
+
 // deriveNextBatch derives the next batch to apply on top of the current L2 safe head,
 // following the validity rules imposed on consecutive batches,
 // based on currently available buffered batch and L1 origin information.
@@ -136,7 +157,8 @@ func (bq *BatchQueue) deriveNextBatch(ctx context.Context) (*BatchData, error) {
 	candidates := bq.batches[nextTimestamp]
 batchLoop:
 	for i, batch := range candidates {
-		validity := CheckBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
+		bq.linkSpanBatch(batch.Batch, l2SafeHead)
+		validity, reason := checkBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
 		switch validity {
 		case BatchFuture:
 			return nil, NewCriticalError(fmt.Errorf("found batch with timestamp %d marked as future batch, but expected timestamp %d", batch.Batch.Timestamp, nextTimestamp))
@@ -149,6 +171,7 @@ batchLoop:
 				"l2_safe_head", l2SafeHead.ID(),
 				"l2_safe_head_time", l2SafeHead.Time,
 			)
+			bq.metrics.RecordDroppedBatch(reason)
 			continue
 		case BatchAccept:
 			nextBatch = batch
@@ -201,7 +224,7 @@ batchLoop:
 	// to preserve that L2 time >= L1 time
 	if nextTimestamp < nextEpoch.Time {
 		return &BatchData{
-			BatchV1{
+			BatchV1: BatchV1{
 				ParentHash:   l2SafeHead.Hash,
 				EpochNum:     rollup.Epoch(epoch.Number),
 				EpochHash:    epoch.Hash,
@@ -213,7 +236,7 @@ batchLoop:
 	// As we move the safe head origin forward, we also drop the old L1 block reference
 	bq.l1Blocks = bq.l1Blocks[1:]
 	return &BatchData{
-		BatchV1{
+		BatchV1: BatchV1{
 			ParentHash:   l2SafeHead.Hash,
 			EpochNum:     rollup.Epoch(nextEpoch.Number),
 			EpochHash:    nextEpoch.Hash,
@@ -65,7 +65,7 @@ func mockHash(time uint64, layer uint8) common.Hash {
 func b(timestamp uint64, epoch eth.L1BlockRef) *BatchData {
 	rng := rand.New(rand.NewSource(int64(timestamp)))
 	data := testutils.RandomData(rng, 20)
-	return &BatchData{BatchV1{
+	return &BatchData{BatchV1: BatchV1{
 		ParentHash:   mockHash(timestamp-2, 2),
 		Timestamp:    timestamp,
 		EpochNum:     rollup.Epoch(epoch.Number),
@@ -116,7 +116,7 @@ func TestBatchQueueEager(t *testing.T) {
 		SeqWindowSize:     30,
 	}
 
-	bq := NewBatchQueue(log, cfg, next)
+	bq := NewBatchQueue(log, cfg, next, &TestMetrics{})
 	require.Equal(t, io.EOF, bq.ResetStep(context.Background(), nil), "reset should complete without l1 fetcher, single step")
 
 	// We start with an open L1 origin as progress in the first step
@@ -135,6 +135,66 @@ func TestBatchQueueEager(t *testing.T) {
 	require.Equal(t, batches, next.batches)
 }
 
+// TestBatchQueueSpan checks that the batches of a span batch are linked to the safe head built from the
+// previous batch of the span, and that a span batch that does not build on the safe head is dropped.
+func TestBatchQueueSpan(t *testing.T) {
+	log := testlog.Logger(t, log.LvlTrace)
+	l1 := L1Chain([]uint64{10, 20, 30})
+	next := &fakeBatchQueueOutput{
+		safeL2Head: eth.L2BlockRef{
+			Hash:     mockHash(10, 2),
+			Time:     10,
+			L1Origin: l1[0].ID(),
+		},
+		progress: Progress{
+			Origin: l1[0],
+			Closed: false,
+		},
+	}
+	cfg := &rollup.Config{
+		Genesis: rollup.Genesis{
+			L2Time: 10,
+		},
+		BlockTime:         2,
+		MaxSequencerDrift: 600,
+		SeqWindowSize:     30,
+		SpanBatches:       true,
+	}
+
+	var dropped []string
+	metrics := &TestMetrics{recordDropped: func(kind string, reason string) { dropped = append(dropped, kind+":"+reason) }}
+	bq := NewBatchQueue(log, cfg, next, metrics)
+	require.Equal(t, io.EOF, bq.ResetStep(context.Background(), nil), "reset should complete without l1 fetcher, single step")
+	progress := bq.progress
+
+	span := &BatchData{Span: &SpanBatchV1{ParentHash: mockHash(10, 2), Timestamp: 12}}
+	for i := 0; i < 3; i++ {
+		span.Span.Blocks = append(span.Span.Blocks, SpanBatchBlock{
+			EpochNum:     rollup.Epoch(l1[0].Number),
+			EpochHash:    l1[0].Hash,
+			Transactions: []hexutil.Bytes{{byte(i)}},
+		})
+	}
+	batches := span.SpanBatches(cfg.BlockTime)
+	for _, batch := range batches {
+		bq.AddBatch(batch)
+	}
+	require.NoError(t, RepeatStep(t, bq.Step, progress, 10))
+	require.Equal(t, batches, next.batches)
+	for i, batch := range next.batches[1:] {
+		require.Equal(t, mockHash(batch.Timestamp-2, 2), batch.ParentHash, "batch %d is linked to the previous block", i+1)
+	}
+
+	// a span that does not build on the safe head is dropped entirely
+	other := &BatchData{Span: &SpanBatchV1{ParentHash: common.Hash{0: 0xff}, Timestamp: 18, Blocks: span.Span.Blocks[:2]}}
+	for _, batch := range other.SpanBatches(cfg.BlockTime) {
+		bq.AddBatch(batch)
+	}
+	require.NoError(t, RepeatStep(t, bq.Step, progress, 10))
+	require.Len(t, next.batches, 3)
+	require.Equal(t, []string{"batch:parent_hash"}, dropped, "the dropped batch is counted by reason")
+}
+
 func TestBatchQueueFull(t *testing.T) {
 	log := testlog.Logger(t, log.LvlTrace)
 	l1 := L1Chain([]uint64{10, 15, 20})
@@ -161,7 +221,7 @@ func TestBatchQueueFull(t *testing.T) {
 		SeqWindowSize:     2,
 	}
 
-	bq := NewBatchQueue(log, cfg, next)
+	bq := NewBatchQueue(log, cfg, next, &TestMetrics{})
 	require.Equal(t, io.EOF, bq.ResetStep(context.Background(), nil), "reset should complete without l1 fetcher, single step")
 
 	// We start with an open L1 origin as progress in the first step
@@ -249,7 +309,7 @@ func TestBatchQueueMissing(t *testing.T) {
 		SeqWindowSize:     2,
 	}
 
-	bq := NewBatchQueue(log, cfg, next)
+	bq := NewBatchQueue(log, cfg, next, &TestMetrics{})
 	require.Equal(t, io.EOF, bq.ResetStep(context.Background(), nil), "reset should complete without l1 fetcher, single step")
 
 	// We start with an open L1 origin as progress in the first step
@@ -27,6 +27,16 @@ func TestBatchRoundTrip(t *testing.T) {
 				Transactions: []hexutil.Bytes{[]byte{0, 0, 0}, []byte{0x76, 0xfd, 0x7c}},
 			},
 		},
+		{
+			Span: &SpanBatchV1{
+				ParentHash: common.Hash{31: 0x42},
+				Timestamp:  1647026951,
+				Blocks: []SpanBatchBlock{
+					{EpochNum: 1, EpochHash: common.Hash{0: 0x01}, Transactions: []hexutil.Bytes{}},
+					{EpochNum: 2, EpochHash: common.Hash{0: 0x02}, Transactions: []hexutil.Bytes{[]byte{0x76, 0xfd, 0x7c}}},
+				},
+			},
+		},
 	}
 
 	for i, batch := range batches {
@@ -38,3 +48,11 @@ func TestBatchRoundTrip(t *testing.T) {
 		assert.Equal(t, batch, &dec, "Batch not equal test case %v", i)
 	}
 }
+
+func TestEmptySpanBatch(t *testing.T) {
+	enc, err := (&BatchData{Span: &SpanBatchV1{}}).MarshalBinary()
+	assert.NoError(t, err)
+	var dec BatchData
+	err = dec.UnmarshalBinary(enc)
+	assert.ErrorContains(t, err, "empty span batch")
+}
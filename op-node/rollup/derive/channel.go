@@ -122,6 +122,26 @@ func (ch *Channel) Reader() io.Reader {
 	return io.MultiReader(readers...)
 }
 
+// AppendData appends the channel data to dst, in one copy, and returns the extended slice.
+// This panics if it is called while `IsReady` is not true.
+func (ch *Channel) AppendData(dst []byte) []byte {
+	n := 0
+	for _, data := range ch.inputs {
+		n += len(data)
+	}
+	if free := cap(dst) - len(dst); free < n {
+		dst = append(make([]byte, 0, len(dst)+n), dst...)
+	}
+	for i := uint64(0); i <= uint64(ch.endFrameNumber); i++ {
+		data, ok := ch.inputs[i]
+		if !ok {
+			panic("dev error in channel.AppendData. Must be called after the channel is ready.")
+		}
+		dst = append(dst, data...)
+	}
+	return dst
+}
+
 // BatchReader provides a function that iteratively consumes batches from the reader.
 // The L1Inclusion block is also provided at creation time.
 func BatchReader(r io.Reader, l1InclusionBlock eth.L1BlockRef) (func() (BatchWithL1InclusionBlock, error), error) {
@@ -35,23 +35,29 @@ type ChannelBank struct {
 	channels     map[ChannelID]*Channel // channels by ID
 	channelQueue []ChannelID            // channels in FIFO order
 
+	// buffer that read channel data is reassembled in, reused between reads
+	readBuf []byte
+
 	resetting bool
 
 	progress Progress
 
 	next ChannelBankOutput
+
+	metrics Metrics
 }
 
 var _ Stage = (*ChannelBank)(nil)
 
 // NewChannelBank creates a ChannelBank, which should be Reset(origin) before use.
-func NewChannelBank(log log.Logger, cfg *rollup.Config, next ChannelBankOutput) *ChannelBank {
+func NewChannelBank(log log.Logger, cfg *rollup.Config, next ChannelBankOutput, metrics Metrics) *ChannelBank {
 	return &ChannelBank{
 		log:          log,
 		cfg:          cfg,
 		channels:     make(map[ChannelID]*Channel),
 		channelQueue: make([]ChannelID, 0, 10),
 		next:         next,
+		metrics:      metrics,
 	}
 }
 
@@ -59,20 +65,38 @@ func (ib *ChannelBank) Progress() Progress {
 	return ib.progress
 }
 
-func (ib *ChannelBank) prune() {
-	// check total size
+// maxSize returns the configured channel bank size limit, or MaxChannelBankSize if not configured.
+func (ib *ChannelBank) maxSize() uint64 {
+	if ib.cfg.MaxChannelBankSize != 0 {
+		return ib.cfg.MaxChannelBankSize
+	}
+	return MaxChannelBankSize
+}
+
+// totalSize returns the estimated memory size of all buffered channels.
+func (ib *ChannelBank) totalSize() uint64 {
 	totalSize := uint64(0)
 	for _, ch := range ib.channels {
 		totalSize += ch.size
 	}
+	return totalSize
+}
+
+func (ib *ChannelBank) prune() {
+	totalSize := ib.totalSize()
+	maxSize := ib.maxSize()
 	// prune until it is reasonable again. The high-priority channel failed to be read, so we start pruning there.
-	for totalSize > MaxChannelBankSize {
+	for totalSize > maxSize {
 		id := ib.channelQueue[0]
 		ch := ib.channels[id]
 		ib.channelQueue = ib.channelQueue[1:]
 		delete(ib.channels, id)
 		totalSize -= ch.size
+		ib.log.Warn("evicted channel from full channel bank", "channel", id, "size", ch.size, "max_size", maxSize)
+		ib.metrics.RecordChannelBankEviction()
+		ib.metrics.RecordDroppedChannel("overflow")
 	}
+	ib.metrics.RecordChannelBankSize(totalSize)
 }
 
 // IngestData adds new L1 data to the channel bank.
@@ -89,6 +113,11 @@ func (ib *ChannelBank) IngestData(data []byte) {
 	frames, err := ParseFrames(data)
 	if err != nil {
 		ib.log.Warn("malformed frame", "err", err)
+		if len(data) > 0 && data[0] != DerivationVersion0 {
+			ib.metrics.RecordDroppedFrame("bad_version")
+		} else {
+			ib.metrics.RecordDroppedFrame("parse_error")
+		}
 		return
 	}
 
@@ -97,11 +126,13 @@ func (ib *ChannelBank) IngestData(data []byte) {
 		// check if the channel is not timed out
 		if f.ID.Time+ib.cfg.ChannelTimeout < ib.progress.Origin.Time {
 			ib.log.Warn("channel is timed out, ignore frame", "channel", f.ID, "id_time", f.ID.Time, "frame", f.FrameNumber)
+			ib.metrics.RecordDroppedFrame("channel_timeout")
 			continue
 		}
 		// check if the channel is not included too soon (otherwise timeouts wouldn't be effective)
 		if f.ID.Time > ib.progress.Origin.Time {
 			ib.log.Warn("channel claims to be from the future, ignore frame", "channel", f.ID, "id_time", f.ID.Time, "frame", f.FrameNumber)
+			ib.metrics.RecordDroppedFrame("channel_future")
 			continue
 		}
 
@@ -116,13 +147,23 @@ func (ib *ChannelBank) IngestData(data []byte) {
 		ib.log.Trace("ingesting frame", "channel", f.ID, "frame_number", f.FrameNumber, "length", len(f.Data))
 		if err := currentCh.AddFrame(f, ib.progress.Origin); err != nil {
 			ib.log.Warn("failed to ingest frame into channel", "channel", f.ID, "frame_number", f.FrameNumber, "err", err)
+			ib.metrics.RecordDroppedFrame("invalid_frame")
 			continue
 		}
 	}
+	ib.metrics.RecordChannelBankSize(ib.totalSize())
 }
 
+// maxReadBufSize is the largest channel data buffer that the channel bank keeps to reuse for the next read.
+// Larger channels are read into a new buffer, to not hold on to the memory of a rare large channel.
+const maxReadBufSize = 10_000_000
+
 // Read the raw data of the first channel, if it's timed-out or closed.
 // Read returns io.EOF if there is nothing new to read.
+//
+// The returned data is only valid until the next Read: the buffer is reused.
+// The channel in reader decodes all data of a channel before the channel bank reads the next channel,
+// since the pipeline only steps the channel bank when the channel in reader is out of data.
 func (ib *ChannelBank) Read() (data []byte, err error) {
 	if len(ib.channelQueue) == 0 {
 		return nil, io.EOF
@@ -135,15 +176,24 @@ func (ib *ChannelBank) Read() (data []byte, err error) {
 	}
 	if ch.IsReady() {
 		ib.log.Debug("channel ready", "channel", first)
+	} else if timedOut {
+		ib.metrics.RecordDroppedChannel("timeout")
 	}
 	if !timedOut && !ch.IsReady() { // check if channel is readya (can then be read)
 		return nil, io.EOF
 	}
 	delete(ib.channels, first)
 	ib.channelQueue = ib.channelQueue[1:]
-	r := ch.Reader()
-	// Suprress error here. io.ReadAll does return nil instead of io.EOF though.
-	data, _ = io.ReadAll(r)
+	ib.metrics.RecordChannelBankSize(ib.totalSize())
+	if !ch.IsReady() {
+		// a timed out channel that is not ready is not reassembled in the read buffer, see Channel.Reader
+		data, _ = io.ReadAll(ch.Reader())
+		return data, nil
+	}
+	data = ch.AppendData(ib.readBuf[:0])
+	if cap(data) <= maxReadBufSize {
+		ib.readBuf = data
+	}
 	return data, nil
 }
 
@@ -184,6 +234,7 @@ func (ib *ChannelBank) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error
 	if ib.progress.Origin.Time+ib.cfg.ChannelTimeout < ib.next.Progress().Origin.Time || ib.progress.Origin.Number <= ib.cfg.Genesis.L1.Number {
 		ib.log.Debug("found reset origin for channel bank", "origin", ib.progress.Origin)
 		ib.resetting = false
+		ib.metrics.RecordChannelBankSize(ib.totalSize())
 		return io.EOF
 	}
 
@@ -37,6 +37,10 @@ type bankTestSetup struct {
 	cb      *ChannelBank
 	out     *MockChannelBankOutput
 	l1      *testutils.MockL1Source
+
+	bankSize  uint64
+	evictions int
+	dropped   []string
 }
 
 type channelBankTestCase struct {
@@ -44,12 +48,14 @@ type channelBankTestCase struct {
 	originTimes    []uint64
 	nextStartsAt   int
 	channelTimeout uint64
+	maxBankSize    uint64
 	fn             func(bt *bankTestSetup)
 }
 
 func (ct *channelBankTestCase) Run(t *testing.T) {
 	cfg := &rollup.Config{
-		ChannelTimeout: ct.channelTimeout,
+		ChannelTimeout:     ct.channelTimeout,
+		MaxChannelBankSize: ct.maxBankSize,
 	}
 
 	bt := &bankTestSetup{
@@ -68,7 +74,12 @@ func (ct *channelBankTestCase) Run(t *testing.T) {
 	}
 
 	bt.out = &MockChannelBankOutput{MockOriginStage{progress: Progress{Origin: bt.origins[ct.nextStartsAt], Closed: false}}}
-	bt.cb = NewChannelBank(testlog.Logger(t, log.LvlError), cfg, bt.out)
+	metrics := &TestMetrics{
+		recordBankSize:     func(size uint64) { bt.bankSize = size },
+		recordBankEviction: func() { bt.evictions += 1 },
+		recordDropped:      func(kind string, reason string) { bt.dropped = append(bt.dropped, kind+":"+reason) },
+	}
+	bt.cb = NewChannelBank(testlog.Logger(t, log.LvlError), cfg, bt.out, metrics)
 
 	ct.fn(bt)
 }
@@ -162,6 +173,10 @@ func (bt *bankTestSetup) assertExpectations() {
 	bt.out.AssertExpectations(bt.t)
 	bt.out.ExpectedCalls = nil
 }
+func (bt *bankTestSetup) assertBankSize(size uint64, evictions int) {
+	require.Equal(bt.t, size, bt.bankSize, "channel bank size")
+	require.Equal(bt.t, evictions, bt.evictions, "channel bank evictions")
+}
 func (bt *bankTestSetup) logf(format string, args ...any) {
 	bt.t.Logf(format, args...)
 }
@@ -279,6 +294,29 @@ func TestL1ChannelBank(t *testing.T) {
 				bt.assertExpectations()
 			},
 		},
+		{
+			name:           "frames of multiple txs in one L1 block",
+			originTimes:    []uint64{101, 102},
+			nextStartsAt:   0,
+			channelTimeout: 3,
+			fn: func(bt *bankTestSetup) {
+				// don't do the whole setup process, just override where the stages are
+				bt.cb.progress = Progress{Origin: bt.origins[0], Closed: false}
+				bt.out.progress = Progress{Origin: bt.origins[0], Closed: false}
+
+				bt.logf("a batcher may submit the frames of a channel in multiple txs, included in any order in the same block")
+				bt.ingestFrames("a:101:2:end!")
+				bt.repeatStep(1, 0, false, nil)
+				bt.ingestFrames("a:101:0:start")
+				bt.repeatStep(1, 0, false, nil)
+				bt.ingestFrames("b:101:0:other!", "a:101:1:middle")
+				bt.logf("the channel is assembled by frame number, and channels are read in the order they were first seen")
+				bt.expectChannel("startmiddleend")
+				bt.expectChannel("other")
+				bt.repeatStep(3, 0, false, nil)
+				bt.assertExpectations()
+			},
+		},
 		{
 			name:           "skip bad frames",
 			originTimes:    []uint64{101, 102},
@@ -302,6 +340,47 @@ func TestL1ChannelBank(t *testing.T) {
 				// Expect the bad frame to render the entire chunk invalid.
 				bt.repeatStep(2, 0, false, nil)
 				bt.assertExpectations()
+
+				bt.ingestData(append([]byte{DerivationVersion0 + 1}, badTx.Bytes()[1:]...))
+				bt.repeatStep(2, 0, false, nil)
+				bt.assertExpectations()
+				require.Equal(bt.t, []string{"frame:parse_error", "frame:bad_version"}, bt.dropped)
+			},
+		},
+		{
+			name:           "evict oldest channels",
+			originTimes:    []uint64{101, 102},
+			nextStartsAt:   0,
+			channelTimeout: 3,
+			maxBankSize:    2*frameOverhead + 100,
+			fn: func(bt *bankTestSetup) {
+				// don't do the whole setup process, just override where the stages are
+				bt.cb.progress = Progress{Origin: bt.origins[0], Closed: false}
+				bt.out.progress = Progress{Origin: bt.origins[0], Closed: false}
+
+				bt.ingestFrames("a:101:0:first")
+				bt.repeatStep(1, 0, false, nil)
+				bt.assertBankSize(frameOverhead+5, 0)
+				bt.ingestFrames("b:101:0:second")
+				bt.repeatStep(1, 0, false, nil)
+				bt.ingestFrames("c:101:0:third")
+				bt.repeatStep(1, 0, false, nil)
+				bt.assertBankSize(3*frameOverhead+16, 0)
+
+				bt.logf("bank is over the limit, the oldest channel is evicted before ingesting more data")
+				bt.ingestFrames("b:101:1:!")
+				bt.assertBankSize(3*frameOverhead+11, 1)
+				bt.expectChannel("second")
+				bt.repeatStep(2, 0, false, nil)
+				bt.assertExpectations()
+				bt.assertBankSize(frameOverhead+5, 1)
+
+				bt.ingestFrames("c:101:1:!")
+				bt.assertBankSize(2*frameOverhead+5, 1)
+				bt.expectChannel("third")
+				bt.repeatStep(2, 0, false, nil)
+				bt.assertExpectations()
+				bt.assertBankSize(0, 1)
 			},
 		},
 	}
@@ -309,3 +388,39 @@ func TestL1ChannelBank(t *testing.T) {
 		t.Run(testCase.name, testCase.Run)
 	}
 }
+
+// BenchmarkChannelBankLargeChannels ingests and reads channels of many large frames, one frame per L1 tx,
+// like a verifier catching up on a chain with high throughput.
+func BenchmarkChannelBankLargeChannels(b *testing.B) {
+	const frameCount, frameSize = 20, 100_000
+	rng := rand.New(rand.NewSource(1234))
+	origin := testutils.RandomBlockRef(rng)
+	id := ChannelID{Time: origin.Time}
+	rng.Read(id.Data[:])
+	var txs [][]byte
+	for i := 0; i < frameCount; i++ {
+		f := Frame{ID: id, FrameNumber: uint16(i), Data: make([]byte, frameSize), IsLast: i == frameCount-1}
+		rng.Read(f.Data)
+		var data bytes.Buffer
+		data.WriteByte(DerivationVersion0)
+		require.NoError(b, f.MarshalBinary(&data))
+		txs = append(txs, data.Bytes())
+	}
+
+	cfg := &rollup.Config{ChannelTimeout: 10}
+	out := &MockChannelBankOutput{MockOriginStage{progress: Progress{Origin: origin}}}
+	cb := NewChannelBank(log.New(), cfg, out, &TestMetrics{})
+	cb.log.SetHandler(log.DiscardHandler())
+	cb.progress = Progress{Origin: origin}
+	b.ReportAllocs()
+	b.SetBytes(frameCount * frameSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, tx := range txs {
+			cb.IngestData(tx)
+		}
+		data, err := cb.Read()
+		require.NoError(b, err)
+		require.Len(b, data, frameCount*frameSize)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -20,9 +21,13 @@ type BatchQueueStage interface {
 
 type ChannelInReader struct {
 	log log.Logger
+	cfg *rollup.Config
 
 	nextBatchFn func() (BatchWithL1InclusionBlock, error)
 
+	// batches of the last read span batch that still have to be passed to the batch queue
+	spanBatches []*BatchData
+
 	progress Progress
 
 	next BatchQueueStage
@@ -31,8 +36,8 @@ type ChannelInReader struct {
 var _ ChannelBankOutput = (*ChannelInReader)(nil)
 
 // NewChannelInReader creates a ChannelInReader, which should be Reset(origin) before use.
-func NewChannelInReader(log log.Logger, next BatchQueueStage) *ChannelInReader {
-	return &ChannelInReader{log: log, next: next}
+func NewChannelInReader(log log.Logger, cfg *rollup.Config, next BatchQueueStage) *ChannelInReader {
+	return &ChannelInReader{log: log, cfg: cfg, next: next}
 }
 
 func (cr *ChannelInReader) Progress() Progress {
@@ -55,6 +60,7 @@ func (cr *ChannelInReader) WriteChannel(data []byte) {
 // resetting any decoding/decompression state to a fresh start.
 func (cr *ChannelInReader) NextChannel() {
 	cr.nextBatchFn = nil
+	cr.spanBatches = nil
 }
 
 func (cr *ChannelInReader) Step(ctx context.Context, outer Progress) error {
@@ -62,6 +68,12 @@ func (cr *ChannelInReader) Step(ctx context.Context, outer Progress) error {
 		return err
 	}
 
+	if len(cr.spanBatches) > 0 {
+		cr.next.AddBatch(cr.spanBatches[0])
+		cr.spanBatches = cr.spanBatches[1:]
+		return nil
+	}
+
 	if cr.nextBatchFn == nil {
 		return io.EOF
 	}
@@ -77,12 +89,22 @@ func (cr *ChannelInReader) Step(ctx context.Context, outer Progress) error {
 		cr.NextChannel()
 		return nil
 	}
+	if batch.Batch.Span != nil {
+		if !cr.cfg.IsSpanBatch(batch.Batch.Span.Timestamp) {
+			cr.log.Warn("span batches are not active, skipping to next channel now", "timestamp", batch.Batch.Span.Timestamp)
+			cr.NextChannel()
+			return nil
+		}
+		cr.spanBatches = batch.Batch.SpanBatches(cr.cfg.BlockTime)
+		return nil
+	}
 	cr.next.AddBatch(batch.Batch)
 	return nil
 }
 
 func (cr *ChannelInReader) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error {
 	cr.nextBatchFn = nil
+	cr.spanBatches = nil
 	cr.progress = cr.next.Progress()
 	return io.EOF
 }
@@ -0,0 +1,52 @@
+package derive
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBatchQueueStage struct {
+	batches []*BatchData
+}
+
+func (f *fakeBatchQueueStage) Progress() Progress {
+	return Progress{}
+}
+
+func (f *fakeBatchQueueStage) AddBatch(batch *BatchData) {
+	f.batches = append(f.batches, batch)
+}
+
+func TestChannelInReaderSpanBatchFork(t *testing.T) {
+	cfg := &rollup.Config{BlockTime: 2, Forks: rollup.ForkSchedule{rollup.ForkSpanBatch: 20}}
+	readSpan := func(timestamp uint64) []*BatchData {
+		next := &fakeBatchQueueStage{}
+		cr := NewChannelInReader(testlog.Logger(t, log.LvlError), cfg, next)
+		span := &BatchData{Span: &SpanBatchV1{Timestamp: timestamp, Blocks: make([]SpanBatchBlock, 2)}}
+		read := false
+		cr.nextBatchFn = func() (BatchWithL1InclusionBlock, error) {
+			if read {
+				return BatchWithL1InclusionBlock{}, io.EOF
+			}
+			read = true
+			return BatchWithL1InclusionBlock{Batch: span}, nil
+		}
+		for i := 0; i < 10; i++ {
+			if err := cr.Step(context.Background(), Progress{}); err == io.EOF {
+				break
+			}
+		}
+		return next.batches
+	}
+
+	require.Empty(t, readSpan(18), "span batches are dropped before the fork activation")
+	batches := readSpan(20)
+	require.Len(t, batches, 2, "span batches are accepted from the fork activation")
+	require.Equal(t, uint64(22), batches[1].Timestamp)
+}
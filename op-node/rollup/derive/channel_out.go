@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -28,6 +29,15 @@ type ChannelOut struct {
 	buf bytes.Buffer
 
 	closed bool
+
+	// spanBatches enables encoding consecutive blocks as span batches
+	spanBatches bool
+	// span batch of the consecutive blocks added since the last flush, nil if none
+	span *SpanBatchV1
+	// hash and time of the last block in the span, and the time between the blocks of the span
+	spanHead      common.Hash
+	spanHeadTime  uint64
+	spanBlockTime uint64
 }
 
 func (co *ChannelOut) ID() ChannelID {
@@ -56,7 +66,21 @@ func NewChannelOut(channelTime uint64) (*ChannelOut, error) {
 	return c, nil
 }
 
-// TODO: reuse ChannelOut for performance
+// NewSpanChannelOut creates a ChannelOut that encodes consecutive blocks as span batches.
+// Experimental: span batches are only accepted by rollups with span batches enabled, see rollup.Config.IsSpanBatch.
+// The blocks of a span are buffered until the channel is flushed or closed, or a non-consecutive block is added.
+func NewSpanChannelOut(channelTime uint64) (*ChannelOut, error) {
+	co, err := NewChannelOut(channelTime)
+	if err != nil {
+		return nil, err
+	}
+	co.spanBatches = true
+	return co, nil
+}
+
+// Reset discards all buffered data, and reopens the channel with a new random ID and the given channel time,
+// reusing the compressor and buffers of the previous channel to avoid allocations.
+// Any frames of the previous channel that were not output yet are lost.
 func (co *ChannelOut) Reset(channelTime uint64) error {
 	co.frame = 0
 	co.offset = 0
@@ -64,6 +88,10 @@ func (co *ChannelOut) Reset(channelTime uint64) error {
 	co.scratch.Reset()
 	co.compress.Reset(&co.buf)
 	co.closed = false
+	co.span = nil
+	co.spanHead = common.Hash{}
+	co.spanHeadTime = 0
+	co.spanBlockTime = 0
 	co.id.Time = channelTime
 	_, err := rand.Read(co.id.Data[:])
 	if err != nil {
@@ -76,7 +104,43 @@ func (co *ChannelOut) AddBlock(block *types.Block) error {
 	if co.closed {
 		return errors.New("already closed")
 	}
-	return blockToBatch(block, co.compress)
+	batch, err := blockToBatch(block)
+	if err != nil {
+		return err
+	}
+	if !co.spanBatches {
+		return rlp.Encode(co.compress, batch)
+	}
+	// start a new span if the block does not continue the current span at the same block time
+	if co.span != nil && (block.ParentHash() != co.spanHead ||
+		(len(co.span.Blocks) > 1 && block.Time() != co.spanHeadTime+co.spanBlockTime)) {
+		if err := co.writeSpan(); err != nil {
+			return err
+		}
+	}
+	if co.span == nil {
+		co.span = &SpanBatchV1{ParentHash: batch.ParentHash, Timestamp: batch.Timestamp}
+	} else if len(co.span.Blocks) == 1 {
+		co.spanBlockTime = block.Time() - co.spanHeadTime
+	}
+	co.span.Blocks = append(co.span.Blocks, SpanBatchBlock{
+		EpochNum:     batch.EpochNum,
+		EpochHash:    batch.EpochHash,
+		Transactions: batch.Transactions,
+	})
+	co.spanHead = block.Hash()
+	co.spanHeadTime = block.Time()
+	return nil
+}
+
+// writeSpan writes the buffered span batch, if any, to the compression stage.
+func (co *ChannelOut) writeSpan() error {
+	if co.span == nil {
+		return nil
+	}
+	span := co.span
+	co.span = nil
+	return rlp.Encode(co.compress, &BatchData{Span: span})
 }
 
 // ReadyBytes returns the number of bytes that the channel out can immediately output into a frame.
@@ -89,6 +153,9 @@ func (co *ChannelOut) ReadyBytes() int {
 // Flush flushes the internal compression stage to the ready buffer. It enables pulling a larger & more
 // complete frame. It reduces the compression efficiency.
 func (co *ChannelOut) Flush() error {
+	if err := co.writeSpan(); err != nil {
+		return err
+	}
 	return co.compress.Flush()
 }
 
@@ -97,9 +164,15 @@ func (co *ChannelOut) Close() error {
 		return errors.New("already closed")
 	}
 	co.closed = true
+	if err := co.writeSpan(); err != nil {
+		return err
+	}
 	return co.compress.Close()
 }
 
+// FrameV0OverHeadSize is the fixed size of a frame, besides its data: 32 + 8 + 2 + 4 + 1 = 47 bytes.
+const FrameV0OverHeadSize = 47
+
 // OutputFrame writes a frame to w with a given max size
 // Use `ReadyBytes`, `Flush`, and `Close` to modify the ready buffer.
 // Returns io.EOF when the channel is closed & there are no more frames
@@ -113,9 +186,8 @@ func (co *ChannelOut) OutputFrame(w *bytes.Buffer, maxSize uint64) error {
 
 	// Copy data from the local buffer into the frame data buffer
 	// Don't go past the maxSize with the fixed frame overhead.
-	// Fixed overhead: 32 + 8 + 2 + 4 + 1  = 47 bytes.
 	// Add one extra byte for the version byte (for the entire L1 tx though)
-	maxDataSize := maxSize - 47 - 1
+	maxDataSize := maxSize - FrameV0OverHeadSize - 1
 	if maxDataSize > uint64(co.buf.Len()) {
 		maxDataSize = uint64(co.buf.Len())
 		// If we are closed & will not spill past the current frame
@@ -142,8 +214,8 @@ func (co *ChannelOut) OutputFrame(w *bytes.Buffer, maxSize uint64) error {
 	}
 }
 
-// blockToBatch writes the raw block bytes (after batch encoding) to the writer
-func blockToBatch(block *types.Block, w io.Writer) error {
+// blockToBatch converts the block into a batch
+func blockToBatch(block *types.Block) (*BatchData, error) {
 	var opaqueTxs []hexutil.Bytes
 	for _, tx := range block.Transactions() {
 		if tx.Type() == types.DepositTxType {
@@ -151,23 +223,22 @@ func blockToBatch(block *types.Block, w io.Writer) error {
 		}
 		otx, err := tx.MarshalBinary()
 		if err != nil {
-			return err // TODO: wrap err
+			return nil, err // TODO: wrap err
 		}
 		opaqueTxs = append(opaqueTxs, otx)
 	}
 	l1InfoTx := block.Transactions()[0]
 	l1Info, err := L1InfoDepositTxData(l1InfoTx.Data())
 	if err != nil {
-		return err // TODO: wrap err
+		return nil, err // TODO: wrap err
 	}
 
-	batch := &BatchData{BatchV1{
+	return &BatchData{BatchV1: BatchV1{
 		ParentHash:   block.ParentHash(),
 		EpochNum:     rollup.Epoch(l1Info.Number),
 		EpochHash:    l1Info.BlockHash,
 		Timestamp:    block.Time(),
 		Transactions: opaqueTxs,
 	},
-	}
-	return rlp.Encode(w, batch)
+	}, nil
 }
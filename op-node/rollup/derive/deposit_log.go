@@ -131,8 +131,9 @@ func unmarshalDepositVersion0(dep *types.DepositTx, to common.Address, opaqueDat
 	// The data may be padded to a multiple of 32 bytes
 	txDataLen := uint64(len(opaqueData)) - offset
 
-	// remaining bytes fill the data
-	dep.Data = opaqueData[offset : offset+txDataLen]
+	// remaining bytes fill the data, copied to not retain the log data
+	dep.Data = make([]byte, txDataLen)
+	copy(dep.Data, opaqueData[offset:offset+txDataLen])
 
 	return nil
 }
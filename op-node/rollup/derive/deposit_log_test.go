@@ -118,3 +118,115 @@ func TestDeriveUserDeposits(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalLogEventMalformed(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	validLog := func() *types.Log {
+		dep := testutils.GenerateDeposit(testutils.RandomHash(rng), rng)
+		dep.Data = testutils.RandomData(rng, 40)
+		return MarshalDepositLogEvent(MockDepositContractAddr, dep)
+	}
+	testCases := []struct {
+		name    string
+		corrupt func(ev *types.Log)
+		errMsg  string
+	}{
+		{"missing topic", func(ev *types.Log) { ev.Topics = ev.Topics[:3] }, "expected 4 event topics"},
+		{"extra topic", func(ev *types.Log) { ev.Topics = append(ev.Topics, common.Hash{}) }, "expected 4 event topics"},
+		{"wrong selector", func(ev *types.Log) { ev.Topics[0] = common.Hash{0xde, 0xad} }, "invalid deposit event selector"},
+		{"unknown version", func(ev *types.Log) { ev.Topics[3] = common.Hash{31: 1} }, "invalid deposit version"},
+		{"no data", func(ev *types.Log) { ev.Data = nil }, "incomplate opaqueData slice header"},
+		{"truncated header", func(ev *types.Log) { ev.Data = ev.Data[:63] }, "incomplate opaqueData slice header"},
+		{"unpadded data", func(ev *types.Log) { ev.Data = ev.Data[:len(ev.Data)-1] }, "multiple of 32 bytes"},
+		{"bad offset", func(ev *types.Log) { ev.Data[31] = 64 }, "invalid opaqueData slice header offset"},
+		{"huge offset", func(ev *types.Log) { ev.Data[0] = 1 }, "invalid opaqueData slice header offset"},
+		{"length exceeds data", func(ev *types.Log) { ev.Data[63] += 32 }, "invalid opaqueData slice header length"},
+		{"huge length", func(ev *types.Log) { ev.Data[32] = 1 }, "invalid opaqueData slice header length"},
+		{"excess padding", func(ev *types.Log) { ev.Data = append(ev.Data, make([]byte, 32)...) }, "invalid opaqueData slice header length"},
+		{"short opaque data", func(ev *types.Log) {
+			ev.Data = ev.Data[:128]
+			ev.Data[63] = 64
+		}, "unexpected opaqueData length"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := validLog()
+			_, err := UnmarshalDepositLogEvent(ev)
+			require.NoError(t, err)
+			tc.corrupt(ev)
+			_, err = UnmarshalDepositLogEvent(ev)
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestUnmarshalLogEventCopiesData(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	dep := testutils.GenerateDeposit(testutils.RandomHash(rng), rng)
+	ev := MarshalDepositLogEvent(MockDepositContractAddr, dep)
+	out, err := UnmarshalDepositLogEvent(ev)
+	require.NoError(t, err)
+	data := common.CopyBytes(out.Data)
+	for i := range ev.Data {
+		ev.Data[i] = 0xff
+	}
+	require.Equal(t, data, out.Data, "deposit data must not alias the log data")
+}
+
+func TestUserDepositsLookalikes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	blockHash := testutils.RandomHash(rng)
+	receipts, expectedDeposits := makeReceipts(rng, blockHash, MockDepositContractAddr,
+		[]receiptData{{true, []bool{true, false}}, {true, []bool{true, true}}})
+
+	// a valid deposit event, emitted by a different contract
+	lookalike := testutils.GenerateDeposit(testutils.RandomHash(rng), rng)
+	receipts[0].Logs = append(receipts[0].Logs, MarshalDepositLogEvent(testutils.RandomAddress(rng), lookalike))
+	// a log of the deposit contract that is not a deposit event
+	receipts[1].Logs = append(receipts[1].Logs, testutils.GenerateLog(MockDepositContractAddr, []common.Hash{testutils.RandomHash(rng)}, nil))
+	// an anonymous log of the deposit contract
+	receipts[1].Logs = append(receipts[1].Logs, testutils.GenerateLog(MockDepositContractAddr, nil, testutils.RandomData(rng, 64)))
+
+	got, err := UserDeposits(receipts, MockDepositContractAddr)
+	require.NoError(t, err)
+	require.Equal(t, expectedDeposits, got)
+}
+
+func TestUserDepositsMalformed(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	blockHash := testutils.RandomHash(rng)
+	receipts, expectedDeposits := makeReceipts(rng, blockHash, MockDepositContractAddr,
+		[]receiptData{{true, []bool{true, true}}, {true, []bool{true}}})
+	// corrupt the second deposit of the first receipt
+	receipts[0].Logs[1].Data = receipts[0].Logs[1].Data[:40]
+
+	got, err := UserDeposits(receipts, MockDepositContractAddr)
+	require.ErrorContains(t, err, "malformatted L1 deposit log in receipt 0, log 1")
+	require.Equal(t, []*types.DepositTx{expectedDeposits[0], expectedDeposits[2]}, got,
+		"valid deposits are still returned")
+
+	_, err = DeriveDeposits(receipts, MockDepositContractAddr)
+	require.ErrorContains(t, err, "malformatted L1 deposit log")
+}
+
+func FuzzUnmarshalDepositLogEvent(f *testing.F) {
+	rng := rand.New(rand.NewSource(1234))
+	for i := 0; i < 5; i++ {
+		dep := testutils.GenerateDeposit(testutils.RandomHash(rng), rng)
+		dep.Data = testutils.RandomData(rng, rng.Intn(100))
+		f.Add(MarshalDepositLogEvent(MockDepositContractAddr, dep).Data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ev := testutils.GenerateLog(MockDepositContractAddr,
+			[]common.Hash{DepositEventABIHash, {}, {}, DepositEventVersion0}, data)
+		dep, err := UnmarshalDepositLogEvent(ev)
+		if err != nil {
+			return
+		}
+		// padding and the isCreation flag are parsed leniently, so the log data may not encode back exactly,
+		// but the encoding of a parsed deposit must parse to the same deposit
+		again, err := UnmarshalDepositLogEvent(MarshalDepositLogEvent(MockDepositContractAddr, dep))
+		require.NoError(t, err)
+		require.Equal(t, dep, again)
+	})
+}
@@ -3,30 +3,85 @@ package derive
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// BlockField is a field of an L2 block that is compared against the derived attributes during consolidation.
+// The values are used as metric labels.
+type BlockField string
+
+const (
+	BlockFieldParentHash   BlockField = "parent_hash"
+	BlockFieldTimestamp    BlockField = "timestamp"
+	BlockFieldPrevRandao   BlockField = "prev_randao"
+	BlockFieldFeeRecipient BlockField = "fee_recipient"
+	BlockFieldTransactions BlockField = "transactions"
+)
+
+// FieldMismatch describes a field of a block that does not match the derived attributes.
+type FieldMismatch struct {
+	Field BlockField
+	// Msg describes the mismatch, with the expected and the actual value
+	Msg string
+}
+
+// BlockMismatchError is the error of a block that does not match the derived attributes,
+// with a mismatch for every field that differs.
+type BlockMismatchError struct {
+	Mismatches []FieldMismatch
+}
+
+func (e *BlockMismatchError) Error() string {
+	msgs := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		msgs = append(msgs, m.Msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Fields returns the mismatched fields, in order of comparison.
+func (e *BlockMismatchError) Fields() []BlockField {
+	out := make([]BlockField, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		out = append(out, m.Field)
+	}
+	return out
+}
+
 // AttributesMatchBlock checks if the L2 attributes pre-inputs match the output
-// nil if it is a match. If err is not nil, the error contains the reason for the mismatch
+// nil if it is a match. If err is not nil, it is a *BlockMismatchError with every field that does not match.
 func AttributesMatchBlock(attrs *eth.PayloadAttributes, parentHash common.Hash, block *eth.ExecutionPayload) error {
+	var mismatches []FieldMismatch
+	mismatch := func(field BlockField, format string, args ...any) {
+		mismatches = append(mismatches, FieldMismatch{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
 	if parentHash != block.ParentHash {
-		return fmt.Errorf("parent hash field does not match. expected: %v. got: %v", parentHash, block.ParentHash)
+		mismatch(BlockFieldParentHash, "parent hash field does not match. expected: %v. got: %v", parentHash, block.ParentHash)
 	}
 	if attrs.Timestamp != block.Timestamp {
-		return fmt.Errorf("timestamp field does not match. expected: %v. got: %v", uint64(attrs.Timestamp), block.Timestamp)
+		mismatch(BlockFieldTimestamp, "timestamp field does not match. expected: %v. got: %v", uint64(attrs.Timestamp), block.Timestamp)
 	}
 	if attrs.PrevRandao != block.PrevRandao {
-		return fmt.Errorf("random field does not match. expected: %v. got: %v", attrs.PrevRandao, block.PrevRandao)
+		mismatch(BlockFieldPrevRandao, "random field does not match. expected: %v. got: %v", attrs.PrevRandao, block.PrevRandao)
 	}
-	if len(attrs.Transactions) != len(block.Transactions) {
-		return fmt.Errorf("transaction count does not match. expected: %d. got: %d", len(attrs.Transactions), len(block.Transactions))
+	if attrs.SuggestedFeeRecipient != block.FeeRecipient {
+		mismatch(BlockFieldFeeRecipient, "fee recipient field does not match. expected: %v. got: %v", attrs.SuggestedFeeRecipient, block.FeeRecipient)
 	}
-	for i, otx := range attrs.Transactions {
-		if expect := block.Transactions[i]; !bytes.Equal(otx, expect) {
-			return fmt.Errorf("transaction %d does not match. expected: %v. got: %v", i, expect, otx)
+	if len(attrs.Transactions) != len(block.Transactions) {
+		mismatch(BlockFieldTransactions, "transaction count does not match. expected: %d. got: %d", len(attrs.Transactions), len(block.Transactions))
+	} else {
+		for i, otx := range attrs.Transactions {
+			if got := block.Transactions[i]; !bytes.Equal(otx, got) {
+				mismatch(BlockFieldTransactions, "transaction %d does not match. expected: %v. got: %v", i, otx, got)
+				break
+			}
 		}
 	}
+	if len(mismatches) > 0 {
+		return &BlockMismatchError{Mismatches: mismatches}
+	}
 	return nil
 }
@@ -0,0 +1,91 @@
+package derive
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestAttributesMatchBlock(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	l1Info := testutils.RandomBlockInfo(rng)
+	l1InfoTx, err := L1InfoDepositBytes(3, l1Info)
+	require.NoError(t, err)
+	userTx := testutils.RandomData(rng, 100)
+	parentHash := testutils.RandomHash(rng)
+
+	attrs := &eth.PayloadAttributes{
+		Timestamp:    eth.Uint64Quantity(l1Info.Time() + 6),
+		PrevRandao:   eth.Bytes32(l1Info.MixDigest()),
+		Transactions: []eth.Data{l1InfoTx, userTx},
+		NoTxPool:     true,
+	}
+	block := func() *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{
+			ParentHash:   parentHash,
+			Timestamp:    attrs.Timestamp,
+			PrevRandao:   attrs.PrevRandao,
+			BlockHash:    testutils.RandomHash(rng),
+			Transactions: []eth.Data{l1InfoTx, userTx},
+		}
+	}
+	require.NoError(t, AttributesMatchBlock(attrs, parentHash, block()))
+
+	testCases := []struct {
+		name   string
+		tamper func(b *eth.ExecutionPayload)
+		errMsg string
+		field  BlockField
+	}{
+		{"parent hash", func(b *eth.ExecutionPayload) { b.ParentHash = common.Hash{1} }, "parent hash", BlockFieldParentHash},
+		{"timestamp", func(b *eth.ExecutionPayload) { b.Timestamp++ }, "timestamp", BlockFieldTimestamp},
+		{"prev randao", func(b *eth.ExecutionPayload) { b.PrevRandao[0] ^= 1 }, "random", BlockFieldPrevRandao},
+		{"fee recipient", func(b *eth.ExecutionPayload) { b.FeeRecipient = common.Address{1} }, "fee recipient", BlockFieldFeeRecipient},
+		{"missing tx", func(b *eth.ExecutionPayload) { b.Transactions = b.Transactions[:1] }, "transaction count", BlockFieldTransactions},
+		{"extra tx", func(b *eth.ExecutionPayload) { b.Transactions = append(b.Transactions, userTx) }, "transaction count", BlockFieldTransactions},
+		{"user tx", func(b *eth.ExecutionPayload) {
+			b.Transactions[1] = testutils.RandomData(rng, 100)
+		}, "transaction 1 does not match", BlockFieldTransactions},
+		{"l1 info sequence number", func(b *eth.ExecutionPayload) {
+			tampered, err := L1InfoDepositBytes(4, l1Info)
+			require.NoError(t, err)
+			b.Transactions[0] = tampered
+		}, "transaction 0 does not match", BlockFieldTransactions},
+		{"l1 info origin", func(b *eth.ExecutionPayload) {
+			tampered, err := L1InfoDepositBytes(3, testutils.RandomBlockInfo(rng))
+			require.NoError(t, err)
+			b.Transactions[0] = tampered
+		}, "transaction 0 does not match", BlockFieldTransactions},
+		{"l1 info tx order", func(b *eth.ExecutionPayload) {
+			b.Transactions[0], b.Transactions[1] = b.Transactions[1], b.Transactions[0]
+		}, "transaction 0 does not match", BlockFieldTransactions},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := block()
+			b.Transactions = append([]eth.Data{}, b.Transactions...)
+			tc.tamper(b)
+			err := AttributesMatchBlock(attrs, parentHash, b)
+			require.ErrorContains(t, err, tc.errMsg)
+			var mismatchErr *BlockMismatchError
+			require.ErrorAs(t, err, &mismatchErr)
+			require.Equal(t, []BlockField{tc.field}, mismatchErr.Fields())
+		})
+	}
+
+	t.Run("multiple fields", func(t *testing.T) {
+		b := block()
+		b.Timestamp++
+		b.FeeRecipient = common.Address{1}
+		b.Transactions = []eth.Data{userTx, l1InfoTx}
+		var mismatchErr *BlockMismatchError
+		require.ErrorAs(t, AttributesMatchBlock(attrs, parentHash, b), &mismatchErr)
+		require.Equal(t, []BlockField{BlockFieldTimestamp, BlockFieldFeeRecipient, BlockFieldTransactions}, mismatchErr.Fields())
+		require.ErrorContains(t, mismatchErr, "transaction 0 does not match")
+	})
+}
@@ -27,6 +27,26 @@ type Engine interface {
 	L2BlockRefByHash(ctx context.Context, l2Hash common.Hash) (eth.L2BlockRef, error)
 }
 
+// UnsafeBackfillSource provides the L2 payloads that are missing between the unsafe head
+// and the queued unsafe payloads, e.g. the L2 engine itself or the L2 RPC of another node.
+type UnsafeBackfillSource interface {
+	PayloadByHash(context.Context, common.Hash) (*eth.ExecutionPayload, error)
+}
+
+// unsafeGap identifies a gap between the unsafe head and the next queued unsafe payload.
+type unsafeGap struct {
+	head common.Hash
+	next common.Hash
+}
+
+// TrustedAnchor identifies a trusted L2 block that derivation can start from instead of the L2 genesis,
+// e.g. to sync a verifier from a snapshot of the L2 chain.
+// The anchor block must be the first block of its epoch, and must be available in the engine.
+type TrustedAnchor struct {
+	L2Hash       common.Hash `json:"l2_hash"`
+	L1OriginHash common.Hash `json:"l1_origin_hash"`
+}
+
 // Max memory used for buffering unsafe payloads
 const maxUnsafePayloadsMemory = 500 * 1024 * 1024
 
@@ -72,6 +92,18 @@ type EngineQueue struct {
 	// Tracks which L2 blocks where last derived from which L1 block. At most finalityLookback large.
 	finalityData []FinalityData
 
+	// trustedAnchor is the L2 block to not reset derivation past, nil if derivation may reset to genesis
+	trustedAnchor *TrustedAnchor
+
+	// source to fetch missing ancestors of queued unsafe payloads from, nil to not backfill
+	unsafeBackfill      UnsafeBackfillSource
+	unsafeBackfillDepth uint64
+	// the gap that was last backfilled, to not retry a backfill that gave up until the gap changes
+	lastUnsafeGap unsafeGap
+
+	// maximum number of consecutive unsafe payloads to insert per forkchoice update, 0 or 1 to update for every payload
+	unsafePayloadBatch uint64
+
 	engine Engine
 
 	metrics Metrics
@@ -81,7 +113,7 @@ var _ AttributesQueueOutput = (*EngineQueue)(nil)
 
 // NewEngineQueue creates a new EngineQueue, which should be Reset(origin) before use.
 func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics Metrics) *EngineQueue {
-	return &EngineQueue{
+	eq := &EngineQueue{
 		log:          log,
 		cfg:          cfg,
 		engine:       engine,
@@ -92,6 +124,15 @@ func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics M
 			SizeFn:  payloadMemSize,
 		},
 	}
+	eq.unsafePayloads.OnDrop = eq.unsafePayloadDropped
+	return eq
+}
+
+// unsafePayloadDropped reports an unsafe payload that was dropped from the queue,
+// e.g. because the queue is full or because it could not be loaded from the spill store.
+func (eq *EngineQueue) unsafePayloadDropped(id eth.BlockID, reason string, err error) {
+	eq.log.Warn("dropped queued unsafe payload", "id", id, "reason", reason, "err", err)
+	eq.metrics.RecordUnsafePayloadRejected(reason)
 }
 
 func (eq *EngineQueue) Progress() Progress {
@@ -103,6 +144,62 @@ func (eq *EngineQueue) SetUnsafeHead(head eth.L2BlockRef) {
 	eq.metrics.RecordL2Ref("l2_unsafe", head)
 }
 
+// SpillUnsafePayloads makes the unsafe payloads queue spill payloads to the given store,
+// up to maxSize, when the queue exceeds its memory limit, rather than dropping the payloads.
+func (eq *EngineQueue) SpillUnsafePayloads(store PayloadStore, maxSize uint64) {
+	eq.unsafePayloads.Spill = store
+	eq.unsafePayloads.MaxSpillSize = maxSize
+}
+
+// SetUnsafeBackfill makes the engine queue fetch the payloads that are missing between the unsafe head
+// and the first queued unsafe payload from the given source, by walking back the parent hashes of the queued payload.
+// The engine queue gives up on gaps of more than maxDepth payloads, or that cannot be filled from the source.
+// Nil disables backfilling.
+func (eq *EngineQueue) SetUnsafeBackfill(source UnsafeBackfillSource, maxDepth uint64) {
+	eq.unsafeBackfill = source
+	eq.unsafeBackfillDepth = maxDepth
+}
+
+// SetUnsafePayloadBatch makes the engine queue insert up to maxSize consecutive queued unsafe payloads
+// with engine_newPayload, and make the last one canonical with a single forkchoice update,
+// rather than updating the forkchoice for every payload. Payloads are only batched when more than one is queued.
+// A maxSize of 0 or 1 disables batching.
+func (eq *EngineQueue) SetUnsafePayloadBatch(maxSize uint64) {
+	eq.unsafePayloadBatch = maxSize
+}
+
+// SetTrustedAnchor makes the engine queue treat the given L2 block like the L2 genesis when resetting:
+// it is always considered safe, and derivation is never reset to a block before it.
+// Nil removes the anchor.
+func (eq *EngineQueue) SetTrustedAnchor(anchor *TrustedAnchor) {
+	eq.trustedAnchor = anchor
+}
+
+// LowestQueuedUnsafeBlock returns the block of the first queued unsafe payload,
+// or a zeroed reference if there is none, or if it cannot be decoded.
+// The unsafe payloads between the unsafe head and this block are missing if it is not the next block.
+func (eq *EngineQueue) LowestQueuedUnsafeBlock() eth.L2BlockRef {
+	payload := eq.unsafePayloads.Peek()
+	if payload == nil {
+		return eth.L2BlockRef{}
+	}
+	ref, err := PayloadToBlockRef(payload, &eq.cfg.Genesis)
+	if err != nil {
+		return eth.L2BlockRef{}
+	}
+	return ref
+}
+
+// QueuedUnsafePayloads returns the number of unsafe payloads that are queued to be processed, including spilled payloads.
+func (eq *EngineQueue) QueuedUnsafePayloads() int {
+	return eq.unsafePayloads.Len()
+}
+
+// HasUnsafePayload returns true if an unsafe payload with the given block hash is queued to be processed.
+func (eq *EngineQueue) HasUnsafePayload(hash common.Hash) bool {
+	return eq.unsafePayloads.Has(hash)
+}
+
 func (eq *EngineQueue) AddUnsafePayload(payload *eth.ExecutionPayload) {
 	if payload == nil {
 		eq.log.Warn("cannot add nil unsafe payload")
@@ -214,22 +311,37 @@ func (eq *EngineQueue) tryNextUnsafePayload(ctx context.Context) error {
 	if uint64(first.BlockNumber) <= eq.safeHead.Number {
 		eq.log.Info("skipping unsafe payload, since it is older than safe head", "safe", eq.safeHead.ID(), "unsafe", first.ID(), "payload", first.ID())
 		eq.unsafePayloads.Pop()
+		eq.metrics.RecordUnsafePayloadRejected("too_old")
 		return nil
 	}
 
 	// TODO: once we support snap-sync we can remove this condition, and handle the "SYNCING" status of the execution engine.
 	if first.ParentHash != eq.unsafeHead.Hash {
+		gap := unsafeGap{head: eq.unsafeHead.Hash, next: first.BlockHash}
+		if uint64(first.BlockNumber) > eq.unsafeHead.Number+1 && eq.unsafeBackfill != nil && eq.lastUnsafeGap != gap {
+			if err := eq.backfillUnsafeGap(ctx, first); err != nil {
+				return err
+			}
+			eq.lastUnsafeGap = gap
+			return nil
+		}
 		if uint64(first.BlockNumber) == eq.unsafeHead.Number+1 {
 			eq.log.Info("skipping unsafe payload, since it does not build onto the existing unsafe chain", "safe", eq.safeHead.ID(), "unsafe", first.ID(), "payload", first.ID())
 			eq.unsafePayloads.Pop()
+			eq.metrics.RecordUnsafePayloadRejected("parent_unknown")
 		}
 		return io.EOF // time to go to next stage if we cannot process the first unsafe payload
 	}
 
+	if eq.unsafePayloadBatch > 1 && eq.unsafePayloads.Len() > 1 {
+		return eq.insertUnsafePayloadBatch(ctx)
+	}
+
 	ref, err := PayloadToBlockRef(first, &eq.cfg.Genesis)
 	if err != nil {
 		eq.log.Error("failed to decode L2 block ref from payload", "err", err)
 		eq.unsafePayloads.Pop()
+		eq.metrics.RecordUnsafePayloadRejected("invalid_payload")
 		return nil
 	}
 
@@ -267,6 +379,7 @@ func (eq *EngineQueue) tryNextUnsafePayload(ctx context.Context) error {
 	}
 	if status.Status != eth.ExecutionValid {
 		eq.unsafePayloads.Pop()
+		eq.metrics.RecordUnsafePayloadRejected("invalid_payload")
 		return NewTemporaryError(fmt.Errorf("cannot process unsafe payload: new - %v; parent: %v; err: %v",
 			first.ID(), first.ParentID(), eth.ForkchoiceUpdateErr(fcRes.PayloadStatus)))
 	}
@@ -279,6 +392,93 @@ func (eq *EngineQueue) tryNextUnsafePayload(ctx context.Context) error {
 	return nil
 }
 
+// insertUnsafePayloadBatch inserts the consecutive queued unsafe payloads that build on the unsafe head,
+// up to the batch size, and then makes the last inserted payload canonical with a single forkchoice update.
+// The unsafe head is only updated once the forkchoice update is valid: if it fails,
+// the inserted payloads are queued again, to be retried.
+func (eq *EngineQueue) insertUnsafePayloadBatch(ctx context.Context) error {
+	head := eq.unsafeHead
+	var inserted []*eth.ExecutionPayload
+	for uint64(len(inserted)) < eq.unsafePayloadBatch && eq.unsafePayloads.Len() > 0 {
+		payload := eq.unsafePayloads.Peek()
+		if payload.ParentHash != head.Hash {
+			break
+		}
+		ref, err := PayloadToBlockRef(payload, &eq.cfg.Genesis)
+		if err != nil {
+			eq.log.Error("failed to decode L2 block ref from payload", "err", err)
+			eq.unsafePayloads.Pop()
+			eq.metrics.RecordUnsafePayloadRejected("invalid_payload")
+			break
+		}
+		status, err := eq.engine.NewPayload(ctx, payload)
+		if err != nil {
+			if len(inserted) == 0 {
+				return NewTemporaryError(fmt.Errorf("failed to update insert payload: %v", err))
+			}
+			eq.log.Warn("failed to insert unsafe payload, continuing with the payloads that were inserted", "payload", payload.ID(), "err", err)
+			break
+		}
+		if status.Status != eth.ExecutionValid {
+			eq.unsafePayloads.Pop()
+			eq.metrics.RecordUnsafePayloadRejected("invalid_payload")
+			if len(inserted) == 0 {
+				return NewTemporaryError(fmt.Errorf("cannot process unsafe payload: new - %v; parent: %v; err: %v",
+					payload.ID(), payload.ParentID(), eth.NewPayloadErr(payload, status)))
+			}
+			eq.log.Warn("unsafe payload is invalid, continuing with the payloads that were inserted", "payload", payload.ID(), "status", status.Status)
+			break
+		}
+		inserted = append(inserted, eq.unsafePayloads.Pop())
+		head = ref
+	}
+	if len(inserted) == 0 {
+		return nil
+	}
+
+	fc := eth.ForkchoiceState{
+		HeadBlockHash:      head.Hash,
+		SafeBlockHash:      eq.safeHead.Hash,
+		FinalizedBlockHash: eq.finalized.Hash,
+	}
+	fcRes, err := eq.engine.ForkchoiceUpdate(ctx, &fc, nil)
+	if err != nil || fcRes.PayloadStatus.Status != eth.ExecutionValid {
+		eq.requeueUnsafePayloads(inserted)
+	}
+	if err != nil {
+		var inputErr eth.InputError
+		if errors.As(err, &inputErr) {
+			switch inputErr.Code {
+			case eth.InvalidForkchoiceState:
+				return NewResetError(fmt.Errorf("forkchoice update of unsafe payload batch was inconsistent with engine, need reset to resolve: %w", inputErr.Unwrap()))
+			default:
+				return NewTemporaryError(fmt.Errorf("unexpected error code in forkchoice-updated response: %w", err))
+			}
+		} else {
+			return NewTemporaryError(fmt.Errorf("failed to update forkchoice to unsafe payload batch: %w", err))
+		}
+	}
+	if fcRes.PayloadStatus.Status != eth.ExecutionValid {
+		return NewTemporaryError(fmt.Errorf("cannot make unsafe payload batch canonical: head %v; err: %v",
+			head, eth.ForkchoiceUpdateErr(fcRes.PayloadStatus)))
+	}
+	eq.unsafeHead = head
+	eq.metrics.RecordL2Ref("l2_unsafe", head)
+	eq.metrics.RecordUnsafePayloadBatch(len(inserted))
+	eq.log.Trace("Executed unsafe payload batch", "head", head, "payloads", len(inserted))
+	eq.logSyncProgress("unsafe payload batch from sequencer")
+	return nil
+}
+
+// requeueUnsafePayloads adds payloads that were inserted, but not made canonical, back to the unsafe payloads queue.
+func (eq *EngineQueue) requeueUnsafePayloads(payloads []*eth.ExecutionPayload) {
+	for _, p := range payloads {
+		if err := eq.unsafePayloads.Push(p); err != nil {
+			eq.log.Warn("could not requeue unsafe payload", "payload", p.ID(), "err", err)
+		}
+	}
+}
+
 func (eq *EngineQueue) tryNextSafeAttributes(ctx context.Context) error {
 	if eq.safeHead.Number < eq.unsafeHead.Number {
 		return eq.consolidateNextSafeAttributes(ctx)
@@ -305,7 +505,13 @@ func (eq *EngineQueue) consolidateNextSafeAttributes(ctx context.Context) error
 		return NewTemporaryError(fmt.Errorf("failed to get existing unsafe payload to compare against derived attributes from L1: %v", err))
 	}
 	if err := AttributesMatchBlock(eq.safeAttributes[0], eq.safeHead.Hash, payload); err != nil {
-		eq.log.Warn("L2 reorg: existing unsafe block does not match derived attributes from L1", "err", err)
+		var mismatchErr *BlockMismatchError
+		if errors.As(err, &mismatchErr) {
+			for _, field := range mismatchErr.Fields() {
+				eq.metrics.RecordConsolidationMismatch(string(field))
+			}
+		}
+		eq.log.Warn("L2 reorg: existing unsafe block does not match derived attributes from L1", "block", payload.ID(), "err", err)
 		// geth cannot wind back a chain without reorging to a new, previously non-canonical, block
 		return eq.forceNextSafeAttributes(ctx)
 	}
@@ -315,6 +521,7 @@ func (eq *EngineQueue) consolidateNextSafeAttributes(ctx context.Context) error
 	}
 	eq.safeHead = ref
 	eq.metrics.RecordL2Ref("l2_safe", ref)
+	eq.metrics.RecordProcessedBatch(ref.Time)
 	// unsafe head stays the same, we did not reorg the chain.
 	eq.safeAttributes = eq.safeAttributes[1:]
 	eq.postProcessSafeL2()
@@ -370,6 +577,7 @@ func (eq *EngineQueue) forceNextSafeAttributes(ctx context.Context) error {
 	eq.unsafeHead = ref
 	eq.metrics.RecordL2Ref("l2_safe", ref)
 	eq.metrics.RecordL2Ref("l2_unsafe", ref)
+	eq.metrics.RecordProcessedBatch(ref.Time)
 	eq.safeAttributes = eq.safeAttributes[1:]
 	eq.postProcessSafeL2()
 	eq.logSyncProgress("processed safe block derived from L1")
@@ -377,13 +585,72 @@ func (eq *EngineQueue) forceNextSafeAttributes(ctx context.Context) error {
 	return nil
 }
 
+// backfillUnsafeGap fetches the payloads between the unsafe head and the given first queued payload
+// from the backfill source, and queues them if they connect the queued payload to the unsafe head.
+// An error is only returned if the backfill should be retried.
+func (eq *EngineQueue) backfillUnsafeGap(ctx context.Context, first *eth.ExecutionPayload) error {
+	size := uint64(first.BlockNumber) - eq.unsafeHead.Number - 1
+	if size > eq.unsafeBackfillDepth {
+		eq.log.Debug("not backfilling unsafe payloads, gap is too large", "unsafe", eq.unsafeHead, "next", first.ID(), "size", size, "max", eq.unsafeBackfillDepth)
+		eq.metrics.RecordUnsafeBackfill("too_deep", 0)
+		return nil
+	}
+	payloads := make([]*eth.ExecutionPayload, 0, size)
+	parent := first.ParentHash
+	for i := uint64(0); i < size; i++ {
+		payload, err := eq.unsafeBackfill.PayloadByHash(ctx, parent)
+		if errors.Is(err, ethereum.NotFound) {
+			eq.log.Debug("cannot backfill unsafe payloads, ancestor not found", "unsafe", eq.unsafeHead, "next", first.ID(), "missing", parent)
+			eq.metrics.RecordUnsafeBackfill("not_found", 0)
+			return nil
+		} else if err != nil {
+			eq.metrics.RecordUnsafeBackfill("error", 0)
+			return NewTemporaryError(fmt.Errorf("failed to backfill unsafe payload %s: %w", parent, err))
+		}
+		payloads = append(payloads, payload)
+		parent = payload.ParentHash
+	}
+	if parent != eq.unsafeHead.Hash {
+		eq.log.Debug("cannot backfill unsafe payloads, gap does not build on unsafe head", "unsafe", eq.unsafeHead, "next", first.ID(), "parent", parent)
+		eq.metrics.RecordUnsafeBackfill("disconnected", 0)
+		return nil
+	}
+	eq.log.Info("backfilled unsafe payloads", "unsafe", eq.unsafeHead, "next", first.ID(), "count", len(payloads))
+	for _, payload := range payloads {
+		eq.AddUnsafePayload(payload)
+	}
+	eq.metrics.RecordUnsafeBackfill("connected", len(payloads))
+	return nil
+}
+
 // ResetStep Walks the L2 chain backwards until it finds an L2 block whose L1 origin is canonical.
 // The unsafe head is set to the head of the L2 chain, unless the existing safe head is not canonical.
+// If a trusted anchor is set, the L2 chain is not walked back past the anchor.
 func (eq *EngineQueue) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error {
+	var anchor *eth.L2BlockRef
+	if eq.trustedAnchor != nil {
+		ref, err := eq.engine.L2BlockRefByHash(ctx, eq.trustedAnchor.L2Hash)
+		if errors.Is(err, ethereum.NotFound) {
+			return NewCriticalError(fmt.Errorf("trusted anchor %s is not available in the engine", eq.trustedAnchor.L2Hash))
+		} else if err != nil {
+			return NewTemporaryError(fmt.Errorf("failed to find the trusted anchor %s: %w", eq.trustedAnchor.L2Hash, err))
+		}
+		if ref.L1Origin.Hash != eq.trustedAnchor.L1OriginHash {
+			return NewCriticalError(fmt.Errorf("trusted anchor %s has L1 origin %s, expected %s", ref, ref.L1Origin, eq.trustedAnchor.L1OriginHash))
+		}
+		if ref.SequenceNumber != 0 {
+			return NewCriticalError(fmt.Errorf("trusted anchor %s is not the first block of its epoch, sequence number: %d", ref, ref.SequenceNumber))
+		}
+		anchor = &ref
+	}
 	finalized, err := eq.engine.L2BlockRefByLabel(ctx, eth.Finalized)
 	if errors.Is(err, ethereum.NotFound) {
-		// default to genesis if we have not finalized anything before.
-		finalized, err = eq.engine.L2BlockRefByHash(ctx, eq.cfg.Genesis.L2.Hash)
+		// default to the trusted anchor, or genesis, if we have not finalized anything before.
+		if anchor != nil {
+			finalized, err = *anchor, nil
+		} else {
+			finalized, err = eq.engine.L2BlockRefByHash(ctx, eq.cfg.Genesis.L2.Hash)
+		}
 	}
 	if err != nil {
 		return NewTemporaryError(fmt.Errorf("failed to find the finalized L2 block: %w", err))
@@ -393,7 +660,7 @@ func (eq *EngineQueue) ResetStep(ctx context.Context, l1Fetcher L1Fetcher) error
 	if err != nil {
 		return NewTemporaryError(fmt.Errorf("failed to find the L2 Head block: %w", err))
 	}
-	unsafe, safe, err := sync.FindL2Heads(ctx, prevUnsafe, eq.cfg.SeqWindowSize, l1Fetcher, eq.engine, &eq.cfg.Genesis)
+	unsafe, safe, err := sync.FindL2Heads(ctx, prevUnsafe, eq.cfg.SeqWindowSize, l1Fetcher, eq.engine, &eq.cfg.Genesis, anchor)
 	if err != nil {
 		return NewTemporaryError(fmt.Errorf("failed to find the L2 Heads to start from: %w", err))
 	}
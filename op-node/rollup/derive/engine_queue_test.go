@@ -1,6 +1,8 @@
 package derive
 
 import (
+	"context"
+	"io"
 	"math/rand"
 	"testing"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
@@ -143,3 +146,317 @@ func TestEngineQueue_Finalize(t *testing.T) {
 	l1F.AssertExpectations(t)
 	eng.AssertExpectations(t)
 }
+
+func TestEngineQueue_ConsolidateUnsafe(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+
+	// L1 origin A, and L2 genesis A0. The verifier receives an unsafe block A1 through gossip,
+	// and then derives a safe block A1 from L1 that either matches or conflicts with it.
+	l1Info := testutils.RandomBlockInfo(rng)
+	refA := eth.L1BlockRef{Hash: l1Info.Hash(), Number: l1Info.NumberU64(), ParentHash: l1Info.ParentHash(), Time: l1Info.Time()}
+	refA0 := eth.L2BlockRef{
+		Hash:     testutils.RandomHash(rng),
+		Number:   0,
+		Time:     refA.Time,
+		L1Origin: refA.ID(),
+	}
+	cfg := &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1:     refA.ID(),
+			L2:     refA0.ID(),
+			L2Time: refA0.Time,
+		},
+		BlockTime:     2,
+		SeqWindowSize: 2,
+	}
+	l1InfoTx, err := L1InfoDepositBytes(1, l1Info)
+	require.NoError(t, err)
+	makePayload := func(txs ...eth.Data) *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{
+			ParentHash:   refA0.Hash,
+			BlockNumber:  eth.Uint64Quantity(refA0.Number + 1),
+			Timestamp:    eth.Uint64Quantity(refA0.Time + cfg.BlockTime),
+			PrevRandao:   eth.Bytes32(l1Info.MixDigest()),
+			BlockHash:    testutils.RandomHash(rng),
+			Transactions: append([]eth.Data{l1InfoTx}, txs...),
+		}
+	}
+	unsafeA1 := makePayload(testutils.RandomData(rng, 100))
+	unsafeRefA1, err := PayloadToBlockRef(unsafeA1, &cfg.Genesis)
+	require.NoError(t, err)
+	genesisFC := &eth.ForkchoiceState{HeadBlockHash: refA0.Hash, SafeBlockHash: refA0.Hash, FinalizedBlockHash: refA0.Hash}
+	valid := &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid}}
+
+	setup := func(t *testing.T) (*EngineQueue, *testutils.MockEngine) {
+		eng := &testutils.MockEngine{}
+		eq := NewEngineQueue(testlog.Logger(t, log.LvlInfo), cfg, eng, &testutils.RecordingMetrics{})
+		eq.finalized, eq.safeHead, eq.unsafeHead = refA0, refA0, refA0
+
+		// accept the gossiped unsafe block
+		eq.AddUnsafePayload(unsafeA1)
+		eng.ExpectForkchoiceUpdate(genesisFC, nil, valid, nil)
+		eng.ExpectNewPayload(unsafeA1, &eth.PayloadStatusV1{Status: eth.ExecutionValid}, nil)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, unsafeRefA1, eq.UnsafeL2Head())
+		require.Equal(t, refA0, eq.SafeL2Head())
+		eq.metrics.(*testutils.RecordingMetrics).RequireLastL2Ref(t, "l2_unsafe", unsafeRefA1)
+		return eq, eng
+	}
+
+	t.Run("matching safe block", func(t *testing.T) {
+		eq, eng := setup(t)
+		eq.safeAttributes = []*eth.PayloadAttributes{{
+			Timestamp:    unsafeA1.Timestamp,
+			PrevRandao:   unsafeA1.PrevRandao,
+			Transactions: unsafeA1.Transactions,
+			NoTxPool:     true,
+		}}
+		eng.ExpectPayloadByNumber(unsafeRefA1.Number, unsafeA1, nil)
+		require.NoError(t, eq.tryNextSafeAttributes(context.Background()))
+		require.Equal(t, unsafeRefA1, eq.SafeL2Head(), "unsafe block is consolidated into the safe chain")
+		require.Equal(t, unsafeRefA1, eq.UnsafeL2Head())
+		eq.metrics.(*testutils.RecordingMetrics).RequireCount(t, "RecordConsolidationMismatch", 0)
+		eng.AssertExpectations(t)
+	})
+
+	t.Run("conflicting safe block", func(t *testing.T) {
+		eq, eng := setup(t)
+		safeA1 := makePayload(testutils.RandomData(rng, 100))
+		safeRefA1, err := PayloadToBlockRef(safeA1, &cfg.Genesis)
+		require.NoError(t, err)
+		attrs := &eth.PayloadAttributes{
+			Timestamp:    safeA1.Timestamp,
+			PrevRandao:   safeA1.PrevRandao,
+			Transactions: safeA1.Transactions,
+			NoTxPool:     true,
+		}
+		eq.safeAttributes = []*eth.PayloadAttributes{attrs}
+
+		eng.ExpectPayloadByNumber(unsafeRefA1.Number, unsafeA1, nil)
+		// the unsafe block does not match, so the derived block is built on the safe head instead
+		payloadID := eth.PayloadID{1}
+		eng.ExpectForkchoiceUpdate(genesisFC, attrs, &eth.ForkchoiceUpdatedResult{
+			PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid},
+			PayloadID:     &payloadID,
+		}, nil)
+		eng.ExpectGetPayload(payloadID, safeA1, nil)
+		eng.ExpectNewPayload(safeA1, &eth.PayloadStatusV1{Status: eth.ExecutionValid}, nil)
+		// and the forkchoice reorgs the unsafe block out
+		eng.ExpectForkchoiceUpdate(&eth.ForkchoiceState{
+			HeadBlockHash:      safeA1.BlockHash,
+			SafeBlockHash:      safeA1.BlockHash,
+			FinalizedBlockHash: refA0.Hash,
+		}, nil, valid, nil)
+
+		require.NoError(t, eq.tryNextSafeAttributes(context.Background()))
+		require.Equal(t, safeRefA1, eq.SafeL2Head())
+		require.Equal(t, safeRefA1, eq.UnsafeL2Head(), "unsafe block is reorged out")
+		m := eq.metrics.(*testutils.RecordingMetrics)
+		m.RequireLastL2Ref(t, "l2_safe", safeRefA1)
+		require.Equal(t, []eth.L2BlockRef{unsafeRefA1, safeRefA1}, m.L2Refs("l2_unsafe"))
+		mismatches := m.Records("RecordConsolidationMismatch")
+		require.Len(t, mismatches, 1)
+		require.Equal(t, string(BlockFieldTransactions), mismatches[0].Name)
+		eng.AssertExpectations(t)
+	})
+}
+
+func TestEngineQueue_TrustedAnchor(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	origin := testutils.RandomBlockRef(rng)
+	anchorRef := eth.L2BlockRef{
+		Hash:           testutils.RandomHash(rng),
+		Number:         100,
+		ParentHash:     testutils.RandomHash(rng),
+		Time:           origin.Time,
+		L1Origin:       origin.ID(),
+		SequenceNumber: 0,
+	}
+	cfg := &rollup.Config{BlockTime: 1, SeqWindowSize: 2}
+
+	t.Run("wrong L1 origin", func(t *testing.T) {
+		eng := &testutils.MockEngine{}
+		eng.ExpectL2BlockRefByHash(anchorRef.Hash, anchorRef, nil)
+		eq := NewEngineQueue(testlog.Logger(t, log.LvlError), cfg, eng, &TestMetrics{})
+		eq.SetTrustedAnchor(&TrustedAnchor{L2Hash: anchorRef.Hash, L1OriginHash: testutils.RandomHash(rng)})
+		err := eq.ResetStep(context.Background(), &testutils.MockL1Source{})
+		require.ErrorIs(t, err, ErrCritical)
+		eng.AssertExpectations(t)
+	})
+	t.Run("not first block of epoch", func(t *testing.T) {
+		ref := anchorRef
+		ref.SequenceNumber = 1
+		eng := &testutils.MockEngine{}
+		eng.ExpectL2BlockRefByHash(ref.Hash, ref, nil)
+		eq := NewEngineQueue(testlog.Logger(t, log.LvlError), cfg, eng, &TestMetrics{})
+		eq.SetTrustedAnchor(&TrustedAnchor{L2Hash: ref.Hash, L1OriginHash: origin.Hash})
+		err := eq.ResetStep(context.Background(), &testutils.MockL1Source{})
+		require.ErrorIs(t, err, ErrCritical)
+		eng.AssertExpectations(t)
+	})
+}
+
+func TestEngineQueue_UnsafeBackfill(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	head := eth.L2BlockRef{Hash: testutils.RandomHash(rng), Number: 10}
+	payloads := make([]*eth.ExecutionPayload, 0, 3)
+	parent := head.Hash
+	for i := uint64(11); i <= 13; i++ {
+		p := &eth.ExecutionPayload{BlockHash: testutils.RandomHash(rng), ParentHash: parent, BlockNumber: eth.Uint64Quantity(i)}
+		payloads = append(payloads, p)
+		parent = p.BlockHash
+	}
+	next := payloads[2]
+
+	newEngineQueue := func(t *testing.T, source UnsafeBackfillSource, maxDepth uint64, results *[]string) *EngineQueue {
+		metrics := &TestMetrics{recordBackfill: func(result string, _ int) { *results = append(*results, result) }}
+		eq := NewEngineQueue(testlog.Logger(t, log.LvlError), &rollup.Config{}, &testutils.MockEngine{}, metrics)
+		eq.SetUnsafeHead(head)
+		eq.SetUnsafeBackfill(source, maxDepth)
+		eq.AddUnsafePayload(next)
+		return eq
+	}
+
+	t.Run("connected", func(t *testing.T) {
+		var results []string
+		source := &testutils.MockEthClient{}
+		source.ExpectPayloadByHash(payloads[1].BlockHash, payloads[1], nil)
+		source.ExpectPayloadByHash(payloads[0].BlockHash, payloads[0], nil)
+		eq := newEngineQueue(t, source, 2, &results)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, []string{"connected"}, results)
+		require.Equal(t, 3, eq.unsafePayloads.Len())
+		require.Equal(t, payloads[0], eq.unsafePayloads.Peek(), "gap is filled")
+		source.AssertExpectations(t)
+	})
+	t.Run("too deep", func(t *testing.T) {
+		var results []string
+		source := &testutils.MockEthClient{}
+		eq := newEngineQueue(t, source, 1, &results)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.ErrorIs(t, eq.tryNextUnsafePayload(context.Background()), io.EOF, "no retry of the same gap")
+		require.Equal(t, []string{"too_deep"}, results)
+		require.Equal(t, 1, eq.unsafePayloads.Len())
+		source.AssertExpectations(t)
+	})
+	t.Run("not found", func(t *testing.T) {
+		var results []string
+		source := &testutils.MockEthClient{}
+		source.ExpectPayloadByHash(payloads[1].BlockHash, nil, ethereum.NotFound)
+		eq := newEngineQueue(t, source, 2, &results)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.ErrorIs(t, eq.tryNextUnsafePayload(context.Background()), io.EOF, "no retry of the same gap")
+		require.Equal(t, []string{"not_found"}, results)
+		source.AssertExpectations(t)
+	})
+	t.Run("disconnected", func(t *testing.T) {
+		var results []string
+		source := &testutils.MockEthClient{}
+		other := *payloads[0]
+		other.ParentHash = testutils.RandomHash(rng)
+		source.ExpectPayloadByHash(payloads[1].BlockHash, payloads[1], nil)
+		source.ExpectPayloadByHash(payloads[0].BlockHash, &other, nil)
+		eq := newEngineQueue(t, source, 2, &results)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, []string{"disconnected"}, results)
+		require.Equal(t, 1, eq.unsafePayloads.Len(), "payloads that do not connect are not queued")
+		source.AssertExpectations(t)
+	})
+}
+
+func TestEngineQueue_UnsafePayloadBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	l1Info := testutils.RandomBlockInfo(rng)
+	refA0 := eth.L2BlockRef{Hash: testutils.RandomHash(rng), Number: 0, Time: l1Info.Time()}
+	cfg := &rollup.Config{
+		Genesis:   rollup.Genesis{L1: eth.BlockID{Hash: l1Info.Hash(), Number: l1Info.NumberU64()}, L2: refA0.ID(), L2Time: refA0.Time},
+		BlockTime: 2,
+	}
+	l1InfoTx, err := L1InfoDepositBytes(1, l1Info)
+	require.NoError(t, err)
+	var payloads []*eth.ExecutionPayload
+	var refs []eth.L2BlockRef
+	parent := refA0
+	for i := 0; i < 4; i++ {
+		p := &eth.ExecutionPayload{
+			ParentHash:   parent.Hash,
+			BlockNumber:  eth.Uint64Quantity(parent.Number + 1),
+			Timestamp:    eth.Uint64Quantity(parent.Time + cfg.BlockTime),
+			BlockHash:    testutils.RandomHash(rng),
+			Transactions: []eth.Data{l1InfoTx},
+		}
+		ref, err := PayloadToBlockRef(p, &cfg.Genesis)
+		require.NoError(t, err)
+		payloads = append(payloads, p)
+		refs = append(refs, ref)
+		parent = ref
+	}
+	validStatus := &eth.PayloadStatusV1{Status: eth.ExecutionValid}
+	valid := &eth.ForkchoiceUpdatedResult{PayloadStatus: *validStatus}
+	fcAt := func(head eth.L2BlockRef) *eth.ForkchoiceState {
+		return &eth.ForkchoiceState{HeadBlockHash: head.Hash, SafeBlockHash: refA0.Hash, FinalizedBlockHash: refA0.Hash}
+	}
+
+	setup := func(t *testing.T) (*EngineQueue, *testutils.MockEngine, *testutils.RecordingMetrics) {
+		eng := &testutils.MockEngine{}
+		metrics := &testutils.RecordingMetrics{}
+		eq := NewEngineQueue(testlog.Logger(t, log.LvlError), cfg, eng, metrics)
+		eq.finalized, eq.safeHead, eq.unsafeHead = refA0, refA0, refA0
+		eq.SetUnsafePayloadBatch(3)
+		for _, p := range payloads {
+			eq.AddUnsafePayload(p)
+		}
+		return eq, eng, metrics
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		eq, eng, metrics := setup(t)
+		for _, p := range payloads[:3] {
+			eng.ExpectNewPayload(p, validStatus, nil)
+		}
+		eng.ExpectForkchoiceUpdate(fcAt(refs[2]), nil, valid, nil)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, refs[2], eq.UnsafeL2Head())
+		require.Equal(t, 1, eq.unsafePayloads.Len())
+		batches := metrics.Records("RecordUnsafePayloadBatch")
+		require.Len(t, batches, 1)
+		require.Equal(t, 3, batches[0].Value)
+		eng.AssertExpectations(t)
+
+		// the last queued payload is processed individually
+		eng.ExpectForkchoiceUpdate(fcAt(refs[2]), nil, valid, nil)
+		eng.ExpectNewPayload(payloads[3], validStatus, nil)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, refs[3], eq.UnsafeL2Head())
+		eng.AssertExpectations(t)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		eq, eng, metrics := setup(t)
+		eng.ExpectNewPayload(payloads[0], validStatus, nil)
+		eng.ExpectNewPayload(payloads[1], &eth.PayloadStatusV1{Status: eth.ExecutionInvalid}, nil)
+		eng.ExpectForkchoiceUpdate(fcAt(refs[0]), nil, valid, nil)
+		require.NoError(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, refs[0], eq.UnsafeL2Head(), "only the valid payload is made canonical")
+		require.Equal(t, 2, eq.unsafePayloads.Len(), "invalid payload is dropped")
+		metrics.RequireCount(t, "RecordUnsafePayloadRejected", 1)
+		batches := metrics.Records("RecordUnsafePayloadBatch")
+		require.Len(t, batches, 1)
+		require.Equal(t, 1, batches[0].Value)
+		eng.AssertExpectations(t)
+	})
+
+	t.Run("forkchoice update fails", func(t *testing.T) {
+		eq, eng, metrics := setup(t)
+		for _, p := range payloads[:3] {
+			eng.ExpectNewPayload(p, validStatus, nil)
+		}
+		eng.ExpectForkchoiceUpdate(fcAt(refs[2]), nil, &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionSyncing}}, nil)
+		require.Error(t, eq.tryNextUnsafePayload(context.Background()))
+		require.Equal(t, refA0, eq.UnsafeL2Head(), "unsafe head is not updated before the forkchoice update is valid")
+		require.Equal(t, len(payloads), eq.unsafePayloads.Len(), "inserted payloads are queued again")
+		require.Equal(t, payloads[0], eq.unsafePayloads.Peek())
+		require.Empty(t, metrics.Records("RecordUnsafePayloadBatch"))
+		eng.AssertExpectations(t)
+	})
+}
@@ -80,6 +80,16 @@ const (
 // If updateSafe is true, the head block is considered to be the safe head as well as the head.
 // It returns the payload, an RPC error (if the payload might still be valid), and a payload error (if the payload was not valid)
 func InsertHeadBlock(ctx context.Context, log log.Logger, eng Engine, fc eth.ForkchoiceState, attrs *eth.PayloadAttributes, updateSafe bool) (out *eth.ExecutionPayload, errTyp BlockInsertionErrType, err error) {
+	payload, errTyp, err := BuildPayload(ctx, eng, fc, attrs)
+	if err != nil {
+		return nil, errTyp, err
+	}
+	return InsertPayload(ctx, log, eng, fc, payload, updateSafe)
+}
+
+// BuildPayload uses the given FC to start the creation of a block with the given attributes, and retrieves the built payload.
+// The payload is not executed nor inserted into the chain, see InsertPayload.
+func BuildPayload(ctx context.Context, eng Engine, fc eth.ForkchoiceState, attrs *eth.PayloadAttributes) (out *eth.ExecutionPayload, errTyp BlockInsertionErrType, err error) {
 	fcRes, err := eng.ForkchoiceUpdate(ctx, &fc, attrs)
 	if err != nil {
 		var inputErr eth.InputError
@@ -118,7 +128,12 @@ func InsertHeadBlock(ctx context.Context, log log.Logger, eng Engine, fc eth.For
 	if err := sanityCheckPayload(payload); err != nil {
 		return nil, BlockInsertPayloadErr, err
 	}
+	return payload, BlockInsertOK, nil
+}
 
+// InsertPayload executes the payload, and sets the FC to the same safe and finalized hashes, but updates the head hash to the payload.
+// If updateSafe is true, the payload is considered to be the safe head as well as the head.
+func InsertPayload(ctx context.Context, log log.Logger, eng Engine, fc eth.ForkchoiceState, payload *eth.ExecutionPayload, updateSafe bool) (out *eth.ExecutionPayload, errTyp BlockInsertionErrType, err error) {
 	status, err := eng.NewPayload(ctx, payload)
 	if err != nil {
 		return nil, BlockInsertTemporaryErr, fmt.Errorf("failed to insert execution payload: %w", err)
@@ -134,7 +149,7 @@ func InsertHeadBlock(ctx context.Context, log log.Logger, eng Engine, fc eth.For
 	if updateSafe {
 		fc.SafeBlockHash = payload.BlockHash
 	}
-	fcRes, err = eng.ForkchoiceUpdate(ctx, &fc, nil)
+	fcRes, err := eng.ForkchoiceUpdate(ctx, &fc, nil)
 	if err != nil {
 		var inputErr eth.InputError
 		if errors.As(err, &inputErr) {
@@ -0,0 +1,128 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+var (
+	BatchSubmittedEventABI     = "BatchSubmitted(address,bytes)"
+	BatchSubmittedEventABIHash = crypto.Keccak256Hash([]byte(BatchSubmittedEventABI))
+)
+
+// NewDataSource creates the data source of the batch inbox mode of the rollup config.
+func NewDataSource(log log.Logger, cfg *rollup.Config, fetcher L1Fetcher) DataAvailabilitySource {
+	if cfg.BatchInboxMode == rollup.BatchInboxEvents {
+		return NewEventSource(log, cfg, fetcher)
+	}
+	return NewCalldataSource(log, cfg, fetcher)
+}
+
+// EventSource reads the receipts of a given block & then filters for
+// batches emitted as events by the batch inbox contract.
+// This is not a stage in the pipeline, but a wrapper for another stage in the pipeline.
+type EventSource struct {
+	log     log.Logger
+	cfg     *rollup.Config
+	fetcher L1ReceiptsFetcher
+}
+
+func NewEventSource(log log.Logger, cfg *rollup.Config, fetcher L1ReceiptsFetcher) *EventSource {
+	return &EventSource{log: log, cfg: cfg, fetcher: fetcher}
+}
+
+func (es *EventSource) OpenData(ctx context.Context, id eth.BlockID) (DataIter, error) {
+	_, _, receiptsFetcher, err := es.fetcher.Fetch(ctx, id.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipts: %w", err)
+	}
+	for {
+		if err := receiptsFetcher.Fetch(ctx); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to fetch more receipts: %w", err)
+		}
+	}
+	receipts, err := receiptsFetcher.Result()
+	if err != nil {
+		return nil, fmt.Errorf("fetched bad receipt data: %w", err)
+	}
+	data := DataFromReceipts(es.cfg, receipts, es.log.New("origin", id))
+	return (*DataSlice)(&data), nil
+}
+
+// DataFromReceipts returns the batches that the batch inbox contract emitted on behalf of the batch sender,
+// in the successful transactions of the receipts.
+func DataFromReceipts(config *rollup.Config, receipts types.Receipts, log log.Logger) []eth.Data {
+	var out []eth.Data
+	for i, rec := range receipts {
+		if rec.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		for j, ev := range rec.Logs {
+			if ev.Address != config.BatchInboxAddress || len(ev.Topics) == 0 || ev.Topics[0] != BatchSubmittedEventABIHash {
+				continue
+			}
+			sender, data, err := UnmarshalBatchSubmittedLogEvent(ev)
+			if err != nil {
+				log.Warn("malformatted batch inbox log", "receipt", i, "log", j, "err", err)
+				continue
+			}
+			// the inbox contract may be called by anyone, only batches of the batch sender are accepted
+			if sender != config.BatchSenderAddress {
+				log.Warn("batch inbox log with unauthorized submitter", "receipt", i, "log", j, "sender", sender)
+				continue
+			}
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+// UnmarshalBatchSubmittedLogEvent decodes an EVM log entry emitted by the batch inbox contract.
+//
+// parse log data for:
+//
+//	event BatchSubmitted(
+//	    address indexed sender,
+//	    bytes data
+//	);
+func UnmarshalBatchSubmittedLogEvent(ev *types.Log) (common.Address, eth.Data, error) {
+	if len(ev.Topics) != 2 {
+		return common.Address{}, nil, fmt.Errorf("expected 2 event topics (event identity, indexed sender), got %d", len(ev.Topics))
+	}
+	if ev.Topics[0] != BatchSubmittedEventABIHash {
+		return common.Address{}, nil, fmt.Errorf("invalid batch event selector: %s, expected %s", ev.Topics[0], BatchSubmittedEventABIHash)
+	}
+	if len(ev.Data) < 64 {
+		return common.Address{}, nil, fmt.Errorf("incomplete data slice header (%d bytes)", len(ev.Data))
+	}
+	if len(ev.Data)%32 != 0 {
+		return common.Address{}, nil, fmt.Errorf("expected log data to be multiple of 32 bytes: got %d bytes", len(ev.Data))
+	}
+	sender := common.BytesToAddress(ev.Topics[1][12:])
+	var offset uint256.Int
+	offset.SetBytes(ev.Data[0:32])
+	if !offset.IsUint64() || offset.Uint64() != 32 {
+		return common.Address{}, nil, fmt.Errorf("invalid data slice header offset: %d", offset.Uint64())
+	}
+	var length uint256.Int
+	length.SetBytes(ev.Data[32:64])
+	// the length must fit the remaining data, with minimal padding
+	if !length.IsUint64() || length.Uint64() > uint64(len(ev.Data)-64) || length.Uint64()+32 <= uint64(len(ev.Data)-64) {
+		return common.Address{}, nil, fmt.Errorf("invalid data slice header length: %d", length.Uint64())
+	}
+	data := make(eth.Data, length.Uint64())
+	copy(data, ev.Data[64:])
+	return sender, data, nil
+}
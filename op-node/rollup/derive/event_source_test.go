@@ -0,0 +1,127 @@
+package derive
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+// batchSubmittedLog encodes a BatchSubmitted event log, as emitted by the batch inbox contract.
+func batchSubmittedLog(inbox common.Address, sender common.Address, data []byte) *types.Log {
+	var enc []byte
+	enc = append(enc, common.BigToHash(big.NewInt(32)).Bytes()...)
+	enc = append(enc, common.BigToHash(big.NewInt(int64(len(data)))).Bytes()...)
+	enc = append(enc, data...)
+	if rem := len(data) % 32; rem != 0 {
+		enc = append(enc, make([]byte, 32-rem)...)
+	}
+	return &types.Log{
+		Address: inbox,
+		Topics:  []common.Hash{BatchSubmittedEventABIHash, common.BytesToHash(sender.Bytes())},
+		Data:    enc,
+	}
+}
+
+func TestUnmarshalBatchSubmittedLogEvent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	for _, n := range []int{0, 1, 31, 32, 33, 1000} {
+		sender := testutils.RandomAddress(rng)
+		data := testutils.RandomData(rng, n)
+		gotSender, gotData, err := UnmarshalBatchSubmittedLogEvent(batchSubmittedLog(common.Address{}, sender, data))
+		require.NoError(t, err)
+		require.Equal(t, sender, gotSender)
+		require.Equal(t, eth.Data(data), gotData)
+	}
+
+	testCases := []struct {
+		name   string
+		tamper func(ev *types.Log)
+	}{
+		{"missing sender", func(ev *types.Log) { ev.Topics = ev.Topics[:1] }},
+		{"wrong selector", func(ev *types.Log) { ev.Topics[0] = DepositEventABIHash }},
+		{"short data", func(ev *types.Log) { ev.Data = ev.Data[:32] }},
+		{"unaligned data", func(ev *types.Log) { ev.Data = ev.Data[:len(ev.Data)-1] }},
+		{"bad offset", func(ev *types.Log) { ev.Data[31] = 64 }},
+		{"length too large", func(ev *types.Log) { ev.Data[63] = 200 }},
+		{"excess padding", func(ev *types.Log) { ev.Data = append(ev.Data, make([]byte, 32)...) }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := batchSubmittedLog(common.Address{}, testutils.RandomAddress(rng), testutils.RandomData(rng, 100))
+			tc.tamper(ev)
+			_, _, err := UnmarshalBatchSubmittedLogEvent(ev)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEventSource_OpenData(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	cfg := &rollup.Config{
+		BatchInboxAddress:  testutils.RandomAddress(rng),
+		BatchSenderAddress: testutils.RandomAddress(rng),
+		BatchInboxMode:     rollup.BatchInboxEvents,
+	}
+	batchA := testutils.RandomData(rng, 1234)
+	batchB := testutils.RandomData(rng, 100)
+
+	malformed := batchSubmittedLog(cfg.BatchInboxAddress, cfg.BatchSenderAddress, testutils.RandomData(rng, 10))
+	malformed.Data = malformed.Data[:40]
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+			batchSubmittedLog(cfg.BatchInboxAddress, cfg.BatchSenderAddress, batchA),
+			// other inbox
+			batchSubmittedLog(testutils.RandomAddress(rng), cfg.BatchSenderAddress, testutils.RandomData(rng, 10)),
+			// unauthorized sender
+			batchSubmittedLog(cfg.BatchInboxAddress, testutils.RandomAddress(rng), testutils.RandomData(rng, 10)),
+			malformed,
+		}},
+		// failed transaction
+		{Status: types.ReceiptStatusFailed, Logs: []*types.Log{
+			batchSubmittedLog(cfg.BatchInboxAddress, cfg.BatchSenderAddress, testutils.RandomData(rng, 10)),
+		}},
+		{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+			batchSubmittedLog(cfg.BatchInboxAddress, cfg.BatchSenderAddress, batchB),
+		}},
+	}
+
+	l1Src := &testutils.MockL1Source{}
+	info := testutils.RandomBlockInfo(rng)
+	l1Src.ExpectFetch(info.Hash(), info, nil, receipts, nil)
+	defer l1Src.Mock.AssertExpectations(t)
+
+	src := NewDataSource(testlog.Logger(t, log.LvlError), cfg, l1Src)
+	require.IsType(t, &EventSource{}, src)
+	dataIter, err := src.OpenData(context.Background(), info.ID())
+	require.NoError(t, err)
+
+	var got []eth.Data
+	for {
+		dat, err := dataIter.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, dat)
+	}
+	require.Equal(t, []eth.Data{batchA, batchB}, got)
+}
+
+func TestNewDataSourceCalldata(t *testing.T) {
+	for _, mode := range []rollup.BatchInboxMode{"", rollup.BatchInboxCalldata} {
+		src := NewDataSource(testlog.Logger(t, log.LvlError), &rollup.Config{BatchInboxMode: mode}, &testutils.MockL1Source{})
+		require.IsType(t, &CalldataSource{}, src)
+	}
+}
@@ -1,7 +1,6 @@
 package derive
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -112,6 +111,47 @@ func (f *Frame) UnmarshalBinary(r ByteReader) error {
 	}
 }
 
+// frameHeaderLen is the length of a frame up to the frame data: channel_id ++ frame_number ++ frame_data_length
+const frameHeaderLen = 32 + 8 + 2 + 4
+
+// unmarshalFrame decodes the first frame of data like UnmarshalBinary, and returns the remaining data.
+// The frame data is not copied: it references the input data.
+func unmarshalFrame(data []byte) (f Frame, rest []byte, err error) {
+	if len(data) < frameHeaderLen {
+		return Frame{}, nil, fmt.Errorf("error reading frame header: %w", io.ErrUnexpectedEOF)
+	}
+	copy(f.ID.Data[:], data[:32])
+	f.ID.Time = binary.BigEndian.Uint64(data[32:40])
+	f.FrameNumber = binary.BigEndian.Uint16(data[40:42])
+	frameLength := binary.BigEndian.Uint32(data[42:46])
+	data = data[frameHeaderLen:]
+
+	// Cap frame length to MaxFrameLen (currently 1MB)
+	if frameLength > MaxFrameLen {
+		return Frame{}, nil, fmt.Errorf("frameLength is too large: %d", frameLength)
+	}
+	if uint64(len(data)) < uint64(frameLength) {
+		return Frame{}, nil, fmt.Errorf("error reading frame data: %w", io.ErrUnexpectedEOF)
+	}
+	// cap the capacity, so appending to the frame data cannot overwrite the next frame
+	f.Data = data[:frameLength:frameLength]
+	data = data[frameLength:]
+
+	// Like UnmarshalBinary, a missing is_last byte at the end of the data is read as false.
+	if len(data) == 0 {
+		return f, nil, nil
+	}
+	switch data[0] {
+	case 0:
+		f.IsLast = false
+	case 1:
+		f.IsLast = true
+	default:
+		return Frame{}, nil, errors.New("invalid byte as is_last")
+	}
+	return f, data[1:], nil
+}
+
 // Frames on stored in L1 transactions with the following format:
 // data = DerivationVersion0 ++ Frame(s)
 // Where there is one or more frames concatenated together.
@@ -121,6 +161,7 @@ func (f *Frame) UnmarshalBinary(r ByteReader) error {
 // format is supported.
 // All frames must be parsed without error and there must not be
 // any left over data and there must be at least one frame.
+// The data of the frames is not copied: the frames reference the input data, which must not be modified after.
 func ParseFrames(data []byte) ([]Frame, error) {
 	if len(data) == 0 {
 		return nil, errors.New("data array must not be empty")
@@ -128,17 +169,15 @@ func ParseFrames(data []byte) ([]Frame, error) {
 	if data[0] != DerivationVersion0 {
 		return nil, fmt.Errorf("invalid derivation format byte: got %d", data[0])
 	}
-	buf := bytes.NewBuffer(data[1:])
+	rest := data[1:]
 	var frames []Frame
-	for buf.Len() > 0 {
-		var f Frame
-		if err := (&f).UnmarshalBinary(buf); err != io.EOF && err != nil {
+	for len(rest) > 0 {
+		f, next, err := unmarshalFrame(rest)
+		if err != nil {
 			return nil, err
 		}
 		frames = append(frames, f)
-	}
-	if buf.Len() != 0 {
-		return nil, fmt.Errorf("did not fully consume data: have %d frames and %d bytes left", len(frames), buf.Len())
+		rest = next
 	}
 	if len(frames) == 0 {
 		return nil, errors.New("was not able to find any frames")
@@ -2,7 +2,11 @@ package derive
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func FuzzFrameUnmarshalBinary(f *testing.F) {
@@ -23,3 +27,44 @@ func FuzzParseFrames(f *testing.F) {
 		}
 	})
 }
+
+// FuzzParseFramesReader checks that ParseFrames decodes the same frames as UnmarshalBinary.
+func FuzzParseFramesReader(f *testing.F) {
+	var seed bytes.Buffer
+	for i, data := range []string{"hello", "", "world"} {
+		frame := Frame{ID: ChannelID{Data: [32]byte{0xaa}, Time: 123}, FrameNumber: uint16(i), Data: []byte(data), IsLast: i == 2}
+		require.NoError(f, frame.MarshalBinary(&seed))
+		f.Add(seed.Bytes())
+	}
+	f.Add(seed.Bytes()[:seed.Len()-1])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		frames, err := ParseFrames(append([]byte{DerivationVersion0}, data...))
+		buf := bytes.NewBuffer(data)
+		var expected []Frame
+		var expectedErr error
+		for buf.Len() > 0 {
+			var f Frame
+			if err := (&f).UnmarshalBinary(buf); err != io.EOF && err != nil {
+				expectedErr = err
+				break
+			}
+			expected = append(expected, f)
+		}
+		if len(expected) == 0 && expectedErr == nil {
+			expectedErr = errors.New("no frames")
+		}
+		if expectedErr != nil {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Len(t, frames, len(expected))
+		for i, f := range frames {
+			require.Equal(t, expected[i].ID, f.ID)
+			require.Equal(t, expected[i].FrameNumber, f.FrameNumber)
+			require.Equal(t, expected[i].IsLast, f.IsLast)
+			require.Equal(t, len(expected[i].Data), len(f.Data))
+			require.True(t, bytes.Equal(expected[i].Data, f.Data))
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Package framegen generates structured, but mutated, channel frames.
+// The frames are valid enough to pass through the frame parsing, but stress the edge-cases
+// of the channel bank and channel reading, such as out of range frame numbers and duplicate final frames.
+package framegen
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// Mutation describes how a sequence of valid frames is modified.
+type Mutation uint8
+
+const (
+	// MutateNone leaves the frames as-is
+	MutateNone Mutation = iota
+	// MutateFrameNumber changes the number of a random frame to a random value
+	MutateFrameNumber
+	// MutateOverlap adds a copy of a random frame, with the same frame number but different data
+	MutateOverlap
+	// MutateDuplicateLast adds a second final frame, with a different frame number
+	MutateDuplicateLast
+	// MutateTruncate cuts off the encoded frames at a random length
+	MutateTruncate
+
+	mutationCount
+)
+
+func (m Mutation) String() string {
+	switch m {
+	case MutateNone:
+		return "none"
+	case MutateFrameNumber:
+		return "frame_number"
+	case MutateOverlap:
+		return "overlap"
+	case MutateDuplicateLast:
+		return "duplicate_last"
+	case MutateTruncate:
+		return "truncate"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(m))
+	}
+}
+
+// Mutations lists all supported mutations.
+func Mutations() []Mutation {
+	out := make([]Mutation, 0, mutationCount)
+	for m := MutateNone; m < mutationCount; m++ {
+		out = append(out, m)
+	}
+	return out
+}
+
+// Frames splits the channel data into valid frames of at most maxFrameSize data bytes each.
+func Frames(id derive.ChannelID, data []byte, maxFrameSize int) []derive.Frame {
+	if maxFrameSize < 1 {
+		maxFrameSize = 1
+	}
+	var frames []derive.Frame
+	for i := 0; ; i++ {
+		n := len(data)
+		if n > maxFrameSize {
+			n = maxFrameSize
+		}
+		frames = append(frames, derive.Frame{
+			ID:          id,
+			FrameNumber: uint16(i),
+			Data:        data[:n],
+			IsLast:      n == len(data),
+		})
+		data = data[n:]
+		if len(data) == 0 {
+			return frames
+		}
+	}
+}
+
+// Mutate applies the frame-level mutation to a copy of the given frames.
+// MutateTruncate is applied on the encoded data instead, see Encode.
+func Mutate(rng *rand.Rand, frames []derive.Frame, m Mutation) []derive.Frame {
+	out := append(make([]derive.Frame, 0, len(frames)+1), frames...)
+	if len(out) == 0 {
+		return out
+	}
+	i := rng.Intn(len(out))
+	switch m {
+	case MutateFrameNumber:
+		out[i].FrameNumber = uint16(rng.Intn(1 << 16))
+	case MutateOverlap:
+		f := out[i]
+		f.Data = randomData(rng, len(f.Data))
+		out = append(out, f)
+	case MutateDuplicateLast:
+		f := out[len(out)-1]
+		f.FrameNumber = uint16(rng.Intn(1 << 16))
+		f.IsLast = true
+		out = append(out, f)
+	}
+	rng.Shuffle(len(out), func(i, j int) {
+		out[i], out[j] = out[j], out[i]
+	})
+	return out
+}
+
+// Encode encodes the frames as L1 transaction data, and truncates the result at a random length
+// if the mutation is MutateTruncate.
+func Encode(rng *rand.Rand, frames []derive.Frame, m Mutation) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(derive.DerivationVersion0)
+	for i, f := range frames {
+		if err := f.MarshalBinary(&buf); err != nil {
+			return nil, fmt.Errorf("failed to encode frame %d: %w", i, err)
+		}
+	}
+	data := buf.Bytes()
+	if m == MutateTruncate {
+		data = data[:1+rng.Intn(len(data))]
+	}
+	return data, nil
+}
+
+// Corpus generates n L1 transaction data entries, each carrying the frames of a channel
+// with random data and a mutation, cycling through all supported mutations.
+func Corpus(rng *rand.Rand, n int) ([][]byte, error) {
+	mutations := Mutations()
+	out := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		m := mutations[i%len(mutations)]
+		var id derive.ChannelID
+		rng.Read(id.Data[:])
+		id.Time = rng.Uint64()
+		frames := Frames(id, randomData(rng, rng.Intn(1000)), 1+rng.Intn(300))
+		data, err := Encode(rng, Mutate(rng, frames, m), m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate corpus entry %d with mutation %s: %w", i, m, err)
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
+
+func randomData(rng *rand.Rand, n int) []byte {
+	out := make([]byte, n)
+	rng.Read(out)
+	return out
+}
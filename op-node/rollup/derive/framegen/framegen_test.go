@@ -0,0 +1,71 @@
+package framegen
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramesRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	id := derive.ChannelID{Data: [32]byte{0x42}, Time: 1000}
+	data := randomData(rng, 1000)
+
+	encoded, err := Encode(rng, Frames(id, data, 300), MutateNone)
+	require.NoError(t, err)
+	frames, err := derive.ParseFrames(encoded)
+	require.NoError(t, err)
+	require.Len(t, frames, 4)
+
+	ch := derive.NewChannel(id)
+	for _, f := range frames {
+		require.NoError(t, ch.AddFrame(f, eth.L1BlockRef{}))
+	}
+	require.True(t, ch.IsReady())
+	out, err := io.ReadAll(ch.Reader())
+	require.NoError(t, err)
+	require.Equal(t, data, out)
+}
+
+// FuzzChannelFrames feeds mutated frames through the frame parsing and channel reading,
+// which must not panic, no matter how the frames are ordered or numbered.
+func FuzzChannelFrames(f *testing.F) {
+	corpus, err := Corpus(rand.New(rand.NewSource(1234)), 50)
+	require.NoError(f, err)
+	for _, data := range corpus {
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		frames, err := derive.ParseFrames(data)
+		if err != nil {
+			return
+		}
+		channels := make(map[derive.ChannelID]*derive.Channel)
+		for _, f := range frames {
+			ch, ok := channels[f.ID]
+			if !ok {
+				ch = derive.NewChannel(f.ID)
+				channels[f.ID] = ch
+			}
+			_ = ch.AddFrame(f, eth.L1BlockRef{})
+		}
+		for _, ch := range channels {
+			if !ch.IsReady() {
+				continue
+			}
+			next, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{})
+			if err != nil {
+				continue
+			}
+			for {
+				if _, err := next(); err != nil {
+					break
+				}
+			}
+		}
+	})
+}
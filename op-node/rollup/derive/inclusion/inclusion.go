@@ -0,0 +1,259 @@
+// Package inclusion verifies the batch submissions of a range of L1 blocks: that the batch inbox data
+// decodes into complete channels, and that the batches of the channels cover a contiguous range of L2 blocks.
+// Operators can use it to verify the batch submitter kept up, and tests to assert against the submitted batches.
+package inclusion
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// L1Source fetches the L1 blocks, transactions and receipts to find the batch submissions in.
+type L1Source interface {
+	derive.L1BlockRefByNumberFetcher
+	derive.L1ReceiptsFetcher
+	derive.L1TransactionFetcher
+}
+
+// Channel is a channel of which frames were submitted in the verified L1 range.
+type Channel struct {
+	ID derive.ChannelID
+	// Opened is the L1 block of the first submitted frame of the channel.
+	Opened eth.L1BlockRef
+	// Ready is the L1 block that completed the channel, if the channel is complete.
+	Ready eth.L1BlockRef
+	// Frames is the number of submitted frames.
+	Frames int
+	// Batches is the number of decoded batches, with span batches counted per block.
+	Batches int
+	// Err describes why the batches of the channel are not derived, if they are not.
+	Err error
+}
+
+// Gap is a range of L2 blocks that is not covered by any batch.
+type Gap struct {
+	// From and To are the timestamps of the first and last missing L2 block.
+	From, To uint64
+}
+
+// Report is the result of verifying the batch submissions of a range of L1 blocks.
+type Report struct {
+	// L1Start and L1End are the first and last verified L1 block.
+	L1Start, L1End eth.L1BlockRef
+	// Submissions is the number of batch inbox submissions, i.e. transactions or events, of the batch sender.
+	Submissions int
+	// InvalidSubmissions is the number of submissions of which the frames could not be parsed.
+	InvalidSubmissions int
+	// Channels are all channels with frames in the L1 range, in order of their first frame.
+	Channels []*Channel
+	// Batches is the number of batches of all complete and valid channels.
+	Batches int
+	// Duplicates is the number of batches with the timestamp of an earlier batch.
+	Duplicates int
+	// First and Last are the timestamps of the first and last L2 block covered by the batches.
+	First, Last uint64
+	// Gaps are the ranges of L2 blocks between First and Last that are not covered by any batch.
+	Gaps []Gap
+}
+
+// OK returns true if all submissions and channels are valid, and the batches cover a contiguous L2 range.
+// Channels that are still incomplete at the end of the L1 range may be completed later, and are not an error.
+func (r *Report) OK() bool {
+	if r.InvalidSubmissions > 0 || len(r.Gaps) > 0 {
+		return false
+	}
+	for _, ch := range r.Channels {
+		if ch.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Incomplete returns the channels that were not completed in the L1 range.
+func (r *Report) Incomplete() []*Channel {
+	var out []*Channel
+	for _, ch := range r.Channels {
+		if ch.Ready == (eth.L1BlockRef{}) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// ErrChannelTimedOut is the error of a channel that was completed after the channel timeout.
+var ErrChannelTimedOut = errors.New("channel timed out")
+
+// Verify fetches the batch submissions of the L1 blocks start to end, inclusive, from the batch inbox
+// of the rollup config, the same way the derivation pipeline does, and verifies them.
+func Verify(ctx context.Context, logger log.Logger, cfg *rollup.Config, l1 L1Source, start, end uint64) (*Report, error) {
+	if end < start {
+		return nil, fmt.Errorf("invalid L1 range: end %d is before start %d", end, start)
+	}
+	var src derive.DataAvailabilitySource
+	if cfg.BatchInboxMode == rollup.BatchInboxEvents {
+		src = derive.NewEventSource(logger, cfg, l1)
+	} else {
+		src = derive.NewCalldataSource(logger, cfg, l1)
+	}
+
+	v := newVerifier(cfg)
+	for num := start; num <= end; num++ {
+		ref, err := l1.L1BlockRefByNumber(ctx, num)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch L1 block %d: %w", num, err)
+		}
+		if num == start {
+			v.report.L1Start = ref
+		}
+		v.report.L1End = ref
+		iter, err := src.OpenData(ctx, ref.ID())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open batch inbox data of L1 block %s: %w", ref, err)
+		}
+		for {
+			data, err := iter.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to read batch inbox data of L1 block %s: %w", ref, err)
+			}
+			v.addData(ref, data)
+		}
+	}
+	return v.finish(), nil
+}
+
+type verifier struct {
+	cfg        *rollup.Config
+	report     Report
+	channels   map[derive.ChannelID]*channel
+	timestamps []uint64
+}
+
+type channel struct {
+	frames *derive.Channel
+	info   *Channel
+}
+
+func newVerifier(cfg *rollup.Config) *verifier {
+	return &verifier{cfg: cfg, channels: make(map[derive.ChannelID]*channel)}
+}
+
+func (v *verifier) addData(ref eth.L1BlockRef, data eth.Data) {
+	v.report.Submissions++
+	frames, err := derive.ParseFrames(data)
+	if err != nil {
+		v.report.InvalidSubmissions++
+		return
+	}
+	for _, f := range frames {
+		ch, ok := v.channels[f.ID]
+		if !ok {
+			ch = &channel{frames: derive.NewChannel(f.ID), info: &Channel{ID: f.ID, Opened: ref}}
+			v.channels[f.ID] = ch
+			v.report.Channels = append(v.report.Channels, ch.info)
+		}
+		if ch.info.Ready != (eth.L1BlockRef{}) {
+			continue // frames after the channel is complete are ignored
+		}
+		if err := ch.frames.AddFrame(f, ref); err != nil {
+			ch.info.Err = fmt.Errorf("invalid frame %d: %w", f.FrameNumber, err)
+			continue
+		}
+		ch.info.Frames++
+		if ch.frames.IsReady() {
+			ch.info.Ready = ref
+			v.readChannel(ch.info, ch.frames)
+		}
+	}
+}
+
+func (v *verifier) readChannel(info *Channel, ch *derive.Channel) {
+	if info.ID.Time+v.cfg.ChannelTimeout < info.Ready.Time {
+		info.Err = ErrChannelTimedOut
+		return
+	}
+	next, err := derive.BatchReader(ch.Reader(), info.Ready)
+	if err != nil {
+		info.Err = fmt.Errorf("invalid channel compression: %w", err)
+		return
+	}
+	for {
+		b, err := next()
+		if errors.Is(err, io.EOF) {
+			return
+		} else if err != nil {
+			info.Err = fmt.Errorf("invalid batch %d: %w", info.Batches, err)
+			return
+		}
+		batches := []*derive.BatchData{b.Batch}
+		if b.Batch.Span != nil {
+			batches = b.Batch.SpanBatches(v.cfg.BlockTime)
+		}
+		for _, batch := range batches {
+			v.timestamps = append(v.timestamps, batch.Timestamp)
+			info.Batches++
+		}
+	}
+}
+
+func (v *verifier) finish() *Report {
+	r := &v.report
+	r.Batches = len(v.timestamps)
+	if len(v.timestamps) == 0 {
+		return r
+	}
+	sort.Slice(v.timestamps, func(i, j int) bool { return v.timestamps[i] < v.timestamps[j] })
+	r.First = v.timestamps[0]
+	r.Last = v.timestamps[len(v.timestamps)-1]
+	for i := 1; i < len(v.timestamps); i++ {
+		prev, t := v.timestamps[i-1], v.timestamps[i]
+		if t == prev {
+			r.Duplicates++
+		} else if t > prev+v.cfg.BlockTime {
+			r.Gaps = append(r.Gaps, Gap{From: prev + v.cfg.BlockTime, To: t - v.cfg.BlockTime})
+		}
+	}
+	return r
+}
+
+// WriteReport renders a summary of the report for humans to review, with all channels that are incomplete
+// or could not be derived, and all gaps.
+func WriteReport(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "L1 blocks %s to %s\n", r.L1Start, r.L1End)
+	fmt.Fprintf(bw, "%d submissions, %d invalid\n", r.Submissions, r.InvalidSubmissions)
+	fmt.Fprintf(bw, "%d channels, %d incomplete\n", len(r.Channels), len(r.Incomplete()))
+	for _, ch := range r.Channels {
+		if ch.Err != nil {
+			fmt.Fprintf(bw, "  channel %s: opened in %s, %d frames: %v\n", ch.ID, ch.Opened, ch.Frames, ch.Err)
+		} else if ch.Ready == (eth.L1BlockRef{}) {
+			fmt.Fprintf(bw, "  channel %s: opened in %s, %d frames: incomplete\n", ch.ID, ch.Opened, ch.Frames)
+		}
+	}
+	if r.Batches == 0 {
+		fmt.Fprintf(bw, "no batches\n")
+	} else {
+		fmt.Fprintf(bw, "%d batches, %d duplicates, L2 timestamps %d to %d\n", r.Batches, r.Duplicates, r.First, r.Last)
+	}
+	for _, g := range r.Gaps {
+		fmt.Fprintf(bw, "  gap: L2 timestamps %d to %d\n", g.From, g.To)
+	}
+	if r.OK() {
+		fmt.Fprintf(bw, "OK\n")
+	} else {
+		fmt.Fprintf(bw, "FAILED\n")
+	}
+	return bw.Flush()
+}
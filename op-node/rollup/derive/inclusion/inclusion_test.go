@@ -0,0 +1,160 @@
+package inclusion
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive/testvectors"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type verifyTest struct {
+	t          *testing.T
+	rng        *rand.Rand
+	cfg        *rollup.Config
+	signer     types.Signer
+	batcherKey *ecdsa.PrivateKey
+	l1         *testutils.MockL1Source
+	blocks     []eth.L1BlockRef
+}
+
+func newVerifyTest(t *testing.T) *verifyTest {
+	batcherPriv := testutils.RandomKey()
+	cfg := &rollup.Config{
+		BlockTime:          2,
+		ChannelTimeout:     100,
+		L1ChainID:          big.NewInt(100),
+		BatchInboxAddress:  testutils.RandomAddress(rand.New(rand.NewSource(1))),
+		BatchSenderAddress: crypto.PubkeyToAddress(batcherPriv.PublicKey),
+	}
+	return &verifyTest{
+		t:          t,
+		rng:        rand.New(rand.NewSource(1234)),
+		cfg:        cfg,
+		signer:     cfg.L1Signer(),
+		batcherKey: batcherPriv,
+		l1:         &testutils.MockL1Source{},
+	}
+}
+
+// addL1Block adds an L1 block with a batch inbox transaction for each of the given frames.
+func (vt *verifyTest) addL1Block(frames ...hexutil.Bytes) eth.L1BlockRef {
+	ref := testutils.RandomBlockRef(vt.rng)
+	ref.Number = uint64(len(vt.blocks))
+	ref.Time = 1000 + 12*ref.Number
+	var txs types.Transactions
+	for i, data := range frames {
+		tx, err := types.SignNewTx(vt.batcherKey, vt.signer, &types.DynamicFeeTx{
+			ChainID:   vt.signer.ChainID(),
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(2 * params.GWei),
+			GasFeeCap: big.NewInt(30 * params.GWei),
+			Gas:       100_000,
+			To:        &vt.cfg.BatchInboxAddress,
+			Data:      data,
+		})
+		require.NoError(vt.t, err)
+		txs = append(txs, tx)
+	}
+	vt.l1.ExpectL1BlockRefByNumber(ref.Number, ref, nil)
+	info := &testutils.MockBlockInfo{InfoHash: ref.Hash, InfoParentHash: ref.ParentHash, InfoNum: ref.Number, InfoTime: ref.Time}
+	vt.l1.ExpectInfoAndTxsByHash(ref.Hash, info, txs, nil)
+	vt.blocks = append(vt.blocks, ref)
+	return ref
+}
+
+// frames encodes the L2 blocks as a channel opened at the given L1 time.
+func (vt *verifyTest) frames(l2Blocks []*types.Block, channelTime uint64) []hexutil.Bytes {
+	v, err := testvectors.FromBlocks("verify", l2Blocks, channelTime, 100)
+	require.NoError(vt.t, err)
+	require.Greater(vt.t, len(v.Frames), 1)
+	return v.Frames
+}
+
+func (vt *verifyTest) verify() *Report {
+	r, err := Verify(context.Background(), testlog.Logger(vt.t, log.LvlError), vt.cfg, vt.l1, 0, uint64(len(vt.blocks)-1))
+	require.NoError(vt.t, err)
+	vt.l1.AssertExpectations(vt.t)
+	return r
+}
+
+func TestVerify(t *testing.T) {
+	vt := newVerifyTest(t)
+	l2Blocks, err := testvectors.RandomBlocks(vt.rng, 10, 3)
+	require.NoError(t, err)
+	first := vt.frames(l2Blocks[:6], 1000)
+	second := vt.frames(l2Blocks[6:], 1012)
+	vt.addL1Block(first[:1]...)
+	vt.addL1Block(append(first[1:], second[0])...)
+	vt.addL1Block(second[1:]...)
+
+	r := vt.verify()
+	require.True(t, r.OK())
+	require.Equal(t, vt.blocks[0], r.L1Start)
+	require.Equal(t, vt.blocks[2], r.L1End)
+	require.Equal(t, len(first)+len(second), r.Submissions)
+	require.Zero(t, r.InvalidSubmissions)
+	require.Len(t, r.Channels, 2)
+	require.Equal(t, vt.blocks[1], r.Channels[0].Ready)
+	require.Equal(t, vt.blocks[2], r.Channels[1].Ready)
+	require.Equal(t, 6, r.Channels[0].Batches)
+	require.Equal(t, 10, r.Batches)
+	require.Equal(t, l2Blocks[0].Time(), r.First)
+	require.Equal(t, l2Blocks[9].Time(), r.Last)
+	require.Empty(t, r.Gaps)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, r))
+	require.Contains(t, out.String(), "10 batches, 0 duplicates")
+}
+
+func TestVerifyGap(t *testing.T) {
+	vt := newVerifyTest(t)
+	l2Blocks, err := testvectors.RandomBlocks(vt.rng, 10, 3)
+	require.NoError(t, err)
+	vt.addL1Block(vt.frames(l2Blocks[:3], 1000)...)
+	vt.addL1Block(append(vt.frames(l2Blocks[7:], 1012), []byte{0x01})...)
+	incomplete := vt.frames(l2Blocks[3:7], 1012)
+	vt.addL1Block(incomplete[:len(incomplete)-1]...)
+
+	r := vt.verify()
+	require.False(t, r.OK())
+	require.Equal(t, 1, r.InvalidSubmissions)
+	require.Equal(t, 6, r.Batches)
+	require.Equal(t, []Gap{{From: l2Blocks[3].Time(), To: l2Blocks[6].Time()}}, r.Gaps)
+	require.Len(t, r.Incomplete(), 1)
+	require.Equal(t, vt.blocks[2], r.Incomplete()[0].Opened)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, r))
+	require.Contains(t, out.String(), "incomplete")
+	require.Contains(t, out.String(), "FAILED")
+}
+
+func TestVerifyChannelTimeout(t *testing.T) {
+	vt := newVerifyTest(t)
+	l2Blocks, err := testvectors.RandomBlocks(vt.rng, 3, 3)
+	require.NoError(t, err)
+	frames := vt.frames(l2Blocks, 1000-vt.cfg.ChannelTimeout)
+	vt.addL1Block(frames[:1]...)
+	vt.addL1Block(frames[1:]...)
+
+	r := vt.verify()
+	require.False(t, r.OK())
+	require.ErrorIs(t, r.Channels[0].Err, ErrChannelTimedOut)
+	require.Zero(t, r.Batches)
+}
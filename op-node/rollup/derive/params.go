@@ -15,6 +15,7 @@ const DerivationVersion0 = 0
 // MaxChannelBankSize is the amount of memory space, in number of bytes,
 // till the bank is pruned by removing channels,
 // starting with the oldest channel.
+// This is the default, the rollup config may override it with a different limit.
 const MaxChannelBankSize = 100_000_000
 
 // DuplicateErr is returned when a newly read frame is already known
@@ -4,8 +4,10 @@ import (
 	"container/heap"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type payloadAndSize struct {
@@ -54,6 +56,9 @@ const (
 	payloadTxMemOverhead uint64 = 24
 )
 
+// number of times to try to load a spilled payload before dropping it
+const maxSpillLoadAttempts = 3
+
 func payloadMemSize(p *eth.ExecutionPayload) uint64 {
 	out := payloadMemFixedCost
 	if p == nil {
@@ -73,21 +78,69 @@ func payloadMemSize(p *eth.ExecutionPayload) uint64 {
 // PayloadsQueue maintains a MaxSize by counting and tracking sizes of added eth.ExecutionPayload entries.
 // When the size grows too large, the first (lowest block-number) payload is removed from the queue.
 // PayloadsQueue allows entries with same block number, or even full duplicates.
+//
+// Optionally the PayloadsQueue spills the highest block-number payloads to a PayloadStore when MaxSize is exceeded,
+// up to MaxSpillSize, and loads them back when there is memory again.
+// Spilled payloads always have a block number that is higher or equal to that of the payloads in memory.
+// When the spill store is full, the highest spilled payloads are dropped to make room for lower payloads.
 type PayloadsQueue struct {
 	pq          payloadsByNumber
 	currentSize uint64
 	MaxSize     uint64
 	SizeFn      func(p *eth.ExecutionPayload) uint64
+
+	Spill        PayloadStore
+	MaxSpillSize uint64
+	spilled      []spilledPayload // ordered by ascending block number
+	spillSize    uint64
+
+	// number of queued payloads by block hash, in memory or spilled
+	queued map[common.Hash]int
+
+	// OnDrop is called with the reason when a queued payload is dropped, optional.
+	OnDrop func(id eth.BlockID, reason string, err error)
+}
+
+type spilledPayload struct {
+	id   eth.BlockID
+	size uint64
 }
 
+// Len returns the number of payloads in the queue, including spilled payloads.
 func (upq *PayloadsQueue) Len() int {
-	return len(upq.pq)
+	return len(upq.pq) + len(upq.spilled)
+}
+
+// Has returns true if a payload with the given block hash is in the queue, in memory or spilled.
+func (upq *PayloadsQueue) Has(hash common.Hash) bool {
+	return upq.queued[hash] > 0
+}
+
+func (upq *PayloadsQueue) track(hash common.Hash) {
+	if upq.queued == nil {
+		upq.queued = make(map[common.Hash]int)
+	}
+	upq.queued[hash] += 1
 }
 
+func (upq *PayloadsQueue) untrack(hash common.Hash) {
+	if upq.queued[hash] <= 1 {
+		delete(upq.queued, hash)
+	} else {
+		upq.queued[hash] -= 1
+	}
+}
+
+// MemSize returns the size of the payloads in memory.
 func (upq *PayloadsQueue) MemSize() uint64 {
 	return upq.currentSize
 }
 
+// SpillSize returns the size of the spilled payloads.
+func (upq *PayloadsQueue) SpillSize() uint64 {
+	return upq.spillSize
+}
+
 // Push adds the payload to the queue, in O(log(N)).
 //
 // Don't DoS ourselves by buffering too many unsafe payloads.
@@ -95,6 +148,9 @@ func (upq *PayloadsQueue) MemSize() uint64 {
 //
 // We prefer higher block numbers over lower block numbers, since lower block numbers are more likely to be conflicts and/or read from L1 sooner.
 // The higher payload block numbers can be preserved, and once L1 contents meets these, they can all be processed in order.
+//
+// If a spill store is configured, the highest payloads are spilled instead, until the spill store is full.
+// A payload higher than the spilled payloads is spilled directly, and an error is returned if that is not possible.
 func (upq *PayloadsQueue) Push(p *eth.ExecutionPayload) error {
 	if p == nil {
 		return errors.New("cannot add nil payload")
@@ -103,17 +159,125 @@ func (upq *PayloadsQueue) Push(p *eth.ExecutionPayload) error {
 	if size > upq.MaxSize {
 		return fmt.Errorf("cannot add payload %s, payload mem size %d is larger than max queue size %d", p.ID(), size, upq.MaxSize)
 	}
+	// Payloads after the spilled payloads are spilled as well, to keep the spilled payloads ordered after those in memory.
+	if len(upq.spilled) > 0 && uint64(p.BlockNumber) > upq.spilled[0].id.Number {
+		if err := upq.spill(payloadAndSize{payload: p, size: size}); err != nil {
+			return err
+		}
+		upq.track(p.BlockHash)
+		return nil
+	}
 	heap.Push(&upq.pq, payloadAndSize{
 		payload: p,
 		size:    size,
 	})
 	upq.currentSize += size
+	upq.track(p.BlockHash)
 	for upq.currentSize > upq.MaxSize {
-		upq.Pop()
+		if err := upq.spillHighest(); err != nil {
+			if dropped := upq.popMem(); dropped != nil {
+				upq.drop(dropped.ID(), "buffer_full", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (upq *PayloadsQueue) drop(id eth.BlockID, reason string, err error) {
+	if upq.OnDrop != nil {
+		upq.OnDrop(id, reason, err)
 	}
+}
+
+// spillHighest moves the payload with the highest block number in memory to the spill store.
+func (upq *PayloadsQueue) spillHighest() error {
+	if len(upq.pq) == 0 {
+		return errors.New("no payloads to spill")
+	}
+	highest := 0
+	for i := range upq.pq {
+		if upq.pq[i].payload.BlockNumber > upq.pq[highest].payload.BlockNumber {
+			highest = i
+		}
+	}
+	if err := upq.spill(upq.pq[highest]); err != nil {
+		return err
+	}
+	ps := heap.Remove(&upq.pq, highest).(payloadAndSize) // nosemgrep
+	upq.currentSize -= ps.size
+	return nil
+}
+
+// spill stores the payload in the spill store, if it is configured and has space left.
+// Spilled payloads with a higher block number are dropped to make space if necessary.
+func (upq *PayloadsQueue) spill(ps payloadAndSize) error {
+	if upq.Spill == nil {
+		return errors.New("no spill store")
+	}
+	if ps.size > upq.MaxSpillSize {
+		return fmt.Errorf("cannot spill payload %s, payload size %d exceeds max spill size %d", ps.payload.ID(), ps.size, upq.MaxSpillSize)
+	}
+	// only evict if that makes enough space, to not drop payloads for nothing
+	space := upq.MaxSpillSize - upq.spillSize
+	n := len(upq.spilled)
+	for space < ps.size && n > 0 && upq.spilled[n-1].id.Number > uint64(ps.payload.BlockNumber) {
+		n -= 1
+		space += upq.spilled[n].size
+	}
+	if space < ps.size {
+		return fmt.Errorf("cannot spill payload %s, spilled payloads would exceed max spill size %d", ps.payload.ID(), upq.MaxSpillSize)
+	}
+	for len(upq.spilled) > n {
+		upq.evictHighest()
+	}
+	if err := upq.Spill.Put(ps.payload); err != nil {
+		return fmt.Errorf("failed to spill payload %s: %w", ps.payload.ID(), err)
+	}
+	i := sort.Search(len(upq.spilled), func(i int) bool {
+		return upq.spilled[i].id.Number > uint64(ps.payload.BlockNumber)
+	})
+	upq.spilled = append(upq.spilled, spilledPayload{})
+	copy(upq.spilled[i+1:], upq.spilled[i:])
+	upq.spilled[i] = spilledPayload{id: ps.payload.ID(), size: ps.size}
+	upq.spillSize += ps.size
 	return nil
 }
 
+// evictHighest drops the spilled payload with the highest block number.
+func (upq *PayloadsQueue) evictHighest() {
+	sp := upq.spilled[len(upq.spilled)-1]
+	upq.spilled = upq.spilled[:len(upq.spilled)-1]
+	upq.spillSize -= sp.size
+	upq.untrack(sp.id.Hash)
+	err := upq.Spill.Delete(sp.id)
+	upq.drop(sp.id, "spill_full", err)
+}
+
+// reload moves spilled payloads back into memory, lowest block number first, while there is memory for them.
+// Loading a spilled payload is retried a few times, before the payload is dropped.
+func (upq *PayloadsQueue) reload() {
+	for len(upq.spilled) > 0 && upq.currentSize+upq.spilled[0].size <= upq.MaxSize {
+		sp := upq.spilled[0]
+		var p *eth.ExecutionPayload
+		var err error
+		for i := 0; i < maxSpillLoadAttempts; i++ {
+			if p, err = upq.Spill.Get(sp.id); err == nil {
+				break
+			}
+		}
+		upq.spilled = upq.spilled[1:]
+		upq.spillSize -= sp.size
+		_ = upq.Spill.Delete(sp.id)
+		if err != nil {
+			upq.untrack(sp.id.Hash)
+			upq.drop(sp.id, "spill_load_failed", err)
+			continue
+		}
+		heap.Push(&upq.pq, payloadAndSize{payload: p, size: sp.size})
+		upq.currentSize += sp.size
+	}
+}
+
 // Peek retrieves the payload with the lowest block number from the queue in O(1), or nil if the queue is empty.
 func (upq *PayloadsQueue) Peek() *eth.ExecutionPayload {
 	if len(upq.pq) == 0 {
@@ -126,11 +290,19 @@ func (upq *PayloadsQueue) Peek() *eth.ExecutionPayload {
 
 // Pop removes the payload with the lowest block number from the queue in O(log(N)),
 // and may return nil if the queue is empty.
+// Spilled payloads are loaded back into memory if the memory allows for it.
 func (upq *PayloadsQueue) Pop() *eth.ExecutionPayload {
+	p := upq.popMem()
+	upq.reload()
+	return p
+}
+
+func (upq *PayloadsQueue) popMem() *eth.ExecutionPayload {
 	if len(upq.pq) == 0 {
 		return nil
 	}
 	ps := heap.Pop(&upq.pq).(payloadAndSize) // nosemgrep
 	upq.currentSize -= ps.size
+	upq.untrack(ps.payload.BlockHash)
 	return ps.payload
 }
@@ -2,9 +2,11 @@ package derive
 
 import (
 	"container/heap"
+	"errors"
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -129,3 +131,128 @@ func TestPayloadsQueue(t *testing.T) {
 	require.Equal(t, pq.Len(), 3, "expecting b, bAlt, c")
 	require.NotContainsf(t, pq.pq[:], a, "a should be dropped after 3 items already exist under max size constraint")
 }
+
+func TestPayloadsQueueSpill(t *testing.T) {
+	store, err := NewDiskPayloadStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+	dropped := make(map[eth.BlockID]string)
+	pq := PayloadsQueue{
+		MaxSize:      payloadMemFixedCost * 2,
+		SizeFn:       payloadMemSize,
+		Spill:        store,
+		MaxSpillSize: payloadMemFixedCost * 2,
+		OnDrop: func(id eth.BlockID, reason string, err error) {
+			dropped[id] = reason
+		},
+	}
+	mk := func(i uint64) *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{BlockNumber: eth.Uint64Quantity(i), BlockHash: common.Hash{byte(i)}}
+	}
+	z, a, b, c, d, e := mk(0), mk(1), mk(2), mk(3), mk(4), mk(5)
+
+	require.NoError(t, pq.Push(c))
+	require.NoError(t, pq.Push(a))
+	require.NoError(t, pq.Push(d))
+	require.Equal(t, 3, pq.Len())
+	require.Equal(t, 2*payloadMemFixedCost, pq.MemSize())
+	require.Equal(t, payloadMemFixedCost, pq.SpillSize(), "expecting d to be spilled")
+
+	require.NoError(t, pq.Push(b))
+	require.Equal(t, 4, pq.Len())
+	require.Equal(t, 2*payloadMemFixedCost, pq.SpillSize(), "expecting c and d to be spilled")
+	require.Equal(t, a, pq.Peek())
+
+	require.Error(t, pq.Push(e), "cannot spill e, spill store is full")
+	require.Equal(t, 4, pq.Len())
+	require.True(t, pq.Has(d.BlockHash), "spilled payloads are queued")
+	require.False(t, pq.Has(e.BlockHash), "rejected payloads are not queued")
+	require.Empty(t, dropped)
+
+	require.NoError(t, pq.Push(z))
+	require.Equal(t, 4, pq.Len())
+	require.Equal(t, 2*payloadMemFixedCost, pq.SpillSize(), "expecting b and c to be spilled")
+	require.False(t, pq.Has(d.BlockHash), "expecting d to be evicted for the lower b")
+	require.Equal(t, map[eth.BlockID]string{d.ID(): "spill_full"}, dropped)
+
+	require.Equal(t, z, pq.Pop())
+	require.Equal(t, payloadMemFixedCost, pq.SpillSize(), "expecting b to be loaded again")
+	require.Equal(t, a, pq.Pop())
+	require.Equal(t, uint64(0), pq.SpillSize(), "expecting c to be loaded again")
+	require.Equal(t, b.ID(), pq.Pop().ID())
+	require.Equal(t, c.ID(), pq.Pop().ID())
+	require.Equal(t, 0, pq.Len())
+	require.Nil(t, pq.Pop())
+	require.False(t, pq.Has(c.BlockHash), "popped payloads are not queued")
+}
+
+type flakyPayloadStore struct {
+	PayloadStore
+	failures int
+}
+
+func (s *flakyPayloadStore) Get(id eth.BlockID) (*eth.ExecutionPayload, error) {
+	if s.failures > 0 {
+		s.failures -= 1
+		return nil, errors.New("flaky store")
+	}
+	return s.PayloadStore.Get(id)
+}
+
+func TestPayloadsQueueSpillLoadFailure(t *testing.T) {
+	disk, err := NewDiskPayloadStore(t.TempDir())
+	require.NoError(t, err)
+	defer disk.Close()
+	store := &flakyPayloadStore{PayloadStore: disk}
+	dropped := make(map[eth.BlockID]string)
+	pq := PayloadsQueue{
+		MaxSize:      payloadMemFixedCost,
+		SizeFn:       payloadMemSize,
+		Spill:        store,
+		MaxSpillSize: payloadMemFixedCost * 2,
+		OnDrop: func(id eth.BlockID, reason string, err error) {
+			require.Error(t, err)
+			dropped[id] = reason
+		},
+	}
+	mk := func(i uint64) *eth.ExecutionPayload {
+		return &eth.ExecutionPayload{BlockNumber: eth.Uint64Quantity(i), BlockHash: common.Hash{byte(i)}}
+	}
+	a, b, c := mk(1), mk(2), mk(3)
+	require.NoError(t, pq.Push(a))
+	require.NoError(t, pq.Push(b))
+	require.NoError(t, pq.Push(c))
+	require.Equal(t, 2*payloadMemFixedCost, pq.SpillSize(), "expecting b and c to be spilled")
+
+	store.failures = maxSpillLoadAttempts - 1
+	require.Equal(t, a, pq.Pop())
+	require.Empty(t, dropped, "loading b is retried")
+	require.Equal(t, b.ID(), pq.Peek().ID())
+
+	store.failures = maxSpillLoadAttempts
+	require.Equal(t, b.ID(), pq.Pop().ID())
+	require.Equal(t, map[eth.BlockID]string{c.ID(): "spill_load_failed"}, dropped)
+	require.False(t, pq.Has(c.BlockHash), "dropped payloads are not queued")
+	require.Equal(t, 0, pq.Len())
+}
+
+func TestDiskPayloadStore(t *testing.T) {
+	store, err := NewDiskPayloadStore(t.TempDir())
+	require.NoError(t, err)
+	p := &eth.ExecutionPayload{
+		BlockNumber:  123,
+		BlockHash:    common.Hash{0xaa},
+		ExtraData:    eth.BytesMax32{0x01},
+		Transactions: []eth.Data{{0x02, 0x03}},
+	}
+	require.NoError(t, store.Put(p))
+	got, err := store.Get(p.ID())
+	require.NoError(t, err)
+	require.Equal(t, p, got)
+	require.NoError(t, store.Delete(p.ID()))
+	_, err = store.Get(p.ID())
+	require.Error(t, err)
+
+	require.NoError(t, store.Close())
+	require.NoDirExists(t, store.dir)
+}
@@ -0,0 +1,70 @@
+package derive
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// PayloadStore stores payloads that do not fit in the memory of a PayloadsQueue.
+type PayloadStore interface {
+	Put(p *eth.ExecutionPayload) error
+	Get(id eth.BlockID) (*eth.ExecutionPayload, error)
+	Delete(id eth.BlockID) error
+}
+
+// DiskPayloadStore is a PayloadStore that stores each payload as SSZ encoded file in a temporary directory.
+type DiskPayloadStore struct {
+	dir string
+}
+
+var _ PayloadStore = (*DiskPayloadStore)(nil)
+
+// NewDiskPayloadStore creates a new temporary directory within the given parent directory to store payloads in.
+// The directory is removed on Close.
+func NewDiskPayloadStore(parentDir string) (*DiskPayloadStore, error) {
+	if err := os.MkdirAll(parentDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create payload store parent directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(parentDir, "unsafe-payloads-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payload store directory: %w", err)
+	}
+	return &DiskPayloadStore{dir: dir}, nil
+}
+
+func (s *DiskPayloadStore) path(id eth.BlockID) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d-%s.ssz", id.Number, id.Hash))
+}
+
+func (s *DiskPayloadStore) Put(p *eth.ExecutionPayload) error {
+	var buf bytes.Buffer
+	if _, err := p.MarshalSSZ(&buf); err != nil {
+		return fmt.Errorf("failed to encode payload %s: %w", p.ID(), err)
+	}
+	return os.WriteFile(s.path(p.ID()), buf.Bytes(), 0o644)
+}
+
+func (s *DiskPayloadStore) Get(id eth.BlockID) (*eth.ExecutionPayload, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload %s: %w", id, err)
+	}
+	var p eth.ExecutionPayload
+	if err := p.UnmarshalSSZ(uint32(len(data)), bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to decode payload %s: %w", id, err)
+	}
+	return &p, nil
+}
+
+func (s *DiskPayloadStore) Delete(id eth.BlockID) error {
+	return os.Remove(s.path(id))
+}
+
+// Close removes the store directory, and all payloads in it.
+func (s *DiskPayloadStore) Close() error {
+	return os.RemoveAll(s.dir)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -14,6 +16,19 @@ type Metrics interface {
 	RecordL1Ref(name string, ref eth.L1BlockRef)
 	RecordL2Ref(name string, ref eth.L2BlockRef)
 	RecordUnsafePayloadsBuffer(length uint64, memSize uint64, next eth.BlockID)
+	RecordUnsafePayloadRejected(reason string)
+	RecordUnsafeBackfill(result string, payloads int)
+	RecordUnsafePayloadBatch(payloads int)
+	RecordDerivationOrigin(origin eth.L1BlockRef)
+	RecordProcessedBatch(l2Time uint64)
+	RecordConsolidationMismatch(field string)
+	RecordChannelBankSize(size uint64)
+	RecordChannelBankEviction()
+	RecordDroppedFrame(reason string)
+	RecordDroppedChannel(reason string)
+	RecordDroppedBatch(reason string)
+	RecordDepositsPending(count uint64)
+	RecordDepositsIncluded(count uint64, latency time.Duration)
 }
 
 type L1Fetcher interface {
@@ -54,6 +69,13 @@ type EngineQueueStage interface {
 	SafeL2Head() eth.L2BlockRef
 	Progress() Progress
 	SetUnsafeHead(head eth.L2BlockRef)
+	SpillUnsafePayloads(store PayloadStore, maxSize uint64)
+	SetTrustedAnchor(anchor *TrustedAnchor)
+	SetUnsafeBackfill(source UnsafeBackfillSource, maxDepth uint64)
+	SetUnsafePayloadBatch(maxSize uint64)
+	LowestQueuedUnsafeBlock() eth.L2BlockRef
+	QueuedUnsafePayloads() int
+	HasUnsafePayload(hash common.Hash) bool
 
 	Finalize(l1Origin eth.BlockID)
 	AddSafeAttributes(attributes *eth.PayloadAttributes)
@@ -84,11 +106,11 @@ type DerivationPipeline struct {
 // NewDerivationPipeline creates a derivation pipeline, which should be reset before use.
 func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, l1Fetcher L1Fetcher, engine Engine, metrics Metrics) *DerivationPipeline {
 	eng := NewEngineQueue(log, cfg, engine, metrics)
-	attributesQueue := NewAttributesQueue(log, cfg, l1Fetcher, eng)
-	batchQueue := NewBatchQueue(log, cfg, attributesQueue)
-	chInReader := NewChannelInReader(log, batchQueue)
-	bank := NewChannelBank(log, cfg, chInReader)
-	dataSrc := NewCalldataSource(log, cfg, l1Fetcher)
+	attributesQueue := NewAttributesQueue(log, cfg, l1Fetcher, eng, metrics)
+	batchQueue := NewBatchQueue(log, cfg, attributesQueue, metrics)
+	chInReader := NewChannelInReader(log, cfg, batchQueue)
+	bank := NewChannelBank(log, cfg, chInReader, metrics)
+	dataSrc := NewDataSource(log, cfg, l1Fetcher)
 	l1Src := NewL1Retrieval(log, dataSrc, bank)
 	l1Traversal := NewL1Traversal(log, l1Fetcher, l1Src)
 	stages := []Stage{eng, attributesQueue, batchQueue, chInReader, bank, l1Src, l1Traversal}
@@ -139,6 +161,41 @@ func (dp *DerivationPipeline) AddUnsafePayload(payload *eth.ExecutionPayload) {
 	dp.eng.AddUnsafePayload(payload)
 }
 
+// LowestQueuedUnsafeBlock returns the block of the first queued unsafe payload, see EngineQueue.LowestQueuedUnsafeBlock.
+func (dp *DerivationPipeline) LowestQueuedUnsafeBlock() eth.L2BlockRef {
+	return dp.eng.LowestQueuedUnsafeBlock()
+}
+
+// QueuedUnsafePayloads returns the number of queued unsafe payloads, see EngineQueue.QueuedUnsafePayloads.
+func (dp *DerivationPipeline) QueuedUnsafePayloads() int {
+	return dp.eng.QueuedUnsafePayloads()
+}
+
+// HasUnsafePayload returns true if an unsafe payload with the given block hash is queued, see EngineQueue.HasUnsafePayload.
+func (dp *DerivationPipeline) HasUnsafePayload(hash common.Hash) bool {
+	return dp.eng.HasUnsafePayload(hash)
+}
+
+// SpillUnsafePayloads configures the store to spill buffered unsafe payloads to, see EngineQueue.SpillUnsafePayloads.
+func (dp *DerivationPipeline) SpillUnsafePayloads(store PayloadStore, maxSize uint64) {
+	dp.eng.SpillUnsafePayloads(store, maxSize)
+}
+
+// SetUnsafeBackfill configures the source to fetch missing unsafe payloads from, see EngineQueue.SetUnsafeBackfill.
+func (dp *DerivationPipeline) SetUnsafeBackfill(source UnsafeBackfillSource, maxDepth uint64) {
+	dp.eng.SetUnsafeBackfill(source, maxDepth)
+}
+
+// SetUnsafePayloadBatch configures the number of unsafe payloads to insert per forkchoice update, see EngineQueue.SetUnsafePayloadBatch.
+func (dp *DerivationPipeline) SetUnsafePayloadBatch(maxSize uint64) {
+	dp.eng.SetUnsafePayloadBatch(maxSize)
+}
+
+// SetTrustedAnchor configures the L2 block to not reset derivation past, see EngineQueue.SetTrustedAnchor.
+func (dp *DerivationPipeline) SetTrustedAnchor(anchor *TrustedAnchor) {
+	dp.eng.SetTrustedAnchor(anchor)
+}
+
 // Step tries to progress the buffer.
 // An EOF is returned if there pipeline is blocked by waiting for new L1 data.
 // If ctx errors no error is returned, but the step may exit early in a state that can still be continued.
@@ -147,6 +204,9 @@ func (dp *DerivationPipeline) AddUnsafePayload(payload *eth.ExecutionPayload) {
 // When Step returns nil, it should be called again, to continue the derivation process.
 func (dp *DerivationPipeline) Step(ctx context.Context) error {
 	defer dp.metrics.RecordL1Ref("l1_derived", dp.Progress().Origin)
+	defer func() {
+		dp.metrics.RecordDerivationOrigin(dp.Progress().Origin)
+	}()
 
 	// if any stages need to be reset, do that first.
 	if dp.resetting < len(dp.stages) {
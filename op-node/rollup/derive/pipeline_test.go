@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
@@ -65,6 +66,11 @@ type TestMetrics struct {
 	recordL1Ref          func(name string, ref eth.L1BlockRef)
 	recordL2Ref          func(name string, ref eth.L2BlockRef)
 	recordUnsafePayloads func(length uint64, memSize uint64, next eth.BlockID)
+	recordRejected       func(reason string)
+	recordBackfill       func(result string, payloads int)
+	recordBankSize       func(size uint64)
+	recordBankEviction   func()
+	recordDropped        func(kind string, reason string)
 }
 
 func (t *TestMetrics) RecordL1Ref(name string, ref eth.L1BlockRef) {
@@ -85,4 +91,59 @@ func (t *TestMetrics) RecordUnsafePayloadsBuffer(length uint64, memSize uint64,
 	}
 }
 
+func (t *TestMetrics) RecordUnsafePayloadRejected(reason string) {
+	if t.recordRejected != nil {
+		t.recordRejected(reason)
+	}
+}
+
+func (t *TestMetrics) RecordUnsafeBackfill(result string, payloads int) {
+	if t.recordBackfill != nil {
+		t.recordBackfill(result, payloads)
+	}
+}
+
+func (t *TestMetrics) RecordUnsafePayloadBatch(payloads int) {
+}
+
+func (t *TestMetrics) RecordDerivationOrigin(origin eth.L1BlockRef) {}
+
+func (t *TestMetrics) RecordProcessedBatch(l2Time uint64) {}
+
+func (t *TestMetrics) RecordConsolidationMismatch(field string) {}
+
+func (t *TestMetrics) RecordChannelBankSize(size uint64) {
+	if t.recordBankSize != nil {
+		t.recordBankSize(size)
+	}
+}
+
+func (t *TestMetrics) RecordChannelBankEviction() {
+	if t.recordBankEviction != nil {
+		t.recordBankEviction()
+	}
+}
+
+func (t *TestMetrics) RecordDroppedFrame(reason string) {
+	if t.recordDropped != nil {
+		t.recordDropped("frame", reason)
+	}
+}
+
+func (t *TestMetrics) RecordDroppedChannel(reason string) {
+	if t.recordDropped != nil {
+		t.recordDropped("channel", reason)
+	}
+}
+
+func (t *TestMetrics) RecordDroppedBatch(reason string) {
+	if t.recordDropped != nil {
+		t.recordDropped("batch", reason)
+	}
+}
+
+func (t *TestMetrics) RecordDepositsPending(count uint64) {}
+
+func (t *TestMetrics) RecordDepositsIncluded(count uint64, latency time.Duration) {}
+
 var _ Metrics = (*TestMetrics)(nil)
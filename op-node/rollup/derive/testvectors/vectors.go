@@ -0,0 +1,223 @@
+// Package testvectors defines batch submission test vectors: channels of batches,
+// encoded as the L1 transaction data that carries them, to test batch encoders and decoders against.
+package testvectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Vector describes a channel at every level of the batch encoding.
+type Vector struct {
+	Name string `json:"name"`
+	// Batches are the binary encoded batches of the channel, in order.
+	Batches []hexutil.Bytes `json:"batches"`
+	// Channel is the compressed channel data, i.e. the concatenated data of all frames.
+	Channel hexutil.Bytes `json:"channel"`
+	// Frames is the L1 transaction data of each frame of the channel, in order.
+	Frames []hexutil.Bytes `json:"frames"`
+}
+
+// FromBlocks encodes the L2 blocks with a derive.ChannelOut, and creates a vector of the output.
+// The expected batches are computed from the blocks independently of the channel encoding.
+// The maxFrameSize is the maximum size of the L1 transaction data of a frame, including the derivation version byte.
+func FromBlocks(name string, blocks []*types.Block, channelTime uint64, maxFrameSize uint64) (*Vector, error) {
+	if maxFrameSize < 2 {
+		return nil, fmt.Errorf("max frame size %d is too small to fit a frame", maxFrameSize)
+	}
+	v := &Vector{Name: name}
+	co, err := derive.NewChannelOut(channelTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	for _, block := range blocks {
+		batch, err := batchFromBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create batch of block %s: %w", block.Hash(), err)
+		}
+		data, err := batch.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode batch of block %s: %w", block.Hash(), err)
+		}
+		v.Batches = append(v.Batches, data)
+		if err := co.AddBlock(block); err != nil {
+			return nil, fmt.Errorf("failed to add block %s to channel: %w", block.Hash(), err)
+		}
+	}
+	if err := co.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close channel: %w", err)
+	}
+	for {
+		var buf bytes.Buffer
+		buf.WriteByte(derive.DerivationVersion0)
+		// subtract one, to account for the version byte
+		err := co.OutputFrame(&buf, maxFrameSize-1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to output frame %d: %w", len(v.Frames), err)
+		}
+		frames, perr := derive.ParseFrames(buf.Bytes())
+		if perr != nil {
+			return nil, fmt.Errorf("failed to parse output frame %d: %w", len(v.Frames), perr)
+		}
+		v.Frames = append(v.Frames, buf.Bytes())
+		v.Channel = append(v.Channel, frames[0].Data...)
+		if errors.Is(err, io.EOF) {
+			return v, nil
+		}
+	}
+}
+
+// Check decodes the frames of the vector, and verifies the channel data and batches match the vector.
+func (v *Vector) Check() error {
+	var ch *derive.Channel
+	var channelData []byte
+	for i, data := range v.Frames {
+		frames, err := derive.ParseFrames(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse frame tx %d: %w", i, err)
+		}
+		for _, f := range frames {
+			if ch == nil {
+				ch = derive.NewChannel(f.ID)
+			}
+			if err := ch.AddFrame(f, eth.L1BlockRef{}); err != nil {
+				return fmt.Errorf("failed to add frame %d of tx %d to channel: %w", f.FrameNumber, i, err)
+			}
+			channelData = append(channelData, f.Data...)
+		}
+	}
+	if ch == nil || !ch.IsReady() {
+		return errors.New("channel is incomplete")
+	}
+	if !bytes.Equal(channelData, v.Channel) {
+		return errors.New("channel data does not match")
+	}
+	next, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{})
+	if err != nil {
+		return fmt.Errorf("failed to read channel: %w", err)
+	}
+	for i := 0; ; i++ {
+		b, err := next()
+		if errors.Is(err, io.EOF) {
+			if i != len(v.Batches) {
+				return fmt.Errorf("expected %d batches, but decoded %d", len(v.Batches), i)
+			}
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to decode batch %d: %w", i, err)
+		}
+		if i >= len(v.Batches) {
+			return fmt.Errorf("decoded more than the expected %d batches", len(v.Batches))
+		}
+		data, err := b.Batch.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to encode decoded batch %d: %w", i, err)
+		}
+		if !bytes.Equal(data, v.Batches[i]) {
+			return fmt.Errorf("batch %d does not match", i)
+		}
+	}
+}
+
+// batchFromBlock creates the batch that is expected to be submitted for the given L2 block.
+func batchFromBlock(block *types.Block) (*derive.BatchData, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 || txs[0].Type() != types.DepositTxType {
+		return nil, errors.New("block is missing the L1 info deposit")
+	}
+	l1Info, err := derive.L1InfoDepositTxData(txs[0].Data())
+	if err != nil {
+		return nil, fmt.Errorf("invalid L1 info deposit: %w", err)
+	}
+	var opaqueTxs []hexutil.Bytes
+	for i, tx := range txs {
+		if tx.Type() == types.DepositTxType {
+			continue
+		}
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tx %d: %w", i, err)
+		}
+		opaqueTxs = append(opaqueTxs, data)
+	}
+	return &derive.BatchData{BatchV1: derive.BatchV1{
+		ParentHash:   block.ParentHash(),
+		EpochNum:     rollup.Epoch(l1Info.Number),
+		EpochHash:    l1Info.BlockHash,
+		Timestamp:    block.Time(),
+		Transactions: opaqueTxs,
+	}}, nil
+}
+
+// RandomBlocks creates a chain of n L2 blocks, each with an L1 info deposit and up to maxTxs random transactions.
+func RandomBlocks(rng *rand.Rand, n int, maxTxs int) ([]*types.Block, error) {
+	var blocks []*types.Block
+	parent := testutils.RandomHash(rng)
+	l1Info := testutils.RandomBlockInfo(rng)
+	for i := 0; i < n; i++ {
+		l1InfoTx, err := derive.L1InfoDeposit(uint64(i), l1Info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create L1 info deposit: %w", err)
+		}
+		txs := []*types.Transaction{types.NewTx(l1InfoTx)}
+		for j := rng.Intn(maxTxs + 1); j > 0; j-- {
+			to := testutils.RandomAddress(rng)
+			txs = append(txs, types.NewTx(&types.LegacyTx{
+				Nonce:    rng.Uint64(),
+				GasPrice: big.NewInt(rng.Int63()),
+				Gas:      21000 + uint64(rng.Intn(1_000_000)),
+				To:       &to,
+				Value:    big.NewInt(rng.Int63()),
+				Data:     testutils.RandomData(rng, rng.Intn(200)),
+				V:        big.NewInt(rng.Int63()),
+				R:        big.NewInt(rng.Int63()),
+				S:        big.NewInt(rng.Int63()),
+			}))
+		}
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(i + 1)),
+			Time:       l1Info.Time() + uint64(i)*2,
+		}
+		block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	return blocks, nil
+}
+
+// WriteFile writes the vectors to a JSON file.
+func WriteFile(path string, vectors []*Vector) error {
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode vectors: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadFile reads vectors from a JSON file.
+func ReadFile(path string) ([]*Vector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vectors file: %w", err)
+	}
+	var vectors []*Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("failed to decode vectors file: %w", err)
+	}
+	return vectors, nil
+}
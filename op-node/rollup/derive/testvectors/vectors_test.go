@@ -0,0 +1,241 @@
+package testvectors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// TestChannelOutRoundTrip checks that the ChannelOut output decodes back to the batches of the original blocks,
+// for a range of channel and frame sizes, also after exporting and importing the vectors.
+func TestChannelOutRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	var vectors []*Vector
+	for _, blockCount := range []int{1, 3, 20} {
+		for _, maxFrameSize := range []uint64{100, 1000, 100_000} {
+			blocks, err := RandomBlocks(rng, blockCount, 5)
+			require.NoError(t, err)
+			name := fmt.Sprintf("blocks_%d_frame_size_%d", blockCount, maxFrameSize)
+			v, err := FromBlocks(name, blocks, 1000, maxFrameSize)
+			require.NoError(t, err)
+			require.Len(t, v.Batches, blockCount)
+			for _, frame := range v.Frames {
+				require.LessOrEqual(t, uint64(len(frame)), maxFrameSize, "frame tx data must fit the max frame size")
+			}
+			require.NoError(t, v.Check(), name)
+			vectors = append(vectors, v)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	require.NoError(t, WriteFile(path, vectors))
+	imported, err := ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, vectors, imported)
+	for _, v := range imported {
+		require.NoError(t, v.Check(), v.Name)
+	}
+}
+
+// TestVectorsFile checks the committed test vectors, generated with:
+//
+//	op-node batch export-vectors --max-blocks 5 --outfile testdata/batch-vectors.json
+func TestVectorsFile(t *testing.T) {
+	vectors, err := ReadFile("testdata/batch-vectors.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors)
+	for _, v := range vectors {
+		for _, frame := range v.Frames {
+			require.LessOrEqual(t, len(frame), 1000, "frame tx data must fit the max frame size")
+		}
+		require.NoError(t, v.Check(), v.Name)
+	}
+}
+
+func TestCheckDetectsMismatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	blocks, err := RandomBlocks(rng, 3, 5)
+	require.NoError(t, err)
+	v, err := FromBlocks("mismatch", blocks, 1000, 200)
+	require.NoError(t, err)
+
+	batch := v.Batches[1]
+	v.Batches[1] = v.Batches[0]
+	require.ErrorContains(t, v.Check(), "batch 1 does not match")
+	v.Batches[1] = batch
+	require.NoError(t, v.Check())
+
+	v.Batches = v.Batches[:2]
+	require.ErrorContains(t, v.Check(), "decoded more than the expected 2 batches")
+
+	v.Frames = v.Frames[:len(v.Frames)-1]
+	require.ErrorContains(t, v.Check(), "channel is incomplete")
+}
+
+// channelBatches closes the channel, and decodes the batches of its frames.
+func channelBatches(t *testing.T, co *derive.ChannelOut) (batches []*derive.BatchData, size int) {
+	require.NoError(t, co.Close())
+	ch := derive.NewChannel(co.ID())
+	for {
+		var buf bytes.Buffer
+		buf.WriteByte(derive.DerivationVersion0)
+		err := co.OutputFrame(&buf, 100_000)
+		if err != nil && !errors.Is(err, io.EOF) {
+			require.NoError(t, err)
+		}
+		frames, perr := derive.ParseFrames(buf.Bytes())
+		require.NoError(t, perr)
+		for _, f := range frames {
+			require.NoError(t, ch.AddFrame(f, eth.L1BlockRef{}))
+			size += len(f.Data)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	require.True(t, ch.IsReady())
+	next, err := derive.BatchReader(ch.Reader(), eth.L1BlockRef{})
+	require.NoError(t, err)
+	for {
+		b, err := next()
+		if errors.Is(err, io.EOF) {
+			return batches, size
+		}
+		require.NoError(t, err)
+		batches = append(batches, b.Batch)
+	}
+}
+
+// TestSpanChannelOut checks that a span channel decodes to the same batches as a regular channel,
+// except for the parent hashes within a span, and that it compresses the consecutive blocks better.
+func TestSpanChannelOut(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	blocks, err := RandomBlocks(rng, 20, 5)
+	require.NoError(t, err)
+
+	co, err := derive.NewChannelOut(1000)
+	require.NoError(t, err)
+	spanCo, err := derive.NewSpanChannelOut(1000)
+	require.NoError(t, err)
+	for _, block := range blocks {
+		require.NoError(t, co.AddBlock(block))
+		require.NoError(t, spanCo.AddBlock(block))
+	}
+	batches, size := channelBatches(t, co)
+	spans, spanSize := channelBatches(t, spanCo)
+	require.Len(t, batches, len(blocks))
+	require.Len(t, spans, 1, "consecutive blocks are encoded as a single span")
+	require.NotNil(t, spans[0].Span)
+	require.Less(t, spanSize, size, "span batch should be smaller")
+
+	expanded := spans[0].SpanBatches(2)
+	require.Len(t, expanded, len(blocks))
+	for i, b := range expanded {
+		expected := batches[i].BatchV1
+		if i > 0 {
+			expected.ParentHash = common.Hash{} // linked by the batch queue
+		}
+		require.Equal(t, expected, b.BatchV1, "batch %d", i)
+	}
+}
+
+func TestSpanChannelOutNonConsecutive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	first, err := RandomBlocks(rng, 3, 5)
+	require.NoError(t, err)
+	second, err := RandomBlocks(rng, 2, 5)
+	require.NoError(t, err)
+
+	co, err := derive.NewSpanChannelOut(1000)
+	require.NoError(t, err)
+	for _, block := range append(first, second...) {
+		require.NoError(t, co.AddBlock(block))
+	}
+	spans, _ := channelBatches(t, co)
+	require.Len(t, spans, 2, "a block that does not build on the span starts a new span")
+	require.Len(t, spans[0].Span.Blocks, 3)
+	require.Len(t, spans[1].Span.Blocks, 2)
+	require.Equal(t, second[0].ParentHash(), spans[1].Span.ParentHash)
+}
+
+// TestChannelOutReset checks that a reset channel has a new ID, and does not output any data of the previous channel,
+// also if the previous channel was not closed or fully output.
+func TestChannelOutReset(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	first, err := RandomBlocks(rng, 3, 5)
+	require.NoError(t, err)
+	second, err := RandomBlocks(rng, 2, 5)
+	require.NoError(t, err)
+
+	co, err := derive.NewSpanChannelOut(1000)
+	require.NoError(t, err)
+	for _, block := range first {
+		require.NoError(t, co.AddBlock(block))
+	}
+	require.NoError(t, co.Flush())
+	require.NotZero(t, co.ReadyBytes())
+	prevID := co.ID()
+
+	require.NoError(t, co.Reset(2000))
+	require.NotEqual(t, prevID, co.ID())
+	require.Equal(t, uint64(2000), co.ID().Time)
+	require.Zero(t, co.ReadyBytes())
+	for _, block := range second {
+		require.NoError(t, co.AddBlock(block))
+	}
+	spans, _ := channelBatches(t, co)
+	require.Len(t, spans, 1, "the previous span is not continued")
+	require.Len(t, spans[0].Span.Blocks, 2)
+	require.Equal(t, second[0].ParentHash(), spans[0].Span.ParentHash)
+}
+
+// BenchmarkChannelOutNew and BenchmarkChannelOutReset compare the allocations of creating a new channel for every
+// channel of blocks to reusing a channel, as the batcher does.
+func BenchmarkChannelOutNew(b *testing.B) {
+	benchmarkChannelOut(b, func(co *derive.ChannelOut) (*derive.ChannelOut, error) {
+		return derive.NewChannelOut(1000)
+	})
+}
+
+func BenchmarkChannelOutReset(b *testing.B) {
+	benchmarkChannelOut(b, func(co *derive.ChannelOut) (*derive.ChannelOut, error) {
+		return co, co.Reset(1000)
+	})
+}
+
+func benchmarkChannelOut(b *testing.B, next func(co *derive.ChannelOut) (*derive.ChannelOut, error)) {
+	rng := rand.New(rand.NewSource(1234))
+	blocks, err := RandomBlocks(rng, 10, 20)
+	require.NoError(b, err)
+	co, err := derive.NewChannelOut(1000)
+	require.NoError(b, err)
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		co, err = next(co)
+		require.NoError(b, err)
+		for _, block := range blocks {
+			require.NoError(b, co.AddBlock(block))
+		}
+		require.NoError(b, co.Close())
+		for {
+			buf.Reset()
+			if err := co.OutputFrame(&buf, 100_000); errors.Is(err, io.EOF) {
+				break
+			} else {
+				require.NoError(b, err)
+			}
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package driver
 
+import (
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
 type Config struct {
 	// VerifierConfDepth is the distance to keep from the L1 head when reading L1 data for L2 derivation.
 	VerifierConfDepth uint64 `json:"verifier_conf_depth"`
@@ -13,4 +19,49 @@ type Config struct {
 
 	// SequencerEnabled is true when the driver should sequence new blocks.
 	SequencerEnabled bool `json:"sequencer_enabled"`
+
+	// SequencerMaxSafeLag is the maximum number of L2 blocks the unsafe head may be ahead of the safe head.
+	// The sequencer pauses until the safe head catches up when the lag exceeds this, e.g. when batches are not landing on L1.
+	// Throttling is disabled if 0.
+	SequencerMaxSafeLag uint64 `json:"sequencer_max_safe_lag"`
+
+	// SequencerBuildDeadline is the maximum time the engine may take to build a block, from starting the payload
+	// to making it canonical. Building is aborted, and retried at the next block creation, if it is exceeded.
+	// No deadline is applied if 0.
+	SequencerBuildDeadline time.Duration `json:"sequencer_build_deadline"`
+
+	// DerivationStepBudget is the maximum time the driver spends on consecutive derivation steps,
+	// before it yields to process new L1 heads, unsafe payloads and other events.
+	// A single step is taken at a time if 0.
+	DerivationStepBudget time.Duration `json:"derivation_step_budget"`
+
+	// UnsafePayloadsSpillDir is the directory to spill buffered unsafe payloads to,
+	// when they exceed the memory limit. Payloads are dropped instead if empty.
+	UnsafePayloadsSpillDir string `json:"unsafe_payloads_spill_dir"`
+
+	// UnsafePayloadsSpillSize is the maximum total size of spilled unsafe payloads.
+	UnsafePayloadsSpillSize uint64 `json:"unsafe_payloads_spill_size"`
+
+	// UnsafeBackfillDepth is the maximum number of missing unsafe payloads to fetch by hash,
+	// when the parent of a received unsafe payload is unknown. Backfilling is disabled if 0.
+	UnsafeBackfillDepth uint64 `json:"unsafe_backfill_depth"`
+
+	// UnsafePayloadBatchSize is the maximum number of consecutive queued unsafe payloads to insert into the engine
+	// before making the last one canonical with a single forkchoice update, to speed up catching up with the unsafe chain.
+	// This only applies to unsafe payloads: blocks derived from L1 are still processed one at a time.
+	// Every payload is made canonical individually if 0 or 1.
+	UnsafePayloadBatchSize uint64 `json:"unsafe_payload_batch_size"`
+
+	// TrustedAnchor is the L2 block to sync from instead of the L2 genesis, nil to sync from genesis.
+	// The L2 chain up to and including the anchor must already be in the engine, e.g. restored from a snapshot.
+	TrustedAnchor *derive.TrustedAnchor `json:"trusted_anchor,omitempty"`
+}
+
+// RuntimeConfig is the part of the driver config that can be changed while the driver is running.
+// Only the settings that are not nil are changed.
+type RuntimeConfig struct {
+	SequencerEnabled    *bool   `json:"sequencer_enabled,omitempty"`
+	VerifierConfDepth   *uint64 `json:"verifier_conf_depth,omitempty"`
+	SequencerConfDepth  *uint64 `json:"sequencer_conf_depth,omitempty"`
+	SequencerMaxSafeLag *uint64 `json:"sequencer_max_safe_lag,omitempty"`
 }
@@ -2,10 +2,13 @@ package driver
 
 import (
 	"context"
+	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
@@ -27,8 +30,26 @@ type Metrics interface {
 	RecordL2Ref(name string, ref eth.L2BlockRef)
 
 	RecordUnsafePayloadsBuffer(length uint64, memSize uint64, next eth.BlockID)
+	RecordUnsafePayloadRejected(reason string)
+	RecordUnsafeBackfill(result string, payloads int)
+	RecordUnsafePayloadBatch(payloads int)
+	RecordDerivationOrigin(origin eth.L1BlockRef)
+	RecordProcessedBatch(l2Time uint64)
+	RecordConsolidationMismatch(field string)
+	RecordChannelBankSize(size uint64)
+	RecordChannelBankEviction()
+	RecordDroppedFrame(reason string)
+	RecordDroppedChannel(reason string)
+	RecordDroppedBatch(reason string)
+	RecordDepositsPending(count uint64)
+	RecordDepositsIncluded(count uint64, latency time.Duration)
 
 	SetDerivationIdle(idle bool)
+	RecordDerivationStep(d time.Duration)
+	RecordDerivationYield()
+	SetSequencerThrottled(throttled bool)
+	RecordSequencerBuildDeadlineMissed()
+	RecordSequencerL1OriginAge(blocks uint64)
 
 	RecordL1ReorgDepth(d uint64)
 	CountSequencedTxs(count int)
@@ -55,6 +76,13 @@ type DerivationPipeline interface {
 	Step(ctx context.Context) error
 	SetUnsafeHead(head eth.L2BlockRef)
 	AddUnsafePayload(payload *eth.ExecutionPayload)
+	SpillUnsafePayloads(store derive.PayloadStore, maxSize uint64)
+	SetTrustedAnchor(anchor *derive.TrustedAnchor)
+	SetUnsafeBackfill(source derive.UnsafeBackfillSource, maxDepth uint64)
+	SetUnsafePayloadBatch(maxSize uint64)
+	LowestQueuedUnsafeBlock() eth.L2BlockRef
+	QueuedUnsafePayloads() int
+	HasUnsafePayload(hash common.Hash) bool
 	Finalized() eth.L2BlockRef
 	SafeL2Head() eth.L2BlockRef
 	UnsafeL2Head() eth.L2BlockRef
@@ -71,18 +99,23 @@ type Network interface {
 	PublishL2Payload(ctx context.Context, payload *eth.ExecutionPayload) error
 }
 
-func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, network Network, log log.Logger, snapshotLog log.Logger, metrics Metrics) *Driver {
-	output := &outputImpl{
-		Config: cfg,
-		dl:     l1,
-		l2:     l2,
-		log:    log,
-	}
+type AltSync interface {
+	// RequestL2Range is called by the driver to request the unsafe payloads between start and end, both exclusive,
+	// when payloads are missing between the unsafe head and the queued unsafe payloads.
+	// The requested payloads are added like unsafe payloads from gossip, if they can be retrieved.
+	// It must not block the driver main loop.
+	RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error
+}
+
+func NewDriver(driverCfg *Config, cfg *rollup.Config, l2 L2Chain, l1 L1Chain, network Network, altSync AltSync, log log.Logger, snapshotLog log.Logger, metrics Metrics, cacheMetrics caching.Metrics, clk clock.Clock) *Driver {
+	sequencer := NewSequencer(log, cfg, driverCfg.SequencerConfDepth, driverCfg.SequencerBuildDeadline, l1, l2, metrics, clk)
 
 	var state *state
 	verifConfDepth := NewConfDepth(driverCfg.VerifierConfDepth, func() eth.L1BlockRef { return state.l1Head }, l1)
-	derivationPipeline := derive.NewDerivationPipeline(log, cfg, verifConfDepth, l2, metrics)
-	state = NewState(driverCfg, log, snapshotLog, cfg, l1, l2, output, derivationPipeline, network, metrics)
+	// tagged as the "derivation" log subsystem of the node, to adjust the log level of derivation separately
+	derivationPipeline := derive.NewDerivationPipeline(log.New("subsystem", "derivation"), cfg, verifConfDepth, l2, metrics)
+	state = NewState(driverCfg, log, snapshotLog, cfg, l1, l2, sequencer, derivationPipeline, network, altSync, metrics, cacheMetrics, clk)
+	state.verifierConfDepth = verifConfDepth
 	return &Driver{s: state}
 }
 
@@ -110,6 +143,30 @@ func (d *Driver) SyncStatus(ctx context.Context) (*SyncStatus, error) {
 	return d.s.SyncStatus(ctx)
 }
 
+func (d *Driver) SequencerThrottle(ctx context.Context) (*SequencerThrottle, error) {
+	return d.s.SequencerThrottle(ctx)
+}
+
+func (d *Driver) SetSequencerMaxSafeLag(ctx context.Context, maxSafeLag uint64) error {
+	return d.s.SetSequencerMaxSafeLag(ctx, maxSafeLag)
+}
+
+func (d *Driver) UpdateRuntimeConfig(ctx context.Context, cfg RuntimeConfig) error {
+	return d.s.UpdateRuntimeConfig(ctx, cfg)
+}
+
+// SubscribeHeads subscribes ch to changes of the L2 heads, see HeadUpdate.
+// ch must be buffered: a stale update in it is replaced with the latest heads if the subscriber is behind.
+func (d *Driver) SubscribeHeads(ch chan HeadUpdate) (unsubscribe func()) {
+	return d.s.SubscribeHeads(ch)
+}
+
+// SetUnsafeBackfillSource sets the source to fetch missing unsafe payloads from, instead of the L2 engine.
+// It must be called before Start.
+func (d *Driver) SetUnsafeBackfillSource(source derive.UnsafeBackfillSource) {
+	d.s.unsafeBackfill = source
+}
+
 func (d *Driver) Start(ctx context.Context) error {
 	return d.s.Start(ctx)
 }
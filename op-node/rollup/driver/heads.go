@@ -0,0 +1,59 @@
+package driver
+
+import (
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// HeadUpdate is a change of the L2 heads of the driver.
+type HeadUpdate struct {
+	Unsafe    eth.L2BlockRef
+	Safe      eth.L2BlockRef
+	Finalized eth.L2BlockRef
+}
+
+// SubscribeHeads sends the L2 heads to ch whenever any of them changes, until unsubscribe is called.
+// The driver does not block on the subscriber: if the buffer of ch is full, the stale update in it
+// is replaced with the latest one, so a slow subscriber always receives the latest heads.
+// ch must be buffered, updates are dropped if ch is not ready to receive them otherwise.
+func (s *state) SubscribeHeads(ch chan HeadUpdate) (unsubscribe func()) {
+	s.headSubsLock.Lock()
+	defer s.headSubsLock.Unlock()
+	s.headSubs[ch] = struct{}{}
+	return func() {
+		s.headSubsLock.Lock()
+		defer s.headSubsLock.Unlock()
+		delete(s.headSubs, ch)
+	}
+}
+
+// notifyHeads sends the L2 heads to the head subscribers, if any of the heads changed since the last notification.
+func (s *state) notifyHeads() {
+	heads := HeadUpdate{
+		Unsafe:    s.derivation.UnsafeL2Head(),
+		Safe:      s.derivation.SafeL2Head(),
+		Finalized: s.derivation.Finalized(),
+	}
+	if heads == s.lastHeads {
+		return
+	}
+	s.lastHeads = heads
+	s.headSubsLock.Lock()
+	defer s.headSubsLock.Unlock()
+	for ch := range s.headSubs {
+		select {
+		case ch <- heads:
+			continue
+		default:
+		}
+		// the subscriber is behind: drop the stale update to make room for the latest
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- heads:
+		default:
+			s.log.Debug("Dropped head update for slow subscriber", "unsafe", heads.Unsafe, "safe", heads.Safe)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestSubscribeHeads(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	pipeline := &fakeHeadsPipeline{}
+	s := &state{
+		log:         testlog.Logger(t, log.LvlError),
+		snapshotLog: NewSnapshotLogger(io.Discard),
+		derivation:  pipeline,
+		headSubs:    make(map[chan HeadUpdate]struct{}),
+	}
+	ch := make(chan HeadUpdate, 1)
+	unsubscribe := s.SubscribeHeads(ch)
+
+	pipeline.unsafe = testutils.RandomL2BlockRef(rng)
+	s.snapshotOnChange("Unsafe head change")
+	require.Equal(t, HeadUpdate{Unsafe: pipeline.unsafe}, <-ch)
+
+	s.l1Head = testutils.RandomBlockRef(rng)
+	s.snapshotOnChange("L1 head change")
+	require.Empty(t, ch, "no update if the L2 heads did not change")
+
+	pipeline.safe = pipeline.unsafe
+	s.snapshotOnChange("Safe head change")
+	pipeline.finalized = pipeline.unsafe
+	s.snapshotOnChange("Finalized head change")
+	require.Equal(t, HeadUpdate{Unsafe: pipeline.unsafe, Safe: pipeline.unsafe, Finalized: pipeline.unsafe}, <-ch,
+		"stale update is replaced with the latest if the subscriber is not ready")
+	require.Empty(t, ch)
+
+	unsubscribe()
+	pipeline.unsafe = testutils.RandomL2BlockRef(rng)
+	s.snapshotOnChange("Unsafe head change")
+	require.Empty(t, ch, "no updates after unsubscribing")
+}
@@ -0,0 +1,27 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cachedPayloadSource looks up payloads in the cache of recently seen unsafe payloads first,
+// before fetching them from the source, e.g. to find the parents of a queued unsafe payload
+// without a roundtrip to the engine.
+type cachedPayloadSource struct {
+	cache  *caching.LRUCache
+	source derive.UnsafeBackfillSource
+}
+
+var _ derive.UnsafeBackfillSource = (*cachedPayloadSource)(nil)
+
+func (s *cachedPayloadSource) PayloadByHash(ctx context.Context, hash common.Hash) (*eth.ExecutionPayload, error) {
+	if payload, ok := s.cache.Get(hash); ok {
+		return payload.(*eth.ExecutionPayload), nil
+	}
+	return s.source.PayloadByHash(ctx, hash)
+}
@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type fakeQueuePipeline struct {
+	fakeHeadsPipeline
+	queued map[common.Hash]*eth.ExecutionPayload
+}
+
+func (f *fakeQueuePipeline) HasUnsafePayload(hash common.Hash) bool {
+	_, ok := f.queued[hash]
+	return ok
+}
+
+func (f *fakeQueuePipeline) AddUnsafePayload(payload *eth.ExecutionPayload) {
+	f.queued[payload.BlockHash] = payload
+}
+
+type fakePayloadSource map[common.Hash]*eth.ExecutionPayload
+
+func (f fakePayloadSource) PayloadByHash(ctx context.Context, hash common.Hash) (*eth.ExecutionPayload, error) {
+	payload, ok := f[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return payload, nil
+}
+
+func TestQueueUnsafePayload(t *testing.T) {
+	pipeline := &fakeQueuePipeline{queued: make(map[common.Hash]*eth.ExecutionPayload)}
+	s := &state{
+		derivation:          pipeline,
+		metrics:             &testutils.RecordingMetrics{},
+		log:                 testlog.Logger(t, log.LvlDebug),
+		unsafePayloadsCache: caching.NewLRUCache(nil, "payloads", unsafePayloadsCacheSize),
+	}
+	a := &eth.ExecutionPayload{BlockNumber: 1, BlockHash: common.Hash{0xa}}
+	b := &eth.ExecutionPayload{BlockNumber: 2, BlockHash: common.Hash{0xb}, ParentHash: a.BlockHash}
+
+	require.True(t, s.queueUnsafePayload(a))
+	require.False(t, s.queueUnsafePayload(a), "repeated gossip of a queued payload is ignored")
+
+	// the engine queue processed a, and dropped b after queueing it
+	delete(pipeline.queued, a.BlockHash)
+	pipeline.unsafe = eth.L2BlockRef{Hash: a.BlockHash, Number: 1}
+	require.True(t, s.queueUnsafePayload(b))
+	delete(pipeline.queued, b.BlockHash)
+
+	require.False(t, s.queueUnsafePayload(a), "the unsafe head is ignored")
+	require.True(t, s.queueUnsafePayload(b), "payloads that were dropped from the queue are accepted again")
+
+	// seen payloads are looked up from the cache before the source
+	source := &cachedPayloadSource{cache: s.unsafePayloadsCache, source: fakePayloadSource{}}
+	got, err := source.PayloadByHash(context.Background(), a.BlockHash)
+	require.NoError(t, err)
+	require.Equal(t, a, got)
+	_, err = source.PayloadByHash(context.Background(), common.Hash{0xc})
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
@@ -0,0 +1,146 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/clock"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type L1Blocks interface {
+	derive.L1BlockRefByHashFetcher
+	derive.L1BlockRefByNumberFetcher
+}
+
+// Sequencer implements the block production of sequencer nodes:
+// it selects the L1 origin of the next L2 block, and builds the block with the engine.
+// It does not track the chain heads itself, those are passed in with every step,
+// so the same sequencing code can be driven by the driver event loop or directly by a test.
+type Sequencer struct {
+	log       log.Logger
+	config    *rollup.Config
+	confDepth uint64
+
+	l1      L1Blocks
+	output  outputInterface
+	metrics SequencerMetrics
+}
+
+// SequencerMetrics tracks the block building of the sequencer.
+type SequencerMetrics interface {
+	RecordSequencerBuildDeadlineMissed()
+	RecordSequencerL1OriginAge(blocks uint64)
+}
+
+// NewSequencer creates a Sequencer that selects L1 origins with the given confirmation depth,
+// and builds blocks on the given L2 engine, within the given build deadline (none if 0).
+func NewSequencer(log log.Logger, cfg *rollup.Config, confDepth uint64, buildDeadline time.Duration, l1 L1Chain, l2 derive.Engine, metrics SequencerMetrics, clk clock.Clock) *Sequencer {
+	return &Sequencer{
+		log:       log,
+		config:    cfg,
+		confDepth: confDepth,
+		l1:        l1,
+		metrics:   metrics,
+		output: &outputImpl{
+			Config:        cfg,
+			dl:            l1,
+			l2:            l2,
+			log:           log,
+			buildDeadline: buildDeadline,
+			metrics:       metrics,
+			clock:         clk,
+		},
+	}
+}
+
+// FindL1Origin determines what the next L1 Origin should be.
+// The L1 Origin is either the L2 Head's Origin, or the following L1 block
+// if the next L2 block's time is greater than or equal to the L2 Head's Origin.
+func (d *Sequencer) FindL1Origin(ctx context.Context, l1Head eth.L1BlockRef, l2Head eth.L2BlockRef) (eth.L1BlockRef, error) {
+	// If we are at the head block, don't do a lookup.
+	if l2Head.L1Origin.Hash == l1Head.Hash {
+		return l1Head, nil
+	}
+
+	// Grab a reference to the current L1 origin block.
+	currentOrigin, err := d.l1.L1BlockRefByHash(ctx, l2Head.L1Origin.Hash)
+	if err != nil {
+		return eth.L1BlockRef{}, err
+	}
+
+	if currentOrigin.Number+1+d.confDepth > l1Head.Number {
+		// TODO: we can decide to ignore confirmation depth if we would be forced
+		//  to make an empty block (only deposits) by staying on the current origin.
+		d.log.Info("sequencing with old origin to preserve conf depth",
+			"current", currentOrigin, "current_time", currentOrigin.Time,
+			"l1_head", l1Head, "l1_head_time", l1Head.Time,
+			"l2_head", l2Head, "l2_head_time", l2Head.Time,
+			"depth", d.confDepth)
+		return currentOrigin, nil
+	}
+
+	// Attempt to find the next L1 origin block, where the next origin is the immediate child of
+	// the current origin block.
+	nextOrigin, err := d.l1.L1BlockRefByNumber(ctx, currentOrigin.Number+1)
+	if err != nil {
+		d.log.Error("Failed to get next origin. Falling back to current origin", "err", err)
+		return currentOrigin, nil
+	}
+
+	// If the next L2 block time is greater than the next origin block's time, we can choose to
+	// start building on top of the next origin. Sequencer implementation has some leeway here and
+	// could decide to continue to build on top of the previous origin until the Sequencer runs out
+	// of slack. For simplicity, we implement our Sequencer to always start building on the latest
+	// L1 block when we can.
+	if l2Head.Time+d.config.BlockTime >= nextOrigin.Time {
+		return nextOrigin, nil
+	}
+
+	return currentOrigin, nil
+}
+
+// CreateNewBlock builds a L2 block on top of the given L2 head (unsafe), with an L1 origin selected by FindL1Origin.
+// It returns a nil payload, and no error, if block production has not started yet
+// because the next L1 origin is behind the L1 genesis.
+func (d *Sequencer) CreateNewBlock(ctx context.Context, l1Head eth.L1BlockRef, l2Head eth.L2BlockRef, l2Safe eth.BlockID, l2Finalized eth.BlockID) (eth.L2BlockRef, *eth.ExecutionPayload, error) {
+	// Figure out which L1 origin block we're going to be building on top of.
+	l1Origin, err := d.FindL1Origin(ctx, l1Head, l2Head)
+	if err != nil {
+		d.log.Error("Error finding next L1 Origin", "err", err)
+		return l2Head, nil, err
+	}
+
+	// Rollup is configured to not start producing blocks until a specific L1 block has been
+	// reached. Don't produce any blocks until we're at that genesis block.
+	if l1Origin.Number < d.config.Genesis.L1.Number {
+		d.log.Info("Skipping block production because the next L1 Origin is behind the L1 genesis", "next", l1Origin.ID(), "genesis", d.config.Genesis.L1)
+		return l2Head, nil, nil
+	}
+
+	// Should never happen. Sequencer will halt if we get into this situation somehow.
+	nextL2Time := l2Head.Time + d.config.BlockTime
+	if nextL2Time < l1Origin.Time {
+		d.log.Error("Cannot build L2 block for time before L1 origin",
+			"l2Unsafe", l2Head, "nextL2Time", nextL2Time, "l1Origin", l1Origin, "l1OriginTime", l1Origin.Time)
+		return l2Head, nil, fmt.Errorf("cannot build L2 block on top %s for time %d before L1 origin %s at time %d",
+			l2Head, nextL2Time, l1Origin, l1Origin.Time)
+	}
+
+	// Actually create the new block.
+	newUnsafeL2Head, payload, err := d.output.createNewBlock(ctx, l2Head, l2Safe, l2Finalized, l1Origin)
+	if err != nil {
+		d.log.Error("Could not extend chain as sequencer", "err", err, "l2_parent", l2Head, "l1_origin", l1Origin)
+		return l2Head, nil, err
+	}
+	// The age of the adopted origin is the number of L1 blocks on top of it,
+	// at least the confirmation depth, unless the origin could not be changed.
+	if l1Head.Number >= l1Origin.Number {
+		d.metrics.RecordSequencerL1OriginAge(l1Head.Number - l1Origin.Number)
+	}
+	return newUnsafeL2Head, payload, nil
+}
@@ -0,0 +1,301 @@
+package driver
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/clock"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeL1Chain is a L1 chain that only grows when a block is mined explicitly.
+type fakeL1Chain struct {
+	L1Chain // only the methods used for sequencing are implemented
+
+	blocks []*testutils.MockBlockInfo
+}
+
+func (c *fakeL1Chain) mine(blockTime uint64) eth.L1BlockRef {
+	info := &testutils.MockBlockInfo{InfoBaseFee: big.NewInt(7), InfoReceiptRoot: types.EmptyRootHash}
+	if n := len(c.blocks); n > 0 {
+		parent := c.blocks[n-1]
+		info.InfoParentHash = parent.InfoHash
+		info.InfoNum = parent.InfoNum + 1
+		info.InfoTime = parent.InfoTime + blockTime
+	}
+	var num [8]byte
+	binary.BigEndian.PutUint64(num[:], info.InfoNum)
+	info.InfoHash = crypto.Keccak256Hash([]byte("l1"), num[:])
+	c.blocks = append(c.blocks, info)
+	return info.BlockRef()
+}
+
+func (c *fakeL1Chain) head() eth.L1BlockRef {
+	return c.blocks[len(c.blocks)-1].BlockRef()
+}
+
+func (c *fakeL1Chain) byHash(hash common.Hash) (*testutils.MockBlockInfo, error) {
+	for _, b := range c.blocks {
+		if b.InfoHash == hash {
+			return b, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeL1Chain) L1BlockRefByHash(ctx context.Context, hash common.Hash) (eth.L1BlockRef, error) {
+	b, err := c.byHash(hash)
+	if err != nil {
+		return eth.L1BlockRef{}, err
+	}
+	return b.BlockRef(), nil
+}
+
+func (c *fakeL1Chain) L1BlockRefByNumber(ctx context.Context, num uint64) (eth.L1BlockRef, error) {
+	if num >= uint64(len(c.blocks)) {
+		return eth.L1BlockRef{}, ethereum.NotFound
+	}
+	return c.blocks[num].BlockRef(), nil
+}
+
+func (c *fakeL1Chain) InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error) {
+	return c.byHash(hash)
+}
+
+func (c *fakeL1Chain) Fetch(ctx context.Context, hash common.Hash) (eth.BlockInfo, types.Transactions, eth.ReceiptsFetcher, error) {
+	b, err := c.byHash(hash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return b, nil, eth.FetchedReceipts(nil), nil
+}
+
+// fakeEngine builds payloads directly from the payload attributes, and remembers the attributes it was given.
+type fakeEngine struct {
+	derive.Engine // only the block building methods are implemented
+
+	numbers map[common.Hash]uint64
+	pending map[eth.PayloadID]*eth.ExecutionPayload
+	attrs   []*eth.PayloadAttributes
+}
+
+func (e *fakeEngine) ForkchoiceUpdate(ctx context.Context, fc *eth.ForkchoiceState, attr *eth.PayloadAttributes) (*eth.ForkchoiceUpdatedResult, error) {
+	res := &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid}}
+	if attr == nil {
+		return res, nil
+	}
+	parentNum, ok := e.numbers[fc.HeadBlockHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown parent %s", fc.HeadBlockHash)
+	}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(attr.Timestamp))
+	payload := &eth.ExecutionPayload{
+		ParentHash:   fc.HeadBlockHash,
+		FeeRecipient: attr.SuggestedFeeRecipient,
+		PrevRandao:   attr.PrevRandao,
+		BlockNumber:  eth.Uint64Quantity(parentNum + 1),
+		Timestamp:    attr.Timestamp,
+		BlockHash:    crypto.Keccak256Hash([]byte("l2"), fc.HeadBlockHash[:], ts[:]),
+		Transactions: attr.Transactions,
+	}
+	id := eth.PayloadID{byte(len(e.attrs))}
+	e.pending[id] = payload
+	e.attrs = append(e.attrs, attr)
+	res.PayloadID = &id
+	return res, nil
+}
+
+func (e *fakeEngine) GetPayload(ctx context.Context, id eth.PayloadID) (*eth.ExecutionPayload, error) {
+	payload, ok := e.pending[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown payload id %s", id)
+	}
+	return payload, nil
+}
+
+func (e *fakeEngine) NewPayload(ctx context.Context, payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
+	e.numbers[payload.BlockHash] = uint64(payload.BlockNumber)
+	return &eth.PayloadStatusV1{Status: eth.ExecutionValid}, nil
+}
+
+// TestSequencerDriftExhaustion stalls L1 while the sequencer keeps producing L2 blocks,
+// until the max sequencer drift forces blocks without tx-pool transactions,
+// and then checks the sequencer catches up with L1 and includes tx-pool transactions again once L1 resumes.
+func TestSequencerDriftExhaustion(t *testing.T) {
+	const l1BlockTime = 12
+	l1 := &fakeL1Chain{}
+	l1Genesis := l1.mine(l1BlockTime)
+
+	l2Genesis := eth.L2BlockRef{Hash: common.Hash{0x2}, Number: 0, Time: l1Genesis.Time, L1Origin: l1Genesis.ID()}
+	cfg := &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1:     l1Genesis.ID(),
+			L2:     l2Genesis.ID(),
+			L2Time: l2Genesis.Time,
+		},
+		BlockTime:         2,
+		MaxSequencerDrift: 10,
+		SeqWindowSize:     100,
+		ChannelTimeout:    30,
+	}
+	engine := &fakeEngine{
+		numbers: map[common.Hash]uint64{l2Genesis.Hash: l2Genesis.Number},
+		pending: make(map[eth.PayloadID]*eth.ExecutionPayload),
+	}
+	seq := NewSequencer(testlog.Logger(t, log.LvlError), cfg, 0, 0, l1, engine, &testutils.RecordingMetrics{}, clock.SystemClock)
+
+	l2Head := l2Genesis
+	buildL2Block := func() (noTxPool bool) {
+		ref, payload, err := seq.CreateNewBlock(context.Background(), l1.head(), l2Head, l2Genesis.ID(), l2Genesis.ID())
+		require.NoError(t, err)
+		require.NotNil(t, payload)
+		require.Equal(t, l2Head.Number+1, ref.Number)
+		require.Equal(t, l2Head.Time+cfg.BlockTime, ref.Time)
+		l2Head = ref
+		return engine.attrs[len(engine.attrs)-1].NoTxPool
+	}
+
+	// L1 is stalled: the sequencer keeps building on the same origin, until the drift is exhausted.
+	for l2Head.Time+cfg.BlockTime < l1Genesis.Time+cfg.MaxSequencerDrift {
+		require.False(t, buildL2Block(), "tx-pool is used within the sequencer drift, at L2 time %d", l2Head.Time)
+		require.Equal(t, l1Genesis.ID(), l2Head.L1Origin)
+	}
+	for i := 0; i < 10; i++ {
+		require.True(t, buildL2Block(), "tx-pool must not be used beyond the sequencer drift, at L2 time %d", l2Head.Time)
+		require.Equal(t, l1Genesis.ID(), l2Head.L1Origin, "origin cannot change while L1 is stalled")
+	}
+
+	// L1 resumes: mine enough blocks to cover the L2 chain time, and let the sequencer catch up.
+	for l1.head().Time < l2Head.Time+cfg.BlockTime {
+		l1.mine(l1BlockTime)
+	}
+	for i := 0; l2Head.L1Origin != l1.head().ID(); i++ {
+		require.Less(t, i, 100, "sequencer must adopt the L1 head as origin eventually")
+		buildL2Block()
+	}
+	require.False(t, buildL2Block(), "tx-pool is used again once the sequencer caught up with L1")
+}
+
+// slowEngine simulates an engine that takes the given delay to start building a payload,
+// and the given insertDelay to update the forkchoice to an inserted payload.
+type slowEngine struct {
+	*fakeEngine
+	delay       time.Duration
+	insertDelay time.Duration
+}
+
+func (e *slowEngine) ForkchoiceUpdate(ctx context.Context, fc *eth.ForkchoiceState, attr *eth.PayloadAttributes) (*eth.ForkchoiceUpdatedResult, error) {
+	delay := e.insertDelay
+	if attr != nil {
+		delay = e.delay
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(delay):
+	}
+	return e.fakeEngine.ForkchoiceUpdate(ctx, fc, attr)
+}
+
+func TestSequencerBuildDeadline(t *testing.T) {
+	l1 := &fakeL1Chain{}
+	l1Genesis := l1.mine(12)
+	l2Genesis := eth.L2BlockRef{Hash: common.Hash{0x2}, Number: 0, Time: l1Genesis.Time, L1Origin: l1Genesis.ID()}
+	cfg := &rollup.Config{
+		Genesis:           rollup.Genesis{L1: l1Genesis.ID(), L2: l2Genesis.ID(), L2Time: l2Genesis.Time},
+		BlockTime:         2,
+		MaxSequencerDrift: 10,
+		SeqWindowSize:     100,
+		ChannelTimeout:    30,
+	}
+	engine := &slowEngine{fakeEngine: &fakeEngine{
+		numbers: map[common.Hash]uint64{l2Genesis.Hash: l2Genesis.Number},
+		pending: make(map[eth.PayloadID]*eth.ExecutionPayload),
+	}}
+	m := &testutils.RecordingMetrics{}
+	seq := NewSequencer(testlog.Logger(t, log.LvlError), cfg, 0, time.Millisecond*50, l1, engine, m, clock.SystemClock)
+
+	// a fast engine builds within the deadline
+	ref, payload, err := seq.CreateNewBlock(context.Background(), l1.head(), l2Genesis, l2Genesis.ID(), l2Genesis.ID())
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+	m.RequireCount(t, "RecordSequencerBuildDeadlineMissed", 0)
+
+	// a slow engine misses the deadline, and the block is not built
+	engine.delay = time.Second
+	next, payload, err := seq.CreateNewBlock(context.Background(), l1.head(), ref, l2Genesis.ID(), l2Genesis.ID())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, payload)
+	require.Equal(t, ref, next)
+	m.RequireCount(t, "RecordSequencerBuildDeadlineMissed", 1)
+
+	// once the engine is fast again, building resumes
+	engine.delay = 0
+	next, payload, err = seq.CreateNewBlock(context.Background(), l1.head(), ref, l2Genesis.ID(), l2Genesis.ID())
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+	require.Equal(t, ref.Number+1, next.Number)
+	m.RequireCount(t, "RecordSequencerBuildDeadlineMissed", 1)
+
+	// the deadline does not apply to the insertion of the built block
+	engine.insertDelay = time.Millisecond * 100
+	next, payload, err = seq.CreateNewBlock(context.Background(), l1.head(), next, l2Genesis.ID(), l2Genesis.ID())
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+	require.Equal(t, ref.Number+2, next.Number)
+	m.RequireCount(t, "RecordSequencerBuildDeadlineMissed", 1)
+}
+
+func TestSequencerL1OriginAge(t *testing.T) {
+	l1 := &fakeL1Chain{}
+	l1Genesis := l1.mine(2)
+	for i := 0; i < 4; i++ {
+		l1.mine(2)
+	}
+	l2Genesis := eth.L2BlockRef{Hash: common.Hash{0x2}, Number: 0, Time: l1Genesis.Time, L1Origin: l1Genesis.ID()}
+	cfg := &rollup.Config{
+		Genesis:           rollup.Genesis{L1: l1Genesis.ID(), L2: l2Genesis.ID(), L2Time: l2Genesis.Time},
+		BlockTime:         2,
+		MaxSequencerDrift: 10,
+		SeqWindowSize:     100,
+		ChannelTimeout:    30,
+	}
+	engine := &fakeEngine{
+		numbers: map[common.Hash]uint64{l2Genesis.Hash: l2Genesis.Number},
+		pending: make(map[eth.PayloadID]*eth.ExecutionPayload),
+	}
+	m := &testutils.RecordingMetrics{}
+	seq := NewSequencer(testlog.Logger(t, log.LvlError), cfg, 2, 0, l1, engine, m, clock.SystemClock)
+
+	l2Head := l2Genesis
+	var origins []uint64
+	for i := 0; i < 3; i++ {
+		ref, payload, err := seq.CreateNewBlock(context.Background(), l1.head(), l2Head, l2Genesis.ID(), l2Genesis.ID())
+		require.NoError(t, err)
+		require.NotNil(t, payload)
+		l2Head = ref
+		origins = append(origins, ref.L1Origin.Number)
+	}
+	// the origin advances with the L2 time, until it is as close to the L1 head as the conf depth allows
+	require.Equal(t, []uint64{1, 2, 2}, origins)
+	records := m.Records("RecordSequencerL1OriginAge")
+	require.Len(t, records, 3)
+	for i, r := range records {
+		require.Equal(t, l1.head().Number-origins[i], r.Value, "age of block %d", i)
+	}
+}
@@ -0,0 +1,104 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOutput struct {
+	l1Origin eth.L1BlockRef
+}
+
+func (f *fakeOutput) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *eth.ExecutionPayload, error) {
+	f.l1Origin = l1Origin
+	ref := eth.L2BlockRef{
+		Hash:       common.Hash{0xff},
+		Number:     l2Head.Number + 1,
+		ParentHash: l2Head.Hash,
+		Time:       l2Head.Time + 2,
+		L1Origin:   l1Origin.ID(),
+	}
+	return ref, &eth.ExecutionPayload{BlockHash: ref.Hash}, nil
+}
+
+func TestSequencerFindL1Origin(t *testing.T) {
+	cfg := &rollup.Config{BlockTime: 2}
+	origin := eth.L1BlockRef{Hash: common.Hash{0xa}, Number: 10, Time: 100}
+	next := eth.L1BlockRef{Hash: common.Hash{0xb}, Number: 11, ParentHash: origin.Hash, Time: 112}
+	head := eth.L1BlockRef{Hash: common.Hash{0xc}, Number: 12, ParentHash: next.Hash, Time: 124}
+
+	newSequencer := func(l1 *testutils.MockL1Source, confDepth uint64) *Sequencer {
+		return &Sequencer{log: testlog.Logger(t, log.LvlError), config: cfg, confDepth: confDepth, l1: l1, output: &fakeOutput{}}
+	}
+
+	t.Run("at L1 head", func(t *testing.T) {
+		l1 := &testutils.MockL1Source{}
+		l2Head := eth.L2BlockRef{Number: 5, Time: 130, L1Origin: head.ID()}
+		out, err := newSequencer(l1, 0).FindL1Origin(context.Background(), head, l2Head)
+		require.NoError(t, err)
+		require.Equal(t, head, out)
+		l1.AssertExpectations(t)
+	})
+	t.Run("keep origin until next origin time", func(t *testing.T) {
+		l1 := &testutils.MockL1Source{}
+		l1.ExpectL1BlockRefByHash(origin.Hash, origin, nil)
+		l1.ExpectL1BlockRefByNumber(next.Number, next, nil)
+		l2Head := eth.L2BlockRef{Number: 5, Time: 108, L1Origin: origin.ID()}
+		out, err := newSequencer(l1, 0).FindL1Origin(context.Background(), head, l2Head)
+		require.NoError(t, err)
+		require.Equal(t, origin, out)
+		l1.AssertExpectations(t)
+	})
+	t.Run("adopt next origin", func(t *testing.T) {
+		l1 := &testutils.MockL1Source{}
+		l1.ExpectL1BlockRefByHash(origin.Hash, origin, nil)
+		l1.ExpectL1BlockRefByNumber(next.Number, next, nil)
+		l2Head := eth.L2BlockRef{Number: 5, Time: 110, L1Origin: origin.ID()}
+		out, err := newSequencer(l1, 0).FindL1Origin(context.Background(), head, l2Head)
+		require.NoError(t, err)
+		require.Equal(t, next, out)
+		l1.AssertExpectations(t)
+	})
+	t.Run("conf depth", func(t *testing.T) {
+		l1 := &testutils.MockL1Source{}
+		l1.ExpectL1BlockRefByHash(origin.Hash, origin, nil)
+		l2Head := eth.L2BlockRef{Number: 5, Time: 110, L1Origin: origin.ID()}
+		out, err := newSequencer(l1, 2).FindL1Origin(context.Background(), head, l2Head)
+		require.NoError(t, err)
+		require.Equal(t, origin, out)
+		l1.AssertExpectations(t)
+	})
+}
+
+func TestSequencerCreateNewBlock(t *testing.T) {
+	l1Head := eth.L1BlockRef{Hash: common.Hash{0xa}, Number: 10, Time: 100}
+	l2Head := eth.L2BlockRef{Hash: common.Hash{0x1}, Number: 5, Time: 100, L1Origin: l1Head.ID()}
+
+	t.Run("before genesis", func(t *testing.T) {
+		cfg := &rollup.Config{BlockTime: 2, Genesis: rollup.Genesis{L1: eth.BlockID{Number: 20}}}
+		out := &fakeOutput{}
+		s := &Sequencer{log: testlog.Logger(t, log.LvlError), config: cfg, l1: &testutils.MockL1Source{}, output: out}
+		ref, payload, err := s.CreateNewBlock(context.Background(), l1Head, l2Head, eth.BlockID{}, eth.BlockID{})
+		require.NoError(t, err)
+		require.Nil(t, payload, "no block production before genesis")
+		require.Equal(t, l2Head, ref)
+	})
+	t.Run("build", func(t *testing.T) {
+		cfg := &rollup.Config{BlockTime: 2}
+		out := &fakeOutput{}
+		s := &Sequencer{log: testlog.Logger(t, log.LvlError), config: cfg, l1: &testutils.MockL1Source{}, output: out, metrics: &testutils.RecordingMetrics{}}
+		ref, payload, err := s.CreateNewBlock(context.Background(), l1Head, l2Head, eth.BlockID{}, eth.BlockID{})
+		require.NoError(t, err)
+		require.NotNil(t, payload)
+		require.Equal(t, l2Head.Number+1, ref.Number)
+		require.Equal(t, l1Head, out.l1Origin)
+	})
+}
@@ -0,0 +1,116 @@
+package driver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// SnapshotState is an entry of the snapshot log, the heads of the driver at the time of an event.
+type SnapshotState struct {
+	Timestamp       string         `json:"t"`
+	EngineAddr      string         `json:"engine_addr"`
+	Event           string         `json:"event"`           // event name
+	L1Head          eth.L1BlockRef `json:"l1Head"`          // what we see as head on L1
+	L1Current       eth.L1BlockRef `json:"l1Current"`       // l1 block that the derivation is currently using
+	L2Head          eth.L2BlockRef `json:"l2Head"`          // l2 block that was last optimistically accepted (unsafe head)
+	L2SafeHead      eth.L2BlockRef `json:"l2SafeHead"`      // l2 block that was last derived
+	L2FinalizedHead eth.BlockID    `json:"l2FinalizedHead"` // l2 block that is irreversible
+}
+
+// UnmarshalJSON decodes a snapshot log entry, of which the heads are encoded as JSON strings.
+func (e *SnapshotState) UnmarshalJSON(data []byte) error {
+	t := struct {
+		Timestamp       string          `json:"t"`
+		EngineAddr      string          `json:"engine_addr"`
+		Event           string          `json:"event"`
+		L1Head          json.RawMessage `json:"l1Head"`
+		L1Current       json.RawMessage `json:"l1Current"`
+		L2Head          json.RawMessage `json:"l2Head"`
+		L2SafeHead      json.RawMessage `json:"l2SafeHead"`
+		L2FinalizedHead json.RawMessage `json:"l2FinalizedHead"`
+	}{}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	e.Timestamp = t.Timestamp
+	e.EngineAddr = t.EngineAddr
+	e.Event = t.Event
+
+	unquote := func(d json.RawMessage) []byte {
+		s, _ := strconv.Unquote(string(d))
+		return []byte(s)
+	}
+
+	if err := json.Unmarshal(unquote(t.L1Head), &e.L1Head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L1Current), &e.L1Current); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2Head), &e.L2Head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2SafeHead), &e.L2SafeHead); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(unquote(t.L2FinalizedHead), &e.L2FinalizedHead); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewSnapshotLogger creates a snapshot logger that writes the snapshots as JSON lines to w.
+func NewSnapshotLogger(w io.Writer) log.Logger {
+	logger := log.New()
+	logger.SetHandler(log.SyncHandler(log.StreamHandler(w, log.JSONFormat())))
+	return logger
+}
+
+// ReadSnapshots parses the JSON lines of a snapshot log.
+func ReadSnapshots(r io.Reader) ([]SnapshotState, error) {
+	var out []SnapshotState
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry SnapshotState
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot log entry %d: %w", len(out), err)
+		}
+		out = append(out, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan snapshot log: %w", err)
+	}
+	return out, nil
+}
+
+// SnapshotHead selects one of the heads of a snapshot.
+type SnapshotHead func(s *SnapshotState) eth.BlockID
+
+var (
+	SnapshotL1Head          SnapshotHead = func(s *SnapshotState) eth.BlockID { return s.L1Head.ID() }
+	SnapshotL1Current       SnapshotHead = func(s *SnapshotState) eth.BlockID { return s.L1Current.ID() }
+	SnapshotL2Head          SnapshotHead = func(s *SnapshotState) eth.BlockID { return s.L2Head.ID() }
+	SnapshotL2SafeHead      SnapshotHead = func(s *SnapshotState) eth.BlockID { return s.L2SafeHead.ID() }
+	SnapshotL2FinalizedHead SnapshotHead = func(s *SnapshotState) eth.BlockID { return s.L2FinalizedHead }
+)
+
+// HeadChanges returns the distinct consecutive values of the selected head in the snapshots,
+// i.e. the movements of the head in order, for tests to assert against.
+func HeadChanges(snapshots []SnapshotState, head SnapshotHead) []eth.BlockID {
+	var out []eth.BlockID
+	for i := range snapshots {
+		id := head(&snapshots[i])
+		if len(out) == 0 || out[len(out)-1] != id {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// SnapshotReorg is a reorg of a head, observed between two consecutive snapshots.
+type SnapshotReorg struct {
+	Index int    // index of the snapshot that observed the reorg
+	Event string // event of the snapshot that observed the reorg
+	Head  string // name of the head, e.g. "l1Head"
+	From  eth.BlockID
+	To    eth.BlockID
+}
+
+// SnapshotReorgs returns the reorgs of the L1 head and the L2 unsafe head in the snapshots:
+// the head moving to a different block at the same or a lower height,
+// or to a next block that does not build on the previous head.
+func SnapshotReorgs(snapshots []SnapshotState) []SnapshotReorg {
+	var out []SnapshotReorg
+	for i := 1; i < len(snapshots); i++ {
+		prev, s := &snapshots[i-1], &snapshots[i]
+		if isReorg(prev.L1Head.ID(), s.L1Head.ID(), s.L1Head.ParentHash) {
+			out = append(out, SnapshotReorg{Index: i, Event: s.Event, Head: "l1Head", From: prev.L1Head.ID(), To: s.L1Head.ID()})
+		}
+		if isReorg(prev.L2Head.ID(), s.L2Head.ID(), s.L2Head.ParentHash) {
+			out = append(out, SnapshotReorg{Index: i, Event: s.Event, Head: "l2Head", From: prev.L2Head.ID(), To: s.L2Head.ID()})
+		}
+	}
+	return out
+}
+
+func isReorg(prev eth.BlockID, next eth.BlockID, nextParent common.Hash) bool {
+	if prev == (eth.BlockID{}) || prev == next {
+		return false
+	}
+	if next.Number <= prev.Number {
+		return true
+	}
+	return next.Number == prev.Number+1 && nextParent != prev.Hash
+}
+
+// WriteSnapshotReport renders a markdown summary of a snapshot log, for humans to review a run:
+// the final heads, the number of blocks every head moved through, the reorgs,
+// and a timeline of all snapshots that changed any of the heads.
+func WriteSnapshotReport(w io.Writer, title string, snapshots []SnapshotState) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", title)
+	if len(snapshots) == 0 {
+		fmt.Fprintf(bw, "No snapshots.\n")
+		return bw.Flush()
+	}
+	first, last := &snapshots[0], &snapshots[len(snapshots)-1]
+	fmt.Fprintf(bw, "%d snapshots, from %s to %s.\n\n", len(snapshots), first.Timestamp, last.Timestamp)
+
+	fmt.Fprintf(bw, "## Heads\n\n")
+	fmt.Fprintf(bw, "| Head | Final | Changes |\n|---|---|---|\n")
+	heads := []struct {
+		name string
+		head SnapshotHead
+	}{
+		{"L1 head", SnapshotL1Head},
+		{"L1 current", SnapshotL1Current},
+		{"L2 unsafe head", SnapshotL2Head},
+		{"L2 safe head", SnapshotL2SafeHead},
+		{"L2 finalized head", SnapshotL2FinalizedHead},
+	}
+	for _, h := range heads {
+		fmt.Fprintf(bw, "| %s | %s | %d |\n", h.name, h.head(last), len(HeadChanges(snapshots, h.head))-1)
+	}
+
+	fmt.Fprintf(bw, "\n## Reorgs\n\n")
+	if reorgs := SnapshotReorgs(snapshots); len(reorgs) == 0 {
+		fmt.Fprintf(bw, "No reorgs.\n")
+	} else {
+		fmt.Fprintf(bw, "| Snapshot | Event | Head | From | To |\n|---|---|---|---|---|\n")
+		for _, r := range reorgs {
+			fmt.Fprintf(bw, "| %d | %s | %s | %s | %s |\n", r.Index, r.Event, r.Head, r.From, r.To)
+		}
+	}
+
+	fmt.Fprintf(bw, "\n## Timeline\n\n")
+	fmt.Fprintf(bw, "| Snapshot | Time | Event | L1 head | L1 current | L2 unsafe | L2 safe | L2 finalized |\n")
+	fmt.Fprintf(bw, "|---|---|---|---|---|---|---|---|\n")
+	for i := range snapshots {
+		s := &snapshots[i]
+		if i > 0 && !headsChanged(&snapshots[i-1], s) {
+			continue
+		}
+		fmt.Fprintf(bw, "| %d | %s | %s | %d | %d | %d | %d | %d |\n", i, s.Timestamp, s.Event,
+			s.L1Head.Number, s.L1Current.Number, s.L2Head.Number, s.L2SafeHead.Number, s.L2FinalizedHead.Number)
+	}
+	return bw.Flush()
+}
+
+func headsChanged(prev, s *SnapshotState) bool {
+	return prev.L1Head.ID() != s.L1Head.ID() || prev.L1Current.ID() != s.L1Current.ID() ||
+		prev.L2Head.ID() != s.L2Head.ID() || prev.L2SafeHead.ID() != s.L2SafeHead.ID() ||
+		prev.L2FinalizedHead != s.L2FinalizedHead
+}
@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestSnapshotReport(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	l1 := testutils.RandomBlockRef(rng)
+	a1 := testutils.NextRandomL2Ref(rng, 2, testutils.RandomL2BlockRef(rng), l1.ID())
+	a2 := testutils.NextRandomL2Ref(rng, 2, a1, l1.ID())
+	b2 := testutils.NextRandomL2Ref(rng, 2, a1, l1.ID()) // reorgs a2
+
+	snapshots := []SnapshotState{
+		{Timestamp: "t0", Event: "Start", L1Head: l1},
+		{Timestamp: "t1", Event: "Step", L1Head: l1, L2Head: a1},
+		{Timestamp: "t2", Event: "Step", L1Head: l1, L2Head: a1},
+		{Timestamp: "t3", Event: "Step", L1Head: l1, L2Head: a2, L2SafeHead: a1},
+		{Timestamp: "t4", Event: "Reset", L1Head: l1, L2Head: b2, L2SafeHead: a1, L2FinalizedHead: a1.ID()},
+	}
+	reorgs := SnapshotReorgs(snapshots)
+	require.Equal(t, []SnapshotReorg{{Index: 4, Event: "Reset", Head: "l2Head", From: a2.ID(), To: b2.ID()}}, reorgs)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshotReport(&buf, "Run", snapshots))
+	out := buf.String()
+	require.Contains(t, out, "# Run\n")
+	require.Contains(t, out, "5 snapshots, from t0 to t4.")
+	require.Contains(t, out, "| L2 unsafe head | "+b2.ID().String()+" | 3 |")
+	require.Contains(t, out, "| 4 | Reset | l2Head | "+a2.ID().String()+" | "+b2.ID().String()+" |")
+	require.NotContains(t, out, "| 2 | t2 |", "snapshots without head changes are not in the timeline")
+	require.Contains(t, out, "| 3 | t3 | Step |")
+
+	buf.Reset()
+	require.NoError(t, WriteSnapshotReport(&buf, "Empty", nil))
+	require.Contains(t, buf.String(), "No snapshots.")
+	require.Empty(t, SnapshotReorgs([]SnapshotState{{L2Head: a1}, {L2Head: a2}}))
+	require.Len(t, SnapshotReorgs([]SnapshotState{{L2Head: a2}, {L2Head: eth.L2BlockRef{Hash: a1.Hash, Number: a1.Number}}}), 1)
+}
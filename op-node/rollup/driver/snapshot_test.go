@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+type fakeHeadsPipeline struct {
+	DerivationPipeline
+	origin                  eth.L1BlockRef
+	unsafe, safe, finalized eth.L2BlockRef
+	lowestQueued            eth.L2BlockRef
+}
+
+func (f *fakeHeadsPipeline) Progress() derive.Progress {
+	return derive.Progress{Origin: f.origin}
+}
+
+func (f *fakeHeadsPipeline) UnsafeL2Head() eth.L2BlockRef { return f.unsafe }
+func (f *fakeHeadsPipeline) SafeL2Head() eth.L2BlockRef   { return f.safe }
+func (f *fakeHeadsPipeline) Finalized() eth.L2BlockRef    { return f.finalized }
+
+func (f *fakeHeadsPipeline) LowestQueuedUnsafeBlock() eth.L2BlockRef { return f.lowestQueued }
+
+func TestSnapshotLog(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	var buf bytes.Buffer
+	pipeline := &fakeHeadsPipeline{}
+	s := &state{
+		snapshotLog: NewSnapshotLogger(&buf),
+		derivation:  pipeline,
+	}
+
+	s.l1Head = testutils.RandomBlockRef(rng)
+	s.snapshot("Start")
+	s.snapshotOnChange("No change")
+
+	unsafe1 := testutils.RandomL2BlockRef(rng)
+	pipeline.unsafe = unsafe1
+	s.snapshotOnChange("Unsafe head change")
+
+	pipeline.origin = s.l1Head
+	pipeline.safe = unsafe1
+	s.snapshotOnChange("Safe head change")
+
+	unsafe2 := testutils.RandomL2BlockRef(rng)
+	pipeline.unsafe = unsafe2
+	pipeline.finalized = unsafe1
+	s.snapshotOnChange("Finalized head change")
+
+	snapshots, err := ReadSnapshots(&buf)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 4)
+	var events []string
+	for _, snap := range snapshots {
+		events = append(events, snap.Event)
+	}
+	require.Equal(t, []string{"Start", "Unsafe head change", "Safe head change", "Finalized head change"}, events)
+	require.Equal(t, s.l1Head, snapshots[0].L1Head)
+	require.Equal(t, unsafe1, snapshots[2].L2SafeHead)
+	require.Equal(t, s.l1Head, snapshots[2].L1Current)
+
+	require.Equal(t, []eth.BlockID{{}, unsafe1.ID(), unsafe2.ID()}, HeadChanges(snapshots, SnapshotL2Head))
+	require.Equal(t, []eth.BlockID{{}, unsafe1.ID()}, HeadChanges(snapshots, SnapshotL2SafeHead))
+	require.Equal(t, []eth.BlockID{{}, unsafe1.ID()}, HeadChanges(snapshots, SnapshotL2FinalizedHead))
+	require.Equal(t, []eth.BlockID{s.l1Head.ID()}, HeadChanges(snapshots, SnapshotL1Head))
+}
+
+func TestReadSnapshotsInvalid(t *testing.T) {
+	_, err := ReadSnapshots(bytes.NewBufferString("{\"event\":\"x\"}\nnot json\n"))
+	require.ErrorContains(t, err, "snapshot log entry 0")
+}
@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/backoff"
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -40,8 +42,27 @@ type SyncStatus struct {
 	// FinalizedL2 points to the L2 block that was derived fully from
 	// finalized L1 information, thus irreversible.
 	FinalizedL2 eth.L2BlockRef `json:"finalized_l2"`
+	// QueuedUnsafePayloads is the number of unsafe payloads received from the sequencer
+	// that are waiting to be processed, e.g. because they do not build on UnsafeL2 yet.
+	QueuedUnsafePayloads int `json:"queued_unsafe_payloads"`
+	// LowestQueuedUnsafeL2 is the block of the first queued unsafe payload, zeroed if there is none.
+	// The unsafe payloads between UnsafeL2 and this block are missing if it is not the next block.
+	LowestQueuedUnsafeL2 eth.L2BlockRef `json:"lowest_queued_unsafe_l2"`
 }
 
+// SequencerThrottle is the status of the throttling of the sequencer, see Config.SequencerMaxSafeLag.
+type SequencerThrottle struct {
+	// MaxSafeLag is the maximum number of L2 blocks the unsafe head may be ahead of the safe head, 0 if throttling is disabled.
+	MaxSafeLag uint64 `json:"max_safe_lag"`
+	// SafeLag is the number of L2 blocks the unsafe head is ahead of the safe head.
+	SafeLag uint64 `json:"safe_lag"`
+	// Throttled is true when the sequencer is paused until the safe head catches up.
+	Throttled bool `json:"throttled"`
+}
+
+// unsafePayloadsCacheSize is the number of recently seen unsafe payloads to remember.
+const unsafePayloadsCacheSize = 100
+
 type state struct {
 	// Latest recorded head, safe block and finalized block of the L1 Chain, independent of derivation work
 	l1Head      eth.L1BlockRef
@@ -62,6 +83,19 @@ type state struct {
 	// It tells the caller that the reset occurred by closing the passed in channel.
 	forceReset chan chan struct{}
 
+	// Requests for the sequencer throttle status, and changes of the max safe lag,
+	// synchronized with the event loop like the sync status requests.
+	sequencerThrottleReq chan chan SequencerThrottle
+	sequencerMaxSafeLag  chan sequencerMaxSafeLagReq
+
+	// Changes of the runtime config, synchronized with the event loop.
+	runtimeConfigReq chan runtimeConfigReq
+
+	// Maximum lag of the safe head behind the unsafe head before sequencing is paused, 0 if disabled
+	maxSafeLag uint64
+	// When the sequencer is paused because the safe head lags too far behind
+	sequencerThrottled bool
+
 	// Rollup config: rollup chain configuration
 	Config *rollup.Config
 
@@ -81,23 +115,45 @@ type state struct {
 	// L2 Signals:
 	unsafeL2Payloads chan *eth.ExecutionPayload
 
-	l1      L1Chain
-	l2      L2Chain
-	output  outputInterface
-	network Network // may be nil, network for is optional
+	// Recently seen unsafe payloads by block hash, to look up the parents of queued unsafe payloads.
+	unsafePayloadsCache *caching.LRUCache
+
+	// Store of unsafe payloads that do not fit in memory, nil if spilling is disabled.
+	unsafePayloadsSpill *derive.DiskPayloadStore
+
+	l1        L1Chain
+	l2        L2Chain
+	sequencer *Sequencer
+	network   Network // may be nil, network for is optional
+	altSync   AltSync // may be nil, to not request missing unsafe payloads
+
+	// source to backfill missing unsafe payloads from, the L2 engine if nil
+	unsafeBackfill derive.UnsafeBackfillSource
+
+	// confirmation depth of the L1 data for derivation, may be nil if the pipeline does not read through it
+	verifierConfDepth *confDepth
 
 	metrics     Metrics
+	clock       clock.Clock
 	log         log.Logger
 	snapshotLog log.Logger
-	done        chan struct{}
+	// heads of the last snapshot, to snapshot any head changes
+	lastSnapshot snapshotHeads
+	done         chan struct{}
+
+	// subscribers to L2 head changes, see SubscribeHeads
+	headSubsLock gosync.Mutex
+	headSubs     map[chan HeadUpdate]struct{}
+	// the L2 heads that the head subscribers were last notified of
+	lastHeads HeadUpdate
 
 	wg gosync.WaitGroup
 }
 
 // NewState creates a new driver state. State changes take effect though
-// the given output, derivation pipeline and network interfaces.
+// the given sequencer, derivation pipeline and network interfaces.
 func NewState(driverCfg *Config, log log.Logger, snapshotLog log.Logger, config *rollup.Config, l1Chain L1Chain, l2Chain L2Chain,
-	output outputInterface, derivationPipeline DerivationPipeline, network Network, metrics Metrics) *state {
+	sequencer *Sequencer, derivationPipeline DerivationPipeline, network Network, altSync AltSync, metrics Metrics, cacheMetrics caching.Metrics, clk clock.Clock) *state {
 	return &state{
 		derivation:       derivationPipeline,
 		idleDerivation:   false,
@@ -110,19 +166,52 @@ func NewState(driverCfg *Config, log log.Logger, snapshotLog log.Logger, config
 		snapshotLog:      snapshotLog,
 		l1:               l1Chain,
 		l2:               l2Chain,
-		output:           output,
+		sequencer:        sequencer,
 		network:          network,
+		altSync:          altSync,
 		metrics:          metrics,
+		clock:            clk,
 		l1HeadSig:        make(chan eth.L1BlockRef, 10),
 		l1SafeSig:        make(chan eth.L1BlockRef, 10),
 		l1FinalizedSig:   make(chan eth.L1BlockRef, 10),
 		unsafeL2Payloads: make(chan *eth.ExecutionPayload, 10),
+
+		unsafePayloadsCache: caching.NewLRUCache(cacheMetrics, "payloads", unsafePayloadsCacheSize),
+
+		sequencerThrottleReq: make(chan chan SequencerThrottle, 10),
+		sequencerMaxSafeLag:  make(chan sequencerMaxSafeLagReq, 10),
+		maxSafeLag:           driverCfg.SequencerMaxSafeLag,
+		runtimeConfigReq:     make(chan runtimeConfigReq, 10),
+
+		headSubs: make(map[chan HeadUpdate]struct{}),
 	}
 }
 
 // Start starts up the state loop.
 // The loop will have been started iff err is not nil.
 func (s *state) Start(_ context.Context) error {
+	if dir := s.DriverConfig.UnsafePayloadsSpillDir; dir != "" {
+		store, err := derive.NewDiskPayloadStore(dir)
+		if err != nil {
+			return fmt.Errorf("failed to open unsafe payloads spill store: %w", err)
+		}
+		s.unsafePayloadsSpill = store
+		s.derivation.SpillUnsafePayloads(store, s.DriverConfig.UnsafePayloadsSpillSize)
+	}
+	if depth := s.DriverConfig.UnsafeBackfillDepth; depth > 0 {
+		source := s.unsafeBackfill
+		if source == nil {
+			source = s.l2
+		}
+		s.derivation.SetUnsafeBackfill(&cachedPayloadSource{cache: s.unsafePayloadsCache, source: source}, depth)
+	}
+	if size := s.DriverConfig.UnsafePayloadBatchSize; size > 1 {
+		s.derivation.SetUnsafePayloadBatch(size)
+	}
+	if anchor := s.DriverConfig.TrustedAnchor; anchor != nil {
+		s.log.Info("Syncing from trusted anchor", "l2", anchor.L2Hash, "l1_origin", anchor.L1OriginHash)
+		s.derivation.SetTrustedAnchor(anchor)
+	}
 	s.derivation.Reset()
 
 	s.wg.Add(1)
@@ -134,6 +223,9 @@ func (s *state) Start(_ context.Context) error {
 func (s *state) Close() error {
 	s.done <- struct{}{}
 	s.wg.Wait()
+	if s.unsafePayloadsSpill != nil {
+		return s.unsafePayloadsSpill.Close()
+	}
 	return nil
 }
 
@@ -213,92 +305,40 @@ func (s *state) handleNewL1FinalizedBlock(finalized eth.L1BlockRef) {
 	// TODO(proto): forward signal to derivation to finalize L2 chain as well
 }
 
-// findL1Origin determines what the next L1 Origin should be.
-// The L1 Origin is either the L2 Head's Origin, or the following L1 block
-// if the next L2 block's time is greater than or equal to the L2 Head's Origin.
-func (s *state) findL1Origin(ctx context.Context) (eth.L1BlockRef, error) {
-	l2Head := s.derivation.UnsafeL2Head()
-	// If we are at the head block, don't do a lookup.
-	if l2Head.L1Origin.Hash == s.l1Head.Hash {
-		return s.l1Head, nil
-	}
-
-	// Grab a reference to the current L1 origin block.
-	currentOrigin, err := s.l1.L1BlockRefByHash(ctx, l2Head.L1Origin.Hash)
-	if err != nil {
-		return eth.L1BlockRef{}, err
-	}
-
-	if currentOrigin.Number+1+s.DriverConfig.SequencerConfDepth > s.l1Head.Number {
-		// TODO: we can decide to ignore confirmation depth if we would be forced
-		//  to make an empty block (only deposits) by staying on the current origin.
-		s.log.Info("sequencing with old origin to preserve conf depth",
-			"current", currentOrigin, "current_time", currentOrigin.Time,
-			"l1_head", s.l1Head, "l1_head_time", s.l1Head.Time,
-			"l2_head", l2Head, "l2_head_time", l2Head.Time,
-			"depth", s.DriverConfig.SequencerConfDepth)
-		return currentOrigin, nil
-	}
-
-	// Attempt to find the next L1 origin block, where the next origin is the immediate child of
-	// the current origin block.
-	nextOrigin, err := s.l1.L1BlockRefByNumber(ctx, currentOrigin.Number+1)
-	if err != nil {
-		s.log.Error("Failed to get next origin. Falling back to current origin", "err", err)
-		return currentOrigin, nil
-	}
-
-	// If the next L2 block time is greater than the next origin block's time, we can choose to
-	// start building on top of the next origin. Sequencer implementation has some leeway here and
-	// could decide to continue to build on top of the previous origin until the Sequencer runs out
-	// of slack. For simplicity, we implement our Sequencer to always start building on the latest
-	// L1 block when we can.
-	if l2Head.Time+s.Config.BlockTime >= nextOrigin.Time {
-		return nextOrigin, nil
+// queueUnsafePayload queues the unsafe payload for derivation, and returns false if it is ignored.
+// Repeated gossip of a queued payload, or of the current unsafe head, is ignored.
+// Payloads that were dropped from the queue are accepted again, e.g. when requested again with alt-sync.
+func (s *state) queueUnsafePayload(payload *eth.ExecutionPayload) bool {
+	if s.derivation.HasUnsafePayload(payload.BlockHash) || s.derivation.UnsafeL2Head().Hash == payload.BlockHash {
+		s.log.Debug("Ignoring already queued unsafe L2 execution payload", "id", payload.ID())
+		return false
 	}
-
-	return currentOrigin, nil
+	s.unsafePayloadsCache.Add(payload.BlockHash, payload)
+	s.log.Info("Optimistically queueing unsafe L2 execution payload", "id", payload.ID())
+	s.derivation.AddUnsafePayload(payload)
+	s.metrics.RecordReceivedUnsafePayload(payload)
+	return true
 }
 
 // createNewL2Block builds a L2 block on top of the L2 Head (unsafe). Used by Sequencer nodes to
 // construct new L2 blocks. Verifier nodes will use handleEpoch instead.
 func (s *state) createNewL2Block(ctx context.Context) error {
-	// Figure out which L1 origin block we're going to be building on top of.
-	l1Origin, err := s.findL1Origin(ctx)
-	if err != nil {
-		s.log.Error("Error finding next L1 Origin", "err", err)
-		return err
-	}
-
-	// Rollup is configured to not start producing blocks until a specific L1 block has been
-	// reached. Don't produce any blocks until we're at that genesis block.
-	if l1Origin.Number < s.Config.Genesis.L1.Number {
-		s.log.Info("Skipping block production because the next L1 Origin is behind the L1 genesis", "next", l1Origin.ID(), "genesis", s.Config.Genesis.L1)
-		return nil
-	}
-
 	l2Head := s.derivation.UnsafeL2Head()
 	l2Safe := s.derivation.SafeL2Head()
 	l2Finalized := s.derivation.Finalized()
 
-	// Should never happen. Sequencer will halt if we get into this situation somehow.
-	nextL2Time := l2Head.Time + s.Config.BlockTime
-	if nextL2Time < l1Origin.Time {
-		s.log.Error("Cannot build L2 block for time before L1 origin",
-			"l2Unsafe", l2Head, "nextL2Time", nextL2Time, "l1Origin", l1Origin, "l1OriginTime", l1Origin.Time)
-		return fmt.Errorf("cannot build L2 block on top %s for time %d before L1 origin %s at time %d",
-			l2Head, nextL2Time, l1Origin, l1Origin.Time)
-	}
-
-	// Actually create the new block.
-	newUnsafeL2Head, payload, err := s.output.createNewBlock(ctx, l2Head, l2Safe.ID(), l2Finalized.ID(), l1Origin)
+	newUnsafeL2Head, payload, err := s.sequencer.CreateNewBlock(ctx, s.l1Head, l2Head, l2Safe.ID(), l2Finalized.ID())
 	if err != nil {
-		s.log.Error("Could not extend chain as sequencer", "err", err, "l2_parent", l2Head, "l1_origin", l1Origin)
 		return err
 	}
+	if payload == nil {
+		return nil
+	}
 
 	// Update our L2 head block based on the new unsafe block we just generated.
 	s.derivation.SetUnsafeHead(newUnsafeL2Head)
+	// Remember the payload, to look it up as parent of the unsafe payloads that build on it.
+	s.unsafePayloadsCache.Add(payload.BlockHash, payload)
 
 	s.log.Info("Sequenced new l2 block", "l2_unsafe", newUnsafeL2Head, "l1_origin", newUnsafeL2Head.L1Origin, "txs", len(payload.Transactions), "time", newUnsafeL2Head.Time)
 	s.metrics.CountSequencedTxs(len(payload.Transactions))
@@ -324,11 +364,28 @@ func (s *state) eventLoop() {
 
 	// Start a ticker to produce L2 blocks at a constant rate. Ticker will only run if we're
 	// running in Sequencer mode.
+	// The sequencer may be enabled and disabled at runtime, see applyRuntimeConfig.
+	var l2BlockCreationTicker clock.Ticker
 	var l2BlockCreationTickerCh <-chan time.Time
-	if s.DriverConfig.SequencerEnabled {
-		l2BlockCreationTicker := time.NewTicker(time.Duration(s.Config.BlockTime) * time.Second)
-		defer l2BlockCreationTicker.Stop()
-		l2BlockCreationTickerCh = l2BlockCreationTicker.C
+	setSequencerTicker := func(enabled bool) {
+		if enabled && l2BlockCreationTicker == nil {
+			l2BlockCreationTicker = s.clock.NewTicker(time.Duration(s.Config.BlockTime) * time.Second)
+			l2BlockCreationTickerCh = l2BlockCreationTicker.Ch()
+		} else if !enabled && l2BlockCreationTicker != nil {
+			l2BlockCreationTicker.Stop()
+			l2BlockCreationTicker, l2BlockCreationTickerCh = nil, nil
+		}
+	}
+	setSequencerTicker(s.DriverConfig.SequencerEnabled)
+	defer setSequencerTicker(false)
+
+	// Check for gaps between the unsafe head and the queued unsafe payloads every block,
+	// to request the missing payloads, rather than waiting for them to be derived from L1.
+	var altSyncTickerCh <-chan time.Time
+	if s.altSync != nil {
+		altSyncTicker := s.clock.NewTicker(time.Duration(s.Config.BlockTime) * time.Second)
+		defer altSyncTicker.Stop()
+		altSyncTickerCh = altSyncTicker.Ch()
 	}
 
 	// stepReqCh is used to request that the driver attempts to step forward by one L1 block.
@@ -371,7 +428,7 @@ func (s *state) eventLoop() {
 			if delayedStepReq == nil {
 				delay := bOffStrategy.Duration(stepAttempts)
 				s.log.Debug("scheduling re-attempt with delay", "attempts", stepAttempts, "delay", delay)
-				delayedStepReq = time.After(delay)
+				delayedStepReq = s.clock.After(delay)
 			} else {
 				s.log.Debug("ignoring step request, already scheduled re-attempt after previous failure", "attempts", stepAttempts)
 			}
@@ -394,13 +451,22 @@ func (s *state) eventLoop() {
 
 		case <-l2BlockCreationReqCh:
 			s.snapshot("L2 Block Creation Request")
+			if !s.DriverConfig.SequencerEnabled {
+				s.log.Debug("not creating block, sequencer is disabled")
+				break
+			}
 			if !s.idleDerivation {
 				s.log.Warn("not creating block, node is deriving new l2 data", "head_l1", s.l1Head)
 				break
 			}
+			if s.checkSequencerThrottle() {
+				s.log.Debug("not creating block, sequencer is throttled until the safe head catches up")
+				break
+			}
 			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			err := s.createNewL2Block(ctx)
 			cancel()
+			s.snapshotOnChange("New L2 block")
 			if err != nil {
 				s.log.Error("Error creating new L2 block", "err", err)
 				s.metrics.RecordSequencingError()
@@ -414,18 +480,23 @@ func (s *state) eventLoop() {
 			if s.l1Head.Number > l2Head.L1Origin.Number+s.DriverConfig.SequencerConfDepth {
 				s.log.Trace("Building another L2 block asap to catch up with L1 head", "l2_unsafe", l2Head, "l2_unsafe_l1_origin", l2Head.L1Origin, "l1_head", s.l1Head)
 				// But not too quickly to minimize busy-waiting for new blocks
-				time.AfterFunc(time.Millisecond*10, reqL2BlockCreation)
+				s.clock.AfterFunc(time.Millisecond*10, reqL2BlockCreation)
 			}
 
 		case payload := <-s.unsafeL2Payloads:
 			s.snapshot("New unsafe payload")
-			s.log.Info("Optimistically queueing unsafe L2 execution payload", "id", payload.ID())
-			s.derivation.AddUnsafePayload(payload)
-			s.metrics.RecordReceivedUnsafePayload(payload)
-			reqStep()
+			if s.queueUnsafePayload(payload) {
+				reqStep()
+			}
+
+		case <-altSyncTickerCh:
+			if err := s.checkForGapInUnsafeQueue(ctx); err != nil {
+				s.log.Warn("Failed to request missing unsafe L2 payloads", "err", err)
+			}
 
 		case newL1Head := <-s.l1HeadSig:
 			s.handleNewL1HeadBlock(newL1Head)
+			s.snapshotOnChange("New L1 Head")
 			reqStep() // a new L1 head may mean we have the data to not get an EOF again.
 		case newL1Safe := <-s.l1SafeSig:
 			s.handleNewL1SafeBlock(newL1Safe)
@@ -440,9 +511,7 @@ func (s *state) eventLoop() {
 			s.metrics.SetDerivationIdle(false)
 			s.idleDerivation = false
 			s.log.Debug("Derivation process step", "onto_origin", s.derivation.Progress().Origin, "onto_closed", s.derivation.Progress().Closed, "attempts", stepAttempts)
-			stepCtx, cancel := context.WithTimeout(ctx, time.Second*10) // TODO pick a timeout for executing a single step
-			err := s.derivation.Step(stepCtx)
-			cancel()
+			err := s.stepDerivation(ctx)
 			stepAttempts += 1 // count as attempt by default. We reset to 0 if we are making healthy progress.
 			if err == io.EOF {
 				s.log.Debug("Derivation process went idle", "progress", s.derivation.Progress().Origin)
@@ -480,11 +549,27 @@ func (s *state) eventLoop() {
 				UnsafeL2:    s.derivation.UnsafeL2Head(),
 				SafeL2:      s.derivation.SafeL2Head(),
 				FinalizedL2: s.derivation.Finalized(),
+
+				QueuedUnsafePayloads: s.derivation.QueuedUnsafePayloads(),
+				LowestQueuedUnsafeL2: s.derivation.LowestQueuedUnsafeBlock(),
 			}
+		case respCh := <-s.sequencerThrottleReq:
+			respCh <- s.sequencerThrottle()
+		case req := <-s.sequencerMaxSafeLag:
+			s.log.Info("Changing sequencer max safe lag", "old", s.maxSafeLag, "new", req.maxSafeLag)
+			s.maxSafeLag = req.maxSafeLag
+			s.checkSequencerThrottle()
+			close(req.done)
+		case req := <-s.runtimeConfigReq:
+			s.applyRuntimeConfig(req.cfg)
+			setSequencerTicker(s.DriverConfig.SequencerEnabled)
+			reqStep() // a lower confirmation depth may expose new L1 data
+			close(req.done)
 		case respCh := <-s.forceReset:
 			s.log.Warn("Derivation pipeline is manually reset")
 			s.derivation.Reset()
 			s.metrics.RecordPipelineReset()
+			s.snapshotOnChange("Derivation pipeline reset")
 			close(respCh)
 		case <-s.done:
 			return
@@ -525,6 +610,122 @@ func (s *state) SyncStatus(ctx context.Context) (*SyncStatus, error) {
 	}
 }
 
+type sequencerMaxSafeLagReq struct {
+	maxSafeLag uint64
+	done       chan struct{}
+}
+
+// SequencerThrottle returns the throttle status of the sequencer.
+func (s *state) SequencerThrottle(ctx context.Context) (*SequencerThrottle, error) {
+	respCh := make(chan SequencerThrottle)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case s.sequencerThrottleReq <- respCh:
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case resp := <-respCh:
+			return &resp, nil
+		}
+	}
+}
+
+// SetSequencerMaxSafeLag changes the maximum lag of the safe head behind the unsafe head,
+// before the sequencer is paused. Throttling is disabled if 0.
+func (s *state) SetSequencerMaxSafeLag(ctx context.Context, maxSafeLag uint64) error {
+	req := sequencerMaxSafeLagReq{maxSafeLag: maxSafeLag, done: make(chan struct{})}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.sequencerMaxSafeLag <- req:
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-req.done:
+			return nil
+		}
+	}
+}
+
+type runtimeConfigReq struct {
+	cfg  RuntimeConfig
+	done chan struct{}
+}
+
+// UpdateRuntimeConfig changes the settings of the runtime config that are not nil.
+// It waits for the event loop to apply the changes.
+func (s *state) UpdateRuntimeConfig(ctx context.Context, cfg RuntimeConfig) error {
+	req := runtimeConfigReq{cfg: cfg, done: make(chan struct{})}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.runtimeConfigReq <- req:
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-req.done:
+			return nil
+		}
+	}
+}
+
+// applyRuntimeConfig changes the driver config, synchronously with the event loop.
+func (s *state) applyRuntimeConfig(cfg RuntimeConfig) {
+	if cfg.SequencerEnabled != nil && *cfg.SequencerEnabled != s.DriverConfig.SequencerEnabled {
+		s.log.Info("Changing sequencer enabled", "old", s.DriverConfig.SequencerEnabled, "new", *cfg.SequencerEnabled)
+		s.DriverConfig.SequencerEnabled = *cfg.SequencerEnabled
+	}
+	if cfg.VerifierConfDepth != nil && *cfg.VerifierConfDepth != s.DriverConfig.VerifierConfDepth {
+		s.log.Info("Changing verifier confirmation depth", "old", s.DriverConfig.VerifierConfDepth, "new", *cfg.VerifierConfDepth)
+		s.DriverConfig.VerifierConfDepth = *cfg.VerifierConfDepth
+		if s.verifierConfDepth != nil {
+			s.verifierConfDepth.depth = *cfg.VerifierConfDepth
+		}
+	}
+	if cfg.SequencerConfDepth != nil && *cfg.SequencerConfDepth != s.DriverConfig.SequencerConfDepth {
+		s.log.Info("Changing sequencer confirmation depth", "old", s.DriverConfig.SequencerConfDepth, "new", *cfg.SequencerConfDepth)
+		s.DriverConfig.SequencerConfDepth = *cfg.SequencerConfDepth
+		if s.sequencer != nil {
+			s.sequencer.confDepth = *cfg.SequencerConfDepth
+		}
+	}
+	if cfg.SequencerMaxSafeLag != nil && *cfg.SequencerMaxSafeLag != s.maxSafeLag {
+		s.log.Info("Changing sequencer max safe lag", "old", s.maxSafeLag, "new", *cfg.SequencerMaxSafeLag)
+		s.maxSafeLag = *cfg.SequencerMaxSafeLag
+		s.checkSequencerThrottle()
+	}
+}
+
+func (s *state) sequencerThrottle() SequencerThrottle {
+	unsafeHead, safeHead := s.derivation.UnsafeL2Head(), s.derivation.SafeL2Head()
+	var lag uint64
+	if unsafeHead.Number > safeHead.Number {
+		lag = unsafeHead.Number - safeHead.Number
+	}
+	return SequencerThrottle{
+		MaxSafeLag: s.maxSafeLag,
+		SafeLag:    lag,
+		Throttled:  s.maxSafeLag > 0 && lag > s.maxSafeLag,
+	}
+}
+
+// checkSequencerThrottle updates and returns whether the sequencer is throttled,
+// i.e. whether the safe head lags too far behind the unsafe head to sequence new blocks.
+func (s *state) checkSequencerThrottle() bool {
+	status := s.sequencerThrottle()
+	if status.Throttled != s.sequencerThrottled {
+		if status.Throttled {
+			s.log.Warn("Pausing sequencer, safe head lags too far behind", "safe_lag", status.SafeLag, "max_safe_lag", status.MaxSafeLag)
+		} else {
+			s.log.Info("Resuming sequencer, safe head caught up", "safe_lag", status.SafeLag, "max_safe_lag", status.MaxSafeLag)
+		}
+		s.sequencerThrottled = status.Throttled
+		s.metrics.SetSequencerThrottled(status.Throttled)
+	}
+	return status.Throttled
+}
+
 // deferJSONString helps avoid a JSON-encoding performance hit if the snapshot logger does not run
 type deferJSONString struct {
 	x any
@@ -535,12 +736,76 @@ func (v deferJSONString) String() string {
 	return string(out)
 }
 
+// snapshotHeads are the heads that are logged in a snapshot
+type snapshotHeads struct {
+	l1Head, l1Current                   eth.L1BlockRef
+	l2Head, l2SafeHead, l2FinalizedHead eth.L2BlockRef
+}
+
+func (s *state) snapshotHeads() snapshotHeads {
+	return snapshotHeads{
+		l1Head:          s.l1Head,
+		l1Current:       s.derivation.Progress().Origin,
+		l2Head:          s.derivation.UnsafeL2Head(),
+		l2SafeHead:      s.derivation.SafeL2Head(),
+		l2FinalizedHead: s.derivation.Finalized(),
+	}
+}
+
 func (s *state) snapshot(event string) {
+	heads := s.snapshotHeads()
+	s.lastSnapshot = heads
+	s.notifyHeads()
 	s.snapshotLog.Info("Rollup State Snapshot",
 		"event", event,
-		"l1Head", deferJSONString{s.l1Head},
-		"l1Current", deferJSONString{s.derivation.Progress().Origin},
-		"l2Head", deferJSONString{s.derivation.UnsafeL2Head()},
-		"l2Safe", deferJSONString{s.derivation.SafeL2Head()},
-		"l2FinalizedHead", deferJSONString{s.derivation.Finalized()})
+		"l1Head", deferJSONString{heads.l1Head},
+		"l1Current", deferJSONString{heads.l1Current},
+		"l2Head", deferJSONString{heads.l2Head},
+		"l2SafeHead", deferJSONString{heads.l2SafeHead},
+		"l2FinalizedHead", deferJSONString{heads.l2FinalizedHead})
+}
+
+// checkForGapInUnsafeQueue requests the unsafe payloads between the unsafe head and the first queued unsafe payload,
+// if any are missing.
+func (s *state) checkForGapInUnsafeQueue(ctx context.Context) error {
+	start := s.derivation.UnsafeL2Head()
+	end := s.derivation.LowestQueuedUnsafeBlock()
+	if end == (eth.L2BlockRef{}) || end.Number <= start.Number+1 {
+		return nil
+	}
+	s.log.Debug("Requesting missing unsafe L2 block range", "start", start, "end", end, "size", end.Number-start.Number-1)
+	return s.altSync.RequestL2Range(ctx, start, end)
+}
+
+// stepDerivation runs derivation steps back to back, until the pipeline returns an error
+// or the step budget is used up, and returns the error of the last step.
+// Only a single step is run if there is no step budget.
+// The budget bounds the time the event loop is blocked on derivation,
+// so it yields to the L1 heads, unsafe payloads and sequencing requests during catch-up derivation.
+func (s *state) stepDerivation(ctx context.Context) error {
+	budget := s.DriverConfig.DerivationStepBudget
+	start := s.clock.Now()
+	for {
+		stepStart := s.clock.Now()
+		stepCtx, cancel := context.WithTimeout(ctx, time.Second*10) // TODO pick a timeout for executing a single step
+		err := s.derivation.Step(stepCtx)
+		cancel()
+		s.metrics.RecordDerivationStep(s.clock.Since(stepStart))
+		s.snapshotOnChange("Derivation step")
+		if err != nil || budget <= 0 {
+			return err
+		}
+		if elapsed := s.clock.Since(start); elapsed >= budget {
+			s.log.Debug("Derivation step budget used up, yielding to other events", "budget", budget, "elapsed", elapsed)
+			s.metrics.RecordDerivationYield()
+			return nil
+		}
+	}
+}
+
+// snapshotOnChange logs a snapshot if any of the heads changed since the last snapshot.
+func (s *state) snapshotOnChange(event string) {
+	if s.snapshotHeads() != s.lastSnapshot {
+		s.snapshot(event)
+	}
 }
@@ -0,0 +1,198 @@
+package driver
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/clock"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestSequencerThrottle(t *testing.T) {
+	pipeline := &fakeHeadsPipeline{
+		unsafe: eth.L2BlockRef{Number: 10},
+		safe:   eth.L2BlockRef{Number: 10},
+	}
+	m := &testutils.RecordingMetrics{}
+	s := &state{
+		derivation: pipeline,
+		metrics:    m,
+		log:        testlog.Logger(t, log.LvlDebug),
+		maxSafeLag: 5,
+	}
+
+	require.False(t, s.checkSequencerThrottle())
+	require.Equal(t, SequencerThrottle{MaxSafeLag: 5}, s.sequencerThrottle())
+
+	// at the max lag the sequencer continues
+	pipeline.unsafe.Number = 15
+	require.False(t, s.checkSequencerThrottle())
+	m.RequireCount(t, "SetSequencerThrottled", 0)
+
+	// beyond the max lag the sequencer pauses
+	pipeline.unsafe.Number = 16
+	require.True(t, s.checkSequencerThrottle())
+	require.Equal(t, SequencerThrottle{MaxSafeLag: 5, SafeLag: 6, Throttled: true}, s.sequencerThrottle())
+	require.True(t, s.checkSequencerThrottle())
+	m.RequireCount(t, "SetSequencerThrottled", 1)
+
+	// and resumes once the safe head catches up
+	pipeline.safe.Number = 12
+	require.False(t, s.checkSequencerThrottle())
+	m.RequireCount(t, "SetSequencerThrottled", 2)
+	records := m.Records("SetSequencerThrottled")
+	require.Equal(t, true, records[0].Value)
+	require.Equal(t, false, records[1].Value)
+
+	// throttling is disabled with a zero max lag
+	s.maxSafeLag = 0
+	pipeline.unsafe.Number = 1000
+	require.False(t, s.checkSequencerThrottle())
+
+	// a safe head ahead of the unsafe head, e.g. during a reset, is no lag
+	s.maxSafeLag = 5
+	pipeline.safe.Number = 2000
+	require.Equal(t, SequencerThrottle{MaxSafeLag: 5}, s.sequencerThrottle())
+}
+
+func TestApplyRuntimeConfig(t *testing.T) {
+	pipeline := &fakeHeadsPipeline{
+		unsafe: eth.L2BlockRef{Number: 20},
+		safe:   eth.L2BlockRef{Number: 10},
+	}
+	m := &testutils.RecordingMetrics{}
+	driverCfg := &Config{VerifierConfDepth: 4, SequencerConfDepth: 3}
+	s := &state{
+		derivation:        pipeline,
+		metrics:           m,
+		log:               testlog.Logger(t, log.LvlDebug),
+		DriverConfig:      driverCfg,
+		sequencer:         &Sequencer{confDepth: 3},
+		verifierConfDepth: &confDepth{depth: 4},
+	}
+
+	// unset settings are not changed
+	s.applyRuntimeConfig(RuntimeConfig{})
+	require.Equal(t, &Config{VerifierConfDepth: 4, SequencerConfDepth: 3}, driverCfg)
+
+	enabled, verifierDepth, sequencerDepth, maxSafeLag := true, uint64(2), uint64(1), uint64(5)
+	s.applyRuntimeConfig(RuntimeConfig{
+		SequencerEnabled:    &enabled,
+		VerifierConfDepth:   &verifierDepth,
+		SequencerConfDepth:  &sequencerDepth,
+		SequencerMaxSafeLag: &maxSafeLag,
+	})
+	require.Equal(t, &Config{SequencerEnabled: true, VerifierConfDepth: 2, SequencerConfDepth: 1}, driverCfg)
+	require.Equal(t, uint64(2), s.verifierConfDepth.depth)
+	require.Equal(t, uint64(1), s.sequencer.confDepth)
+	require.Equal(t, uint64(5), s.maxSafeLag)
+	require.True(t, s.sequencerThrottled, "the new max safe lag applies immediately")
+	m.RequireCount(t, "SetSequencerThrottled", 1)
+}
+
+type fakeStepPipeline struct {
+	fakeHeadsPipeline
+	clock     *clock.DeterministicClock
+	steps     int
+	stepTime  time.Duration
+	stepsLeft int // steps until the pipeline goes idle
+}
+
+func (f *fakeStepPipeline) Step(ctx context.Context) error {
+	f.clock.AdvanceTime(f.stepTime)
+	f.steps += 1
+	if f.stepsLeft == 0 {
+		return io.EOF
+	}
+	f.stepsLeft -= 1
+	return nil
+}
+
+func TestStepDerivation(t *testing.T) {
+	newState := func(budget time.Duration, pipeline *fakeStepPipeline) (*state, *testutils.RecordingMetrics) {
+		m := &testutils.RecordingMetrics{}
+		pipeline.clock = clock.NewDeterministicClock(time.Unix(1000, 0))
+		return &state{
+			derivation:   pipeline,
+			metrics:      m,
+			clock:        pipeline.clock,
+			log:          testlog.Logger(t, log.LvlDebug),
+			DriverConfig: &Config{DerivationStepBudget: budget},
+		}, m
+	}
+
+	t.Run("no budget", func(t *testing.T) {
+		pipeline := &fakeStepPipeline{stepsLeft: 100}
+		s, m := newState(0, pipeline)
+		require.NoError(t, s.stepDerivation(context.Background()))
+		require.Equal(t, 1, pipeline.steps)
+		m.RequireCount(t, "RecordDerivationStep", 1)
+		m.RequireCount(t, "RecordDerivationYield", 0)
+	})
+
+	t.Run("budget used up", func(t *testing.T) {
+		pipeline := &fakeStepPipeline{stepsLeft: 1000, stepTime: time.Millisecond}
+		s, m := newState(20*time.Millisecond, pipeline)
+		require.NoError(t, s.stepDerivation(context.Background()))
+		require.Equal(t, 20, pipeline.steps, "yields as soon as the budget is used up")
+		m.RequireCount(t, "RecordDerivationStep", 20)
+		m.RequireCount(t, "RecordDerivationYield", 1)
+		for _, r := range m.Records("RecordDerivationStep") {
+			require.Equal(t, time.Millisecond, r.Value.(time.Duration))
+		}
+	})
+
+	t.Run("idle within budget", func(t *testing.T) {
+		pipeline := &fakeStepPipeline{stepsLeft: 3}
+		s, m := newState(time.Minute, pipeline)
+		require.ErrorIs(t, s.stepDerivation(context.Background()), io.EOF)
+		require.Equal(t, 4, pipeline.steps)
+		m.RequireCount(t, "RecordDerivationStep", 4)
+		m.RequireCount(t, "RecordDerivationYield", 0)
+	})
+}
+
+type fakeAltSync struct {
+	requests [][2]eth.L2BlockRef
+}
+
+func (f *fakeAltSync) RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error {
+	f.requests = append(f.requests, [2]eth.L2BlockRef{start, end})
+	return nil
+}
+
+func TestCheckForGapInUnsafeQueue(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	pipeline := &fakeHeadsPipeline{}
+	altSync := &fakeAltSync{}
+	s := &state{
+		derivation: pipeline,
+		altSync:    altSync,
+		log:        testlog.Logger(t, log.LvlDebug),
+	}
+	pipeline.unsafe = testutils.RandomL2BlockRef(rng)
+	pipeline.unsafe.Number = 100
+
+	// no queued payloads
+	require.NoError(t, s.checkForGapInUnsafeQueue(context.Background()))
+	require.Empty(t, altSync.requests)
+
+	// the queued payload builds on the unsafe head
+	pipeline.lowestQueued = testutils.RandomL2BlockRef(rng)
+	pipeline.lowestQueued.Number = 101
+	require.NoError(t, s.checkForGapInUnsafeQueue(context.Background()))
+	require.Empty(t, altSync.requests)
+
+	// payloads are missing between the unsafe head and the queued payload
+	pipeline.lowestQueued.Number = 110
+	require.NoError(t, s.checkForGapInUnsafeQueue(context.Background()))
+	require.Equal(t, [][2]eth.L2BlockRef{{pipeline.unsafe, pipeline.lowestQueued}}, altSync.requests)
+}
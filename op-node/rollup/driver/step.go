@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/clock"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
@@ -17,6 +18,11 @@ type outputImpl struct {
 	l2     derive.Engine
 	log    log.Logger
 	Config *rollup.Config
+
+	// maximum time to build a block with the engine, no deadline if 0
+	buildDeadline time.Duration
+	metrics       SequencerMetrics
+	clock         clock.Clock
 }
 
 func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *eth.ExecutionPayload, error) {
@@ -44,8 +50,25 @@ func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef,
 		FinalizedBlockHash: l2Finalized.Hash,
 	}
 
-	// Actually execute the block and add it to the head of the chain.
-	payload, errType, err := derive.InsertHeadBlock(ctx, d.log, d.l2, fc, attrs, false)
+	// Build the block within the build deadline. The deadline only applies to the building:
+	// once built, the block is always executed and added to the head of the chain.
+	buildCtx, cancelBuild := ctx, context.CancelFunc(func() {})
+	if d.buildDeadline > 0 {
+		buildCtx, cancelBuild = context.WithTimeout(ctx, d.buildDeadline)
+	}
+	start := d.clock.Now()
+	payload, errType, err := derive.BuildPayload(buildCtx, d.l2, fc, attrs)
+	missedDeadline := d.buildDeadline > 0 && ctx.Err() == nil && (buildCtx.Err() != nil || d.clock.Since(start) > d.buildDeadline)
+	cancelBuild()
+	if missedDeadline {
+		d.log.Warn("Block building missed the deadline", "parent", l2Head, "deadline", d.buildDeadline, "elapsed", d.clock.Since(start))
+		d.metrics.RecordSequencerBuildDeadlineMissed()
+	}
+	if err != nil {
+		return l2Head, nil, fmt.Errorf("failed to build L2 block, error (%d): %w", errType, err)
+	}
+
+	payload, errType, err = derive.InsertPayload(ctx, d.log, d.l2, fc, payload, false)
 	if err != nil {
 		return l2Head, nil, fmt.Errorf("failed to extend L2 chain, error (%d): %w", errType, err)
 	}
@@ -0,0 +1,124 @@
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Fork identifies a rollup upgrade, which changes the derivation rules from its activation time onwards.
+type Fork string
+
+const (
+	// ForkSpanBatch activates span batches, which encode a span of consecutive L2 blocks in a single batch.
+	ForkSpanBatch Fork = "span_batch"
+)
+
+// KnownForks lists the rollup upgrades that this version of the rollup node implements.
+var KnownForks = []Fork{ForkSpanBatch}
+
+// ForkSchedule maps rollup upgrades to their activation time, an L2 block timestamp.
+// An upgrade applies to the L2 blocks with a timestamp at or after the activation time.
+// Upgrades that are not scheduled are not active.
+type ForkSchedule map[Fork]uint64
+
+var (
+	ErrUnknownFork        = errors.New("unknown fork")
+	ErrIncompatibleConfig = errors.New("incompatible rollup config")
+)
+
+// IsActive returns true if the upgrade is active at the given L2 block timestamp.
+func (s ForkSchedule) IsActive(fork Fork, timestamp uint64) bool {
+	activation, ok := s[fork]
+	return ok && timestamp >= activation
+}
+
+// Check verifies that only known upgrades are scheduled.
+func (s ForkSchedule) Check() error {
+	for fork := range s {
+		known := false
+		for _, f := range KnownForks {
+			if f == fork {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: %q", ErrUnknownFork, fork)
+		}
+	}
+	return nil
+}
+
+// String lists the scheduled upgrades, ordered by activation time.
+func (s ForkSchedule) String() string {
+	forks := make([]Fork, 0, len(s))
+	for fork := range s {
+		forks = append(forks, fork)
+	}
+	sort.Slice(forks, func(i, j int) bool {
+		if s[forks[i]] != s[forks[j]] {
+			return s[forks[i]] < s[forks[j]]
+		}
+		return forks[i] < forks[j]
+	})
+	out := ""
+	for i, fork := range forks {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%s@%d", fork, s[fork])
+	}
+	return out
+}
+
+// IsSpanBatch returns true if span batches are accepted for L2 blocks at the given timestamp.
+func (cfg *Config) IsSpanBatch(timestamp uint64) bool {
+	return cfg.SpanBatches || cfg.Forks.IsActive(ForkSpanBatch, timestamp)
+}
+
+// CheckCompatible verifies that the other config derives the same L2 chain as this config,
+// e.g. to check that the configs of a sequencer and a verifier agree.
+// Only the derivation rules are compared: the settings of the p2p layer may differ.
+func (cfg *Config) CheckCompatible(other *Config) error {
+	if cfg.Genesis != other.Genesis {
+		return fmt.Errorf("%w: genesis %+v, other %+v", ErrIncompatibleConfig, cfg.Genesis, other.Genesis)
+	}
+	if !sameChainID(cfg.L1ChainID, other.L1ChainID) || !sameChainID(cfg.L2ChainID, other.L2ChainID) {
+		return fmt.Errorf("%w: chain IDs %v/%v, other %v/%v", ErrIncompatibleConfig, cfg.L1ChainID, cfg.L2ChainID, other.L1ChainID, other.L2ChainID)
+	}
+	if cfg.BlockTime != other.BlockTime {
+		return fmt.Errorf("%w: block time %d, other %d", ErrIncompatibleConfig, cfg.BlockTime, other.BlockTime)
+	}
+	if cfg.MaxSequencerDrift != other.MaxSequencerDrift || cfg.SeqWindowSize != other.SeqWindowSize ||
+		cfg.ChannelTimeout != other.ChannelTimeout || cfg.MaxChannelBankSize != other.MaxChannelBankSize {
+		return fmt.Errorf("%w: different sequencing or channel parameters", ErrIncompatibleConfig)
+	}
+	if cfg.FeeRecipientAddress != other.FeeRecipientAddress || cfg.BatchInboxAddress != other.BatchInboxAddress ||
+		cfg.BatchSenderAddress != other.BatchSenderAddress || cfg.DepositContractAddress != other.DepositContractAddress {
+		return fmt.Errorf("%w: different derivation addresses", ErrIncompatibleConfig)
+	}
+	if cfg.BatchInboxMode != other.BatchInboxMode {
+		return fmt.Errorf("%w: batch inbox mode %q, other %q", ErrIncompatibleConfig, cfg.BatchInboxMode, other.BatchInboxMode)
+	}
+	if cfg.SpanBatches != other.SpanBatches {
+		return fmt.Errorf("%w: span batches %v, other %v", ErrIncompatibleConfig, cfg.SpanBatches, other.SpanBatches)
+	}
+	if len(cfg.Forks) != len(other.Forks) {
+		return fmt.Errorf("%w: forks [%s], other [%s]", ErrIncompatibleConfig, cfg.Forks, other.Forks)
+	}
+	for fork, activation := range cfg.Forks {
+		if otherActivation, ok := other.Forks[fork]; !ok || otherActivation != activation {
+			return fmt.Errorf("%w: forks [%s], other [%s]", ErrIncompatibleConfig, cfg.Forks, other.Forks)
+		}
+	}
+	return nil
+}
+
+func sameChainID(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,68 @@
+package rollup
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkSchedule(t *testing.T) {
+	var unscheduled ForkSchedule
+	require.False(t, unscheduled.IsActive(ForkSpanBatch, 1000))
+	require.NoError(t, unscheduled.Check())
+
+	s := ForkSchedule{ForkSpanBatch: 100}
+	require.False(t, s.IsActive(ForkSpanBatch, 99))
+	require.True(t, s.IsActive(ForkSpanBatch, 100))
+	require.True(t, s.IsActive(ForkSpanBatch, 101))
+	require.NoError(t, s.Check())
+	require.Equal(t, "span_batch@100", s.String())
+
+	require.ErrorIs(t, ForkSchedule{"unknown": 0}.Check(), ErrUnknownFork)
+}
+
+func TestConfigIsSpanBatch(t *testing.T) {
+	cfg := &Config{}
+	require.False(t, cfg.IsSpanBatch(100))
+	cfg.Forks = ForkSchedule{ForkSpanBatch: 100}
+	require.False(t, cfg.IsSpanBatch(99))
+	require.True(t, cfg.IsSpanBatch(100))
+	cfg.SpanBatches = true
+	require.True(t, cfg.IsSpanBatch(0), "enabled from genesis")
+}
+
+func TestConfigCheckCompatible(t *testing.T) {
+	sequencer := randConfig()
+	sequencer.L2ChainID = big.NewInt(901)
+	sequencer.Forks = ForkSchedule{ForkSpanBatch: 100}
+	verifier := *sequencer
+	verifier.L1ChainID = big.NewInt(900)
+	verifier.Forks = ForkSchedule{ForkSpanBatch: 100}
+	verifier.P2PSequencerAddress = common.Address{0x1}
+	verifier.P2PForkVersion = 2
+	require.NoError(t, sequencer.CheckCompatible(&verifier), "p2p settings may differ")
+
+	tests := []struct {
+		name     string
+		modifier func(cfg *Config)
+	}{
+		{"genesis", func(cfg *Config) { cfg.Genesis.L2Time += 1 }},
+		{"chain id", func(cfg *Config) { cfg.L2ChainID = big.NewInt(902) }},
+		{"block time", func(cfg *Config) { cfg.BlockTime += 1 }},
+		{"seq window", func(cfg *Config) { cfg.SeqWindowSize += 1 }},
+		{"batch inbox", func(cfg *Config) { cfg.BatchInboxAddress = common.Address{0x2} }},
+		{"inbox mode", func(cfg *Config) { cfg.BatchInboxMode = BatchInboxEvents }},
+		{"fork time", func(cfg *Config) { cfg.Forks = ForkSchedule{ForkSpanBatch: 102} }},
+		{"fork unscheduled", func(cfg *Config) { cfg.Forks = nil }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			other := *sequencer
+			test.modifier(&other)
+			require.ErrorIs(t, sequencer.CheckCompatible(&other), ErrIncompatibleConfig)
+			require.ErrorIs(t, other.CheckCompatible(sequencer), ErrIncompatibleConfig)
+		})
+	}
+}
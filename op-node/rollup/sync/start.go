@@ -90,8 +90,11 @@ func isAheadOrCanonical(ctx context.Context, l1 L1Chain, block eth.BlockID) (ahe
 //
 //	Attributes deposit within the L2 block) is not canonical at another height in the L1 chain,
 //	and the same holds for all its ancestors.
+//
+// If a trusted anchor is given, it is treated like the L2 genesis: the L2 chain is not walked back past the anchor,
+// and the anchor is always safe.
 func FindL2Heads(ctx context.Context, start eth.L2BlockRef, seqWindowSize uint64,
-	l1 L1Chain, l2 L2Chain, genesis *rollup.Genesis) (unsafe eth.L2BlockRef, safe eth.L2BlockRef, err error) {
+	l1 L1Chain, l2 L2Chain, genesis *rollup.Genesis, anchor *eth.L2BlockRef) (unsafe eth.L2BlockRef, safe eth.L2BlockRef, err error) {
 
 	// Loop 1. Walk the L2 chain backwards until we find an L2 block whose L1 origin is canonical.
 
@@ -138,6 +141,10 @@ func FindL2Heads(ctx context.Context, start eth.L2BlockRef, seqWindowSize uint64
 		if n.Hash == genesis.L2.Hash || n.Number == genesis.L2.Number {
 			return eth.L2BlockRef{}, eth.L2BlockRef{}, WrongChainErr
 		}
+		// Likewise, don't walk past the trusted anchor.
+		if anchor != nil && n.Number <= anchor.Number {
+			return eth.L2BlockRef{}, eth.L2BlockRef{}, WrongChainErr
+		}
 
 		// Pull L2 parent for next iteration
 		n, err = l2.L2BlockRefByHash(ctx, n.ParentHash)
@@ -186,6 +193,14 @@ func FindL2Heads(ctx context.Context, start eth.L2BlockRef, seqWindowSize uint64
 			return highestPlausibleCanonicalOrigin, n, nil
 		}
 
+		// The trusted anchor is always safe.
+		if anchor != nil && n.Number <= anchor.Number {
+			if n.Hash != anchor.Hash {
+				return eth.L2BlockRef{}, eth.L2BlockRef{}, WrongChainErr
+			}
+			return highestPlausibleCanonicalOrigin, *anchor, nil
+		}
+
 		// Genesis is always safe.
 		if n.Hash == genesis.L2.Hash || n.Number == genesis.L2.Number {
 			safe = eth.L2BlockRef{Hash: genesis.L2.Hash, Number: genesis.L2.Number,
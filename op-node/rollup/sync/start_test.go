@@ -47,6 +47,7 @@ type syncStartTestCase struct {
 	GenesisL2    rune
 
 	SeqWindowSize uint64
+	Anchor        rune // trusted L2 anchor, none if zero
 	SafeL2Head    rune
 	UnsafeL2Head  rune
 	ExpectedErr   error
@@ -59,7 +60,21 @@ func refToRune(r eth.BlockID) rune {
 func (c *syncStartTestCase) Run(t *testing.T) {
 	chain, l2Head, genesis := c.generateFakeL2(t)
 
-	unsafeL2Head, safeHead, err := FindL2Heads(context.Background(), l2Head, c.SeqWindowSize, chain, chain, &genesis)
+	var anchor *eth.L2BlockRef
+	if c.Anchor != 0 {
+		for n := l2Head; ; {
+			if refToRune(n.ID()) == c.Anchor {
+				anchor = &n
+				break
+			}
+			require.NotEqual(t, genesis.L2.Hash, n.Hash, "anchor not found in L2 chain")
+			var err error
+			n, err = chain.L2BlockRefByHash(context.Background(), n.ParentHash)
+			require.NoError(t, err)
+		}
+	}
+
+	unsafeL2Head, safeHead, err := FindL2Heads(context.Background(), l2Head, c.SeqWindowSize, chain, chain, &genesis, anchor)
 
 	if c.ExpectedErr != nil {
 		require.Error(t, err, "Expecting an error in this test case")
@@ -240,6 +255,47 @@ func TestFindSyncStart(t *testing.T) {
 			UnsafeL2Head: 0,
 			ExpectedErr:  WrongChainErr,
 		},
+		{
+			Name:          "anchor within sequencing window",
+			GenesisL1Num:  0,
+			L1:            "abcdefgh",
+			L2:            "ABCDEFGH",
+			NewL1:         "abcdefgh",
+			GenesisL1:     'a',
+			GenesisL2:     'A',
+			UnsafeL2Head:  'H',
+			SeqWindowSize: 5,
+			Anchor:        'F',
+			SafeL2Head:    'F',
+			ExpectedErr:   nil,
+		},
+		{
+			Name:          "anchor before sequencing window",
+			GenesisL1Num:  0,
+			L1:            "abcdefgh",
+			L2:            "ABCDEFGH",
+			NewL1:         "abcdefgh",
+			GenesisL1:     'a',
+			GenesisL2:     'A',
+			UnsafeL2Head:  'H',
+			SeqWindowSize: 2,
+			Anchor:        'C',
+			SafeL2Head:    'G',
+			ExpectedErr:   nil,
+		},
+		{
+			Name:          "reorg past anchor",
+			GenesisL1Num:  0,
+			L1:            "abcdefgh",
+			L2:            "ABCDEFGH",
+			NewL1:         "abcdexyz",
+			GenesisL1:     'a',
+			GenesisL2:     'A',
+			UnsafeL2Head:  0,
+			SeqWindowSize: 2,
+			Anchor:        'F',
+			ExpectedErr:   WrongChainErr,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -34,6 +34,11 @@ type Config struct {
 	SeqWindowSize uint64 `json:"seq_window_size"`
 	// Number of seconds (w.r.t. L1 time) that a frame can be valid when included in L1
 	ChannelTimeout uint64 `json:"channel_timeout"`
+	// Maximum number of bytes (including frame overhead) the channel bank may buffer,
+	// before the oldest channels are evicted. Defaults to derive.MaxChannelBankSize if 0.
+	//
+	// Note: eviction affects which channels are read, this must be the same network-wide.
+	MaxChannelBankSize uint64 `json:"max_channel_bank_size,omitempty"`
 	// Required to verify L1 signatures
 	L1ChainID *big.Int `json:"l1_chain_id"`
 	// Required to identify the L2 network and create p2p signatures unique for this chain.
@@ -41,6 +46,9 @@ type Config struct {
 
 	// Address of the key the sequencer uses to sign blocks on the P2P layer
 	P2PSequencerAddress common.Address `json:"p2p_sequencer_address"`
+	// Fork version of the P2P layer, part of the gossip topic names together with the L2 chain ID.
+	// Nodes with a different fork version are on different topics, and do not exchange blocks.
+	P2PForkVersion uint64 `json:"p2p_fork_version,omitempty"`
 
 	// Note: below addresses are part of the block-derivation process,
 	// and required to be the same network-wide to stay in consensus.
@@ -49,58 +57,137 @@ type Config struct {
 	FeeRecipientAddress common.Address `json:"fee_recipient_address"`
 	// L1 address that batches are sent to.
 	BatchInboxAddress common.Address `json:"batch_inbox_address"`
+	// How batches are submitted to the batch inbox, BatchInboxCalldata if empty.
+	BatchInboxMode BatchInboxMode `json:"batch_inbox_mode,omitempty"`
+	// Experimental: accept span batches, which encode a span of consecutive L2 blocks in a single batch.
+	// Span batches are dropped if not enabled, see IsSpanBatch.
+	SpanBatches bool `json:"span_batches,omitempty"`
+	// Activation times of the rollup upgrades, see ForkSchedule.
+	Forks ForkSchedule `json:"forks,omitempty"`
 	// Acceptable batch-sender address
 	BatchSenderAddress common.Address `json:"batch_sender_address"`
 	// L1 Deposit Contract Address
 	DepositContractAddress common.Address `json:"deposit_contract_address"`
 }
 
-// Check verifies that the given configuration makes sense
+// BatchInboxMode identifies how the batch inbox receives batches.
+type BatchInboxMode string
+
+const (
+	// BatchInboxCalldata reads batches from the calldata of transactions of the batch sender to the batch inbox address.
+	BatchInboxCalldata BatchInboxMode = "calldata"
+	// BatchInboxEvents reads batches from the events that the batch inbox contract emits on behalf of the batch sender.
+	BatchInboxEvents BatchInboxMode = "events"
+)
+
+var (
+	ErrBlockTimeZero                 = errors.New("block time cannot be 0")
+	ErrMissingChannelTimeout         = errors.New("channel timeout must be set, this should cover at least a L1 block time")
+	ErrInvalidSeqWindowSize          = errors.New("sequencing window size must at least be 2")
+	ErrSequencerDriftTooSmall        = errors.New("max sequencer drift must be at least the block time")
+	ErrMissingGenesisL1Hash          = errors.New("genesis L1 hash cannot be empty")
+	ErrMissingGenesisL2Hash          = errors.New("genesis L2 hash cannot be empty")
+	ErrGenesisHashesSame             = errors.New("achievement get! rollup inception: L1 and L2 genesis cannot be the same")
+	ErrMissingGenesisL2Time          = errors.New("missing L2 genesis time")
+	ErrMissingP2PSequencerAddress    = errors.New("missing p2p sequencer address")
+	ErrMissingFeeRecipientAddress    = errors.New("missing fee recipient address")
+	ErrMissingBatchInboxAddress      = errors.New("missing batch inbox address")
+	ErrMissingBatchSenderAddress     = errors.New("missing batch sender address")
+	ErrMissingDepositContractAddress = errors.New("missing deposit contract address")
+	ErrUnknownBatchInboxMode         = errors.New("unknown batch inbox mode")
+	ErrMissingL1ChainID              = errors.New("L1 chain ID must not be nil")
+	ErrMissingL2ChainID              = errors.New("L2 chain ID must not be nil")
+	ErrInvalidL1ChainID              = errors.New("L1 chain ID must be positive")
+	ErrInvalidL2ChainID              = errors.New("L2 chain ID must be positive")
+	ErrChainIDsSame                  = errors.New("L1 and L2 chain IDs must be different")
+	ErrL1BlockTimeZero               = errors.New("L1 block time cannot be 0")
+	ErrBlockTimeNotDivisor           = errors.New("L2 block time must divide the L1 block time")
+	ErrChannelTimeoutTooLarge        = errors.New("channel timeout must not exceed the sequencing window duration")
+)
+
+// Check verifies that the given configuration makes sense.
+// The returned error wraps one of the Err* values of this package.
 func (cfg *Config) Check() error {
 	if cfg.BlockTime == 0 {
-		return fmt.Errorf("block time cannot be 0, got %d", cfg.BlockTime)
+		return ErrBlockTimeZero
 	}
 	if cfg.ChannelTimeout == 0 {
-		return fmt.Errorf("channel timeout must be set, this should cover at least a L1 block time")
+		return ErrMissingChannelTimeout
 	}
 	if cfg.SeqWindowSize < 2 {
-		return fmt.Errorf("sequencing window size must at least be 2, got %d", cfg.SeqWindowSize)
+		return fmt.Errorf("%w, got %d", ErrInvalidSeqWindowSize, cfg.SeqWindowSize)
+	}
+	if cfg.MaxSequencerDrift < cfg.BlockTime {
+		return fmt.Errorf("%w: drift %d, block time %d", ErrSequencerDriftTooSmall, cfg.MaxSequencerDrift, cfg.BlockTime)
 	}
 	if cfg.Genesis.L1.Hash == (common.Hash{}) {
-		return errors.New("genesis l1 hash cannot be empty")
+		return ErrMissingGenesisL1Hash
 	}
 	if cfg.Genesis.L2.Hash == (common.Hash{}) {
-		return errors.New("genesis l2 hash cannot be empty")
+		return ErrMissingGenesisL2Hash
 	}
 	if cfg.Genesis.L2.Hash == cfg.Genesis.L1.Hash {
-		return errors.New("achievement get! rollup inception: L1 and L2 genesis cannot be the same")
+		return ErrGenesisHashesSame
 	}
 	if cfg.Genesis.L2Time == 0 {
-		return errors.New("missing L2 genesis time")
+		return ErrMissingGenesisL2Time
 	}
 	if cfg.P2PSequencerAddress == (common.Address{}) {
-		return errors.New("missing p2p sequencer address")
+		return ErrMissingP2PSequencerAddress
 	}
 	if cfg.FeeRecipientAddress == (common.Address{}) {
-		return errors.New("missing fee recipient address")
+		return ErrMissingFeeRecipientAddress
 	}
 	if cfg.BatchInboxAddress == (common.Address{}) {
-		return errors.New("missing batch inbox address")
+		return ErrMissingBatchInboxAddress
 	}
 	if cfg.BatchSenderAddress == (common.Address{}) {
-		return errors.New("missing batch sender address")
+		return ErrMissingBatchSenderAddress
 	}
 	if cfg.DepositContractAddress == (common.Address{}) {
-		return errors.New("missing deposit contract address")
+		return ErrMissingDepositContractAddress
+	}
+	switch cfg.BatchInboxMode {
+	case "", BatchInboxCalldata, BatchInboxEvents:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownBatchInboxMode, cfg.BatchInboxMode)
+	}
+	if err := cfg.Forks.Check(); err != nil {
+		return err
 	}
 	if cfg.L1ChainID == nil {
-		return errors.New("l1 chain ID must not be nil")
+		return ErrMissingL1ChainID
 	}
 	if cfg.L2ChainID == nil {
-		return errors.New("l2 chain ID must not be nil")
+		return ErrMissingL2ChainID
+	}
+	if cfg.L1ChainID.Sign() <= 0 {
+		return fmt.Errorf("%w, got %d", ErrInvalidL1ChainID, cfg.L1ChainID)
+	}
+	if cfg.L2ChainID.Sign() <= 0 {
+		return fmt.Errorf("%w, got %d", ErrInvalidL2ChainID, cfg.L2ChainID)
 	}
 	if cfg.L1ChainID.Cmp(cfg.L2ChainID) == 0 {
-		return errors.New("l1 and l2 chain IDs must be different")
+		return fmt.Errorf("%w, got %d", ErrChainIDsSame, cfg.L1ChainID)
+	}
+	return nil
+}
+
+// CheckL1BlockTime verifies the timing parameters of the configuration against the L1 block time.
+// The L1 block time is not part of the rollup configuration, thus this is checked separately,
+// by setups that know the L1 block time.
+func (cfg *Config) CheckL1BlockTime(l1BlockTime uint64) error {
+	if l1BlockTime == 0 {
+		return ErrL1BlockTimeZero
+	}
+	if cfg.BlockTime == 0 {
+		return ErrBlockTimeZero
+	}
+	if l1BlockTime%cfg.BlockTime != 0 {
+		return fmt.Errorf("%w: L2 block time %d, L1 block time %d", ErrBlockTimeNotDivisor, cfg.BlockTime, l1BlockTime)
+	}
+	if window := cfg.SeqWindowSize * l1BlockTime; cfg.ChannelTimeout > window {
+		return fmt.Errorf("%w: channel timeout %d, sequencing window %d seconds", ErrChannelTimeoutTooLarge, cfg.ChannelTimeout, window)
 	}
 	return nil
 }
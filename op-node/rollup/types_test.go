@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func randConfig() *Config {
@@ -45,3 +46,110 @@ func TestConfigJSON(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(data, &roundTripped))
 	assert.Equal(t, &roundTripped, config)
 }
+
+func TestConfigCheck(t *testing.T) {
+	valid := func() *Config {
+		cfg := randConfig()
+		cfg.ChannelTimeout = 30
+		cfg.L2ChainID = big.NewInt(901)
+		cfg.P2PSequencerAddress = common.Address{0xaa}
+		cfg.DepositContractAddress = common.Address{0xbb}
+		return cfg
+	}
+	require.NoError(t, valid().Check())
+
+	tests := []struct {
+		name     string
+		modifier func(cfg *Config)
+		err      error
+	}{
+		{"block time", func(cfg *Config) { cfg.BlockTime = 0 }, ErrBlockTimeZero},
+		{"channel timeout", func(cfg *Config) { cfg.ChannelTimeout = 0 }, ErrMissingChannelTimeout},
+		{"seq window", func(cfg *Config) { cfg.SeqWindowSize = 1 }, ErrInvalidSeqWindowSize},
+		{"drift", func(cfg *Config) { cfg.MaxSequencerDrift = cfg.BlockTime - 1 }, ErrSequencerDriftTooSmall},
+		{"genesis l1", func(cfg *Config) { cfg.Genesis.L1.Hash = common.Hash{} }, ErrMissingGenesisL1Hash},
+		{"genesis l2", func(cfg *Config) { cfg.Genesis.L2.Hash = common.Hash{} }, ErrMissingGenesisL2Hash},
+		{"genesis same", func(cfg *Config) { cfg.Genesis.L2.Hash = cfg.Genesis.L1.Hash }, ErrGenesisHashesSame},
+		{"genesis time", func(cfg *Config) { cfg.Genesis.L2Time = 0 }, ErrMissingGenesisL2Time},
+		{"p2p sequencer", func(cfg *Config) { cfg.P2PSequencerAddress = common.Address{} }, ErrMissingP2PSequencerAddress},
+		{"fee recipient", func(cfg *Config) { cfg.FeeRecipientAddress = common.Address{} }, ErrMissingFeeRecipientAddress},
+		{"batch inbox", func(cfg *Config) { cfg.BatchInboxAddress = common.Address{} }, ErrMissingBatchInboxAddress},
+		{"batch sender", func(cfg *Config) { cfg.BatchSenderAddress = common.Address{} }, ErrMissingBatchSenderAddress},
+		{"deposit contract", func(cfg *Config) { cfg.DepositContractAddress = common.Address{} }, ErrMissingDepositContractAddress},
+		{"batch inbox mode", func(cfg *Config) { cfg.BatchInboxMode = "blobs" }, ErrUnknownBatchInboxMode},
+		{"unknown fork", func(cfg *Config) { cfg.Forks = ForkSchedule{"unknown": 0} }, ErrUnknownFork},
+		{"l1 chain id nil", func(cfg *Config) { cfg.L1ChainID = nil }, ErrMissingL1ChainID},
+		{"l2 chain id nil", func(cfg *Config) { cfg.L2ChainID = nil }, ErrMissingL2ChainID},
+		{"l1 chain id zero", func(cfg *Config) { cfg.L1ChainID = big.NewInt(0) }, ErrInvalidL1ChainID},
+		{"l2 chain id negative", func(cfg *Config) { cfg.L2ChainID = big.NewInt(-1) }, ErrInvalidL2ChainID},
+		{"chain ids same", func(cfg *Config) { cfg.L2ChainID = new(big.Int).Set(cfg.L1ChainID) }, ErrChainIDsSame},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := valid()
+			test.modifier(cfg)
+			require.ErrorIs(t, cfg.Check(), test.err)
+		})
+	}
+}
+
+func TestConfigCheckL1BlockTime(t *testing.T) {
+	cfg := randConfig()
+	cfg.BlockTime = 2
+	cfg.SeqWindowSize = 10
+	cfg.ChannelTimeout = 60
+	require.NoError(t, cfg.CheckL1BlockTime(12))
+	require.ErrorIs(t, cfg.CheckL1BlockTime(0), ErrL1BlockTimeZero)
+	require.ErrorIs(t, cfg.CheckL1BlockTime(13), ErrBlockTimeNotDivisor)
+	require.ErrorIs(t, cfg.CheckL1BlockTime(4), ErrChannelTimeoutTooLarge)
+}
+
+func TestConfigHash(t *testing.T) {
+	config := randConfig()
+	h, err := config.Hash()
+	require.NoError(t, err)
+
+	// re-encoding the config must not affect the hash
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	var roundTripped Config
+	require.NoError(t, json.Unmarshal(data, &roundTripped))
+	h2, err := roundTripped.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, h, h2)
+
+	// any change to the config changes the hash
+	roundTripped.BlockTime += 1
+	h3, err := roundTripped.Hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, h, h3)
+
+	_, err = ComputeConfigHash(ConfigHashVersion0+1, config)
+	assert.Error(t, err)
+}
+
+// TestConfigHashGolden pins the config hash of a fixed config.
+// If this test fails, the JSON encoding of the config changed, and the config hash version must be bumped.
+func TestConfigHashGolden(t *testing.T) {
+	config := &Config{
+		Genesis: Genesis{
+			L1:     eth.BlockID{Hash: common.HexToHash("0x01"), Number: 424242},
+			L2:     eth.BlockID{Hash: common.HexToHash("0x02"), Number: 1337},
+			L2Time: 1660000000,
+		},
+		BlockTime:              2,
+		MaxSequencerDrift:      100,
+		SeqWindowSize:          120,
+		ChannelTimeout:         30,
+		L1ChainID:              big.NewInt(900),
+		L2ChainID:              big.NewInt(901),
+		P2PSequencerAddress:    common.HexToAddress("0x03"),
+		FeeRecipientAddress:    common.HexToAddress("0x04"),
+		BatchInboxAddress:      common.HexToAddress("0x05"),
+		BatchSenderAddress:     common.HexToAddress("0x06"),
+		DepositContractAddress: common.HexToAddress("0x07"),
+	}
+	h, err := ComputeConfigHash(ConfigHashVersion0, config)
+	require.NoError(t, err)
+	assert.Equal(t, "0x3d2b9cc3b139e257db5aef1c7d100f8e4e9013a022064d31283df9bc69b7c911", h.String())
+}
@@ -3,6 +3,7 @@ package opnode
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +20,9 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/node"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/chains"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
 	"github.com/urfave/cli"
 )
 
@@ -67,20 +71,49 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 			ListenAddr:  ctx.GlobalString(flags.RPCListenAddr.Name),
 			ListenPort:  ctx.GlobalInt(flags.RPCListenPort.Name),
 			EnableAdmin: ctx.GlobalBool(flags.RPCEnableAdmin.Name),
+			CORSOrigins: splitList(ctx.GlobalString(flags.RPCCORSOrigins.Name)),
+			EnableWS:    ctx.GlobalBool(flags.RPCEnableWS.Name),
+			Access:      NewRPCAccessConfig(ctx),
 		},
 		Metrics: node.MetricsConfig{
 			Enabled:    ctx.GlobalBool(flags.MetricsEnabledFlag.Name),
 			ListenAddr: ctx.GlobalString(flags.MetricsAddrFlag.Name),
 			ListenPort: ctx.GlobalInt(flags.MetricsPortFlag.Name),
+			AuthToken:  ctx.GlobalString(flags.MetricsAuthTokenFlag.Name),
+			AccessLog:  ctx.GlobalBool(flags.MetricsAccessLogFlag.Name),
 		},
 		Pprof: node.PprofConfig{
 			Enabled:    ctx.GlobalBool(flags.PprofEnabledFlag.Name),
 			ListenAddr: ctx.GlobalString(flags.PprofAddrFlag.Name),
 			ListenPort: ctx.GlobalString(flags.PprofPortFlag.Name),
 		},
+		Heartbeat: node.HeartbeatConfig{
+			Enabled:  ctx.GlobalBool(flags.HeartbeatEnabledFlag.Name),
+			URL:      ctx.GlobalString(flags.HeartbeatURLFlag.Name),
+			Interval: ctx.GlobalDuration(flags.HeartbeatIntervalFlag.Name),
+		},
 		P2P:                 p2pConfig,
 		P2PSigner:           p2pSignerSetup,
+		L1HeadPollInterval:  ctx.GlobalDuration(flags.L1HeadPollIntervalFlag.Name),
 		L1EpochPollInterval: ctx.GlobalDuration(flags.L1EpochPollIntervalFlag.Name),
+		L1DBPath:            ctx.GlobalString(flags.L1DBPath.Name),
+		L2PrefetchPayloads:  ctx.GlobalUint64(flags.L2PrefetchPayloads.Name),
+		L2BackfillRPC:       ctx.GlobalString(flags.L2BackfillRPC.Name),
+		RuntimeConfigPath:   ctx.GlobalString(flags.RuntimeConfigFlag.Name),
+		EngineCalls: sources.EngineCallsConfig{
+			ForkchoiceUpdate: sources.EngineCallConfig{
+				Timeout: ctx.GlobalDuration(flags.L2EngineForkchoiceTimeout.Name),
+				Retries: ctx.GlobalInt(flags.L2EngineForkchoiceRetries.Name),
+			},
+			NewPayload: sources.EngineCallConfig{
+				Timeout: ctx.GlobalDuration(flags.L2EngineNewPayloadTimeout.Name),
+				Retries: ctx.GlobalInt(flags.L2EngineNewPayloadRetries.Name),
+			},
+			GetPayload: sources.EngineCallConfig{
+				Timeout: ctx.GlobalDuration(flags.L2EngineGetPayloadTimeout.Name),
+				Retries: ctx.GlobalInt(flags.L2EngineGetPayloadRetries.Name),
+			},
+		},
 	}
 	if err := cfg.Check(); err != nil {
 		return nil, err
@@ -88,6 +121,27 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 	return cfg, nil
 }
 
+func NewRPCAccessConfig(ctx *cli.Context) node.RPCAccessConfig {
+	return node.RPCAccessConfig{
+		AllowedMethods: splitList(ctx.GlobalString(flags.RPCAllowedMethods.Name)),
+		AdminToken:     ctx.GlobalString(flags.RPCAdminToken.Name),
+		RateLimit:      ctx.GlobalFloat64(flags.RPCRateLimit.Name),
+		RateBurst:      ctx.GlobalInt(flags.RPCRateBurst.Name),
+		MaxRequestSize: ctx.GlobalInt64(flags.RPCMaxRequestSize.Name),
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring empty elements.
+func splitList(v string) []string {
+	var out []string
+	for _, elem := range strings.Split(v, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			out = append(out, elem)
+		}
+	}
+	return out
+}
+
 func NewL1EndpointConfig(ctx *cli.Context) (*node.L1EndpointConfig, error) {
 	return &node.L1EndpointConfig{
 		L1NodeAddr: ctx.GlobalString(flags.L1NodeAddr.Name),
@@ -126,14 +180,58 @@ func NewL2EndpointConfig(ctx *cli.Context, log log.Logger) (*node.L2EndpointConf
 }
 
 func NewDriverConfig(ctx *cli.Context) (*driver.Config, error) {
+	anchor, err := NewTrustedAnchor(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return &driver.Config{
 		VerifierConfDepth:  ctx.GlobalUint64(flags.VerifierL1Confs.Name),
 		SequencerConfDepth: ctx.GlobalUint64(flags.SequencerL1Confs.Name),
 		SequencerEnabled:   ctx.GlobalBool(flags.SequencerEnabledFlag.Name),
+
+		SequencerMaxSafeLag:    ctx.GlobalUint64(flags.SequencerMaxSafeLagFlag.Name),
+		SequencerBuildDeadline: ctx.GlobalDuration(flags.SequencerBuildDeadlineFlag.Name),
+
+		DerivationStepBudget: ctx.GlobalDuration(flags.VerifierStepBudget.Name),
+
+		UnsafePayloadsSpillDir:  ctx.GlobalString(flags.VerifierUnsafePayloadsSpillDir.Name),
+		UnsafePayloadsSpillSize: ctx.GlobalUint64(flags.VerifierUnsafePayloadsSpillSize.Name),
+		UnsafeBackfillDepth:     ctx.GlobalUint64(flags.VerifierUnsafeBackfillDepth.Name),
+		UnsafePayloadBatchSize:  ctx.GlobalUint64(flags.VerifierUnsafePayloadBatchSize.Name),
+
+		TrustedAnchor: anchor,
 	}, nil
 }
 
+// NewTrustedAnchor returns the trusted L2 anchor to sync from, or nil if none is configured.
+func NewTrustedAnchor(ctx *cli.Context) (*derive.TrustedAnchor, error) {
+	l2Hash := ctx.GlobalString(flags.VerifierAnchorL2Hash.Name)
+	l1OriginHash := ctx.GlobalString(flags.VerifierAnchorL1OriginHash.Name)
+	if l2Hash == "" && l1OriginHash == "" {
+		return nil, nil
+	}
+	if l2Hash == "" || l1OriginHash == "" {
+		return nil, fmt.Errorf("both %s and %s must be set to sync from a trusted anchor", flags.VerifierAnchorL2Hash.Name, flags.VerifierAnchorL1OriginHash.Name)
+	}
+	var anchor derive.TrustedAnchor
+	if err := anchor.L2Hash.UnmarshalText([]byte(l2Hash)); err != nil {
+		return nil, fmt.Errorf("invalid trusted anchor L2 hash: %w", err)
+	}
+	if err := anchor.L1OriginHash.UnmarshalText([]byte(l1OriginHash)); err != nil {
+		return nil, fmt.Errorf("invalid trusted anchor L1 origin hash: %w", err)
+	}
+	return &anchor, nil
+}
+
 func NewRollupConfig(ctx *cli.Context) (*rollup.Config, error) {
+	if network := ctx.GlobalString(flags.Network.Name); network != "" {
+		rollupConfig, err := chains.GetRollupConfig(network)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rollup config of network %q: %w", network, err)
+		}
+		return rollupConfig, nil
+	}
+
 	rollupConfigPath := ctx.GlobalString(flags.RollupConfig.Name)
 	file, err := os.Open(rollupConfigPath)
 	if err != nil {
@@ -143,16 +241,33 @@ func NewRollupConfig(ctx *cli.Context) (*rollup.Config, error) {
 
 	var rollupConfig rollup.Config
 	if err := json.NewDecoder(file).Decode(&rollupConfig); err != nil {
-		return nil, fmt.Errorf("failed to decode rollup config: %v", err)
+		return nil, fmt.Errorf("failed to decode rollup config %q: %w", rollupConfigPath, describeJSONError(err))
 	}
 	return &rollupConfig, nil
 }
 
+// describeJSONError adds the location of the invalid data to JSON decoding errors, if known.
+func describeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("invalid JSON at byte offset %d: %w", syntaxErr.Offset, err)
+	} else if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Errorf("field %q must be of type %s, got JSON %s: %w", typeErr.Field, typeErr.Type, typeErr.Value, err)
+	}
+	return err
+}
+
 // NewLogConfig creates a log config from the provided flags or environment variables.
 func NewLogConfig(ctx *cli.Context) (node.LogConfig, error) {
 	cfg := node.DefaultLogConfig() // Done to set color based on terminal type
 	cfg.Level = ctx.GlobalString(flags.LogLevelFlag.Name)
 	cfg.Format = ctx.GlobalString(flags.LogFormatFlag.Name)
+	subsystemLevels, err := node.ParseSubsystemLevels(ctx.GlobalString(flags.LogSubsystemLevelsFlag.Name))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.SubsystemLevels = subsystemLevels
 	if ctx.IsSet(flags.LogColorFlag.Name) {
 		cfg.Color = ctx.GlobalBool(flags.LogColorFlag.Name)
 	}
@@ -0,0 +1,50 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// API serves a Signer under the "signer" JSON-RPC namespace, the interface that RemoteSigner uses.
+// It is the reference for external signer services, and should not be exposed without authentication.
+type API struct {
+	s Signer
+}
+
+func NewAPI(s Signer) *API {
+	return &API{s: s}
+}
+
+func (api *API) Address(ctx context.Context) (common.Address, error) {
+	return api.s.Address(), nil
+}
+
+func (api *API) SignBlockPayload(ctx context.Context, domain common.Hash, chainID *hexutil.Big, payload hexutil.Bytes) (hexutil.Bytes, error) {
+	if chainID == nil {
+		return nil, fmt.Errorf("missing chain ID")
+	}
+	sig, err := api.s.SignBlockPayload(ctx, domain, chainID.ToInt(), payload)
+	if err != nil {
+		return nil, err
+	}
+	return sig[:], nil
+}
+
+func (api *API) SignTransaction(ctx context.Context, chainID *hexutil.Big, tx hexutil.Bytes) (hexutil.Bytes, error) {
+	if chainID == nil {
+		return nil, fmt.Errorf("missing chain ID")
+	}
+	var unsigned types.Transaction
+	if err := unsigned.UnmarshalBinary(tx); err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
+	}
+	signed, err := api.s.SignTx(ctx, chainID.ToInt(), &unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return signed.MarshalBinary()
+}
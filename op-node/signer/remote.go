@@ -0,0 +1,101 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RemoteSigner signs with a key of an external signer service, over JSON-RPC.
+// The service implements the "signer" namespace of API.
+type RemoteSigner struct {
+	client *rpc.Client
+	addr   common.Address
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// DialRemoteSigner connects to the signer service at the given endpoint, and retrieves the address of its key.
+func DialRemoteSigner(ctx context.Context, endpoint string) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer: %w", err)
+	}
+	s, err := NewRemoteSigner(ctx, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewRemoteSigner creates a RemoteSigner of the signer service of the client, and retrieves the address of its key.
+func NewRemoteSigner(ctx context.Context, client *rpc.Client) (*RemoteSigner, error) {
+	var addr common.Address
+	if err := client.CallContext(ctx, &addr, "signer_address"); err != nil {
+		return nil, fmt.Errorf("failed to get remote signer address: %w", err)
+	}
+	return &RemoteSigner{client: client, addr: addr}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.addr
+}
+
+func (s *RemoteSigner) SignBlockPayload(ctx context.Context, domain [32]byte, chainID *big.Int, payloadBytes []byte) (*[65]byte, error) {
+	var sig hexutil.Bytes
+	if err := s.client.CallContext(ctx, &sig, "signer_signBlockPayload", common.Hash(domain), (*hexutil.Big)(chainID), hexutil.Bytes(payloadBytes)); err != nil {
+		return nil, fmt.Errorf("failed to sign block payload remotely: %w", err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("remote signer returned signature of %d bytes, expected 65", len(sig))
+	}
+	// the signature must cover the same payload, by the key of the signer
+	signingHash := PayloadSigningHash(domain, chainID, payloadBytes)
+	pub, err := crypto.SigToPub(signingHash[:], sig)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid signature: %w", err)
+	}
+	if addr := crypto.PubkeyToAddress(*pub); addr != s.addr {
+		return nil, fmt.Errorf("remote signer returned payload signed by %s, expected %s", addr, s.addr)
+	}
+	return (*[65]byte)(sig), nil
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tx: %w", err)
+	}
+	var res hexutil.Bytes
+	if err := s.client.CallContext(ctx, &res, "signer_signTransaction", (*hexutil.Big)(chainID), hexutil.Bytes(data)); err != nil {
+		return nil, fmt.Errorf("failed to sign tx remotely: %w", err)
+	}
+	var signed types.Transaction
+	if err := signed.UnmarshalBinary(res); err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid tx: %w", err)
+	}
+	// the signature must cover the same transaction, by the key of the signer
+	if sigHash, signedHash := types.LatestSignerForChainID(chainID).Hash(tx), types.LatestSignerForChainID(chainID).Hash(&signed); sigHash != signedHash {
+		return nil, fmt.Errorf("remote signer returned different tx %s, expected %s", signedHash, sigHash)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), &signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned tx with invalid signature: %w", err)
+	}
+	if sender != s.addr {
+		return nil, fmt.Errorf("remote signer returned tx signed by %s, expected %s", sender, s.addr)
+	}
+	return &signed, nil
+}
+
+func (s *RemoteSigner) Close() error {
+	s.client.Close()
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package signer provides the signers of the sequencer and batcher keys:
+// the sequencer signs the block payloads it gossips, and the batcher signs its batch transactions.
+// The keys can be kept in the process with a LocalSigner, or in an external signer service with a RemoteSigner.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrClosed = errors.New("signer is closed")
+
+type Signer interface {
+	// Address returns the address of the signing key.
+	Address() common.Address
+	// SignBlockPayload signs the encoded block payload, for the given signing domain and chain, see PayloadSigningHash.
+	SignBlockPayload(ctx context.Context, domain [32]byte, chainID *big.Int, payloadBytes []byte) (*[65]byte, error)
+	// SignTx signs the transaction for the given chain.
+	SignTx(ctx context.Context, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error)
+	io.Closer
+}
+
+// PayloadSigningHash is the hash of the domain, chain ID and encoded payload, that block payloads are signed over.
+func PayloadSigningHash(domain [32]byte, chainID *big.Int, payloadBytes []byte) common.Hash {
+	var msgInput [32 + 32 + 32]byte
+	// domain: first 32 bytes
+	copy(msgInput[:32], domain[:])
+	// chain_id: second 32 bytes
+	chainID.FillBytes(msgInput[32:64])
+	// payload_hash: third 32 bytes, hash of encoded payload
+	copy(msgInput[32:], crypto.Keccak256(payloadBytes))
+
+	return crypto.Keccak256Hash(msgInput[:])
+}
+
+// LocalSigner signs with a private key that is kept in memory.
+type LocalSigner struct {
+	priv *ecdsa.PrivateKey
+	addr common.Address
+}
+
+var _ Signer = (*LocalSigner)(nil)
+
+func NewLocalSigner(priv *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{priv: priv, addr: crypto.PubkeyToAddress(priv.PublicKey)}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.addr
+}
+
+func (s *LocalSigner) SignBlockPayload(ctx context.Context, domain [32]byte, chainID *big.Int, payloadBytes []byte) (*[65]byte, error) {
+	if s.priv == nil {
+		return nil, ErrClosed
+	}
+	signingHash := PayloadSigningHash(domain, chainID, payloadBytes)
+	signature, err := crypto.Sign(signingHash[:], s.priv)
+	if err != nil {
+		return nil, err
+	}
+	return (*[65]byte)(signature), nil
+}
+
+func (s *LocalSigner) SignTx(ctx context.Context, chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
+	if s.priv == nil {
+		return nil, ErrClosed
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.priv)
+}
+
+func (s *LocalSigner) Close() error {
+	s.priv = nil
+	return nil
+}
@@ -0,0 +1,104 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// startRemote serves the signer over HTTP, and returns a remote signer of it.
+func startRemote(t *testing.T, s Signer) *RemoteSigner {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("signer", NewAPI(s)))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(server.Stop)
+	remote, err := DialRemoteSigner(context.Background(), httpServer.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = remote.Close() })
+	return remote
+}
+
+func TestSigners(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	local := NewLocalSigner(priv)
+	signers := map[string]Signer{
+		"local":  local,
+		"remote": startRemote(t, local),
+	}
+	chainID := big.NewInt(900)
+	for name, s := range signers {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, crypto.PubkeyToAddress(priv.PublicKey), s.Address())
+
+			payload := []byte("payload")
+			sig, err := s.SignBlockPayload(context.Background(), [32]byte{1}, chainID, payload)
+			require.NoError(t, err)
+			h := PayloadSigningHash([32]byte{1}, chainID, payload)
+			pub, err := crypto.SigToPub(h[:], sig[:])
+			require.NoError(t, err)
+			require.Equal(t, s.Address(), crypto.PubkeyToAddress(*pub))
+
+			to := common.Address{0xaa}
+			tx := types.NewTx(&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     3,
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: big.NewInt(10),
+				Gas:       21000,
+				To:        &to,
+				Data:      []byte{1, 2, 3},
+			})
+			signed, err := s.SignTx(context.Background(), chainID, tx)
+			require.NoError(t, err)
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+			require.NoError(t, err)
+			require.Equal(t, s.Address(), sender)
+			require.Equal(t, tx.Nonce(), signed.Nonce())
+			require.Equal(t, tx.Data(), signed.Data())
+		})
+	}
+}
+
+// otherSigner signs with a different key than it reports
+type otherSigner struct {
+	Signer
+	addr common.Address
+}
+
+func (s *otherSigner) Address() common.Address {
+	return s.addr
+}
+
+func TestRemoteSignerChecksSender(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	remote := startRemote(t, &otherSigner{Signer: NewLocalSigner(priv), addr: common.Address{0xbb}})
+	require.Equal(t, common.Address{0xbb}, remote.Address())
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(900), Gas: 21000})
+	_, err = remote.SignTx(context.Background(), big.NewInt(900), tx)
+	require.ErrorContains(t, err, "expected "+common.Address{0xbb}.String())
+
+	_, err = remote.SignBlockPayload(context.Background(), [32]byte{}, big.NewInt(900), []byte("payload"))
+	require.ErrorContains(t, err, "expected "+common.Address{0xbb}.String())
+}
+
+func TestLocalSignerClosed(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	s := NewLocalSigner(priv)
+	require.NoError(t, s.Close())
+	_, err = s.SignBlockPayload(context.Background(), [32]byte{}, big.NewInt(1), nil)
+	require.ErrorIs(t, err, ErrClosed)
+	_, err = s.SignTx(context.Background(), big.NewInt(1), types.NewTx(&types.DynamicFeeTx{}))
+	require.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,232 @@
+package sources
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// maxStoredBlocks bounds the number of blocks kept below the highest stored block,
+// so the database does not grow unbounded if L1 finality stalls.
+const maxStoredBlocks = 10_000
+
+// Key prefixes of the block data. Block data is stored by block hash, so it is never invalidated by reorgs.
+var (
+	headerPrefix   = []byte("h") // headerPrefix + hash -> JSON encoded header
+	txsPrefix      = []byte("t") // txsPrefix + hash -> RLP encoded transactions
+	receiptsPrefix = []byte("r") // receiptsPrefix + hash -> JSON encoded receipts
+	numberPrefix   = []byte("n") // numberPrefix + num (uint64 big endian) + hash -> empty, to prune by number
+)
+
+// BlockDB persists the block headers, transactions and receipts fetched from a RPC,
+// so they do not have to be fetched again after a restart.
+// Blocks are pruned once they are older than the finalized block by more than the retained number of blocks.
+type BlockDB struct {
+	db     ethdb.KeyValueStore
+	log    log.Logger
+	retain uint64
+
+	mu      sync.Mutex
+	highest uint64
+}
+
+// OpenBlockDB opens (or creates) a leveldb database at the given path, to store blocks in.
+// The given number of blocks is kept below the finalized block.
+func OpenBlockDB(path string, retain uint64, log log.Logger) (*BlockDB, error) {
+	db, err := leveldb.New(path, 16, 16, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open block database %q: %w", path, err)
+	}
+	return NewBlockDB(db, retain, log), nil
+}
+
+// NewBlockDB stores blocks in the given key-value store.
+func NewBlockDB(db ethdb.KeyValueStore, retain uint64, log log.Logger) *BlockDB {
+	return &BlockDB{db: db, log: log, retain: retain}
+}
+
+func blockKey(prefix []byte, hash common.Hash) []byte {
+	return append(append([]byte{}, prefix...), hash[:]...)
+}
+
+func numberKey(num uint64, hash common.Hash) []byte {
+	key := make([]byte, len(numberPrefix)+8, len(numberPrefix)+8+common.HashLength)
+	copy(key, numberPrefix)
+	binary.BigEndian.PutUint64(key[len(numberPrefix):], num)
+	return append(key, hash[:]...)
+}
+
+func (b *BlockDB) get(key []byte) ([]byte, bool) {
+	if ok, err := b.db.Has(key); err != nil || !ok {
+		return nil, false
+	}
+	data, err := b.db.Get(key)
+	if err != nil {
+		b.log.Warn("Failed to read from block database", "err", err)
+		return nil, false
+	}
+	return data, true
+}
+
+func (b *BlockDB) put(num uint64, hash common.Hash, key []byte, data []byte) {
+	batch := b.db.NewBatch()
+	_ = batch.Put(numberKey(num, hash), nil)
+	_ = batch.Put(key, data)
+	if err := batch.Write(); err != nil {
+		b.log.Warn("Failed to write to block database", "block", hash, "err", err)
+		return
+	}
+	b.mu.Lock()
+	if num > b.highest {
+		b.highest = num
+	}
+	b.mu.Unlock()
+}
+
+// Header returns the stored header of the block with the given hash, if any.
+func (b *BlockDB) Header(hash common.Hash) (*rpcHeader, bool) {
+	data, ok := b.get(blockKey(headerPrefix, hash))
+	if !ok {
+		return nil, false
+	}
+	var header rpcHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		b.log.Warn("Failed to decode stored header", "block", hash, "err", err)
+		return nil, false
+	}
+	return &header, true
+}
+
+// PutHeader stores the header of a block.
+func (b *BlockDB) PutHeader(header *rpcHeader) {
+	data, err := json.Marshal(header)
+	if err != nil {
+		b.log.Warn("Failed to encode header", "block", header.Hash, "err", err)
+		return
+	}
+	b.put(uint64(header.Number), header.Hash, blockKey(headerPrefix, header.Hash), data)
+}
+
+// Transactions returns the stored transactions of the block with the given hash, if any.
+func (b *BlockDB) Transactions(hash common.Hash) (types.Transactions, bool) {
+	data, ok := b.get(blockKey(txsPrefix, hash))
+	if !ok {
+		return nil, false
+	}
+	var txs types.Transactions
+	if err := rlp.DecodeBytes(data, &txs); err != nil {
+		b.log.Warn("Failed to decode stored transactions", "block", hash, "err", err)
+		return nil, false
+	}
+	return txs, true
+}
+
+// PutTransactions stores the transactions of a block.
+func (b *BlockDB) PutTransactions(num uint64, hash common.Hash, txs types.Transactions) {
+	data, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		b.log.Warn("Failed to encode transactions", "block", hash, "err", err)
+		return
+	}
+	b.put(num, hash, blockKey(txsPrefix, hash), data)
+}
+
+// Receipts returns the stored receipts of the block with the given hash, if any.
+func (b *BlockDB) Receipts(hash common.Hash) (types.Receipts, bool) {
+	data, ok := b.get(blockKey(receiptsPrefix, hash))
+	if !ok {
+		return nil, false
+	}
+	var receipts types.Receipts
+	if err := json.Unmarshal(data, &receipts); err != nil {
+		b.log.Warn("Failed to decode stored receipts", "block", hash, "err", err)
+		return nil, false
+	}
+	return receipts, true
+}
+
+// PutReceipts stores the receipts of a block. The receipts must have been verified against the block.
+func (b *BlockDB) PutReceipts(num uint64, hash common.Hash, receipts types.Receipts) {
+	// JSON encoding is used, since the storage RLP encoding of receipts does not include the derived fields
+	data, err := json.Marshal(receipts)
+	if err != nil {
+		b.log.Warn("Failed to encode receipts", "block", hash, "err", err)
+		return
+	}
+	b.put(num, hash, blockKey(receiptsPrefix, hash), data)
+}
+
+// Prune deletes the blocks that are older than the finalized block by more than the retained number of blocks,
+// and the blocks that are more than maxStoredBlocks older than the highest stored block.
+func (b *BlockDB) Prune(finalized uint64) {
+	var bound uint64
+	if finalized > b.retain {
+		bound = finalized - b.retain
+	}
+	b.mu.Lock()
+	if b.highest > maxStoredBlocks && b.highest-maxStoredBlocks > bound {
+		bound = b.highest - maxStoredBlocks
+	}
+	b.mu.Unlock()
+
+	iter := b.db.NewIterator(numberPrefix, nil)
+	defer iter.Release()
+	batch := b.db.NewBatch()
+	pruned := 0
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != len(numberPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(numberPrefix):]) >= bound {
+			break // the keys are sorted by number
+		}
+		hash := common.BytesToHash(key[len(numberPrefix)+8:])
+		_ = batch.Delete(blockKey(headerPrefix, hash))
+		_ = batch.Delete(blockKey(txsPrefix, hash))
+		_ = batch.Delete(blockKey(receiptsPrefix, hash))
+		_ = batch.Delete(common.CopyBytes(key))
+		pruned += 1
+	}
+	if err := iter.Error(); err != nil {
+		b.log.Warn("Failed to iterate block database", "err", err)
+	}
+	if err := batch.Write(); err != nil {
+		b.log.Warn("Failed to prune block database", "err", err)
+		return
+	}
+	if pruned > 0 {
+		b.log.Debug("Pruned block database", "below", bound, "blocks", pruned)
+	}
+}
+
+// Close closes the database.
+func (b *BlockDB) Close() error {
+	return b.db.Close()
+}
+
+// storingReceiptsFetcher stores the receipts in the database once they are fetched and verified.
+type storingReceiptsFetcher struct {
+	eth.ReceiptsFetcher
+	db     *BlockDB
+	block  eth.BlockID
+	stored bool
+}
+
+func (f *storingReceiptsFetcher) Result() (types.Receipts, error) {
+	receipts, err := f.ReceiptsFetcher.Result()
+	if err == nil && !f.stored {
+		f.db.PutReceipts(f.block.Number, f.block.Hash, receipts)
+		f.stored = true
+	}
+	return receipts, err
+}
@@ -0,0 +1,133 @@
+package sources
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDB(t *testing.T) {
+	db := NewBlockDB(memorydb.New(), 10, testlog.Logger(t, log.LvlError))
+	_, rhdr := randHeader()
+	hash, num := rhdr.Hash, uint64(rhdr.Number)
+
+	_, ok := db.Header(hash)
+	require.False(t, ok)
+	db.PutHeader(rhdr)
+	stored, ok := db.Header(hash)
+	require.True(t, ok)
+	require.Equal(t, rhdr, stored)
+
+	txs := types.Transactions{
+		types.NewTransaction(1, common.Address{0x1}, big.NewInt(2), 21000, big.NewInt(3), []byte("data")),
+	}
+	db.PutTransactions(num, hash, txs)
+	storedTxs, ok := db.Transactions(hash)
+	require.True(t, ok)
+	require.Len(t, storedTxs, 1)
+	require.Equal(t, txs[0].Hash(), storedTxs[0].Hash())
+
+	receipts := types.Receipts{{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs: []*types.Log{{
+			Address:     common.Address{0x2},
+			Topics:      []common.Hash{{0x3}},
+			Data:        []byte("log"),
+			BlockNumber: num,
+			TxHash:      txs[0].Hash(),
+			BlockHash:   hash,
+		}},
+		TxHash:      txs[0].Hash(),
+		GasUsed:     21000,
+		BlockHash:   hash,
+		BlockNumber: new(big.Int).SetUint64(num),
+	}}
+	db.PutReceipts(num, hash, receipts)
+	storedReceipts, ok := db.Receipts(hash)
+	require.True(t, ok)
+	require.Equal(t, types.DeriveSha(receipts, trie.NewStackTrie(nil)), types.DeriveSha(storedReceipts, trie.NewStackTrie(nil)))
+	require.Equal(t, receipts[0].Logs, storedReceipts[0].Logs)
+	require.Equal(t, receipts[0].TxHash, storedReceipts[0].TxHash)
+	require.Equal(t, receipts[0].BlockHash, storedReceipts[0].BlockHash)
+	require.Equal(t, receipts[0].BlockNumber, storedReceipts[0].BlockNumber)
+
+	// within the retained blocks below the finalized block, nothing is pruned
+	db.Prune(num + 10)
+	_, ok = db.Header(hash)
+	require.True(t, ok)
+
+	db.Prune(num + 11)
+	_, ok = db.Header(hash)
+	require.False(t, ok)
+	_, ok = db.Transactions(hash)
+	require.False(t, ok)
+	_, ok = db.Receipts(hash)
+	require.False(t, ok)
+}
+
+func TestBlockDBMaxStoredBlocks(t *testing.T) {
+	db := NewBlockDB(memorydb.New(), 10, testlog.Logger(t, log.LvlError))
+	_, old := randHeader()
+	_, recent := randHeader()
+	recent.Number = old.Number + maxStoredBlocks + 1
+	db.PutHeader(old)
+	db.PutHeader(recent)
+
+	// even without finality, the blocks too far below the highest block are pruned
+	db.Prune(0)
+	_, ok := db.Header(old.Hash)
+	require.False(t, ok)
+	_, ok = db.Header(recent.Hash)
+	require.True(t, ok)
+}
+
+func TestEthClient_StoredBlocks(t *testing.T) {
+	db := NewBlockDB(memorydb.New(), 10, testlog.Logger(t, log.LvlError))
+	_, rhdr := randHeader()
+	expectedInfo, _ := rhdr.Info(true, false)
+	ctx := context.Background()
+
+	m := new(mockRPC)
+	m.On("CallContext", ctx, new(*rpcHeader),
+		"eth_getBlockByHash", []interface{}{rhdr.Hash, false}).Run(func(args mock.Arguments) {
+		*args[1].(**rpcHeader) = rhdr
+	}).Return([]error{nil})
+	s, err := NewEthClient(m, nil, nil, testEthClientConfig)
+	require.NoError(t, err)
+	s.db = db
+	info, err := s.InfoByHash(ctx, rhdr.Hash)
+	require.NoError(t, err)
+	require.Equal(t, expectedInfo, info)
+	m.Mock.AssertExpectations(t)
+
+	// after a restart, the block is loaded from the database, without RPC calls
+	m = new(mockRPC)
+	s, err = NewEthClient(m, nil, nil, testEthClientConfig)
+	require.NoError(t, err)
+	s.db = db
+	info, err = s.InfoByHash(ctx, rhdr.Hash)
+	require.NoError(t, err)
+	require.Equal(t, expectedInfo, info)
+	m.Mock.AssertExpectations(t)
+
+	// receipts are stored once fetched and verified
+	receipts := types.Receipts{}
+	rhdr.ReceiptHash = types.EmptyRootHash
+	fetcher := &storingReceiptsFetcher{ReceiptsFetcher: eth.FetchedReceipts(receipts), db: db, block: eth.BlockID{Hash: rhdr.Hash, Number: uint64(rhdr.Number)}}
+	_, err = fetcher.Result()
+	require.NoError(t, err)
+	stored, ok := db.Receipts(rhdr.Hash)
+	require.True(t, ok)
+	require.Equal(t, receipts, stored)
+}
@@ -2,7 +2,9 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
@@ -13,23 +15,98 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// engineRetryDelay is the time to wait before retrying a failed engine API call.
+const engineRetryDelay = 200 * time.Millisecond
+
+// EngineCallConfig configures the timeout and retries of calls to an engine API method.
+type EngineCallConfig struct {
+	// Timeout of a single call attempt. No timeout is applied if zero.
+	Timeout time.Duration
+	// Retries is the number of times a call is retried after a timeout or another RPC failure.
+	// Calls that the engine responded to with an error are never retried.
+	Retries int
+}
+
+func (c *EngineCallConfig) Check() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("negative timeout: %s", c.Timeout)
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("negative number of retries: %d", c.Retries)
+	}
+	return nil
+}
+
+// EngineCallsConfig configures the calls to each of the engine API methods.
+type EngineCallsConfig struct {
+	ForkchoiceUpdate EngineCallConfig
+	NewPayload       EngineCallConfig
+	GetPayload       EngineCallConfig
+}
+
+func EngineCallsDefaultConfig() EngineCallsConfig {
+	return EngineCallsConfig{
+		ForkchoiceUpdate: EngineCallConfig{Timeout: time.Second * 5},
+		NewPayload:       EngineCallConfig{Timeout: time.Second * 5},
+		GetPayload:       EngineCallConfig{Timeout: time.Second * 5},
+	}
+}
+
+func (c *EngineCallsConfig) Check() error {
+	if err := c.ForkchoiceUpdate.Check(); err != nil {
+		return fmt.Errorf("invalid forkchoice-update config: %w", err)
+	}
+	if err := c.NewPayload.Check(); err != nil {
+		return fmt.Errorf("invalid new-payload config: %w", err)
+	}
+	if err := c.GetPayload.Check(); err != nil {
+		return fmt.Errorf("invalid get-payload config: %w", err)
+	}
+	return nil
+}
+
 type EngineClientConfig struct {
 	L2ClientConfig
+	EngineCallsConfig
 }
 
 func EngineClientDefaultConfig(config *rollup.Config) *EngineClientConfig {
 	return &EngineClientConfig{
 		// engine is trusted, no need to recompute responses etc.
-		L2ClientConfig: *L2ClientDefaultConfig(config, true),
+		L2ClientConfig:    *L2ClientDefaultConfig(config, true),
+		EngineCallsConfig: EngineCallsDefaultConfig(),
 	}
 }
 
+// EngineMetrics tracks the failures of engine API calls, and the forkchoice updates that were skipped.
+type EngineMetrics interface {
+	RecordEngineTimeout(method string)
+	RecordEngineRetry(method string)
+	RecordEngineForkchoiceSkip()
+}
+
 // EngineClient extends L2Client with engine API bindings.
 type EngineClient struct {
 	*L2Client
+
+	calls   EngineCallsConfig
+	metrics EngineMetrics
+
+	// fcLock guards the last forkchoice state, it is not held during engine calls.
+	fcLock sync.Mutex
+	// lastFC is the last forkchoice state the engine accepted as valid, nil if unknown.
+	lastFC *eth.ForkchoiceState
+	// lastFCResult is the result of the call that applied lastFC.
+	lastFCResult eth.ForkchoiceUpdatedResult
+	// fcGen is bumped whenever the engine state may change, so the result of an update
+	// is only remembered if no other update or payload was sent while it was in flight.
+	fcGen uint64
 }
 
-func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics, config *EngineClientConfig) (*EngineClient, error) {
+func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics, engineMetrics EngineMetrics, config *EngineClientConfig) (*EngineClient, error) {
+	if err := config.EngineCallsConfig.Check(); err != nil {
+		return nil, fmt.Errorf("bad engine calls config: %w", err)
+	}
 	l2Client, err := NewL2Client(client, log, metrics, &config.L2ClientConfig)
 	if err != nil {
 		return nil, err
@@ -37,9 +114,42 @@ func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics,
 
 	return &EngineClient{
 		L2Client: l2Client,
+		calls:    config.EngineCallsConfig,
+		metrics:  engineMetrics,
 	}, nil
 }
 
+// call calls the engine API method, with the timeout and retries of the given config.
+// The engine responding with an error, or the parent context being done, stops any retries.
+func (s *EngineClient) call(ctx context.Context, cfg EngineCallConfig, result any, method string, args ...any) error {
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if cfg.Timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		}
+		err := s.client.CallContext(callCtx, result, method, args...)
+		timedOut := ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if timedOut {
+			s.metrics.RecordEngineTimeout(method)
+		}
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) || attempt >= cfg.Retries || ctx.Err() != nil {
+			return err
+		}
+		s.metrics.RecordEngineRetry(method)
+		s.log.Warn("Retrying engine API call", "method", method, "attempt", attempt+1, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(engineRetryDelay):
+		}
+	}
+}
+
 // ForkchoiceUpdate updates the forkchoice on the execution client. If attributes is not nil, the engine client will also begin building a block
 // based on attributes after the new head block and return the payload ID.
 //
@@ -47,13 +157,42 @@ func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics,
 // 1. Processing error: ForkchoiceUpdatedResult.PayloadStatusV1.ValidationError or other non-success PayloadStatusV1,
 // 2. `error` as eth.InputError: the forkchoice state or attributes are not valid.
 // 3. Other types of `error`: temporary RPC errors, like timeouts.
+//
+// Updates without attributes to the forkchoice state that the engine last accepted as valid are skipped,
+// as they do not change anything, and the result of the previous update is returned instead.
 func (s *EngineClient) ForkchoiceUpdate(ctx context.Context, fc *eth.ForkchoiceState, attributes *eth.PayloadAttributes) (*eth.ForkchoiceUpdatedResult, error) {
 	e := s.log.New("state", fc, "attr", attributes)
+	s.fcLock.Lock()
+	if attributes == nil && s.lastFC != nil && *s.lastFC == *fc {
+		result := s.lastFCResult
+		s.fcLock.Unlock()
+		e.Trace("Skipping redundant forkchoice-updated signal")
+		s.metrics.RecordEngineForkchoiceSkip()
+		return &result, nil
+	}
+	// forget the last state, in case the update fails halfway
+	s.lastFC = nil
+	s.fcGen++
+	gen := s.fcGen
+	s.fcLock.Unlock()
+
 	e.Trace("Sharing forkchoice-updated signal")
-	fcCtx, cancel := context.WithTimeout(ctx, time.Second*5)
-	defer cancel()
 	var result eth.ForkchoiceUpdatedResult
-	err := s.client.CallContext(fcCtx, &result, "engine_forkchoiceUpdatedV1", fc, attributes)
+	err := s.call(ctx, s.calls.ForkchoiceUpdate, &result, "engine_forkchoiceUpdatedV1", fc, attributes)
+
+	s.fcLock.Lock()
+	if err == nil && result.PayloadStatus.Status == eth.ExecutionValid {
+		if gen == s.fcGen {
+			state := *fc
+			s.lastFC = &state
+			s.lastFCResult = eth.ForkchoiceUpdatedResult{PayloadStatus: result.PayloadStatus}
+		}
+	} else {
+		// the engine may be in any state after a failed, syncing or invalid update
+		s.lastFC = nil
+	}
+	s.fcLock.Unlock()
+
 	if err == nil {
 		e.Trace("Shared forkchoice-updated signal")
 		if attributes != nil { // block building is optional, we only get a payload ID if we are building a block
@@ -85,10 +224,18 @@ func (s *EngineClient) NewPayload(ctx context.Context, payload *eth.ExecutionPay
 	e := s.log.New("block_hash", payload.BlockHash)
 	e.Trace("sending payload for execution")
 
-	execCtx, cancel := context.WithTimeout(ctx, time.Second*5)
-	defer cancel()
+	if s.prefetcher != nil {
+		// the payload may replace blocks that were prefetched
+		s.prefetcher.Reset()
+	}
+	s.fcLock.Lock()
+	// the payload may change what the forkchoice state points to, e.g. when re-inserting blocks during a reorg,
+	// so the next forkchoice update is never skipped.
+	s.lastFC = nil
+	s.fcGen++
+	s.fcLock.Unlock()
 	var result eth.PayloadStatusV1
-	err := s.client.CallContext(execCtx, &result, "engine_newPayloadV1", payload)
+	err := s.call(ctx, s.calls.NewPayload, &result, "engine_newPayloadV1", payload)
 	e.Trace("Received payload execution result", "status", result.Status, "latestValidHash", result.LatestValidHash, "message", result.ValidationError)
 	if err != nil {
 		e.Error("Payload execution failed", "err", err)
@@ -105,7 +252,7 @@ func (s *EngineClient) GetPayload(ctx context.Context, payloadId eth.PayloadID)
 	e := s.log.New("payload_id", payloadId)
 	e.Trace("getting payload")
 	var result eth.ExecutionPayload
-	err := s.client.CallContext(ctx, &result, "engine_getPayloadV1", payloadId)
+	err := s.call(ctx, s.calls.GetPayload, &result, "engine_getPayloadV1", payloadId)
 	if err != nil {
 		e.Warn("Failed to get payload", "payload_id", payloadId, "err", err)
 		if rpcErr, ok := err.(rpc.Error); ok {
@@ -0,0 +1,218 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// scriptedRPC returns the next scripted error for each call.
+// An errBlock entry makes the call block until the call context is done.
+type scriptedRPC struct {
+	client.RPC
+	errs  []error
+	calls int
+}
+
+var errBlock = errors.New("block until done")
+
+func (s *scriptedRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	err := s.errs[s.calls]
+	s.calls++
+	if err == errBlock {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return err
+}
+
+type engineMetrics struct {
+	timeouts map[string]int
+	retries  map[string]int
+	skips    int
+}
+
+func (m *engineMetrics) RecordEngineTimeout(method string) {
+	m.timeouts[method]++
+}
+
+func (m *engineMetrics) RecordEngineRetry(method string) {
+	m.retries[method]++
+}
+
+func (m *engineMetrics) RecordEngineForkchoiceSkip() {
+	m.skips++
+}
+
+type engineErr struct{}
+
+func (engineErr) Error() string  { return "engine error" }
+func (engineErr) ErrorCode() int { return -38003 }
+
+func TestEngineClientCall(t *testing.T) {
+	const method = "engine_getPayloadV1"
+	tmpErr := errors.New("connection reset")
+	testCases := []struct {
+		name     string
+		cfg      EngineCallConfig
+		errs     []error
+		expErr   error
+		calls    int
+		timeouts int
+		retries  int
+	}{
+		{"success", EngineCallConfig{Timeout: time.Second}, []error{nil}, nil, 1, 0, 0},
+		{"no retries", EngineCallConfig{Timeout: time.Second}, []error{tmpErr}, tmpErr, 1, 0, 0},
+		{"retry failure", EngineCallConfig{Timeout: time.Second, Retries: 2}, []error{tmpErr, nil}, nil, 2, 0, 1},
+		{"retries exhausted", EngineCallConfig{Timeout: time.Second, Retries: 1}, []error{tmpErr, tmpErr}, tmpErr, 2, 0, 1},
+		{"retry timeout", EngineCallConfig{Timeout: time.Millisecond * 10, Retries: 1}, []error{errBlock, nil}, nil, 2, 1, 1},
+		{"timeout", EngineCallConfig{Timeout: time.Millisecond * 10}, []error{errBlock}, context.DeadlineExceeded, 1, 1, 0},
+		{"engine error", EngineCallConfig{Timeout: time.Second, Retries: 3}, []error{engineErr{}}, engineErr{}, 1, 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpc := &scriptedRPC{errs: tc.errs}
+			m := &engineMetrics{timeouts: make(map[string]int), retries: make(map[string]int)}
+			cfg := EngineClientDefaultConfig(&rollup.Config{})
+			cfg.GetPayload = tc.cfg
+			cl, err := NewEngineClient(rpc, testlog.Logger(t, log.LvlError), nil, m, cfg)
+			require.NoError(t, err)
+
+			var result eth.ExecutionPayload
+			err = cl.call(context.Background(), cl.calls.GetPayload, &result, method)
+			if tc.expErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.expErr)
+			}
+			require.Equal(t, tc.calls, rpc.calls)
+			require.Equal(t, tc.timeouts, m.timeouts[method])
+			require.Equal(t, tc.retries, m.retries[method])
+		})
+	}
+}
+
+func TestEngineClientCallParentDone(t *testing.T) {
+	rpc := &scriptedRPC{errs: []error{errBlock, nil}}
+	m := &engineMetrics{timeouts: make(map[string]int), retries: make(map[string]int)}
+	cfg := EngineClientDefaultConfig(&rollup.Config{})
+	cfg.ForkchoiceUpdate = EngineCallConfig{Timeout: time.Second, Retries: 3}
+	cl, err := NewEngineClient(rpc, testlog.Logger(t, log.LvlError), nil, m, cfg)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	var result eth.ForkchoiceUpdatedResult
+	err = cl.call(ctx, cl.calls.ForkchoiceUpdate, &result, "engine_forkchoiceUpdatedV1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, rpc.calls, "no retries after the parent context is done")
+	require.Empty(t, m.timeouts, "the parent context timing out is not an engine timeout")
+	require.Empty(t, m.retries)
+}
+
+func TestEngineCallsConfigCheck(t *testing.T) {
+	cfg := EngineCallsDefaultConfig()
+	require.NoError(t, cfg.Check())
+	cfg.NewPayload.Retries = -1
+	require.ErrorContains(t, cfg.Check(), "new-payload")
+	cfg = EngineCallsDefaultConfig()
+	cfg.GetPayload.Timeout = -time.Second
+	require.ErrorContains(t, cfg.Check(), "get-payload")
+}
+
+// forkchoiceRPC responds to forkchoice updates with the given status, and counts the calls.
+type forkchoiceRPC struct {
+	client.RPC
+	status eth.ExecutePayloadStatus
+	err    error
+	calls  int
+	// onCall is called once, on the next call
+	onCall func()
+}
+
+func (f *forkchoiceRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	f.calls++
+	if onCall := f.onCall; onCall != nil {
+		f.onCall = nil
+		onCall()
+	}
+	if f.err != nil {
+		return f.err
+	}
+	switch r := result.(type) {
+	case *eth.ForkchoiceUpdatedResult:
+		r.PayloadStatus.Status = f.status
+	case *eth.PayloadStatusV1:
+		r.Status = f.status
+	}
+	return nil
+}
+
+func TestEngineClientForkchoiceUpdateSkip(t *testing.T) {
+	rpc := &forkchoiceRPC{status: eth.ExecutionValid}
+	m := &engineMetrics{timeouts: make(map[string]int), retries: make(map[string]int)}
+	cl, err := NewEngineClient(rpc, testlog.Logger(t, log.LvlError), nil, m, EngineClientDefaultConfig(&rollup.Config{}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	fcA := &eth.ForkchoiceState{HeadBlockHash: common.Hash{1}, SafeBlockHash: common.Hash{1}}
+	fcB := &eth.ForkchoiceState{HeadBlockHash: common.Hash{2}, SafeBlockHash: common.Hash{1}}
+	update := func(fc *eth.ForkchoiceState, attrs *eth.PayloadAttributes, calls int, skips int) {
+		t.Helper()
+		res, err := cl.ForkchoiceUpdate(ctx, fc, attrs)
+		require.NoError(t, err)
+		require.Equal(t, eth.ExecutionValid, res.PayloadStatus.Status)
+		require.Equal(t, calls, rpc.calls, "calls")
+		require.Equal(t, skips, m.skips, "skips")
+	}
+
+	update(fcA, nil, 1, 0)
+	update(fcA, nil, 1, 1)
+	update(fcB, nil, 2, 1)
+	update(fcB, nil, 2, 2)
+	// block building is never skipped
+	update(fcB, &eth.PayloadAttributes{}, 3, 2)
+	update(fcB, nil, 3, 3)
+
+	// a new payload may change what the forkchoice points to
+	_, err = cl.NewPayload(ctx, &eth.ExecutionPayload{})
+	require.NoError(t, err)
+	update(fcB, nil, 5, 3)
+
+	// states that the engine did not accept as valid are not skipped
+	rpc.status = eth.ExecutionSyncing
+	_, err = cl.ForkchoiceUpdate(ctx, fcA, nil)
+	require.NoError(t, err)
+	_, err = cl.ForkchoiceUpdate(ctx, fcA, nil)
+	require.NoError(t, err)
+	require.Equal(t, 7, rpc.calls)
+
+	// nor are states after a failed update
+	rpc.status = eth.ExecutionValid
+	update(fcB, nil, 8, 3)
+	rpc.err = errors.New("connection reset")
+	_, err = cl.ForkchoiceUpdate(ctx, fcA, nil)
+	require.Error(t, err)
+	rpc.err = nil
+	update(fcB, nil, 10, 3)
+
+	// nor are states that were updated while a payload was sent to the engine
+	update(fcA, nil, 11, 3)
+	rpc.onCall = func() {
+		_, err := cl.NewPayload(ctx, &eth.ExecutionPayload{})
+		require.NoError(t, err)
+	}
+	update(fcB, nil, 13, 3)
+	update(fcB, nil, 14, 3)
+	update(fcB, nil, 14, 4)
+}
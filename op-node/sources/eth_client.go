@@ -95,6 +95,9 @@ type EthClient struct {
 	// cache payloads by hash
 	// common.Hash -> *eth.ExecutionPayload
 	payloadsCache *caching.LRUCache
+
+	// persists fetched headers, transactions and receipts, optional
+	db *BlockDB
 }
 
 // NewEthClient wraps a RPC with bindings to fetch ethereum data,
@@ -137,6 +140,9 @@ func (s *EthClient) headerCall(ctx context.Context, method string, id interface{
 		return nil, err
 	}
 	s.headersCache.Add(info.Hash(), info)
+	if s.db != nil {
+		s.db.PutHeader(header)
+	}
 	return info, nil
 }
 
@@ -155,9 +161,31 @@ func (s *EthClient) blockCall(ctx context.Context, method string, id interface{}
 	}
 	s.headersCache.Add(info.Hash(), info)
 	s.transactionsCache.Add(info.Hash(), txs)
+	if s.db != nil {
+		s.db.PutHeader(&block.rpcHeader)
+		s.db.PutTransactions(info.NumberU64(), info.Hash(), txs)
+	}
 	return info, txs, nil
 }
 
+// storedInfo loads the header of a block from the database into the cache, if it is stored.
+func (s *EthClient) storedInfo(hash common.Hash) (*HeaderInfo, bool) {
+	if s.db == nil {
+		return nil, false
+	}
+	header, ok := s.db.Header(hash)
+	if !ok {
+		return nil, false
+	}
+	// the header was verified before it was stored
+	info, err := header.Info(true, false)
+	if err != nil {
+		return nil, false
+	}
+	s.headersCache.Add(hash, info)
+	return info, true
+}
+
 func (s *EthClient) payloadCall(ctx context.Context, method string, id interface{}) (*eth.ExecutionPayload, error) {
 	var block *rpcBlock
 	err := s.client.CallContext(ctx, &block, method, id, true)
@@ -179,6 +207,9 @@ func (s *EthClient) InfoByHash(ctx context.Context, hash common.Hash) (eth.Block
 	if header, ok := s.headersCache.Get(hash); ok {
 		return header.(*HeaderInfo), nil
 	}
+	if info, ok := s.storedInfo(hash); ok {
+		return info, nil
+	}
 	return s.headerCall(ctx, "eth_getBlockByHash", hash)
 }
 
@@ -203,6 +234,14 @@ func (s *EthClient) InfoAndTxsByHash(ctx context.Context, hash common.Hash) (eth
 			return header.(*HeaderInfo), txs.(types.Transactions), nil
 		}
 	}
+	if s.db != nil {
+		if txs, ok := s.db.Transactions(hash); ok {
+			if info, ok := s.storedInfo(hash); ok {
+				s.transactionsCache.Add(hash, txs)
+				return info, txs, nil
+			}
+		}
+	}
 	return s.blockCall(ctx, "eth_getBlockByHash", hash)
 }
 
@@ -239,11 +278,21 @@ func (s *EthClient) Fetch(ctx context.Context, blockHash common.Hash) (eth.Block
 	if v, ok := s.receiptsCache.Get(blockHash); ok {
 		return info, txs, v.(eth.ReceiptsFetcher), nil
 	}
+	if s.db != nil {
+		if receipts, ok := s.db.Receipts(blockHash); ok {
+			r := eth.FetchedReceipts(receipts)
+			s.receiptsCache.Add(blockHash, r)
+			return info, txs, r, nil
+		}
+	}
 	txHashes := make([]common.Hash, len(txs))
 	for i := 0; i < len(txs); i++ {
 		txHashes[i] = txs[i].Hash()
 	}
-	r := NewReceiptsFetcher(info.ID(), info.ReceiptHash(), txHashes, s.client.BatchCallContext, s.maxBatchSize)
+	var r eth.ReceiptsFetcher = NewReceiptsFetcher(info.ID(), info.ReceiptHash(), txHashes, s.client.BatchCallContext, s.maxBatchSize)
+	if s.db != nil {
+		r = &storingReceiptsFetcher{ReceiptsFetcher: r, db: s.db, block: info.ID()}
+	}
 	s.receiptsCache.Add(blockHash, r)
 	return info, txs, r, nil
 }
@@ -278,6 +327,9 @@ func (s *EthClient) BlockIDRange(ctx context.Context, begin eth.BlockID, max uin
 				return nil, fmt.Errorf("bad header data for block %s: %w", headerRequests[i].Args[0], err)
 			}
 			s.headersCache.Add(info.Hash(), info)
+			if s.db != nil {
+				s.db.PutHeader(result)
+			}
 			out = append(out, info.ID())
 			prev := begin
 			if i > 0 {
@@ -16,6 +16,10 @@ type L1ClientConfig struct {
 	EthClientConfig
 
 	L1BlockRefsCacheSize int
+
+	// DB persists the fetched L1 blocks across restarts, optional.
+	// The blocks older than the finalized L1 block are pruned when the finalized L1 block is fetched.
+	DB *BlockDB
 }
 
 func L1ClientDefaultConfig(config *rollup.Config, trustRPC bool) *L1ClientConfig {
@@ -57,6 +61,7 @@ func NewL1Client(client client.RPC, log log.Logger, metrics caching.Metrics, con
 	if err != nil {
 		return nil, err
 	}
+	ethClient.db = config.DB
 
 	return &L1Client{
 		EthClient:        ethClient,
@@ -71,6 +76,9 @@ func (s *L1Client) L1BlockRefByLabel(ctx context.Context, label eth.BlockLabel)
 	}
 	ref := eth.InfoToL1BlockRef(info)
 	s.l1BlockRefsCache.Add(ref.Hash, ref)
+	if label == eth.Finalized && s.db != nil {
+		s.db.Prune(ref.Number)
+	}
 	return ref, nil
 }
 
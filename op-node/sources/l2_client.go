@@ -18,9 +18,25 @@ type L2ClientConfig struct {
 
 	L2BlockRefsCacheSize int
 
+	// PrefetchPayloads is the number of payloads to prefetch ahead of sequentially requested payloads.
+	// Prefetching is disabled if 0.
+	PrefetchPayloads uint64
+	// PrefetchWorkers is the maximum number of concurrent prefetch requests.
+	PrefetchWorkers int
+
 	Genesis rollup.Genesis
 }
 
+func (c *L2ClientConfig) Check() error {
+	if err := c.EthClientConfig.Check(); err != nil {
+		return err
+	}
+	if c.PrefetchPayloads > 0 && c.PrefetchWorkers < 1 {
+		return fmt.Errorf("expected at least 1 prefetch worker, but got %d", c.PrefetchWorkers)
+	}
+	return nil
+}
+
 func L2ClientDefaultConfig(config *rollup.Config, trustRPC bool) *L2ClientConfig {
 	// Cache 3/2 worth of sequencing window of payloads, block references, receipts and txs
 	span := int(config.SeqWindowSize) * 3 / 2
@@ -46,6 +62,7 @@ func L2ClientDefaultConfig(config *rollup.Config, trustRPC bool) *L2ClientConfig
 			MustBePostMerge:       true,
 		},
 		L2BlockRefsCacheSize: span,
+		PrefetchWorkers:      4,
 		Genesis:              config.Genesis,
 	}
 }
@@ -58,19 +75,45 @@ type L2Client struct {
 	// cache L2BlockRef by hash
 	// common.Hash -> eth.L2BlockRef
 	l2BlockRefsCache *caching.LRUCache
+
+	// prefetcher of sequentially requested payloads, nil if disabled
+	prefetcher *payloadPrefetcher
 }
 
 func NewL2Client(client client.RPC, log log.Logger, metrics caching.Metrics, config *L2ClientConfig) (*L2Client, error) {
+	if err := config.Check(); err != nil {
+		return nil, fmt.Errorf("bad config, cannot create L2 source: %w", err)
+	}
 	ethClient, err := NewEthClient(client, log, metrics, &config.EthClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	return &L2Client{
+	l2Client := &L2Client{
 		EthClient:        ethClient,
 		genesis:          &config.Genesis,
 		l2BlockRefsCache: caching.NewLRUCache(metrics, "blockrefs", config.L2BlockRefsCacheSize),
-	}, nil
+	}
+	if config.PrefetchPayloads > 0 {
+		l2Client.prefetcher = newPayloadPrefetcher(log, metrics, ethClient.PayloadByNumber, config.PrefetchPayloads, config.PrefetchWorkers)
+	}
+	return l2Client, nil
+}
+
+// PayloadByNumber returns the payload of the given block number.
+// If prefetching is enabled, sequential requests are served from prefetched payloads where possible.
+func (s *L2Client) PayloadByNumber(ctx context.Context, number uint64) (*eth.ExecutionPayload, error) {
+	if s.prefetcher == nil {
+		return s.EthClient.PayloadByNumber(ctx, number)
+	}
+	return s.prefetcher.PayloadByNumber(ctx, number)
+}
+
+func (s *L2Client) Close() {
+	if s.prefetcher != nil {
+		s.prefetcher.Close()
+	}
+	s.EthClient.Close()
 }
 
 // L2BlockRefByLabel returns the L2 block reference for the given label.
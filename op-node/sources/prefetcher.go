@@ -0,0 +1,184 @@
+package sources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
+)
+
+// prefetchTimeout is the timeout of a single speculative payload fetch.
+const prefetchTimeout = 10 * time.Second
+
+type fetchPayloadFn func(ctx context.Context, number uint64) (*eth.ExecutionPayload, error)
+
+// payloadPrefetcher speculatively fetches the payloads after the last payload that was requested by number,
+// if the payloads are requested sequentially, e.g. by the derivation pipeline consolidating the unsafe chain.
+//
+// Prefetched payloads may be reorged out before they are requested, and are only served if they build on
+// the last payload that was served, and until the engine processes a new payload.
+type payloadPrefetcher struct {
+	log     log.Logger
+	metrics caching.Metrics
+	fetch   fetchPayloadFn
+
+	// depth is the number of payloads to fetch ahead of the last requested payload
+	depth uint64
+	// workers limits the number of concurrent prefetch requests
+	workers chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// wg tracks the in-flight prefetch requests, to wait for them on Close
+	wg sync.WaitGroup
+
+	mu sync.Mutex
+	// last is the last payload that was requested by number
+	last *eth.ExecutionPayload
+	// payloads holds the prefetched payloads by number
+	payloads map[uint64]*eth.ExecutionPayload
+	// pending holds the numbers of the payloads that are being fetched
+	pending map[uint64]struct{}
+	// generation is bumped on reset, to drop the results of in-flight fetches
+	generation uint64
+	// genCtx is canceled on reset, to cancel the in-flight fetches of the current generation
+	genCtx    context.Context
+	genCancel context.CancelFunc
+}
+
+func newPayloadPrefetcher(log log.Logger, metrics caching.Metrics, fetch fetchPayloadFn, depth uint64, workers int) *payloadPrefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	genCtx, genCancel := context.WithCancel(ctx)
+	return &payloadPrefetcher{
+		log:       log,
+		metrics:   metrics,
+		fetch:     fetch,
+		depth:     depth,
+		workers:   make(chan struct{}, workers),
+		ctx:       ctx,
+		cancel:    cancel,
+		payloads:  make(map[uint64]*eth.ExecutionPayload),
+		pending:   make(map[uint64]struct{}),
+		genCtx:    genCtx,
+		genCancel: genCancel,
+	}
+}
+
+// PayloadByNumber returns the prefetched payload of the given number if it is available and builds on the
+// last served payload, and fetches it otherwise. Sequential requests trigger the prefetching of the next payloads.
+func (p *payloadPrefetcher) PayloadByNumber(ctx context.Context, number uint64) (*eth.ExecutionPayload, error) {
+	payload := p.get(number)
+	if p.metrics != nil {
+		p.metrics.CacheGet("prefetch", payload != nil)
+	}
+	if payload == nil {
+		var err error
+		payload, err = p.fetch(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.served(payload)
+	return payload, nil
+}
+
+func (p *payloadPrefetcher) get(number uint64) *eth.ExecutionPayload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payload, ok := p.payloads[number]
+	if !ok {
+		return nil
+	}
+	delete(p.payloads, number)
+	if p.last == nil || uint64(p.last.BlockNumber)+1 != number || payload.ParentHash != p.last.BlockHash {
+		return nil
+	}
+	return payload
+}
+
+// served registers the payload as last served payload, and schedules the prefetching of the next payloads
+// if the payload directly follows the previous served payload.
+func (p *payloadPrefetcher) served(payload *eth.ExecutionPayload) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	number := uint64(payload.BlockNumber)
+	sequential := p.last != nil && uint64(p.last.BlockNumber)+1 == number
+	p.last = payload
+	for n := range p.payloads {
+		if n <= number || n > number+p.depth {
+			delete(p.payloads, n)
+		}
+	}
+	if !sequential {
+		return
+	}
+	for n := number + 1; n <= number+p.depth; n++ {
+		if _, ok := p.payloads[n]; ok {
+			continue
+		}
+		if _, ok := p.pending[n]; ok {
+			continue
+		}
+		select {
+		case p.workers <- struct{}{}:
+		default:
+			// all workers are busy, the remaining payloads are scheduled on the next request
+			return
+		}
+		p.pending[n] = struct{}{}
+		p.wg.Add(1)
+		go p.prefetch(p.genCtx, n, p.generation)
+	}
+}
+
+func (p *payloadPrefetcher) prefetch(genCtx context.Context, number uint64, generation uint64) {
+	defer p.wg.Done()
+	defer func() { <-p.workers }()
+	ctx, cancel := context.WithTimeout(genCtx, prefetchTimeout)
+	defer cancel()
+	payload, err := p.fetch(ctx, number)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the pending and prefetched payloads of a previous generation were dropped on reset
+	if generation != p.generation {
+		return
+	}
+	delete(p.pending, number)
+	if err != nil {
+		if genCtx.Err() == nil {
+			p.log.Debug("Failed to prefetch payload", "number", number, "err", err)
+		}
+		return
+	}
+	if p.last == nil || number <= uint64(p.last.BlockNumber) {
+		return
+	}
+	p.payloads[number] = payload
+	if p.metrics != nil {
+		p.metrics.CacheAdd("prefetch", len(p.payloads), false)
+	}
+}
+
+// Reset drops all prefetched payloads, e.g. when the chain may have changed,
+// and cancels the in-flight prefetch requests. Results of requests that still complete are dropped.
+func (p *payloadPrefetcher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.genCancel()
+	p.genCtx, p.genCancel = context.WithCancel(p.ctx)
+	p.generation++
+	p.last = nil
+	p.payloads = make(map[uint64]*eth.ExecutionPayload)
+	p.pending = make(map[uint64]struct{})
+}
+
+// Close cancels all in-flight prefetch requests, and waits for them to return.
+func (p *payloadPrefetcher) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
@@ -0,0 +1,203 @@
+package sources
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+// fakeChain serves payloads of a chain by number, and counts the fetches of each number.
+type fakeChain struct {
+	mu       sync.Mutex
+	payloads []*eth.ExecutionPayload
+	fetches  map[uint64]int
+}
+
+func newFakeChain(rng *rand.Rand, n int) *fakeChain {
+	c := &fakeChain{fetches: make(map[uint64]int)}
+	parent := testutils.RandomHash(rng)
+	for i := 0; i < n; i++ {
+		c.payloads = append(c.payloads, &eth.ExecutionPayload{
+			ParentHash:  parent,
+			BlockNumber: eth.Uint64Quantity(i),
+			BlockHash:   testutils.RandomHash(rng),
+		})
+		parent = c.payloads[i].BlockHash
+	}
+	return c
+}
+
+func (c *fakeChain) PayloadByNumber(ctx context.Context, number uint64) (*eth.ExecutionPayload, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetches[number]++
+	return c.payloads[number], nil
+}
+
+func (c *fakeChain) fetchCount(number uint64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches[number]
+}
+
+// reorg replaces the payloads from the given number onwards.
+func (c *fakeChain) reorg(rng *rand.Rand, from uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := from; i < uint64(len(c.payloads)); i++ {
+		c.payloads[i] = &eth.ExecutionPayload{
+			ParentHash:  c.payloads[i-1].BlockHash,
+			BlockNumber: eth.Uint64Quantity(i),
+			BlockHash:   testutils.RandomHash(rng),
+		}
+	}
+}
+
+type prefetchMetrics struct {
+	mu   sync.Mutex
+	hits int
+}
+
+func (m *prefetchMetrics) CacheAdd(label string, cacheSize int, evicted bool) {}
+
+func (m *prefetchMetrics) CacheGet(label string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	}
+}
+
+func TestPayloadPrefetcher(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := newFakeChain(rng, 100)
+	m := &prefetchMetrics{}
+	p := newPayloadPrefetcher(testlog.Logger(t, log.LvlError), m, chain.PayloadByNumber, 4, 2)
+	defer p.Close()
+
+	waitPrefetched := func(number uint64) {
+		require.Eventually(t, func() bool {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			_, ok := p.payloads[number]
+			return ok
+		}, time.Second, time.Millisecond)
+	}
+
+	for i := uint64(0); i < 10; i++ {
+		payload, err := p.PayloadByNumber(context.Background(), i)
+		require.NoError(t, err)
+		require.Equal(t, chain.payloads[i], payload)
+		if i > 0 {
+			waitPrefetched(i + 1)
+		}
+	}
+	for i := uint64(0); i < 10; i++ {
+		require.Equal(t, 1, chain.fetchCount(i), "payload %d is fetched only once", i)
+	}
+	require.Equal(t, 8, m.hits, "payloads 2 to 9 were prefetched")
+
+	// random access does not trigger prefetching
+	_, err := p.PayloadByNumber(context.Background(), 50)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 20)
+	require.Zero(t, chain.fetchCount(51))
+
+	// prefetched payloads that were reorged out are not served
+	_, err = p.PayloadByNumber(context.Background(), 51)
+	require.NoError(t, err)
+	waitPrefetched(52)
+	chain.reorg(rng, 51)
+	p.Reset()
+	payload, err := p.PayloadByNumber(context.Background(), 51)
+	require.NoError(t, err)
+	require.Equal(t, chain.payloads[51], payload)
+	_, err = p.PayloadByNumber(context.Background(), 52)
+	require.NoError(t, err)
+	waitPrefetched(53)
+	payload, err = p.PayloadByNumber(context.Background(), 53)
+	require.NoError(t, err)
+	require.Equal(t, chain.payloads[53], payload)
+}
+
+func TestPayloadPrefetcherParentMismatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := newFakeChain(rng, 10)
+	p := newPayloadPrefetcher(testlog.Logger(t, log.LvlError), nil, chain.PayloadByNumber, 2, 2)
+	defer p.Close()
+
+	_, err := p.PayloadByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	_, err = p.PayloadByNumber(context.Background(), 2)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.payloads[3]; !ok {
+			return false
+		}
+		// replace the prefetched payload 3 with one that does not build on payload 2
+		p.payloads[3] = &eth.ExecutionPayload{BlockNumber: 3, ParentHash: common.Hash{0xaa}}
+		return true
+	}, time.Second, time.Millisecond)
+	payload, err := p.PayloadByNumber(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, chain.payloads[3], payload)
+}
+
+func TestPayloadPrefetcherReset(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	chain := newFakeChain(rng, 10)
+	started := make(chan uint64, 10)
+	canceled := make(chan uint64, 10)
+	fetch := func(ctx context.Context, number uint64) (*eth.ExecutionPayload, error) {
+		if number <= 2 {
+			return chain.PayloadByNumber(ctx, number)
+		}
+		// block prefetch requests until they are canceled
+		started <- number
+		<-ctx.Done()
+		canceled <- number
+		return nil, ctx.Err()
+	}
+	p := newPayloadPrefetcher(testlog.Logger(t, log.LvlError), nil, fetch, 1, 2)
+
+	_, err := p.PayloadByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	_, err = p.PayloadByNumber(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), <-started)
+
+	p.Reset()
+	select {
+	case n := <-canceled:
+		require.Equal(t, uint64(3), n)
+	case <-time.After(time.Second):
+		t.Fatal("expected in-flight prefetch to be canceled on reset")
+	}
+
+	// the prefetch of the next generation is not affected by the canceled prefetch
+	_, err = p.PayloadByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	_, err = p.PayloadByNumber(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), <-started)
+	p.mu.Lock()
+	_, pending := p.pending[3]
+	p.mu.Unlock()
+	require.True(t, pending)
+
+	p.Close()
+	require.Equal(t, uint64(3), <-canceled, "expected close to cancel and wait for in-flight prefetch")
+	require.Empty(t, p.payloads)
+}
@@ -0,0 +1,228 @@
+package testutils
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// RecordedMetric is a call to a RecordingMetrics method.
+type RecordedMetric struct {
+	Time   time.Time
+	Method string
+	// Name is the name of the recorded ref, or the reason of a rejection, if any
+	Name string
+	// Value is the recorded value, if any, e.g. an eth.L1BlockRef, eth.L2BlockRef or size
+	Value any
+}
+
+// RecordingMetrics implements the metrics of the rollup driver and derivation pipeline,
+// and records all calls, for tests to assert the recorded metrics against.
+// It is safe for concurrent use.
+type RecordingMetrics struct {
+	mu      sync.Mutex
+	records []RecordedMetric
+}
+
+func (m *RecordingMetrics) record(method string, name string, value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, RecordedMetric{Time: time.Now(), Method: method, Name: name, Value: value})
+}
+
+func (m *RecordingMetrics) RecordPipelineReset() {
+	m.record("RecordPipelineReset", "", nil)
+}
+
+func (m *RecordingMetrics) RecordSequencingError() {
+	m.record("RecordSequencingError", "", nil)
+}
+
+func (m *RecordingMetrics) RecordSequencerBuildDeadlineMissed() {
+	m.record("RecordSequencerBuildDeadlineMissed", "", nil)
+}
+
+func (m *RecordingMetrics) RecordSequencerL1OriginAge(blocks uint64) {
+	m.record("RecordSequencerL1OriginAge", "", blocks)
+}
+
+func (m *RecordingMetrics) RecordPublishingError() {
+	m.record("RecordPublishingError", "", nil)
+}
+
+func (m *RecordingMetrics) RecordDerivationError() {
+	m.record("RecordDerivationError", "", nil)
+}
+
+func (m *RecordingMetrics) RecordReceivedUnsafePayload(payload *eth.ExecutionPayload) {
+	m.record("RecordReceivedUnsafePayload", "", payload.ID())
+}
+
+func (m *RecordingMetrics) RecordL1Ref(name string, ref eth.L1BlockRef) {
+	m.record("RecordL1Ref", name, ref)
+}
+
+func (m *RecordingMetrics) RecordL2Ref(name string, ref eth.L2BlockRef) {
+	m.record("RecordL2Ref", name, ref)
+}
+
+func (m *RecordingMetrics) RecordUnsafePayloadsBuffer(length uint64, memSize uint64, next eth.BlockID) {
+	m.record("RecordUnsafePayloadsBuffer", "", length)
+}
+
+func (m *RecordingMetrics) RecordUnsafePayloadRejected(reason string) {
+	m.record("RecordUnsafePayloadRejected", reason, nil)
+}
+
+func (m *RecordingMetrics) RecordDerivationOrigin(origin eth.L1BlockRef) {
+	m.record("RecordDerivationOrigin", "", origin)
+}
+
+func (m *RecordingMetrics) RecordProcessedBatch(l2Time uint64) {
+	m.record("RecordProcessedBatch", "", l2Time)
+}
+
+func (m *RecordingMetrics) RecordUnsafeBackfill(result string, payloads int) {
+	m.record("RecordUnsafeBackfill", result, payloads)
+}
+
+func (m *RecordingMetrics) RecordUnsafePayloadBatch(payloads int) {
+	m.record("RecordUnsafePayloadBatch", "", payloads)
+}
+
+func (m *RecordingMetrics) RecordGossipPayloadSize(direction string, wireSize int, size int) {
+	m.record("RecordGossipPayloadSize", direction, [2]int{wireSize, size})
+}
+
+func (m *RecordingMetrics) RecordPeerPenalty(reason string) {
+	m.record("RecordPeerPenalty", reason, nil)
+}
+
+func (m *RecordingMetrics) SetBannedPeers(count int) {
+	m.record("SetBannedPeers", "", count)
+}
+
+func (m *RecordingMetrics) RecordPeerDial(source string, success bool) {
+	m.record("RecordPeerDial", source, success)
+}
+
+func (m *RecordingMetrics) RecordConsolidationMismatch(field string) {
+	m.record("RecordConsolidationMismatch", field, nil)
+}
+
+func (m *RecordingMetrics) RecordChannelBankSize(size uint64) {
+	m.record("RecordChannelBankSize", "", size)
+}
+
+func (m *RecordingMetrics) RecordChannelBankEviction() {
+	m.record("RecordChannelBankEviction", "", nil)
+}
+
+func (m *RecordingMetrics) RecordDroppedFrame(reason string) {
+	m.record("RecordDroppedFrame", reason, nil)
+}
+
+func (m *RecordingMetrics) RecordDroppedChannel(reason string) {
+	m.record("RecordDroppedChannel", reason, nil)
+}
+
+func (m *RecordingMetrics) RecordDroppedBatch(reason string) {
+	m.record("RecordDroppedBatch", reason, nil)
+}
+
+func (m *RecordingMetrics) RecordDepositsPending(count uint64) {
+	m.record("RecordDepositsPending", "", count)
+}
+
+func (m *RecordingMetrics) RecordDepositsIncluded(count uint64, latency time.Duration) {
+	m.record("RecordDepositsIncluded", "", latency)
+}
+
+func (m *RecordingMetrics) SetDerivationIdle(idle bool) {
+	m.record("SetDerivationIdle", "", idle)
+}
+
+func (m *RecordingMetrics) RecordDerivationStep(d time.Duration) {
+	m.record("RecordDerivationStep", "", d)
+}
+
+func (m *RecordingMetrics) RecordDerivationYield() {
+	m.record("RecordDerivationYield", "", nil)
+}
+
+func (m *RecordingMetrics) SetSequencerThrottled(throttled bool) {
+	m.record("SetSequencerThrottled", "", throttled)
+}
+
+func (m *RecordingMetrics) RecordL1ReorgDepth(d uint64) {
+	m.record("RecordL1ReorgDepth", "", d)
+}
+
+func (m *RecordingMetrics) CountSequencedTxs(count int) {
+	m.record("CountSequencedTxs", "", count)
+}
+
+// Records returns all recorded calls of the given method, in order.
+func (m *RecordingMetrics) Records(method string) []RecordedMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []RecordedMetric
+	for _, r := range m.records {
+		if r.Method == method {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Count returns the number of recorded calls of the given method.
+func (m *RecordingMetrics) Count(method string) int {
+	return len(m.Records(method))
+}
+
+// L2Refs returns the L2 block refs recorded with the given name, e.g. "l2_safe", in order.
+func (m *RecordingMetrics) L2Refs(name string) []eth.L2BlockRef {
+	var out []eth.L2BlockRef
+	for _, r := range m.Records("RecordL2Ref") {
+		if r.Name == name {
+			out = append(out, r.Value.(eth.L2BlockRef))
+		}
+	}
+	return out
+}
+
+// L1Refs returns the L1 block refs recorded with the given name, e.g. "l1_derived", in order.
+func (m *RecordingMetrics) L1Refs(name string) []eth.L1BlockRef {
+	var out []eth.L1BlockRef
+	for _, r := range m.Records("RecordL1Ref") {
+		if r.Name == name {
+			out = append(out, r.Value.(eth.L1BlockRef))
+		}
+	}
+	return out
+}
+
+// Reset drops all recorded calls.
+func (m *RecordingMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = nil
+}
+
+// RequireCount asserts the given method was called exactly n times.
+func (m *RecordingMetrics) RequireCount(t *testing.T, method string, n int) {
+	t.Helper()
+	require.Equal(t, n, m.Count(method), "expected %d calls of %s", n, method)
+}
+
+// RequireLastL2Ref asserts the last L2 block ref recorded with the given name.
+func (m *RecordingMetrics) RequireLastL2Ref(t *testing.T, name string, ref eth.L2BlockRef) {
+	t.Helper()
+	refs := m.L2Refs(name)
+	require.NotEmpty(t, refs, "expected %s to be recorded", name)
+	require.Equal(t, ref, refs[len(refs)-1], "unexpected last %s", name)
+}
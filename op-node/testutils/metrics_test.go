@@ -0,0 +1,45 @@
+package testutils
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+var (
+	_ derive.Metrics = (*RecordingMetrics)(nil)
+	_ driver.Metrics = (*RecordingMetrics)(nil)
+)
+
+func TestRecordingMetrics(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	m := &RecordingMetrics{}
+	a, b := RandomL2BlockRef(rng), RandomL2BlockRef(rng)
+	l1 := RandomBlockRef(rng)
+
+	m.RecordPipelineReset()
+	m.RecordL2Ref("l2_safe", a)
+	m.RecordL2Ref("l2_unsafe", b)
+	m.RecordL2Ref("l2_safe", b)
+	m.RecordL1Ref("l1_derived", l1)
+	m.RecordUnsafePayloadRejected("too_old")
+
+	m.RequireCount(t, "RecordPipelineReset", 1)
+	m.RequireCount(t, "RecordL2Ref", 3)
+	m.RequireCount(t, "RecordDerivationError", 0)
+	m.RequireLastL2Ref(t, "l2_safe", b)
+	require.Equal(t, []eth.L2BlockRef{a, b}, m.L2Refs("l2_safe"))
+	require.Equal(t, l1, m.L1Refs("l1_derived")[0])
+	require.Equal(t, "too_old", m.Records("RecordUnsafePayloadRejected")[0].Name)
+
+	records := m.Records("RecordL2Ref")
+	require.False(t, records[2].Time.Before(records[0].Time), "records are in order")
+
+	m.Reset()
+	m.RequireCount(t, "RecordPipelineReset", 0)
+}
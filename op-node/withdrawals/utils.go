@@ -10,6 +10,8 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -228,6 +230,13 @@ func FinalizeWithdrawalParameters(ctx context.Context, l2client ProofClient, txH
 	}, nil
 }
 
+// OutputRoot computes the output root that the output root proof commits to.
+// It must match the output root proposed for the L2 block, e.g. as returned by optimism_outputAtBlock,
+// for the withdrawal to be provable against that proposal.
+func OutputRoot(proof bindings.TypesOutputRootProof) eth.Bytes32 {
+	return rollup.ComputeL2OutputRoot(eth.Bytes32(proof.Version), proof.LatestBlockhash, proof.StateRoot, proof.WithdrawerStorageRoot)
+}
+
 // Standard ABI types copied from golang ABI tests
 var (
 	Uint256Type, _ = abi.NewType("uint256", "", nil)
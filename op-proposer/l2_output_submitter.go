@@ -51,7 +51,10 @@ func Main(version string) func(ctx *cli.Context) error {
 		l := oplog.NewLogger(cfg.LogConfig)
 		l.Info("Initializing L2 Output Submitter")
 
-		l2OutputSubmitter, err := NewL2OutputSubmitter(cfg, version, l)
+		registry := opmetrics.NewRegistry()
+		m := txmgr.NewMetrics("op_proposer", registry)
+
+		l2OutputSubmitter, err := NewL2OutputSubmitter(cfg, version, l, m)
 		if err != nil {
 			l.Error("Unable to create L2 Output Submitter", "error", err)
 			return err
@@ -78,7 +81,6 @@ func Main(version string) func(ctx *cli.Context) error {
 			}()
 		}
 
-		registry := opmetrics.NewRegistry()
 		metricsCfg := cfg.MetricsConfig
 		if metricsCfg.Enabled {
 			l.Info("starting metrics server", "addr", metricsCfg.ListenAddr, "port", metricsCfg.ListenPort)
@@ -127,6 +129,7 @@ func NewL2OutputSubmitter(
 	cfg Config,
 	gitVersion string,
 	l log.Logger,
+	m txmgr.Metricer,
 ) (*L2OutputSubmitter, error) {
 
 	ctx := context.Background()
@@ -193,6 +196,7 @@ func NewL2OutputSubmitter(
 		ReceiptQueryInterval:      time.Second,
 		NumConfirmations:          cfg.NumConfirmations,
 		SafeAbortNonceTooLowCount: cfg.SafeAbortNonceTooLowCount,
+		Metrics:                   m,
 	}
 
 	l2OutputDriver, err := l2output.NewDriver(l2output.Config{
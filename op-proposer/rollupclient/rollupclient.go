@@ -37,6 +37,12 @@ func (r *RollupClient) RollupConfig(ctx context.Context) (*rollup.Config, error)
 	return output, err
 }
 
+func (r *RollupClient) RollupConfigHash(ctx context.Context) (*rollup.ConfigHashResult, error) {
+	var output *rollup.ConfigHashResult
+	err := r.rpc.CallContext(ctx, &output, "optimism_rollupConfigHash")
+	return output, err
+}
+
 func (r *RollupClient) Version(ctx context.Context) (string, error) {
 	var output string
 	err := r.rpc.CallContext(ctx, &output, "optimism_version")
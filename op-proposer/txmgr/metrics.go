@@ -0,0 +1,80 @@
+package txmgr
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metricer records the transactions sent by a tx manager.
+type Metricer interface {
+	// RecordTxPublished records a published transaction, bumped if it replaces an earlier publication with a higher fee.
+	RecordTxPublished(bumped bool)
+	// RecordTxConfirmed records a transaction that reached the confirmation depth, with the time it took since Send.
+	RecordTxConfirmed(latency time.Duration)
+	// RecordTxReorged records a mined transaction that is no longer mined, e.g. because of an L1 reorg.
+	RecordTxReorged()
+}
+
+// Metrics is a Metricer that records to prometheus, in the given namespace.
+type Metrics struct {
+	TxPublished        *prometheus.CounterVec
+	TxConfirmed        prometheus.Counter
+	TxConfirmedLatency prometheus.Histogram
+	TxReorged          prometheus.Counter
+}
+
+func NewMetrics(ns string, registry *prometheus.Registry) *Metrics {
+	return &Metrics{
+		TxPublished: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "txmgr_published_total",
+			Help:      "Count of published transactions, by whether the transaction was fee bumped",
+		}, []string{
+			"bumped",
+		}),
+		TxConfirmed: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "txmgr_confirmed_total",
+			Help:      "Count of transactions that reached the confirmation depth",
+		}),
+		TxConfirmedLatency: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "txmgr_confirmed_latency_seconds",
+			Help:      "Histogram of the time from sending a transaction until it reached the confirmation depth",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		}),
+		TxReorged: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "txmgr_reorged_total",
+			Help:      "Count of mined transactions that were no longer mined when checking for confirmations, e.g. after an L1 reorg",
+		}),
+	}
+}
+
+func (m *Metrics) RecordTxPublished(bumped bool) {
+	if bumped {
+		m.TxPublished.WithLabelValues("true").Inc()
+	} else {
+		m.TxPublished.WithLabelValues("false").Inc()
+	}
+}
+
+func (m *Metrics) RecordTxConfirmed(latency time.Duration) {
+	m.TxConfirmed.Inc()
+	m.TxConfirmedLatency.Observe(latency.Seconds())
+}
+
+func (m *Metrics) RecordTxReorged() {
+	m.TxReorged.Inc()
+}
+
+type noopMetrics struct{}
+
+// NoopMetrics discards all metrics.
+var NoopMetrics Metricer = noopMetrics{}
+
+func (noopMetrics) RecordTxPublished(bool)          {}
+func (noopMetrics) RecordTxConfirmed(time.Duration) {}
+func (noopMetrics) RecordTxReorged()                {}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -46,6 +47,10 @@ type Config struct {
 	// are required to give up on a tx at a particular nonce without receiving
 	// confirmation.
 	SafeAbortNonceTooLowCount uint64
+
+	// Metrics records the published and confirmed transactions.
+	// Metrics are discarded if nil.
+	Metrics Metricer
 }
 
 // TxManager is an interface that allows callers to reliably publish txs,
@@ -95,6 +100,9 @@ func NewSimpleTxManager(
 	if cfg.NumConfirmations == 0 {
 		panic("txmgr: NumConfirmations cannot be zero")
 	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = NoopMetrics
+	}
 
 	return &SimpleTxManager{
 		name:    name,
@@ -117,6 +125,7 @@ func (m *SimpleTxManager) Send(
 ) (*types.Receipt, error) {
 
 	name := m.name
+	start := time.Now()
 
 	// Initialize a wait group to track any spawned goroutines, and ensure
 	// we properly clean up any dangling resources this method generates.
@@ -132,6 +141,9 @@ func (m *SimpleTxManager) Send(
 
 	sendState := NewSendState(m.cfg.SafeAbortNonceTooLowCount)
 
+	// The first publication is at the initial gas price, every later one is fee bumped.
+	var publications int32
+
 	// Create a closure that will block on passed sendTx function in the
 	// background, returning the first successfully mined receipt back to
 	// the main event loop via receiptChan.
@@ -174,12 +186,13 @@ func (m *SimpleTxManager) Send(
 
 		m.l.Info(name+" transaction published successfully", "hash", txHash,
 			"nonce", nonce, "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
+		m.cfg.Metrics.RecordTxPublished(atomic.AddInt32(&publications, 1) > 1)
 
 		// Wait for the transaction to be mined, reporting the receipt
 		// back to the main event loop if found.
 		receipt, err := waitMined(
 			m.l, ctxc, m.backend, tx, m.cfg.ReceiptQueryInterval,
-			m.cfg.NumConfirmations, sendState,
+			m.cfg.NumConfirmations, sendState, m.cfg.Metrics,
 		)
 		if err != nil {
 			m.l.Debug(name+" send tx failed", "hash", txHash,
@@ -233,6 +246,7 @@ func (m *SimpleTxManager) Send(
 
 		// The transaction has confirmed.
 		case receipt := <-receiptChan:
+			m.cfg.Metrics.RecordTxConfirmed(time.Since(start))
 			return receipt, nil
 		}
 	}
@@ -249,11 +263,13 @@ func WaitMined(
 	queryInterval time.Duration,
 	numConfirmations uint64,
 ) (*types.Receipt, error) {
-	return waitMined(log.New(), ctx, backend, tx, queryInterval, numConfirmations, nil)
+	return waitMined(log.New(), ctx, backend, tx, queryInterval, numConfirmations, nil, NoopMetrics)
 }
 
 // waitMined implements the core functionality of WaitMined, with the option to
 // pass in a SendState to record whether or not the transaction is mined.
+// The receipt is queried again until the transaction is confirmed,
+// so a transaction that is reorged out before it is confirmed is waited on until it is mined again.
 func waitMined(
 	l log.Logger,
 	ctx context.Context,
@@ -262,12 +278,15 @@ func waitMined(
 	queryInterval time.Duration,
 	numConfirmations uint64,
 	sendState *SendState,
+	metrics Metricer,
 ) (*types.Receipt, error) {
 
 	queryTicker := time.NewTicker(queryInterval)
 	defer queryTicker.Stop()
 
 	txHash := tx.Hash()
+	// the block the transaction was last seen mined in, nil if it was not seen mined
+	var minedIn *common.Hash
 
 	for {
 		receipt, err := backend.TransactionReceipt(ctx, txHash)
@@ -276,6 +295,12 @@ func waitMined(
 			if sendState != nil {
 				sendState.TxMined(txHash)
 			}
+			if minedIn != nil && *minedIn != receipt.BlockHash {
+				l.Warn("Transaction was reorged into a different block", "txHash", txHash,
+					"prevBlock", *minedIn, "block", receipt.BlockHash)
+				metrics.RecordTxReorged()
+			}
+			minedIn = &receipt.BlockHash
 
 			txHeight := receipt.BlockNumber.Uint64()
 			tipHeight, err := backend.BlockNumber(ctx)
@@ -314,6 +339,12 @@ func waitMined(
 			if sendState != nil {
 				sendState.TxNotMined(txHash)
 			}
+			if minedIn != nil {
+				l.Warn("Transaction was reorged out, waiting for it to be mined again",
+					"txHash", txHash, "prevBlock", *minedIn)
+				metrics.RecordTxReorged()
+				minedIn = nil
+			}
 			l.Trace("Transaction not yet mined", "hash", txHash)
 		}
 
@@ -137,6 +137,15 @@ func (b *mockBackend) mine(txHash *common.Hash, gasFeeCap *big.Int) {
 	}
 }
 
+// reorg removes the txHash from the mined transactions, as if the block it was
+// mined in was reorged out.
+func (b *mockBackend) reorg(txHash common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.minedTxs, txHash)
+}
+
 // BlockNumber returns the most recent block number.
 func (b *mockBackend) BlockNumber(ctx context.Context) (uint64, error) {
 	b.mu.RLock()
@@ -475,6 +484,83 @@ func TestWaitMinedMultipleConfs(t *testing.T) {
 	require.Equal(t, txHash, receipt.TxHash)
 }
 
+// recordingMetrics is a txmgr.Metricer that counts the recorded events.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	published int
+	bumped    int
+	confirmed int
+	reorged   int
+}
+
+func (m *recordingMetrics) RecordTxPublished(bumped bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published++
+	if bumped {
+		m.bumped++
+	}
+}
+
+func (m *recordingMetrics) RecordTxConfirmed(latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.confirmed++
+}
+
+func (m *recordingMetrics) RecordTxReorged() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reorged++
+}
+
+// TestTxMgrWaitsForReorgedTx asserts that Send keeps waiting for confirmations
+// when a mined tx is reorged out, and returns the receipt once the tx is mined
+// again and confirmed.
+func TestTxMgrWaitsForReorgedTx(t *testing.T) {
+	t.Parallel()
+
+	metrics := new(recordingMetrics)
+	cfg := configWithNumConfs(2)
+	cfg.ResubmissionTimeout = 10 * time.Second
+	cfg.Metrics = metrics
+	h := newTestHarnessWithConfig(cfg)
+
+	tx := types.NewTx(&types.LegacyTx{})
+	txHash := tx.Hash()
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		return tx, nil
+	}
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		h.backend.mine(&txHash, new(big.Int))
+		return nil
+	}
+
+	go func() {
+		// Reorg the tx out after it was seen mined, and mine it again with enough confirmations.
+		time.Sleep(5 * cfg.ReceiptQueryInterval)
+		h.backend.reorg(txHash)
+		time.Sleep(5 * cfg.ReceiptQueryInterval)
+		h.backend.mine(&txHash, new(big.Int))
+		h.backend.mine(nil, nil)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Send(ctx, updateGasPrice, sendTx)
+	require.Nil(t, err)
+	require.NotNil(t, receipt)
+	require.Equal(t, txHash, receipt.TxHash)
+	require.Equal(t, uint64(2), receipt.BlockNumber.Uint64())
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	require.Equal(t, 1, metrics.published)
+	require.Equal(t, 0, metrics.bumped)
+	require.Equal(t, 1, metrics.confirmed)
+	require.Equal(t, 1, metrics.reorged)
+}
+
 // TestManagerPanicOnZeroConfs ensures that the NewSimpleTxManager will panic
 // when attempting to configure with NumConfirmations set to zero.
 func TestManagerPanicOnZeroConfs(t *testing.T) {